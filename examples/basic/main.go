@@ -25,15 +25,13 @@ func main() {
 
 	// Create a simple chat completion
 	fmt.Println("Sending chat completion request...")
-	resp, err := client.CreateChatCompletion(context.Background(), models.ChatCompletionRequest{
-		Model: "google/gemini-2.5-pro", // You can also try "anthropic/claude-3.5-sonnet"
-		Messages: []models.Message{
-			models.NewTextMessage(models.RoleSystem, "You are a helpful assistant."),
-			models.NewTextMessage(models.RoleUser, "What is the capital of France?"),
-		},
-		Temperature: float64Ptr(0.7),
-		MaxTokens:   intPtr(150), // Be careful with low limits on models that include reasoning
-	})
+	req := models.NewChatRequest("google/gemini-2.5-pro", // You can also try "anthropic/claude-3.5-sonnet"
+		models.WithSystemMessage("You are a helpful assistant."),
+		models.WithUserMessage("What is the capital of France?"),
+		models.WithTemperature(0.7),
+		models.WithMaxTokens(150), // Be careful with low limits on models that include reasoning
+	)
+	resp, err := client.CreateChatCompletion(context.Background(), req)
 
 	if err != nil {
 		log.Fatalf("Error creating completion: %v", err)
@@ -75,11 +73,3 @@ func getMessageContent(msg *models.Message) string {
 	content, _ := msg.GetTextContent()
 	return content
 }
-
-func float64Ptr(f float64) *float64 {
-	return &f
-}
-
-func intPtr(i int) *int {
-	return &i
-}
@@ -78,8 +78,8 @@ func observableExample(apiKey string) {
 			Messages: []models.Message{
 				models.NewTextMessage(models.RoleUser, fmt.Sprintf("What is %d + %d?", i, i+1)),
 			},
-			MaxTokens:   intPtr(50),
-			Temperature: float64Ptr(0),
+			MaxTokens:   models.Ptr(50),
+			Temperature: models.Ptr(0.0),
 		})
 
 		if err != nil {
@@ -189,7 +189,7 @@ func advancedRoutingExample(apiKey string) {
 		Provider: models.NewProviderPreferences().
 			WithQuantizations(models.QuantizationFP16, models.QuantizationBF16).
 			WithDataCollection(models.DataCollectionDeny),
-		MaxTokens: intPtr(100),
+		MaxTokens: models.Ptr(100),
 	})
 
 	if err != nil {
@@ -299,7 +299,7 @@ func completeWorkflowExample(apiKey string) {
 				fmt.Sprintf("Create a detailed prompt for an image that visualizes this quantum computing breakthrough: %s",
 					summary.Breakthroughs[0].Title)),
 		},
-		MaxTokens: intPtr(150),
+		MaxTokens: models.Ptr(150),
 	})
 
 	if err != nil {
@@ -317,14 +317,6 @@ func completeWorkflowExample(apiKey string) {
 	fmt.Println(string(finalJSON))
 }
 
-func intPtr(i int) *int {
-	return &i
-}
-
-func float64Ptr(f float64) *float64 {
-	return &f
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
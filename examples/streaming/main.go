@@ -47,7 +47,7 @@ func basicStreaming(client *pkg.Client) {
 		Messages: []models.Message{
 			models.NewTextMessage(models.RoleUser, "Write a haiku about programming"),
 		},
-		Temperature: float64Ptr(0.7),
+		Temperature: models.Ptr(0.7),
 	})
 
 	if err != nil {
@@ -165,7 +165,3 @@ func concurrentStreaming(client *pkg.Client) {
 		}
 	}
 }
-
-func float64Ptr(f float64) *float64 {
-	return &f
-}
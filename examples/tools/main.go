@@ -77,10 +77,11 @@ func simpleToolExample(client *pkg.Client) {
 	}
 
 	// Check for tool calls
-	if len(resp.Choices) > 0 && len(resp.Choices[0].Message.ToolCalls) > 0 {
+	if toolCalls := resp.ToolCalls(); len(toolCalls) > 0 {
 		messages = append(messages, *resp.Choices[0].Message)
 
-		for _, toolCall := range resp.Choices[0].Message.ToolCalls {
+		results := make(map[string]string)
+		for _, toolCall := range toolCalls {
 			fmt.Printf("Tool called: %s\n", toolCall.Function.Name)
 			fmt.Printf("Arguments: %s\n", toolCall.Function.Arguments)
 
@@ -94,14 +95,16 @@ func simpleToolExample(client *pkg.Client) {
 			result := evaluateExpression(args.Expression)
 			fmt.Printf("Result: %s\n", result)
 
-			// Add tool result to messages
-			messages = append(messages, models.NewToolMessage(
-				toolCall.ID,
-				toolCall.Function.Name,
-				result,
-			))
+			results[toolCall.ID] = result
 		}
 
+		// Answer every call, in order, with its ID and name
+		toolMessages, err := models.PairToolResults(toolCalls, results)
+		if err != nil {
+			log.Fatalf("Error pairing tool results: %v", err)
+		}
+		messages = append(messages, toolMessages...)
+
 		// Get final response
 		finalResp, err := client.CreateChatCompletion(ctx, models.ChatCompletionRequest{
 			Model:    "openai/gpt-3.5-turbo",
@@ -0,0 +1,247 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: openrouter/v1/openrouter.proto
+
+package openrouterv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	OpenRouter_CreateChatCompletion_FullMethodName = "/openrouter.v1.OpenRouter/CreateChatCompletion"
+	OpenRouter_StreamChatCompletion_FullMethodName = "/openrouter.v1.OpenRouter/StreamChatCompletion"
+	OpenRouter_ListModels_FullMethodName           = "/openrouter.v1.OpenRouter/ListModels"
+	OpenRouter_GetCredits_FullMethodName           = "/openrouter.v1.OpenRouter/GetCredits"
+)
+
+// OpenRouterClient is the client API for OpenRouter service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// OpenRouter exposes chat completions, models and credits of an OpenRouter account
+type OpenRouterClient interface {
+	// CreateChatCompletion returns a complete response
+	CreateChatCompletion(ctx context.Context, in *ChatCompletionRequest, opts ...grpc.CallOption) (*ChatCompletionResponse, error)
+	// StreamChatCompletion streams the response as chunks carrying deltas
+	StreamChatCompletion(ctx context.Context, in *ChatCompletionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChatCompletionResponse], error)
+	ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error)
+	GetCredits(ctx context.Context, in *GetCreditsRequest, opts ...grpc.CallOption) (*GetCreditsResponse, error)
+}
+
+type openRouterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOpenRouterClient(cc grpc.ClientConnInterface) OpenRouterClient {
+	return &openRouterClient{cc}
+}
+
+func (c *openRouterClient) CreateChatCompletion(ctx context.Context, in *ChatCompletionRequest, opts ...grpc.CallOption) (*ChatCompletionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatCompletionResponse)
+	err := c.cc.Invoke(ctx, OpenRouter_CreateChatCompletion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *openRouterClient) StreamChatCompletion(ctx context.Context, in *ChatCompletionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChatCompletionResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OpenRouter_ServiceDesc.Streams[0], OpenRouter_StreamChatCompletion_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ChatCompletionRequest, ChatCompletionResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OpenRouter_StreamChatCompletionClient = grpc.ServerStreamingClient[ChatCompletionResponse]
+
+func (c *openRouterClient) ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListModelsResponse)
+	err := c.cc.Invoke(ctx, OpenRouter_ListModels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *openRouterClient) GetCredits(ctx context.Context, in *GetCreditsRequest, opts ...grpc.CallOption) (*GetCreditsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCreditsResponse)
+	err := c.cc.Invoke(ctx, OpenRouter_GetCredits_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OpenRouterServer is the server API for OpenRouter service.
+// All implementations must embed UnimplementedOpenRouterServer
+// for forward compatibility.
+//
+// OpenRouter exposes chat completions, models and credits of an OpenRouter account
+type OpenRouterServer interface {
+	// CreateChatCompletion returns a complete response
+	CreateChatCompletion(context.Context, *ChatCompletionRequest) (*ChatCompletionResponse, error)
+	// StreamChatCompletion streams the response as chunks carrying deltas
+	StreamChatCompletion(*ChatCompletionRequest, grpc.ServerStreamingServer[ChatCompletionResponse]) error
+	ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error)
+	GetCredits(context.Context, *GetCreditsRequest) (*GetCreditsResponse, error)
+	mustEmbedUnimplementedOpenRouterServer()
+}
+
+// UnimplementedOpenRouterServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedOpenRouterServer struct{}
+
+func (UnimplementedOpenRouterServer) CreateChatCompletion(context.Context, *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateChatCompletion not implemented")
+}
+func (UnimplementedOpenRouterServer) StreamChatCompletion(*ChatCompletionRequest, grpc.ServerStreamingServer[ChatCompletionResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamChatCompletion not implemented")
+}
+func (UnimplementedOpenRouterServer) ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListModels not implemented")
+}
+func (UnimplementedOpenRouterServer) GetCredits(context.Context, *GetCreditsRequest) (*GetCreditsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCredits not implemented")
+}
+func (UnimplementedOpenRouterServer) mustEmbedUnimplementedOpenRouterServer() {}
+func (UnimplementedOpenRouterServer) testEmbeddedByValue()                    {}
+
+// UnsafeOpenRouterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OpenRouterServer will
+// result in compilation errors.
+type UnsafeOpenRouterServer interface {
+	mustEmbedUnimplementedOpenRouterServer()
+}
+
+func RegisterOpenRouterServer(s grpc.ServiceRegistrar, srv OpenRouterServer) {
+	// If the following call pancis, it indicates UnimplementedOpenRouterServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&OpenRouter_ServiceDesc, srv)
+}
+
+func _OpenRouter_CreateChatCompletion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChatCompletionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpenRouterServer).CreateChatCompletion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpenRouter_CreateChatCompletion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpenRouterServer).CreateChatCompletion(ctx, req.(*ChatCompletionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpenRouter_StreamChatCompletion_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ChatCompletionRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OpenRouterServer).StreamChatCompletion(m, &grpc.GenericServerStream[ChatCompletionRequest, ChatCompletionResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OpenRouter_StreamChatCompletionServer = grpc.ServerStreamingServer[ChatCompletionResponse]
+
+func _OpenRouter_ListModels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListModelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpenRouterServer).ListModels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpenRouter_ListModels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpenRouterServer).ListModels(ctx, req.(*ListModelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpenRouter_GetCredits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCreditsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpenRouterServer).GetCredits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OpenRouter_GetCredits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpenRouterServer).GetCredits(ctx, req.(*GetCreditsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// OpenRouter_ServiceDesc is the grpc.ServiceDesc for OpenRouter service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OpenRouter_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "openrouter.v1.OpenRouter",
+	HandlerType: (*OpenRouterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateChatCompletion",
+			Handler:    _OpenRouter_CreateChatCompletion_Handler,
+		},
+		{
+			MethodName: "ListModels",
+			Handler:    _OpenRouter_ListModels_Handler,
+		},
+		{
+			MethodName: "GetCredits",
+			Handler:    _OpenRouter_GetCredits_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamChatCompletion",
+			Handler:       _OpenRouter_StreamChatCompletion_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "openrouter/v1/openrouter.proto",
+}
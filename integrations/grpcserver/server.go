@@ -0,0 +1,337 @@
+// Package grpcserver exposes chat completions, models and credits as a gRPC service
+// backed by a go-openrouter client, for platforms that standardize on gRPC:
+//
+//	srv := grpc.NewServer()
+//	openrouterv1.RegisterOpenRouterServer(srv, grpcserver.New(pkg.NewClient(apiKey)))
+//	srv.Serve(listener)
+//
+// The service is defined in proto/openrouter/v1/openrouter.proto; regenerate the
+// openrouterv1 package with `buf generate` after changing it. It is a separate module so
+// the SDK itself keeps no dependencies.
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rizome-dev/go-openrouter/integrations/grpcserver/openrouterv1"
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/errors"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// Client is the part of a go-openrouter client the server uses, implemented by
+// *pkg.Client and *pkg.ObservableClient
+type Client interface {
+	CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, req models.ChatCompletionRequest) (*streaming.ChatCompletionStreamReader, error)
+	ListModels(ctx context.Context, opts *pkg.ListModelsOptions) (*models.ModelsResponse, error)
+	GetCredits(ctx context.Context) (*models.CreditsResponse, error)
+}
+
+// Server implements openrouterv1.OpenRouterServer
+type Server struct {
+	openrouterv1.UnimplementedOpenRouterServer
+	client Client
+}
+
+// New creates a server sending requests through client
+func New(client Client) *Server {
+	return &Server{client: client}
+}
+
+// CreateChatCompletion implements openrouterv1.OpenRouterServer
+func (s *Server) CreateChatCompletion(ctx context.Context, in *openrouterv1.ChatCompletionRequest) (*openrouterv1.ChatCompletionResponse, error) {
+	req, err := chatRequest(in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp, err := s.client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return chatResponse(resp), nil
+}
+
+// StreamChatCompletion implements openrouterv1.OpenRouterServer
+func (s *Server) StreamChatCompletion(in *openrouterv1.ChatCompletionRequest, out openrouterv1.OpenRouter_StreamChatCompletionServer) error {
+	req, err := chatRequest(in)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	stream, err := s.client.CreateChatCompletionStream(out.Context(), req)
+	if err != nil {
+		return grpcError(err)
+	}
+	defer stream.Close()
+
+	for {
+		chunk, err := stream.Read()
+		if stderrors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return grpcError(err)
+		}
+		if err := out.Send(chatResponse(chunk)); err != nil {
+			return err
+		}
+	}
+}
+
+// ListModels implements openrouterv1.OpenRouterServer
+func (s *Server) ListModels(ctx context.Context, in *openrouterv1.ListModelsRequest) (*openrouterv1.ListModelsResponse, error) {
+	resp, err := s.client.ListModels(ctx, &pkg.ListModelsOptions{Category: in.GetCategory()})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	out := &openrouterv1.ListModelsResponse{}
+	for _, m := range resp.Data {
+		out.Models = append(out.Models, &openrouterv1.Model{
+			Id:                  m.ID,
+			Name:                m.Name,
+			Description:         m.Description,
+			ContextLength:       int64(m.ContextLength),
+			MaxCompletionTokens: int64(m.TopProvider.MaxCompletionTokens),
+			PromptPrice:         m.Pricing.Prompt,
+			CompletionPrice:     m.Pricing.Completion,
+			SupportedParameters: m.SupportedParams,
+		})
+	}
+	return out, nil
+}
+
+// GetCredits implements openrouterv1.OpenRouterServer
+func (s *Server) GetCredits(ctx context.Context, in *openrouterv1.GetCreditsRequest) (*openrouterv1.GetCreditsResponse, error) {
+	resp, err := s.client.GetCredits(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &openrouterv1.GetCreditsResponse{
+		TotalCredits: resp.Data.TotalCredits,
+		TotalUsage:   resp.Data.TotalUsage,
+	}, nil
+}
+
+// grpcError translates an SDK error into a gRPC status
+func grpcError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if ctxErr := status.FromContextError(err); ctxErr.Code() != codes.Unknown {
+		return ctxErr.Err()
+	}
+
+	var validation *models.ValidationError
+	if stderrors.As(err, &validation) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var apiErr *errors.APIError
+	if !stderrors.As(err, &apiErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+	code := codes.Internal
+	switch apiErr.Code {
+	case errors.ErrorCodeBadRequest:
+		code = codes.InvalidArgument
+	case errors.ErrorCodeUnauthorized:
+		code = codes.Unauthenticated
+	case errors.ErrorCodeInsufficientCredits:
+		code = codes.FailedPrecondition
+	case errors.ErrorCodeForbidden:
+		code = codes.PermissionDenied
+	case 404:
+		code = codes.NotFound
+	case errors.ErrorCodeTimeout, errors.ErrorCodeGatewayTimeout:
+		code = codes.DeadlineExceeded
+	case errors.ErrorCodeRateLimited:
+		code = codes.ResourceExhausted
+	case errors.ErrorCodeBadGateway, errors.ErrorCodeServiceUnavailable:
+		code = codes.Unavailable
+	}
+	return status.Error(code, apiErr.Error())
+}
+
+// chatRequest translates a gRPC request
+func chatRequest(in *openrouterv1.ChatCompletionRequest) (models.ChatCompletionRequest, error) {
+	req := models.ChatCompletionRequest{
+		Model:             in.GetModel(),
+		Models:            in.GetModels(),
+		User:              in.GetUser(),
+		Stop:              in.GetStop(),
+		Temperature:       in.Temperature,
+		TopP:              in.TopP,
+		FrequencyPenalty:  in.FrequencyPenalty,
+		PresencePenalty:   in.PresencePenalty,
+		RepetitionPenalty: in.RepetitionPenalty,
+	}
+	if in.MaxTokens != nil {
+		req.MaxTokens = models.Ptr(int(in.GetMaxTokens()))
+	}
+	if in.TopK != nil {
+		req.TopK = models.Ptr(int(in.GetTopK()))
+	}
+	if in.Seed != nil {
+		req.Seed = models.Ptr(int(in.GetSeed()))
+	}
+
+	for i, m := range in.GetMessages() {
+		msg, err := message(m)
+		if err != nil {
+			return req, fmt.Errorf("message %d: %w", i, err)
+		}
+		req.Messages = append(req.Messages, msg)
+	}
+
+	for _, tool := range in.GetTools() {
+		fn := tool.GetFunction()
+		params := json.RawMessage(fn.GetParametersJson())
+		if len(params) == 0 {
+			params = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		if !json.Valid(params) {
+			return req, fmt.Errorf("tool %s: parameters_json is not valid JSON", fn.GetName())
+		}
+		toolType := tool.GetType()
+		if toolType == "" {
+			toolType = "function"
+		}
+		req.Tools = append(req.Tools, models.Tool{
+			Type:     toolType,
+			Function: models.FunctionDescription{Name: fn.GetName(), Description: fn.GetDescription(), Parameters: params},
+		})
+	}
+	switch choice := in.GetToolChoice(); choice {
+	case "":
+	case string(models.ToolChoiceNone), string(models.ToolChoiceAuto), string(models.ToolChoiceRequired):
+		req.ToolChoice = models.StringToolChoice(choice)
+	default:
+		req.ToolChoice = models.NewFunctionToolChoice(choice)
+	}
+
+	if raw := in.GetProviderJson(); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &req.Provider); err != nil {
+			return req, fmt.Errorf("invalid provider_json: %w", err)
+		}
+	}
+	if raw := in.GetResponseFormatJson(); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &req.ResponseFormat); err != nil {
+			return req, fmt.Errorf("invalid response_format_json: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// message translates a gRPC message
+func message(in *openrouterv1.Message) (models.Message, error) {
+	role := models.Role(in.GetRole())
+	var msg models.Message
+	switch {
+	case role == models.RoleTool:
+		msg = models.NewToolMessage(in.GetToolCallId(), in.GetName(), in.GetContent())
+	case len(in.GetParts()) > 0:
+		var parts []models.Content
+		for _, part := range in.GetParts() {
+			switch p := part.GetPart().(type) {
+			case *openrouterv1.ContentPart_Text:
+				parts = append(parts, models.Text(p.Text))
+			case *openrouterv1.ContentPart_ImageUrl:
+				parts = append(parts, models.Image(p.ImageUrl.GetUrl(), p.ImageUrl.GetDetail()))
+			case *openrouterv1.ContentPart_File:
+				parts = append(parts, models.FileContent{
+					Type: models.ContentTypeFile,
+					File: models.File{Filename: p.File.GetFilename(), FileData: p.File.GetFileData()},
+				})
+			default:
+				return msg, fmt.Errorf("empty content part")
+			}
+		}
+		var err error
+		if msg, err = models.NewMultiContentMessage(role, parts...); err != nil {
+			return msg, err
+		}
+	default:
+		msg = models.NewTextMessage(role, in.GetContent())
+	}
+	msg.Name = in.GetName()
+	msg.Reasoning = in.GetReasoning()
+	for _, call := range in.GetToolCalls() {
+		msg.ToolCalls = append(msg.ToolCalls, models.ToolCall{
+			ID:       call.GetId(),
+			Type:     call.GetType(),
+			Function: models.FunctionCall{Name: call.GetFunction().GetName(), Arguments: call.GetFunction().GetArguments()},
+		})
+	}
+	return msg, nil
+}
+
+// chatResponse translates a response or stream chunk
+func chatResponse(resp *models.ChatCompletionResponse) *openrouterv1.ChatCompletionResponse {
+	out := &openrouterv1.ChatCompletionResponse{
+		Id:       resp.ID,
+		Model:    resp.Model,
+		Provider: resp.Provider,
+		Created:  resp.Created,
+	}
+	for _, choice := range resp.Choices {
+		out.Choices = append(out.Choices, &openrouterv1.Choice{
+			Index:              int32(choice.Index),
+			Message:            protoMessage(choice.Message),
+			Delta:              protoMessage(choice.Delta),
+			FinishReason:       choice.FinishReason,
+			NativeFinishReason: choice.NativeFinishReason,
+		})
+	}
+	if resp.Usage != nil {
+		out.Usage = &openrouterv1.Usage{
+			PromptTokens:     int32(resp.Usage.PromptTokens),
+			CompletionTokens: int32(resp.Usage.CompletionTokens),
+			TotalTokens:      int32(resp.Usage.TotalTokens),
+			Cost:             resp.Usage.Cost,
+		}
+	}
+	return out
+}
+
+// protoMessage translates a response message, nil for nil
+func protoMessage(msg *models.Message) *openrouterv1.Message {
+	if msg == nil {
+		return nil
+	}
+	out := &openrouterv1.Message{
+		Role:       string(msg.Role),
+		Name:       msg.Name,
+		ToolCallId: msg.ToolCallID,
+		Reasoning:  msg.Reasoning,
+	}
+	if text, err := msg.GetTextContent(); err == nil {
+		out.Content = text
+	} else if parts, err := msg.GetMultiContent(); err == nil {
+		for _, part := range parts {
+			switch p := part.(type) {
+			case models.TextContent:
+				out.Parts = append(out.Parts, &openrouterv1.ContentPart{Part: &openrouterv1.ContentPart_Text{Text: p.Text}})
+			case models.ImageContent:
+				out.Parts = append(out.Parts, &openrouterv1.ContentPart{Part: &openrouterv1.ContentPart_ImageUrl{
+					ImageUrl: &openrouterv1.ImageURL{Url: p.ImageURL.URL, Detail: p.ImageURL.Detail},
+				}})
+			}
+		}
+	}
+	for _, call := range msg.ToolCalls {
+		out.ToolCalls = append(out.ToolCalls, &openrouterv1.ToolCall{
+			Id:       call.ID,
+			Type:     call.Type,
+			Function: &openrouterv1.FunctionCall{Name: call.Function.Name, Arguments: call.Function.Arguments},
+		})
+	}
+	return out
+}
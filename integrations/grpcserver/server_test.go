@@ -0,0 +1,140 @@
+package grpcserver_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/rizome-dev/go-openrouter/integrations/grpcserver"
+	"github.com/rizome-dev/go-openrouter/integrations/grpcserver/openrouterv1"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// dial serves the service for srv's client over an in-memory connection
+func dial(t *testing.T, srv *openroutertest.Server) openrouterv1.OpenRouterClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	openrouterv1.RegisterOpenRouterServer(server, grpcserver.New(srv.Client()))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return openrouterv1.NewOpenRouterClient(conn)
+}
+
+func TestCreateChatCompletion(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("Hello over gRPC"))
+	client := dial(t, srv)
+
+	resp, err := client.CreateChatCompletion(context.Background(), &openrouterv1.ChatCompletionRequest{
+		Model: "openai/gpt-4o",
+		Messages: []*openrouterv1.Message{
+			{Role: "system", Content: "Be brief"},
+			{Role: "user", Parts: []*openrouterv1.ContentPart{
+				{Part: &openrouterv1.ContentPart_Text{Text: "What is this?"}},
+				{Part: &openrouterv1.ContentPart_ImageUrl{ImageUrl: &openrouterv1.ImageURL{Url: "https://example.com/cat.jpg"}}},
+			}},
+		},
+		MaxTokens:    proto.Int32(100),
+		Temperature:  proto.Float64(0),
+		Tools:        []*openrouterv1.Tool{{Function: &openrouterv1.FunctionDescription{Name: "lookup", ParametersJson: `{"type":"object"}`}}},
+		ToolChoice:   "lookup",
+		ProviderJson: `{"sort":"price"}`,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Choices, 1)
+	assert.Equal(t, "Hello over gRPC", resp.Choices[0].Message.Content)
+	assert.Equal(t, "assistant", resp.Choices[0].Message.Role)
+
+	req, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o", req.Model)
+	assert.Equal(t, 100, *req.MaxTokens)
+	require.NotNil(t, req.Temperature)
+	assert.Equal(t, 0.0, *req.Temperature)
+	parts, err := req.Messages[1].GetMultiContent()
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	assert.Equal(t, "lookup", req.Tools[0].Function.Name)
+	assert.Equal(t, models.NewFunctionToolChoice("lookup"), req.ToolChoice)
+	assert.Equal(t, models.SortByPrice, req.Provider.Sort)
+}
+
+func TestStreamChatCompletion(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("one two three"))
+	client := dial(t, srv)
+
+	stream, err := client.StreamChatCompletion(context.Background(), &openrouterv1.ChatCompletionRequest{
+		Model:    "m",
+		Messages: []*openrouterv1.Message{{Role: "user", Content: "count"}},
+	})
+	require.NoError(t, err)
+	var text string
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		for _, choice := range chunk.Choices {
+			text += choice.GetDelta().GetContent()
+		}
+	}
+	assert.Equal(t, "one two three", text)
+}
+
+func TestErrorsMapToStatusCodes(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.ErrorReply(429, "slow down"))
+	client := dial(t, srv)
+	req := &openrouterv1.ChatCompletionRequest{Model: "m", Messages: []*openrouterv1.Message{{Role: "user", Content: "hi"}}}
+
+	_, err := client.CreateChatCompletion(context.Background(), req)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = client.CreateChatCompletion(context.Background(), &openrouterv1.ChatCompletionRequest{Model: "m"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	req.ProviderJson = "{"
+	_, err = client.CreateChatCompletion(context.Background(), req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestModelsAndCredits(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetCredits(50, 12.5)
+	client := dial(t, srv)
+
+	list, err := client.ListModels(context.Background(), &openrouterv1.ListModelsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Models, 1)
+	assert.Equal(t, "openai/gpt-4o-mini", list.Models[0].Id)
+	assert.Equal(t, int64(128000), list.Models[0].ContextLength)
+
+	credits, err := client.GetCredits(context.Background(), &openrouterv1.GetCreditsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, credits.TotalCredits)
+	assert.Equal(t, 12.5, credits.TotalUsage)
+}
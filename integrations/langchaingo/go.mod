@@ -0,0 +1,20 @@
+module github.com/rizome-dev/go-openrouter/integrations/langchaingo
+
+go 1.24.4
+
+require (
+	github.com/rizome-dev/go-openrouter v0.0.0
+	github.com/stretchr/testify v1.10.0
+	github.com/tmc/langchaingo v0.1.14
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/dlclark/regexp2 v1.10.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/pkoukk/tiktoken-go v0.1.6 // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/rizome-dev/go-openrouter => ../..
@@ -0,0 +1,302 @@
+// Package langchaingo adapts go-openrouter clients to langchaingo's llms.Model, so existing
+// chains and agents can use OpenRouter routing, retries and observability:
+//
+//	client := pkg.NewObservableClient(apiKey, pkg.ObservabilityOptions{Logger: logger})
+//	llm := langchaingo.New(client, "anthropic/claude-3.5-sonnet")
+//	answer, err := llms.GenerateFromSinglePrompt(ctx, llm, "Hello!")
+//
+// It is a separate module so the SDK itself keeps no dependencies.
+package langchaingo
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// ChatClient is the part of a go-openrouter client the adapter uses, implemented by
+// *pkg.Client, *pkg.ObservableClient and *pkg.MultiKeyClient
+type ChatClient interface {
+	CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, req models.ChatCompletionRequest) (*streaming.ChatCompletionStreamReader, error)
+}
+
+// RequestOption adjusts every request the adapter sends, e.g.
+// models.WithProvider(models.PreferencesCheapest())
+type RequestOption = models.RequestOption
+
+// LLM implements llms.Model with a go-openrouter client
+type LLM struct {
+	client ChatClient
+	model  string
+	opts   []RequestOption
+}
+
+var _ llms.Model = (*LLM)(nil)
+
+// New creates an llms.Model sending requests for model through client. The model is
+// overridden by llms.WithModel.
+func New(client ChatClient, model string, opts ...RequestOption) *LLM {
+	return &LLM{client: client, model: model, opts: opts}
+}
+
+// Call implements llms.Model, sending prompt as a single user message
+func (l *LLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, l, prompt, options...)
+}
+
+// GenerateContent implements llms.Model. With llms.WithStreamingFunc or
+// llms.WithStreamingReasoningFunc the response is streamed, and the streaming function
+// receives content, and reasoning, as it arrives.
+func (l *LLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var opts llms.CallOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	req, err := l.request(messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *models.ChatCompletionResponse
+	if opts.StreamingFunc != nil || opts.StreamingReasoningFunc != nil {
+		resp, err = l.stream(ctx, req, opts)
+	} else {
+		resp, err = l.client.CreateChatCompletion(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return contentResponse(resp), nil
+}
+
+// stream sends req as a stream, passing deltas to the streaming functions, and assembles
+// the response
+func (l *LLM) stream(ctx context.Context, req models.ChatCompletionRequest, opts llms.CallOptions) (*models.ChatCompletionResponse, error) {
+	stream, err := l.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	acc := streaming.NewAccumulator()
+	for {
+		chunk, err := stream.Read()
+		if errors.Is(err, io.EOF) {
+			return acc.Response(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		acc.Add(chunk)
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta == nil {
+			continue
+		}
+		delta := chunk.Choices[0].Delta
+		text, _ := delta.GetTextContent()
+		if opts.StreamingReasoningFunc != nil && (text != "" || delta.Reasoning != "") {
+			if err := opts.StreamingReasoningFunc(ctx, []byte(delta.Reasoning), []byte(text)); err != nil {
+				return nil, fmt.Errorf("streaming function failed: %w", err)
+			}
+		}
+		if opts.StreamingFunc != nil && text != "" {
+			if err := opts.StreamingFunc(ctx, []byte(text)); err != nil {
+				return nil, fmt.Errorf("streaming function failed: %w", err)
+			}
+		}
+	}
+}
+
+// request translates langchaingo messages and options into a chat completion request
+func (l *LLM) request(messages []llms.MessageContent, opts llms.CallOptions) (models.ChatCompletionRequest, error) {
+	model := l.model
+	if opts.Model != "" {
+		model = opts.Model
+	}
+	req := models.NewChatRequest(model, l.opts...)
+
+	for _, mc := range messages {
+		msgs, err := convertMessage(mc)
+		if err != nil {
+			return req, err
+		}
+		req.Messages = append(req.Messages, msgs...)
+	}
+
+	// langchaingo can't tell unset parameters from zero ones, so zeros are left to the
+	// provider's defaults
+	if opts.MaxTokens > 0 {
+		req.MaxTokens = models.Ptr(opts.MaxTokens)
+	}
+	if opts.Temperature != 0 {
+		req.Temperature = models.Ptr(opts.Temperature)
+	}
+	if opts.TopP != 0 {
+		req.TopP = models.Ptr(opts.TopP)
+	}
+	if opts.TopK != 0 {
+		req.TopK = models.Ptr(opts.TopK)
+	}
+	if opts.Seed != 0 {
+		req.Seed = models.Ptr(opts.Seed)
+	}
+	if opts.FrequencyPenalty != 0 {
+		req.FrequencyPenalty = models.Ptr(opts.FrequencyPenalty)
+	}
+	if opts.PresencePenalty != 0 {
+		req.PresencePenalty = models.Ptr(opts.PresencePenalty)
+	}
+	if opts.RepetitionPenalty != 0 {
+		req.RepetitionPenalty = models.Ptr(opts.RepetitionPenalty)
+	}
+	if len(opts.StopWords) > 0 {
+		req.Stop = opts.StopWords
+	}
+	if opts.JSONMode {
+		models.WithJSONMode()(&req)
+	}
+
+	for _, tool := range opts.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		converted, err := models.NewTool(tool.Function.Name, tool.Function.Description, tool.Function.Parameters)
+		if err != nil {
+			return req, fmt.Errorf("invalid tool %s: %w", tool.Function.Name, err)
+		}
+		req.Tools = append(req.Tools, *converted)
+	}
+	switch choice := opts.ToolChoice.(type) {
+	case string:
+		req.ToolChoice = models.StringToolChoice(choice)
+	case llms.ToolChoice:
+		if choice.Function != nil {
+			req.ToolChoice = models.NewFunctionToolChoice(choice.Function.Name)
+		} else if choice.Type != "" {
+			req.ToolChoice = models.StringToolChoice(choice.Type)
+		}
+	}
+	return req, nil
+}
+
+// convertMessage translates a langchaingo message. Tool results become one tool message
+// each.
+func convertMessage(mc llms.MessageContent) ([]models.Message, error) {
+	var role models.Role
+	switch mc.Role {
+	case llms.ChatMessageTypeSystem:
+		role = models.RoleSystem
+	case llms.ChatMessageTypeHuman, llms.ChatMessageTypeGeneric:
+		role = models.RoleUser
+	case llms.ChatMessageTypeAI:
+		role = models.RoleAssistant
+	case llms.ChatMessageTypeTool, llms.ChatMessageTypeFunction:
+		role = models.RoleTool
+	default:
+		return nil, fmt.Errorf("unsupported message role %q", mc.Role)
+	}
+
+	var parts []models.Content
+	var toolCalls []models.ToolCall
+	var results []models.Message
+	for _, part := range mc.Parts {
+		switch p := part.(type) {
+		case llms.TextContent:
+			parts = append(parts, models.Text(p.Text))
+		case llms.ImageURLContent:
+			parts = append(parts, models.Image(p.URL, p.Detail))
+		case llms.BinaryContent:
+			dataURL := "data:" + p.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(p.Data)
+			switch {
+			case strings.HasPrefix(p.MIMEType, "image/"):
+				parts = append(parts, models.Image(dataURL))
+			case p.MIMEType == "application/pdf":
+				parts = append(parts, models.PDF("document.pdf", p.Data))
+			default:
+				return nil, fmt.Errorf("unsupported binary content type %q", p.MIMEType)
+			}
+		case llms.ToolCall:
+			call := models.ToolCall{ID: p.ID, Type: "function"}
+			if p.FunctionCall != nil {
+				call.Function = models.FunctionCall{Name: p.FunctionCall.Name, Arguments: p.FunctionCall.Arguments}
+			}
+			toolCalls = append(toolCalls, call)
+		case llms.ToolCallResponse:
+			results = append(results, models.NewToolMessage(p.ToolCallID, p.Name, p.Content))
+		default:
+			return nil, fmt.Errorf("unsupported content part %T", part)
+		}
+	}
+
+	if role == models.RoleTool {
+		if len(parts) > 0 {
+			return nil, fmt.Errorf("tool messages must only contain tool call responses")
+		}
+		return results, nil
+	}
+	if len(results) > 0 {
+		return nil, fmt.Errorf("tool call responses must be sent in tool messages")
+	}
+
+	var msg models.Message
+	switch role {
+	case models.RoleSystem:
+		msg = models.System(parts...)
+	case models.RoleAssistant:
+		msg = models.Assistant(parts...)
+	default:
+		msg = models.User(parts...)
+	}
+	msg.ToolCalls = toolCalls
+	return []models.Message{msg}, nil
+}
+
+// contentResponse translates a chat completion response
+func contentResponse(resp *models.ChatCompletionResponse) *llms.ContentResponse {
+	result := &llms.ContentResponse{}
+	for _, choice := range resp.Choices {
+		if choice.Message == nil {
+			continue
+		}
+		text, _ := choice.Message.GetTextContent()
+		c := &llms.ContentChoice{
+			Content:          text,
+			StopReason:       choice.FinishReason,
+			ReasoningContent: choice.Message.Reasoning,
+			GenerationInfo: map[string]any{
+				"Model":    resp.Model,
+				"Provider": resp.Provider,
+			},
+		}
+		if resp.Usage != nil {
+			c.GenerationInfo["PromptTokens"] = resp.Usage.PromptTokens
+			c.GenerationInfo["CompletionTokens"] = resp.Usage.CompletionTokens
+			c.GenerationInfo["TotalTokens"] = resp.Usage.TotalTokens
+			c.GenerationInfo["Cost"] = resp.Usage.Cost
+		}
+		for _, call := range choice.Message.ToolCalls {
+			c.ToolCalls = append(c.ToolCalls, llms.ToolCall{
+				ID:   call.ID,
+				Type: call.Type,
+				FunctionCall: &llms.FunctionCall{
+					Name:      call.Function.Name,
+					Arguments: call.Function.Arguments,
+				},
+			})
+		}
+		if len(c.ToolCalls) > 0 {
+			c.FuncCall = c.ToolCalls[0].FunctionCall
+		}
+		result.Choices = append(result.Choices, c)
+	}
+	return result
+}
@@ -0,0 +1,102 @@
+package langchaingo_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/rizome-dev/go-openrouter/integrations/langchaingo"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestCall(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("Hello from OpenRouter"))
+
+	llm := langchaingo.New(srv.Client(), "openai/gpt-4o", models.WithUser("chain"))
+	answer, err := llm.Call(context.Background(), "Hi")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello from OpenRouter", answer)
+
+	req, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o", req.Model)
+	assert.Equal(t, "chain", req.User)
+}
+
+func TestGenerateContentStreaming(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("one two three"))
+
+	var streamed strings.Builder
+	resp, err := langchaingo.New(srv.Client(), "m").GenerateContent(context.Background(),
+		[]llms.MessageContent{{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{llms.TextContent{Text: "count"}}}},
+		llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			streamed.Write(chunk)
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "one two three", streamed.String())
+	require.Len(t, resp.Choices, 1)
+	assert.Equal(t, "one two three", resp.Choices[0].Content)
+
+	req, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.True(t, req.Stream)
+}
+
+func TestGenerateContentTranslatesToolsAndImages(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.ToolCallReply(models.ToolCall{
+		ID:       "call_2",
+		Type:     "function",
+		Function: models.FunctionCall{Name: "get_weather", Arguments: `{"city":"Oslo"}`},
+	}))
+
+	messages := []llms.MessageContent{
+		{Role: llms.ChatMessageTypeSystem, Parts: []llms.ContentPart{llms.TextContent{Text: "Be brief"}}},
+		{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{
+			llms.TextContent{Text: "Weather where this photo was taken?"},
+			llms.ImageURLContent{URL: "https://example.com/fjord.jpg"},
+		}},
+		{Role: llms.ChatMessageTypeAI, Parts: []llms.ContentPart{llms.ToolCall{
+			ID: "call_1", Type: "function", FunctionCall: &llms.FunctionCall{Name: "locate", Arguments: `{}`},
+		}}},
+		{Role: llms.ChatMessageTypeTool, Parts: []llms.ContentPart{llms.ToolCallResponse{ToolCallID: "call_1", Name: "locate", Content: "Oslo"}}},
+	}
+	tools := []llms.Tool{{Type: "function", Function: &llms.FunctionDefinition{
+		Name:        "get_weather",
+		Description: "Current weather",
+		Parameters:  map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}},
+	}}}
+
+	resp, err := langchaingo.New(srv.Client(), "m").GenerateContent(context.Background(), messages, llms.WithTools(tools))
+	require.NoError(t, err)
+	require.Len(t, resp.Choices, 1)
+	require.Len(t, resp.Choices[0].ToolCalls, 1)
+	assert.Equal(t, "get_weather", resp.Choices[0].ToolCalls[0].FunctionCall.Name)
+	assert.Equal(t, `{"city":"Oslo"}`, resp.Choices[0].FuncCall.Arguments)
+
+	req, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	require.Len(t, req.Messages, 4)
+	assert.Equal(t, models.RoleSystem, req.Messages[0].Role)
+	parts, err := req.Messages[1].GetMultiContent()
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	assert.Equal(t, "https://example.com/fjord.jpg", parts[1].(models.ImageContent).ImageURL.URL)
+	assert.Equal(t, "locate", req.Messages[2].ToolCalls[0].Function.Name)
+	assert.Equal(t, models.RoleTool, req.Messages[3].Role)
+	assert.Equal(t, "call_1", req.Messages[3].ToolCallID)
+	require.Len(t, req.Tools, 1)
+	assert.Equal(t, "get_weather", req.Tools[0].Function.Name)
+}
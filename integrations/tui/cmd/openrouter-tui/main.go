@@ -0,0 +1,62 @@
+// Command openrouter-tui is a terminal chat client for OpenRouter. Sessions are saved in
+// the user config directory, under openrouter/sessions, unless -sessions names another.
+//
+//	OPENROUTER_API_KEY=sk-or-... openrouter-tui -model openai/gpt-4o-mini -session notes
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rizome-dev/go-openrouter/integrations/tui"
+	"github.com/rizome-dev/go-openrouter/pkg"
+)
+
+func main() {
+	model := flag.String("model", tui.DefaultModel, "model new sessions start with")
+	session := flag.String("session", tui.DefaultSession, "session to open or create")
+	sessions := flag.String("sessions", "", "directory sessions are saved in")
+	system := flag.String("system", "", "system prompt of new sessions")
+	maxTokens := flag.Int("max-tokens", 0, "history budget in tokens; older messages are dropped past it")
+	flag.Parse()
+
+	apiKey := os.Getenv("OPENROUTER_API_KEY")
+	if apiKey == "" {
+		log.Fatal("Please set OPENROUTER_API_KEY environment variable")
+	}
+
+	if *sessions == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			log.Fatal(err)
+		}
+		*sessions = filepath.Join(dir, "openrouter", "sessions")
+	}
+	store, err := tui.NewSessionStore(*sessions)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := pkg.NewClient(apiKey,
+		pkg.WithHTTPReferer("https://github.com/rizome-dev/go-openrouter"),
+		pkg.WithXTitle("OpenRouterGo TUI"),
+	)
+	m, err := tui.New(client, tui.Options{
+		Model:        *model,
+		SystemPrompt: *system,
+		MaxTokens:    *maxTokens,
+		Sessions:     store,
+		Session:      *session,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run(); err != nil {
+		log.Fatal(err)
+	}
+}
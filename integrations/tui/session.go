@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// ErrSessionNotFound is returned by SessionStore.Load for sessions that were never saved
+var ErrSessionNotFound = errors.New("session not found")
+
+// sessionNamePattern keeps session names usable as file names
+var sessionNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// SessionStore keeps each session's history in a JSON file named after the session, in
+// the format of models.MarshalHistory, so sessions survive restarts
+type SessionStore struct {
+	dir string
+}
+
+// NewSessionStore creates a session store in dir, creating the directory if needed
+func NewSessionStore(dir string) (*SessionStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	return &SessionStore{dir: dir}, nil
+}
+
+// Save writes a session's history, replacing any previous version atomically
+func (s *SessionStore) Save(name string, messages []models.Message) error {
+	if err := validateSessionName(name); err != nil {
+		return err
+	}
+	data, err := models.MarshalHistory(messages)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path(name))
+}
+
+// Load reads a session's history
+func (s *SessionStore) Load(name string) ([]models.Message, error) {
+	if err := validateSessionName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.path(name))
+	if os.IsNotExist(err) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	messages, err := models.UnmarshalHistory(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode session %s: %w", name, err)
+	}
+	return messages, nil
+}
+
+// List returns the names of the saved sessions in order
+func (s *SessionStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if ok && !entry.IsDir() && sessionNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *SessionStore) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// validateSessionName rejects names that aren't plain file names
+func validateSessionName(name string) error {
+	if !sessionNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid session name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
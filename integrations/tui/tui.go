@@ -0,0 +1,721 @@
+// Package tui is a terminal chat client built on the SDK's Conversation, streaming and
+// model catalog APIs with Bubble Tea:
+//
+//	store, err := tui.NewSessionStore(filepath.Join(home, ".openrouter", "sessions"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	m, err := tui.New(pkg.NewClient(apiKey), tui.Options{Model: "openai/gpt-4o-mini", Sessions: store})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	_, err = tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run()
+//
+// Enter sends a message and its reply streams into the scrollback, which PgUp, PgDn and
+// the mouse wheel scroll. Esc stops a reply. Ctrl+O opens the model switcher, filled from
+// the model list. Slash commands manage sessions; /help lists them. The status bar shows
+// the model, its context length, and the tokens and cost of the session so far.
+//
+// The cmd/openrouter-tui command runs it with OPENROUTER_API_KEY. It is a separate module
+// so the SDK itself keeps no dependencies.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+const (
+	// DefaultModel is the model new sessions start with by default
+	DefaultModel = "openrouter/auto"
+
+	// DefaultSession is the session opened by default
+	DefaultSession = "default"
+
+	// catalogTimeout bounds fetching the model list
+	catalogTimeout = 30 * time.Second
+)
+
+// helpText lists the keys and slash commands
+const helpText = `Enter sends a message, Esc stops a reply, PgUp/PgDn scroll, Ctrl+O switches models, Ctrl+C quits.
+/model <id>     switch to a model
+/models         pick a model from the list
+/new [name]     start a new session
+/open <name>    open a saved session
+/sessions       list saved sessions
+/help           show this help
+/quit           quit`
+
+// Options configures a Model
+type Options struct {
+	// Model is the model new sessions start with. Defaults to DefaultModel.
+	Model string
+
+	// SystemPrompt starts every new session
+	SystemPrompt string
+
+	// MaxTokens is the history budget of each session's pkg.Conversation. Zero keeps the
+	// whole history.
+	MaxTokens int
+
+	// Sessions saves every session after each reply, so it can be reopened. Without it
+	// sessions last as long as the program.
+	Sessions *SessionStore
+
+	// Session names the session to open, created if it hasn't been saved. Defaults to
+	// DefaultSession.
+	Session string
+}
+
+// entry kinds of the scrollback
+const (
+	entryUser = iota
+	entryAssistant
+	entryNotice
+	entryError
+)
+
+// entry is a block of the scrollback
+type entry struct {
+	kind int
+	text string
+
+	// model wrote an assistant entry
+	model string
+}
+
+// Model is the Bubble Tea model of the chat client. Run it with tea.NewProgram.
+type Model struct {
+	client  *pkg.Client
+	catalog *pkg.ModelCatalog
+	opts    Options
+
+	conv    *pkg.Conversation
+	model   string
+	info    *models.Model
+	session string
+	entries []entry
+
+	// usage totals the tokens and cost of the session's replies
+	usage models.Usage
+
+	turn   *turn
+	picker *picker
+
+	viewport viewport.Model
+	input    textinput.Model
+	width    int
+}
+
+// turn is a reply being streamed
+type turn struct {
+	cancel context.CancelFunc
+	events chan tea.Msg
+}
+
+// Messages delivered to Update by commands
+type (
+	deltaMsg    string
+	turnDoneMsg struct {
+		usage *models.Usage
+		err   error
+	}
+	modelInfoMsg struct {
+		id    string
+		model *models.Model
+		err   error
+	}
+	modelListMsg struct {
+		models []models.Model
+		err    error
+	}
+)
+
+// New creates the chat client, opening the configured session
+func New(client *pkg.Client, opts Options) (*Model, error) {
+	if opts.Model == "" {
+		opts.Model = DefaultModel
+	}
+	if opts.Session == "" {
+		opts.Session = DefaultSession
+	}
+
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "Send a message, or /help"
+	input.Cursor.SetMode(cursor.CursorStatic)
+	input.Focus()
+
+	m := &Model{
+		client:   client,
+		catalog:  pkg.NewModelCatalog(client, 0),
+		opts:     opts,
+		model:    opts.Model,
+		viewport: viewport.New(0, 0),
+		input:    input,
+	}
+	if err := m.openSession(opts.Session); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Init implements tea.Model
+func (m *Model) Init() tea.Cmd {
+	return m.loadModelInfo()
+}
+
+// Update implements tea.Model
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.viewport.Width = msg.Width
+		m.viewport.Height = max(msg.Height-2, 1)
+		m.input.Width = max(msg.Width-len(m.input.Prompt)-1, 1)
+		m.refresh()
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+
+	case deltaMsg:
+		m.entries[len(m.entries)-1].text += string(msg)
+		m.refresh()
+		return m, m.turn.next()
+
+	case turnDoneMsg:
+		m.finishTurn(msg)
+		return m, nil
+
+	case modelInfoMsg:
+		if msg.id == m.model {
+			m.info = msg.model
+			if msg.err != nil {
+				m.notice(entryError, "Failed to load the model catalog: "+msg.err.Error())
+			}
+		}
+		return m, nil
+
+	case modelListMsg:
+		if m.picker != nil {
+			m.picker.setModels(msg.models, msg.err)
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// handleKey routes a key to the picker, the scrollback or the input
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		if m.turn != nil {
+			m.turn.cancel()
+		}
+		return m, tea.Quit
+	case tea.KeyPgUp:
+		m.viewport.PageUp()
+		return m, nil
+	case tea.KeyPgDown:
+		m.viewport.PageDown()
+		return m, nil
+	}
+
+	if m.picker != nil {
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.picker = nil
+		case tea.KeyUp:
+			m.picker.move(-1)
+		case tea.KeyDown:
+			m.picker.move(1)
+		case tea.KeyEnter:
+			if chosen, ok := m.picker.chosen(); ok {
+				m.picker = nil
+				return m, m.switchModel(chosen)
+			}
+		default:
+			var cmd tea.Cmd
+			m.picker.filter, cmd = m.picker.filter.Update(msg)
+			m.picker.apply()
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		if m.turn != nil {
+			m.turn.cancel()
+		}
+		return m, nil
+	case tea.KeyCtrlO:
+		return m, m.command("/models")
+	case tea.KeyUp:
+		m.viewport.ScrollUp(1)
+		return m, nil
+	case tea.KeyDown:
+		m.viewport.ScrollDown(1)
+		return m, nil
+	case tea.KeyEnter:
+		text := strings.TrimSpace(m.input.Value())
+		if text == "" {
+			return m, nil
+		}
+		if strings.HasPrefix(text, "/") {
+			m.input.Reset()
+			return m, m.command(text)
+		}
+		if m.turn != nil {
+			return m, nil
+		}
+		m.input.Reset()
+		return m, m.send(text)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// View implements tea.Model
+func (m *Model) View() string {
+	main := m.viewport.View()
+	if m.picker != nil {
+		main = m.picker.view(m.width, m.viewport.Height)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, main, m.statusBar(), m.input.View())
+}
+
+// send starts streaming the reply to text
+func (m *Model) send(text string) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &turn{cancel: cancel, events: make(chan tea.Msg, 64)}
+	m.turn = t
+	m.entries = append(m.entries, entry{kind: entryUser, text: text}, entry{kind: entryAssistant, model: m.model})
+	m.refresh()
+	m.viewport.GotoBottom()
+
+	conv := m.conv
+	go func() {
+		resp, err := conv.Stream(ctx, models.NewTextMessage(models.RoleUser, text), func(chunk *models.ChatCompletionResponse) {
+			// The usage chunk has no choices
+			if len(chunk.Choices) == 0 || chunk.Choices[0].Delta == nil {
+				return
+			}
+			if delta, _ := chunk.Choices[0].Delta.GetTextContent(); delta != "" {
+				select {
+				case t.events <- deltaMsg(delta):
+				case <-ctx.Done():
+				}
+			}
+		})
+		done := turnDoneMsg{err: err}
+		if err == nil {
+			done.usage = resp.Usage
+		}
+		t.events <- done
+	}()
+	return t.next()
+}
+
+// next waits for the turn's next event
+func (t *turn) next() tea.Cmd {
+	return func() tea.Msg {
+		return <-t.events
+	}
+}
+
+// finishTurn records the end of a reply and saves the session
+func (m *Model) finishTurn(done turnDoneMsg) {
+	m.turn.cancel()
+	m.turn = nil
+
+	reply := &m.entries[len(m.entries)-1]
+	switch {
+	case errors.Is(done.err, context.Canceled):
+		m.notice(entryNotice, "Reply stopped. Your message stays in the history.")
+	case done.err != nil:
+		m.notice(entryError, "Reply failed: "+done.err.Error())
+	case reply.text == "":
+		reply.text = "(no text)"
+	}
+	if done.usage != nil {
+		m.usage.PromptTokens += done.usage.PromptTokens
+		m.usage.CompletionTokens += done.usage.CompletionTokens
+		m.usage.Cost += done.usage.Cost
+	}
+	m.save()
+	m.refresh()
+}
+
+// command runs a slash command
+func (m *Model) command(line string) tea.Cmd {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	if m.turn != nil && name != "/help" && name != "/quit" {
+		m.notice(entryError, "Wait for the reply, or press Esc to stop it, before running "+name+".")
+		return nil
+	}
+
+	switch name {
+	case "/quit":
+		if m.turn != nil {
+			m.turn.cancel()
+		}
+		return tea.Quit
+	case "/help":
+		m.notice(entryNotice, helpText)
+	case "/model":
+		if arg == "" {
+			m.notice(entryNotice, "Model: "+m.model)
+			return nil
+		}
+		return m.switchModel(arg)
+	case "/models":
+		return m.openPicker()
+	case "/new":
+		if arg == "" {
+			arg = "session-" + time.Now().Format("20060102-150405")
+		}
+		if err := validateSessionName(arg); err != nil {
+			m.notice(entryError, err.Error())
+			return nil
+		}
+		m.startSession(arg, nil)
+	case "/open":
+		if m.opts.Sessions == nil {
+			m.notice(entryError, "Sessions aren't saved, so there is nothing to open.")
+			return nil
+		}
+		if arg == "" {
+			m.notice(entryError, "Usage: /open <name>")
+			return nil
+		}
+		messages, err := m.opts.Sessions.Load(arg)
+		if err != nil {
+			m.notice(entryError, err.Error())
+			return nil
+		}
+		m.startSession(arg, messages)
+	case "/sessions":
+		if m.opts.Sessions == nil {
+			m.notice(entryError, "Sessions aren't saved.")
+			return nil
+		}
+		names, err := m.opts.Sessions.List()
+		if err != nil {
+			m.notice(entryError, err.Error())
+			return nil
+		}
+		if len(names) == 0 {
+			m.notice(entryNotice, "No saved sessions.")
+			return nil
+		}
+		m.notice(entryNotice, "Saved sessions: "+strings.Join(names, ", "))
+	default:
+		m.notice(entryError, "Unknown command "+name+". Type /help for the list.")
+	}
+	return nil
+}
+
+// openSession opens a saved session, or starts it if it hasn't been saved
+func (m *Model) openSession(name string) error {
+	if err := validateSessionName(name); err != nil {
+		return err
+	}
+	var messages []models.Message
+	if m.opts.Sessions != nil {
+		var err error
+		messages, err = m.opts.Sessions.Load(name)
+		if err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return err
+		}
+	}
+	m.startSession(name, messages)
+	return nil
+}
+
+// startSession replaces the conversation with a session holding messages, or a new one
+// starting with the system prompt if messages is nil
+func (m *Model) startSession(name string, messages []models.Message) {
+	if messages == nil && m.opts.SystemPrompt != "" {
+		messages = []models.Message{models.NewTextMessage(models.RoleSystem, m.opts.SystemPrompt)}
+	}
+	m.session = name
+	m.conv = m.newConversation(messages)
+	m.usage = models.Usage{}
+	m.entries = nil
+	for _, msg := range messages {
+		text, _ := msg.GetTextContent()
+		switch msg.Role {
+		case models.RoleUser:
+			m.entries = append(m.entries, entry{kind: entryUser, text: text})
+		case models.RoleAssistant:
+			if text != "" {
+				m.entries = append(m.entries, entry{kind: entryAssistant, text: text, model: m.model})
+			}
+		}
+	}
+	status := "New session " + name
+	if len(m.entries) > 0 {
+		status = fmt.Sprintf("Opened session %s with %d messages", name, len(messages))
+	}
+	m.notice(entryNotice, status+". Type /help for commands.")
+	m.viewport.GotoBottom()
+}
+
+// newConversation creates a conversation with the current model, asking for usage and
+// cost with every streamed reply
+func (m *Model) newConversation(messages []models.Message) *pkg.Conversation {
+	return pkg.NewConversation(m.client, pkg.ConversationOptions{
+		Request: models.NewChatRequest(m.model,
+			models.WithStreamUsage(),
+			models.WithUsageAccounting(),
+		),
+		MaxTokens: m.opts.MaxTokens,
+	}, messages...)
+}
+
+// switchModel continues the conversation with another model
+func (m *Model) switchModel(id string) tea.Cmd {
+	if id == m.model {
+		return nil
+	}
+	m.model = id
+	m.info = nil
+	m.conv = m.newConversation(m.conv.Messages())
+	m.notice(entryNotice, "Switched to "+id+".")
+	return m.loadModelInfo()
+}
+
+// loadModelInfo looks the current model up in the catalog
+func (m *Model) loadModelInfo() tea.Cmd {
+	id, catalog := m.model, m.catalog
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), catalogTimeout)
+		defer cancel()
+		model, err := catalog.Model(ctx, id)
+		return modelInfoMsg{id: id, model: model, err: err}
+	}
+}
+
+// openPicker shows the model switcher and fetches the model list
+func (m *Model) openPicker() tea.Cmd {
+	m.picker = newPicker(m.model)
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), catalogTimeout)
+		defer cancel()
+		resp, err := client.ListModels(ctx, nil)
+		if err != nil {
+			return modelListMsg{err: err}
+		}
+		return modelListMsg{models: resp.Data}
+	}
+}
+
+// save writes the session to the store, if there is one
+func (m *Model) save() {
+	if m.opts.Sessions == nil {
+		return
+	}
+	if err := m.opts.Sessions.Save(m.session, m.conv.Messages()); err != nil {
+		m.notice(entryError, "Failed to save the session: "+err.Error())
+	}
+}
+
+// notice adds a line of information to the scrollback
+func (m *Model) notice(kind int, text string) {
+	m.entries = append(m.entries, entry{kind: kind, text: text})
+	m.refresh()
+}
+
+// refresh rerenders the scrollback, following new output if it was scrolled to the end
+func (m *Model) refresh() {
+	follow := m.viewport.AtBottom()
+	m.viewport.SetContent(m.renderEntries())
+	if follow {
+		m.viewport.GotoBottom()
+	}
+}
+
+var (
+	userStyle      = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	assistantStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10"))
+	noticeStyle    = lipgloss.NewStyle().Faint(true)
+	errorStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	statusStyle    = lipgloss.NewStyle().Reverse(true)
+)
+
+// renderEntries renders the scrollback, wrapped to the window
+func (m *Model) renderEntries() string {
+	wrap := lipgloss.NewStyle()
+	if m.width > 0 {
+		wrap = wrap.Width(m.width)
+	}
+
+	blocks := make([]string, 0, len(m.entries))
+	for _, e := range m.entries {
+		switch e.kind {
+		case entryUser:
+			blocks = append(blocks, userStyle.Render("You")+"\n"+wrap.Render(e.text))
+		case entryAssistant:
+			blocks = append(blocks, assistantStyle.Render(e.model)+"\n"+wrap.Render(e.text))
+		case entryNotice:
+			blocks = append(blocks, noticeStyle.Inherit(wrap).Render(e.text))
+		case entryError:
+			blocks = append(blocks, errorStyle.Inherit(wrap).Render(e.text))
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// statusBar shows the model, session, and session usage
+func (m *Model) statusBar() string {
+	parts := []string{m.model}
+	if m.info != nil && m.info.ContextLength > 0 {
+		parts = append(parts, formatTokens(m.info.ContextLength)+" context")
+	}
+	parts = append(parts,
+		"session "+m.session,
+		fmt.Sprintf("%s in / %s out", formatTokens(m.usage.PromptTokens), formatTokens(m.usage.CompletionTokens)),
+		fmt.Sprintf("$%.4f", m.usage.Cost),
+	)
+	if m.turn != nil {
+		parts = append(parts, "replying, Esc stops")
+	}
+
+	bar := statusStyle
+	if m.width > 0 {
+		bar = bar.Width(m.width).MaxHeight(1)
+	}
+	return bar.Render(" " + strings.Join(parts, " · "))
+}
+
+// formatTokens shortens token counts, e.g. 128000 as "128k"
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000 && n%1_000_000 == 0:
+		return fmt.Sprintf("%dM", n/1_000_000)
+	case n >= 10_000:
+		return fmt.Sprintf("%dk", n/1000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprint(n)
+}
+
+// picker is the model switcher
+type picker struct {
+	current  string
+	filter   textinput.Model
+	all      []models.Model
+	matches  []models.Model
+	selected int
+	loading  bool
+	err      error
+}
+
+func newPicker(current string) *picker {
+	filter := textinput.New()
+	filter.Prompt = "Model: "
+	filter.Placeholder = "type to filter"
+	filter.Cursor.SetMode(cursor.CursorStatic)
+	filter.Focus()
+	return &picker{current: current, filter: filter, loading: true}
+}
+
+// setModels fills the picker with the model list, sorted by ID
+func (p *picker) setModels(list []models.Model, err error) {
+	p.loading = false
+	p.err = err
+	p.all = append([]models.Model(nil), list...)
+	sort.Slice(p.all, func(i, j int) bool { return p.all[i].ID < p.all[j].ID })
+	p.apply()
+	for i, model := range p.matches {
+		if model.ID == p.current {
+			p.selected = i
+		}
+	}
+}
+
+// apply filters the models by ID or name
+func (p *picker) apply() {
+	query := strings.ToLower(strings.TrimSpace(p.filter.Value()))
+	p.matches = p.matches[:0]
+	for _, model := range p.all {
+		if query == "" || strings.Contains(strings.ToLower(model.ID), query) || strings.Contains(strings.ToLower(model.Name), query) {
+			p.matches = append(p.matches, model)
+		}
+	}
+	p.selected = min(p.selected, max(len(p.matches)-1, 0))
+}
+
+func (p *picker) move(delta int) {
+	if len(p.matches) > 0 {
+		p.selected = (p.selected + delta + len(p.matches)) % len(p.matches)
+	}
+}
+
+// chosen returns the selected model ID, or the typed one if nothing matches it
+func (p *picker) chosen() (string, bool) {
+	if len(p.matches) > 0 {
+		return p.matches[p.selected].ID, true
+	}
+	typed := strings.TrimSpace(p.filter.Value())
+	return typed, typed != ""
+}
+
+// view renders the filter and the matches around the selection in height lines
+func (p *picker) view(width, height int) string {
+	lines := []string{p.filter.View()}
+	switch {
+	case p.loading:
+		lines = append(lines, noticeStyle.Render("Loading models..."))
+	case p.err != nil:
+		lines = append(lines, errorStyle.Render("Failed to list models: "+p.err.Error()))
+	case len(p.matches) == 0:
+		lines = append(lines, noticeStyle.Render("No match. Enter switches to the typed ID."))
+	}
+
+	rows := max(height-len(lines), 1)
+	first := max(min(p.selected-rows/2, len(p.matches)-rows), 0)
+	for i := first; i < len(p.matches) && i < first+rows; i++ {
+		model := p.matches[i]
+		line := fmt.Sprintf("  %s  %s context", model.ID, formatTokens(model.ContextLength))
+		if i == p.selected {
+			line = statusStyle.Render("> " + line[2:])
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+
+	style := lipgloss.NewStyle().MaxHeight(height)
+	if width > 0 {
+		style = style.MaxWidth(width)
+	}
+	return style.Render(strings.Join(lines, "\n"))
+}
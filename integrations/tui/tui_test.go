@@ -0,0 +1,273 @@
+package tui_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/integrations/tui"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// program drives a Model the way tea.Program does, without a terminal
+type program struct {
+	t *testing.T
+	m tea.Model
+}
+
+func start(t *testing.T, srv *openroutertest.Server, opts tui.Options) *program {
+	t.Helper()
+	m, err := tui.New(srv.Client(), opts)
+	require.NoError(t, err)
+	p := &program{t: t, m: m}
+	p.send(tea.WindowSizeMsg{Width: 100, Height: 30})
+	p.run(m.Init())
+	return p
+}
+
+// send delivers msg and runs the commands it returns to completion
+func (p *program) send(msg tea.Msg) {
+	p.t.Helper()
+	var cmd tea.Cmd
+	p.m, cmd = p.m.Update(msg)
+	p.run(cmd)
+}
+
+// run runs cmd and the commands that follow from it
+func (p *program) run(cmd tea.Cmd) {
+	p.t.Helper()
+	for cmd != nil {
+		msg := p.await(cmd)
+		if _, ok := msg.(tea.QuitMsg); ok {
+			return
+		}
+		p.m, cmd = p.m.Update(msg)
+	}
+}
+
+// await runs cmd, failing the test if it doesn't finish
+func (p *program) await(cmd tea.Cmd) tea.Msg {
+	p.t.Helper()
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	select {
+	case msg := <-done:
+		return msg
+	case <-time.After(5 * time.Second):
+		p.t.Fatal("command did not finish")
+		return nil
+	}
+}
+
+func (p *program) typeText(text string) {
+	p.t.Helper()
+	p.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+}
+
+func (p *program) press(key tea.KeyType) {
+	p.t.Helper()
+	p.send(tea.KeyMsg{Type: key})
+}
+
+// submit types a line and presses Enter
+func (p *program) submit(line string) {
+	p.t.Helper()
+	p.typeText(line)
+	p.press(tea.KeyEnter)
+}
+
+// view returns the screen without the padding that fills each line
+func (p *program) view() string {
+	lines := strings.Split(p.m.View(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func catalogServer(t *testing.T) *openroutertest.Server {
+	t.Helper()
+	srv := openroutertest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetModels(
+		models.Model{ID: "anthropic/claude-3.5-sonnet", Name: "Claude 3.5 Sonnet", ContextLength: 200000},
+		models.Model{ID: "openai/gpt-4o-mini", Name: "GPT-4o mini", ContextLength: 128000},
+	)
+	return srv
+}
+
+func TestChatStreamsRepliesWithUsage(t *testing.T) {
+	srv := catalogServer(t)
+	reply := openroutertest.TextReply("Hello there, how can I help?")
+	reply.Response.Usage.Cost = 0.0021
+	srv.EnqueueChat(reply)
+	store, err := tui.NewSessionStore(t.TempDir())
+	require.NoError(t, err)
+
+	p := start(t, srv, tui.Options{Model: "openai/gpt-4o-mini", SystemPrompt: "Be brief.", Sessions: store})
+	assert.Contains(t, p.view(), "openai/gpt-4o-mini · 128k context · session default · 0 in / 0 out · $0.0000")
+
+	p.submit("hi")
+	view := p.view()
+	assert.Contains(t, view, "You\nhi")
+	assert.Contains(t, view, "openai/gpt-4o-mini\nHello there, how can I help?")
+	assert.Contains(t, view, "10 in / 6 out · $0.0021")
+
+	// Replies are streamed with usage and cost
+	var chats []openroutertest.RecordedRequest
+	for _, req := range srv.Requests() {
+		if req.Path == "/chat/completions" {
+			chats = append(chats, req)
+		}
+	}
+	require.Len(t, chats, 1)
+	sent, err := chats[0].ChatRequest()
+	require.NoError(t, err)
+	assert.True(t, sent.Stream)
+	require.NotNil(t, sent.StreamOptions)
+	assert.True(t, sent.StreamOptions.IncludeUsage)
+	require.NotNil(t, sent.Usage)
+	assert.True(t, sent.Usage.Include)
+	require.Len(t, sent.Messages, 2)
+	assert.Equal(t, models.RoleSystem, sent.Messages[0].Role)
+
+	// The session is saved after each reply
+	saved, err := store.Load("default")
+	require.NoError(t, err)
+	require.Len(t, saved, 3)
+	text, _ := saved[2].GetTextContent()
+	assert.Equal(t, "Hello there, how can I help?", text)
+
+	// Failed replies are reported in the scrollback
+	srv.EnqueueChat(openroutertest.ErrorReply(503, "no provider available"))
+	p.submit("again")
+	assert.Contains(t, p.view(), "Reply failed")
+	assert.Contains(t, p.view(), "no provider available")
+}
+
+func TestModelSwitcher(t *testing.T) {
+	srv := catalogServer(t)
+	p := start(t, srv, tui.Options{Model: "openai/gpt-4o-mini"})
+	p.submit("hi")
+
+	// The switcher opens on the current model
+	p.press(tea.KeyCtrlO)
+	view := p.view()
+	assert.Contains(t, view, "  anthropic/claude-3.5-sonnet  200k context\n> openai/gpt-4o-mini  128k context")
+
+	// The filter matches names too
+	p.typeText("claude")
+	assert.NotContains(t, p.view(), "openai/gpt-4o-mini  128k")
+	p.press(tea.KeyEnter)
+	view = p.view()
+	assert.Contains(t, view, "Switched to anthropic/claude-3.5-sonnet.")
+	assert.Contains(t, view, "anthropic/claude-3.5-sonnet · 200k context")
+	assert.Contains(t, view, "openai/gpt-4o-mini\nhi", "earlier replies keep their model")
+
+	// The conversation continues with the new model
+	p.submit("and you?")
+	requests := srv.Requests()
+	sent, err := requests[len(requests)-1].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic/claude-3.5-sonnet", sent.Model)
+	assert.Len(t, sent.Messages, 3)
+
+	// Esc closes the picker without switching
+	p.press(tea.KeyCtrlO)
+	p.press(tea.KeyEsc)
+	assert.NotContains(t, p.view(), "type to filter")
+	assert.Contains(t, p.view(), "anthropic/claude-3.5-sonnet · 200k context")
+
+	// /model switches to any ID, even one missing from the catalog
+	p.submit("/model openrouter/auto")
+	assert.Contains(t, p.view(), "openrouter/auto · session default")
+}
+
+func TestSessions(t *testing.T) {
+	srv := catalogServer(t)
+	store, err := tui.NewSessionStore(t.TempDir())
+	require.NoError(t, err)
+
+	p := start(t, srv, tui.Options{Sessions: store})
+	assert.Contains(t, p.view(), "New session default.")
+	p.submit("first session")
+
+	p.submit("/new work")
+	assert.Contains(t, p.view(), "New session work.")
+	assert.NotContains(t, p.view(), "first session", "a new session starts with an empty scrollback")
+	p.submit("second session")
+
+	p.submit("/sessions")
+	assert.Contains(t, p.view(), "Saved sessions: default, work")
+
+	p.submit("/open default")
+	view := p.view()
+	assert.Contains(t, view, "Opened session default with 2 messages.")
+	assert.Contains(t, view, "first session")
+	assert.NotContains(t, view, "second session")
+	assert.Contains(t, view, "session default · 0 in / 0 out")
+
+	p.submit("/open missing")
+	assert.Contains(t, p.view(), "session not found")
+	p.submit("/new ../escape")
+	assert.Contains(t, p.view(), "invalid session name")
+	p.submit("/bogus")
+	assert.Contains(t, p.view(), "Unknown command /bogus")
+
+	// Sessions are reopened on start
+	p = start(t, srv, tui.Options{Sessions: store, Session: "work"})
+	assert.Contains(t, p.view(), "Opened session work with 2 messages.")
+	assert.Contains(t, p.view(), "second session")
+}
+
+func TestStopReply(t *testing.T) {
+	srv := catalogServer(t)
+	reply := openroutertest.TextReply("one two three four five six seven eight nine ten")
+	reply.ChunkDelay = 100 * time.Millisecond
+	srv.EnqueueChat(reply)
+	p := start(t, srv, tui.Options{})
+
+	// Read the first word, then stop
+	p.typeText("count")
+	var cmd tea.Cmd
+	p.m, cmd = p.m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	p.m, cmd = p.m.Update(p.await(cmd))
+	assert.Contains(t, p.view(), "replying, Esc stops")
+
+	p.submit("/new other")
+	assert.Contains(t, p.view(), "Wait for the reply")
+
+	p.press(tea.KeyEsc)
+	p.run(cmd)
+	view := p.view()
+	assert.Contains(t, view, "Reply stopped.")
+	assert.NotContains(t, view, "ten")
+	assert.NotContains(t, view, "replying")
+	assert.Contains(t, view, "one")
+}
+
+func TestSessionStore(t *testing.T) {
+	store, err := tui.NewSessionStore(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.Load("chat")
+	assert.ErrorIs(t, err, tui.ErrSessionNotFound)
+	names, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	messages := []models.Message{models.NewTextMessage(models.RoleUser, "hi")}
+	require.NoError(t, store.Save("chat", messages))
+	loaded, err := store.Load("chat")
+	require.NoError(t, err)
+	assert.Equal(t, messages, loaded)
+
+	for _, name := range []string{"", "../chat", ".hidden", "a/b"} {
+		assert.ErrorContains(t, store.Save(name, messages), "invalid session name", name)
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/rizome-dev/go-openrouter/integrations/wschat
+
+go 1.22
+
+require (
+	github.com/coder/websocket v1.8.12
+	github.com/rizome-dev/go-openrouter v0.0.0
+	github.com/stretchr/testify v1.8.4
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/rizome-dev/go-openrouter => ../..
@@ -0,0 +1,450 @@
+// Package wschat serves interactive chat sessions over WebSocket. Each session is a
+// pkg.Conversation: browsers send messages as JSON frames, and replies stream back as
+// delta frames. Sessions outlive their connections, so a browser that reconnects with its
+// session ID and the last sequence number it saw resumes where it left off, including a
+// reply still being generated:
+//
+//	h := wschat.New(wschat.Options{
+//		NewConversation: func(r *http.Request, user string) (*pkg.Conversation, error) {
+//			return pkg.NewConversation(client, pkg.ConversationOptions{
+//				Request: models.ChatCompletionRequest{Model: "openai/gpt-4o-mini"},
+//			}), nil
+//		},
+//	})
+//	defer h.Close()
+//	http.Handle("/ws/chat", h)
+//
+// It complements the SSE handlers of pkg/chathttp for apps that keep a connection open.
+// It is a separate module so the SDK itself keeps no dependencies.
+package wschat
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+const (
+	// DefaultSessionTTL is how long a session without a connection is kept by default
+	DefaultSessionTTL = 10 * time.Minute
+
+	// DefaultReplayFrames is how many frames a session keeps for resuming by default
+	DefaultReplayFrames = 1024
+
+	// writeTimeout bounds writing a frame to a slow connection
+	writeTimeout = 10 * time.Second
+)
+
+// Frame types sent by browsers
+const (
+	// FrameMessage sends Content as a user message, starting a turn
+	FrameMessage = "message"
+
+	// FrameCancel cancels the turn in progress
+	FrameCancel = "cancel"
+)
+
+// Frame types sent by the handler
+const (
+	// FrameSession is sent on every connection with the session ID to resume with
+	FrameSession = "session"
+
+	// FrameHistory carries the whole conversation, sent on resume when frames the
+	// browser missed are no longer buffered
+	FrameHistory = "history"
+
+	// FrameDelta carries the next piece of a reply
+	FrameDelta = "delta"
+
+	// FrameDone ends a turn, with its finish reason and usage
+	FrameDone = "done"
+
+	// FrameError reports a failed turn or an invalid frame
+	FrameError = "error"
+)
+
+// Frame is a JSON WebSocket message in either direction. Frames sent by the handler are
+// numbered by Seq, starting at 1, except session frames, which carry the latest Seq.
+type Frame struct {
+	Type         string           `json:"type"`
+	Seq          uint64           `json:"seq,omitempty"`
+	Session      string           `json:"session,omitempty"`
+	Turn         int              `json:"turn,omitempty"`
+	Content      string           `json:"content,omitempty"`
+	Reasoning    string           `json:"reasoning,omitempty"`
+	FinishReason string           `json:"finish_reason,omitempty"`
+	Usage        *models.Usage    `json:"usage,omitempty"`
+	Messages     []models.Message `json:"messages,omitempty"`
+	Error        string           `json:"error,omitempty"`
+}
+
+// Options configures a Handler
+type Options struct {
+	// Authenticate identifies the user of a connection, or rejects it with an error,
+	// answered with 401 Unauthorized. Sessions can only be resumed by the user who
+	// started them. Without it every connection is anonymous.
+	Authenticate func(r *http.Request) (user string, err error)
+
+	// NewConversation starts the conversation of a new session, e.g. with a system
+	// prompt. Returning an error rejects the connection with 403 Forbidden.
+	NewConversation func(r *http.Request, user string) (*pkg.Conversation, error)
+
+	// SessionTTL is how long a session without a connection is kept. Defaults to
+	// DefaultSessionTTL.
+	SessionTTL time.Duration
+
+	// ReplayFrames is how many frames a session keeps for resuming. Defaults to
+	// DefaultReplayFrames.
+	ReplayFrames int
+
+	// AcceptOptions configures the WebSocket handshake, e.g. allowed origins
+	AcceptOptions *websocket.AcceptOptions
+
+	// Clock defaults to pkg.SystemClock
+	Clock pkg.Clock
+}
+
+// Handler is an http.Handler upgrading requests to chat sessions. Browsers connect
+// without parameters to start a session, or with ?session=<id>&after=<seq> to resume
+// one.
+type Handler struct {
+	opts   Options
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// New creates a handler. NewConversation is required.
+func New(opts Options) *Handler {
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = DefaultSessionTTL
+	}
+	if opts.ReplayFrames <= 0 {
+		opts.ReplayFrames = DefaultReplayFrames
+	}
+	if opts.Clock == nil {
+		opts.Clock = pkg.SystemClock
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Handler{opts: opts, ctx: ctx, cancel: cancel, sessions: make(map[string]*session)}
+}
+
+// Close cancels every turn in progress and closes every connection
+func (h *Handler) Close() {
+	h.cancel()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id, s := range h.sessions {
+		s.close()
+		delete(h.sessions, id)
+	}
+}
+
+// Sessions returns the number of live sessions
+func (h *Handler) Sessions() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sweep()
+	return len(h.sessions)
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var user string
+	if h.opts.Authenticate != nil {
+		var err error
+		if user, err = h.opts.Authenticate(r); err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	after, _ := strconv.ParseUint(r.URL.Query().Get("after"), 10, 64)
+	s, err := h.session(r, user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, h.opts.AcceptOptions)
+	if err != nil {
+		return // Accept has written the response
+	}
+	if replaced := s.attach(conn, after); replaced != nil {
+		replaced.Close(websocket.StatusPolicyViolation, "session resumed on another connection")
+	}
+	defer s.detach(conn)
+
+	for {
+		_, data, err := conn.Read(r.Context())
+		if err != nil {
+			return
+		}
+		var frame Frame
+		if err := json.Unmarshal(data, &frame); err != nil {
+			s.emit(Frame{Type: FrameError, Error: "invalid frame: " + err.Error()})
+			continue
+		}
+		switch frame.Type {
+		case FrameMessage:
+			s.startTurn(frame.Content)
+		case FrameCancel:
+			s.cancelTurn()
+		default:
+			s.emit(Frame{Type: FrameError, Error: fmt.Sprintf("unknown frame type %q", frame.Type)})
+		}
+	}
+}
+
+// session returns the session r resumes, or starts a new one. Unknown or expired sessions
+// are replaced by new ones; browsers notice by the ID in the session frame.
+func (h *Handler) session(r *http.Request, user string) (*session, error) {
+	h.mu.Lock()
+	h.sweep()
+	s, ok := h.sessions[r.URL.Query().Get("session")]
+	h.mu.Unlock()
+	if ok {
+		if s.user != user {
+			return nil, errors.New("session belongs to another user")
+		}
+		return s, nil
+	}
+
+	conv, err := h.opts.NewConversation(r, user)
+	if err != nil {
+		return nil, err
+	}
+	s = &session{
+		id:       newSessionID(),
+		user:     user,
+		conv:     conv,
+		handler:  h,
+		lastSeen: h.opts.Clock.Now(),
+		history:  conv.Messages(),
+	}
+	h.mu.Lock()
+	h.sessions[s.id] = s
+	h.mu.Unlock()
+	return s, nil
+}
+
+// sweep drops sessions idle for longer than SessionTTL. h.mu must be held.
+func (h *Handler) sweep() {
+	now := h.opts.Clock.Now()
+	for id, s := range h.sessions {
+		if s.expired(now, h.opts.SessionTTL) {
+			delete(h.sessions, id)
+		}
+	}
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("wschat: failed to generate session ID: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// session is a conversation with the frames recently sent for it
+type session struct {
+	id      string
+	user    string
+	conv    *pkg.Conversation
+	handler *Handler
+
+	// mu serializes frames, so they are buffered and written in Seq order
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	seq      uint64
+	frames   []Frame
+	turn     int
+	cancel   context.CancelFunc
+	lastSeen time.Time
+
+	// history is the conversation as of the last turn, and pending the message of the
+	// turn in progress. The conversation itself is locked while a turn streams.
+	history []models.Message
+	pending models.Message
+}
+
+// attach makes conn the session's connection and sends it the session frame and whatever
+// it missed after Seq after. It returns the connection conn replaces, for the caller to
+// close.
+func (s *session) attach(conn *websocket.Conn, after uint64) (replaced *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	replaced = s.conn
+	s.conn = conn
+	s.write(Frame{Type: FrameSession, Session: s.id, Seq: s.seq})
+
+	if after >= s.seq {
+		return replaced
+	}
+	replay := s.frames
+	if len(replay) == 0 || replay[0].Seq > after+1 {
+		// Frames were dropped from the buffer; send the history instead, then the turn in
+		// progress as far as it is buffered
+		history := s.history
+		if s.cancel != nil {
+			history = append(history[:len(history):len(history)], s.pending)
+		}
+		s.write(Frame{Type: FrameHistory, Seq: s.seq, Messages: history})
+		replay = nil
+		if s.cancel != nil {
+			for i, frame := range s.frames {
+				if frame.Turn == s.turn {
+					replay = s.frames[i:]
+					break
+				}
+			}
+		}
+	}
+	for _, frame := range replay {
+		if frame.Seq > after {
+			s.write(frame)
+		}
+	}
+	return replaced
+}
+
+// detach forgets conn if it is still the session's connection
+func (s *session) detach(conn *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == conn {
+		s.conn = nil
+		s.lastSeen = s.handler.opts.Clock.Now()
+	}
+	conn.CloseNow()
+}
+
+// emit numbers, buffers and sends a frame
+func (s *session) emit(frame Frame) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emitLocked(frame)
+}
+
+func (s *session) emitLocked(frame Frame) {
+	s.seq++
+	frame.Seq = s.seq
+	s.frames = append(s.frames, frame)
+	if extra := len(s.frames) - s.handler.opts.ReplayFrames; extra > 0 {
+		s.frames = append(s.frames[:0:0], s.frames[extra:]...)
+	}
+	s.write(frame)
+}
+
+// write sends a frame to the connection, if any. A connection that fails is dropped; the
+// browser resumes from the buffer when it reconnects.
+func (s *session) write(frame Frame) {
+	if s.conn == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(s.handler.ctx, writeTimeout)
+	defer cancel()
+	if err := wsjson.Write(ctx, s.conn, frame); err != nil {
+		s.conn.CloseNow()
+		s.conn = nil
+		s.lastSeen = s.handler.opts.Clock.Now()
+	}
+}
+
+// startTurn sends text to the conversation, streaming the reply as delta frames. The turn
+// runs independently of the connection, so it completes while the browser reconnects.
+func (s *session) startTurn(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.emitLocked(Frame{Type: FrameError, Turn: s.turn, Error: "a turn is already in progress"})
+		return
+	}
+	s.turn++
+	turn := s.turn
+	ctx, cancel := context.WithCancel(s.handler.ctx)
+	s.cancel = cancel
+	msg := models.NewTextMessage(models.RoleUser, text)
+	s.pending = msg
+
+	go func() {
+		defer cancel()
+		resp, err := s.conv.Stream(ctx, msg, func(chunk *models.ChatCompletionResponse) {
+			if len(chunk.Choices) == 0 || chunk.Choices[0].Delta == nil {
+				return
+			}
+			delta := chunk.Choices[0].Delta
+			content, _ := delta.GetTextContent()
+			if content != "" || delta.Reasoning != "" {
+				s.emit(Frame{Type: FrameDelta, Turn: turn, Content: content, Reasoning: delta.Reasoning})
+			}
+		})
+
+		history := s.conv.Messages()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.cancel = nil
+		s.history = history
+		s.lastSeen = s.handler.opts.Clock.Now()
+		switch {
+		case errors.Is(err, context.Canceled):
+			s.emitLocked(Frame{Type: FrameError, Turn: turn, Error: "turn canceled"})
+		case err != nil:
+			s.emitLocked(Frame{Type: FrameError, Turn: turn, Error: err.Error()})
+		default:
+			done := Frame{Type: FrameDone, Turn: turn, Usage: resp.Usage}
+			if len(resp.Choices) > 0 {
+				done.FinishReason = resp.Choices[0].FinishReason
+			}
+			s.emitLocked(done)
+		}
+	}()
+}
+
+// cancelTurn cancels the turn in progress, if any
+func (s *session) cancelTurn() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// expired reports whether the session has been idle, without a connection or a turn in
+// progress, for longer than ttl
+func (s *session) expired(now time.Time, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn == nil && s.cancel == nil && now.Sub(s.lastSeen) > ttl
+}
+
+// close cancels the turn in progress and closes the connection
+func (s *session) close() {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	conn := s.conn
+	s.conn = nil
+	s.mu.Unlock()
+
+	// Closing waits for the connection's read loop, which detaches it under s.mu
+	if conn != nil {
+		conn.Close(websocket.StatusGoingAway, "server shutting down")
+	}
+}
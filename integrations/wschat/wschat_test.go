@@ -0,0 +1,221 @@
+package wschat_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/integrations/wschat"
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func newHandler(t *testing.T, api *openroutertest.Server, configure ...func(*wschat.Options)) *httptest.Server {
+	t.Helper()
+	opts := wschat.Options{
+		Authenticate: func(r *http.Request) (string, error) {
+			user := r.URL.Query().Get("user")
+			if user == "" {
+				return "", errors.New("no session")
+			}
+			return user, nil
+		},
+		NewConversation: func(r *http.Request, user string) (*pkg.Conversation, error) {
+			return pkg.NewConversation(api.Client(), pkg.ConversationOptions{
+				Request: models.ChatCompletionRequest{Model: "openai/gpt-4o-mini"},
+			}), nil
+		},
+	}
+	for _, fn := range configure {
+		fn(&opts)
+	}
+	h := wschat.New(opts)
+	srv := httptest.NewServer(h)
+	t.Cleanup(func() {
+		h.Close()
+		srv.Close()
+	})
+	return srv
+}
+
+func dial(t *testing.T, srv *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"?"+query, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.CloseNow() })
+	return conn
+}
+
+func read(t *testing.T, conn *websocket.Conn) wschat.Frame {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var frame wschat.Frame
+	require.NoError(t, wsjson.Read(ctx, conn, &frame))
+	return frame
+}
+
+func send(t *testing.T, conn *websocket.Conn, frame wschat.Frame) {
+	t.Helper()
+	require.NoError(t, wsjson.Write(context.Background(), conn, frame))
+}
+
+// readTurn reads frames until a turn ends, returning its text and final frame
+func readTurn(t *testing.T, conn *websocket.Conn) (string, wschat.Frame) {
+	t.Helper()
+	var text strings.Builder
+	for {
+		frame := read(t, conn)
+		switch frame.Type {
+		case wschat.FrameDelta:
+			text.WriteString(frame.Content)
+		case wschat.FrameDone, wschat.FrameError:
+			return text.String(), frame
+		}
+	}
+}
+
+func TestChatTurns(t *testing.T) {
+	api := openroutertest.NewServer()
+	defer api.Close()
+	api.EnqueueChat(openroutertest.TextReply("Hello there, how can I help?"))
+	api.EnqueueChat(openroutertest.TextReply("Sure."))
+	srv := newHandler(t, api)
+
+	conn := dial(t, srv, "user=alice")
+	session := read(t, conn)
+	require.Equal(t, wschat.FrameSession, session.Type)
+	assert.NotEmpty(t, session.Session)
+
+	send(t, conn, wschat.Frame{Type: wschat.FrameMessage, Content: "Hi"})
+	text, done := readTurn(t, conn)
+	assert.Equal(t, "Hello there, how can I help?", text)
+	assert.Equal(t, wschat.FrameDone, done.Type)
+	assert.Equal(t, 1, done.Turn)
+
+	send(t, conn, wschat.Frame{Type: wschat.FrameMessage, Content: "Help me"})
+	text, done = readTurn(t, conn)
+	assert.Equal(t, "Sure.", text)
+	assert.Equal(t, 2, done.Turn)
+
+	// The second turn is sent with the history of the first
+	sent, err := api.Requests()[1].ChatRequest()
+	require.NoError(t, err)
+	require.Len(t, sent.Messages, 3)
+	assert.True(t, sent.Stream)
+
+	send(t, conn, wschat.Frame{Type: "bogus"})
+	assert.Equal(t, wschat.FrameError, read(t, conn).Type)
+}
+
+func TestResume(t *testing.T) {
+	api := openroutertest.NewServer()
+	defer api.Close()
+	reply := openroutertest.TextReply("one two three four five six")
+	reply.ChunkDelay = 20 * time.Millisecond
+	api.EnqueueChat(reply)
+	srv := newHandler(t, api)
+
+	conn := dial(t, srv, "user=alice")
+	session := read(t, conn)
+	send(t, conn, wschat.Frame{Type: wschat.FrameMessage, Content: "Count"})
+
+	// Drop the connection after the first delta; the turn carries on without it
+	first := read(t, conn)
+	require.Equal(t, wschat.FrameDelta, first.Type)
+	conn.Close(websocket.StatusNormalClosure, "")
+
+	conn = dial(t, srv, "user=alice&session="+session.Session+"&after="+strconv.FormatUint(first.Seq, 10))
+	resumed := read(t, conn)
+	assert.Equal(t, session.Session, resumed.Session)
+
+	rest, done := readTurn(t, conn)
+	assert.Equal(t, wschat.FrameDone, done.Type)
+	assert.Equal(t, "one two three four five six", first.Content+rest)
+
+	// Another user can't take the session over
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, resp, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"?user=mallory&session="+session.Session, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestResumeUnknownSessionStartsNewOne(t *testing.T) {
+	api := openroutertest.NewServer()
+	defer api.Close()
+	srv := newHandler(t, api)
+
+	conn := dial(t, srv, "user=alice&session=expired&after=10")
+	session := read(t, conn)
+	assert.Equal(t, wschat.FrameSession, session.Type)
+	assert.NotEqual(t, "expired", session.Session)
+	assert.Zero(t, session.Seq)
+}
+
+func TestResumeReplaysBufferedFrames(t *testing.T) {
+	api := openroutertest.NewServer()
+	defer api.Close()
+	api.EnqueueChat(openroutertest.TextReply("Hello there"))
+	srv := newHandler(t, api)
+
+	conn := dial(t, srv, "user=alice")
+	session := read(t, conn)
+	send(t, conn, wschat.Frame{Type: wschat.FrameMessage, Content: "Hi"})
+	readTurn(t, conn)
+	conn.Close(websocket.StatusNormalClosure, "")
+
+	// A new device has seen nothing, and gets every buffered frame
+	conn = dial(t, srv, "user=alice&session="+session.Session)
+	read(t, conn)
+	text, done := readTurn(t, conn)
+	assert.Equal(t, "Hello there", text)
+	assert.Equal(t, wschat.FrameDone, done.Type)
+}
+
+func TestResumeWithHistory(t *testing.T) {
+	api := openroutertest.NewServer()
+	defer api.Close()
+	api.EnqueueChat(openroutertest.TextReply("Hello there"))
+	srv := newHandler(t, api, func(opts *wschat.Options) { opts.ReplayFrames = 1 })
+
+	conn := dial(t, srv, "user=alice")
+	session := read(t, conn)
+	send(t, conn, wschat.Frame{Type: wschat.FrameMessage, Content: "Hi"})
+	readTurn(t, conn)
+	conn.Close(websocket.StatusNormalClosure, "")
+
+	// The missed deltas are gone, so the conversation is sent instead
+	conn = dial(t, srv, "user=alice&session="+session.Session)
+	read(t, conn)
+	history := read(t, conn)
+	require.Equal(t, wschat.FrameHistory, history.Type)
+	require.Len(t, history.Messages, 2)
+	text, _ := history.Messages[1].GetTextContent()
+	assert.Equal(t, "Hello there", text)
+}
+
+func TestUnauthorized(t *testing.T) {
+	api := openroutertest.NewServer()
+	defer api.Close()
+	srv := newHandler(t, api)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, resp, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
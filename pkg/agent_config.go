@@ -0,0 +1,191 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// AgentConfig is a declarative agent definition that can be kept in a JSON file,
+// so agent behavior can be tuned without recompiling:
+//
+//	{
+//	  "model": "openai/gpt-4o",
+//	  "system_prompt": "You are a support agent.",
+//	  "temperature": 0.2,
+//	  "tools": [{"name": "lookup_order", "description": "Find an order", "parameters": {"type": "object"}}],
+//	  "limits": {"max_iterations": 8, "max_tool_calls": 20, "timeout": "2m"},
+//	  "provider": {"order": ["openai"], "allow_fallbacks": true}
+//	}
+//
+// Tool implementations are still registered in Go and matched by name.
+type AgentConfig struct {
+	Model        string                      `json:"model"`
+	Models       []string                    `json:"models,omitempty"`
+	SystemPrompt string                      `json:"system_prompt,omitempty"`
+	Temperature  *float64                    `json:"temperature,omitempty"`
+	MaxTokens    *int                        `json:"max_tokens,omitempty"`
+	Tools        []AgentToolConfig           `json:"tools,omitempty"`
+	Limits       AgentLimitsConfig           `json:"limits,omitempty"`
+	Provider     *models.ProviderPreferences `json:"provider,omitempty"`
+	Reasoning    *models.ReasoningConfig     `json:"reasoning,omitempty"`
+	ToolChoice   string                      `json:"tool_choice,omitempty"` // "auto", "none", "required", or a tool name
+}
+
+// AgentToolConfig declares a tool by its JSON schema
+type AgentToolConfig struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// AgentLimitsConfig declares the run limits. Timeout uses Go duration syntax, e.g. "90s".
+type AgentLimitsConfig struct {
+	MaxIterations        int    `json:"max_iterations,omitempty"`
+	MaxToolCalls         int    `json:"max_tool_calls,omitempty"`
+	MaxRepeatedToolCalls int    `json:"max_repeated_tool_calls,omitempty"`
+	Timeout              string `json:"timeout,omitempty"`
+	FailOnMaxIterations  bool   `json:"fail_on_max_iterations,omitempty"`
+}
+
+// LoadAgentConfig reads an agent definition from a JSON file. Unknown fields are
+// rejected so typos don't silently fall back to defaults.
+func LoadAgentConfig(path string) (*AgentConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent config: %w", err)
+	}
+	return ParseAgentConfig(data)
+}
+
+// ParseAgentConfig parses an agent definition from JSON
+func ParseAgentConfig(data []byte) (*AgentConfig, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var config AgentConfig
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse agent config: %w", err)
+	}
+	return &config, nil
+}
+
+// AgentFromConfig creates an agent from a definition. Every declared tool must have an
+// executor in registry.
+func AgentFromConfig(client *Client, config *AgentConfig, registry *ToolRegistry) (*Agent, error) {
+	if config.Model == "" && len(config.Models) == 0 {
+		return nil, fmt.Errorf("agent config: model is required")
+	}
+	if registry == nil {
+		registry = NewToolRegistry()
+	}
+
+	// The profile holds a copy so the caller's config can be reused
+	profile := &agentProfile{config: *config}
+
+	for _, tool := range config.Tools {
+		if tool.Name == "" {
+			return nil, fmt.Errorf("agent config: tool without a name")
+		}
+		if !registry.has(tool.Name) {
+			return nil, fmt.Errorf("agent config: tool %s has no registered executor", tool.Name)
+		}
+
+		parameters := tool.Parameters
+		if len(parameters) == 0 {
+			parameters = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		profile.tools = append(profile.tools, models.Tool{
+			Type: "function",
+			Function: models.FunctionDescription{
+				Name:        ToolWireName(tool.Name),
+				Description: tool.Description,
+				Parameters:  parameters,
+			},
+		})
+	}
+
+	switch config.ToolChoice {
+	case "":
+	case string(models.ToolChoiceAuto), string(models.ToolChoiceNone), string(models.ToolChoiceRequired):
+		profile.toolChoice = models.StringToolChoice(config.ToolChoice)
+	default:
+		if !registry.has(config.ToolChoice) {
+			return nil, fmt.Errorf("agent config: tool_choice %s is not a registered tool", config.ToolChoice)
+		}
+		profile.toolChoice = models.NewFunctionToolChoice(ToolWireName(config.ToolChoice))
+	}
+
+	if config.Limits.Timeout != "" {
+		timeout, err := time.ParseDuration(config.Limits.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("agent config: invalid timeout: %w", err)
+		}
+		profile.timeout = timeout
+	}
+
+	return &Agent{
+		client:   client,
+		registry: registry,
+		model:    config.Model,
+		profile:  profile,
+	}, nil
+}
+
+// agentProfile is an agent config resolved by AgentFromConfig
+type agentProfile struct {
+	config     AgentConfig
+	tools      []models.Tool
+	toolChoice models.ToolChoice
+	timeout    time.Duration
+}
+
+// applyRunDefaults fills options the caller left unset from the config
+func (p *agentProfile) applyRunDefaults(opts RunOptions) RunOptions {
+	c := p.config
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = c.Limits.MaxIterations
+	}
+	if opts.MaxToolCalls <= 0 {
+		opts.MaxToolCalls = c.Limits.MaxToolCalls
+	}
+	if opts.MaxRepeatedToolCalls <= 0 {
+		opts.MaxRepeatedToolCalls = c.Limits.MaxRepeatedToolCalls
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = p.timeout
+	}
+	if !opts.FailOnMaxIterations {
+		opts.FailOnMaxIterations = c.Limits.FailOnMaxIterations
+	}
+	if len(opts.Tools) == 0 {
+		opts.Tools = p.tools
+	}
+	if opts.ToolChoice == nil {
+		opts.ToolChoice = p.toolChoice
+	}
+	return opts
+}
+
+// withSystemPrompt prepends the configured system prompt unless the conversation has one
+func (p *agentProfile) withSystemPrompt(messages []models.Message) []models.Message {
+	c := p.config
+	if c.SystemPrompt == "" || (len(messages) > 0 && messages[0].Role == models.RoleSystem) {
+		return messages
+	}
+	return append([]models.Message{models.NewTextMessage(models.RoleSystem, c.SystemPrompt)}, messages...)
+}
+
+// applyRequestDefaults sets the configured request parameters
+func (p *agentProfile) applyRequestDefaults(req *models.ChatCompletionRequest) {
+	c := p.config
+	req.Models = c.Models
+	req.Temperature = c.Temperature
+	req.MaxTokens = c.MaxTokens
+	req.Provider = c.Provider
+	req.Reasoning = c.Reasoning
+}
@@ -0,0 +1,212 @@
+package pkg_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+const supportAgentConfig = `{
+  "model": "openai/gpt-4o",
+  "system_prompt": "You are a support agent.",
+  "temperature": 0.2,
+  "tools": [{"name": "lookup_order", "description": "Find an order", "parameters": {"type": "object"}}],
+  "tool_choice": "lookup_order",
+  "limits": {"max_iterations": 2, "timeout": "1m", "fail_on_max_iterations": true},
+  "provider": {"order": ["openai"]}
+}`
+
+// orderRegistry returns a registry with an executor for lookup_order
+func orderRegistry() *pkg.ToolRegistry {
+	registry := pkg.NewToolRegistry()
+	registry.RegisterFunc("lookup_order", func(call models.ToolCall) (string, error) {
+		return "order 42 shipped", nil
+	})
+	return registry
+}
+
+func TestLoadAgentConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.json")
+	require.NoError(t, os.WriteFile(path, []byte(supportAgentConfig), 0o644))
+
+	config, err := pkg.LoadAgentConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o", config.Model)
+	assert.Equal(t, 0.2, *config.Temperature)
+	require.Len(t, config.Tools, 1)
+	assert.Equal(t, "lookup_order", config.Tools[0].Name)
+	assert.Equal(t, 2, config.Limits.MaxIterations)
+	assert.True(t, config.Limits.FailOnMaxIterations)
+	assert.Equal(t, []string{"openai"}, config.Provider.Order)
+
+	_, err = pkg.LoadAgentConfig(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read agent config")
+	_, err = pkg.ParseAgentConfig([]byte(`{"model": "m", "temprature": 0.2}`))
+	assert.ErrorContains(t, err, `unknown field "temprature"`)
+}
+
+func TestAgentFromConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"no model", `{"system_prompt": "Hi"}`, "model is required"},
+		{"unnamed tool", `{"model": "m", "tools": [{"description": "?"}]}`, "tool without a name"},
+		{"unregistered tool", `{"model": "m", "tools": [{"name": "refund"}]}`, "tool refund has no registered executor"},
+		{"unknown tool choice", `{"model": "m", "tool_choice": "refund"}`, "tool_choice refund is not a registered tool"},
+		{"bad timeout", `{"model": "m", "limits": {"timeout": "soon"}}`, "invalid timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := pkg.ParseAgentConfig([]byte(tt.config))
+			require.NoError(t, err)
+			_, err = pkg.AgentFromConfig(pkg.NewClient("key"), config, orderRegistry())
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestAgentFromConfigRun(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	config, err := pkg.ParseAgentConfig([]byte(supportAgentConfig))
+	require.NoError(t, err)
+	agent, err := pkg.AgentFromConfig(srv.Client(), config, orderRegistry())
+	require.NoError(t, err)
+
+	srv.EnqueueChat(
+		openroutertest.ToolCallReply(openroutertest.NewToolCall("call_1", "lookup_order", map[string]string{"id": "42"})),
+		openroutertest.TextReply("Your order shipped."),
+	)
+	messages, err := agent.Run(context.Background(), openroutertest.NewMessages("Where is order 42?"), pkg.RunOptions{})
+	require.NoError(t, err)
+	final, _ := messages[len(messages)-1].GetTextContent()
+	assert.Equal(t, "Your order shipped.", final)
+
+	// The config supplies the system prompt and request defaults
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o", sent.Model)
+	require.Len(t, sent.Messages, 2)
+	system, _ := sent.Messages[0].GetTextContent()
+	assert.Equal(t, "You are a support agent.", system)
+	assert.Equal(t, 0.2, *sent.Temperature)
+	assert.Equal(t, []string{"openai"}, sent.Provider.Order)
+	require.Len(t, sent.Tools, 1)
+	assert.Equal(t, "lookup_order", sent.Tools[0].Function.Name)
+	assert.Contains(t, string(srv.Requests()[0].Body), `"tool_choice":{"type":"function","function":{"name":"lookup_order"}}`)
+
+	// And the limits
+	srv.SetChatHandler(func(req models.ChatCompletionRequest) openroutertest.Reply {
+		return openroutertest.ToolCallReply(openroutertest.NewToolCall("call_2", "lookup_order", map[string]string{"id": "43"}))
+	})
+	_, err = agent.Run(context.Background(), openroutertest.NewMessages("And order 43?"), pkg.RunOptions{})
+	assert.ErrorIs(t, err, pkg.ErrMaxIterations)
+}
+
+func TestAgentFromConfigRunStreamEvents(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	config, err := pkg.ParseAgentConfig([]byte(supportAgentConfig))
+	require.NoError(t, err)
+	agent, err := pkg.AgentFromConfig(srv.Client(), config, orderRegistry())
+	require.NoError(t, err)
+
+	srv.EnqueueChat(
+		openroutertest.Reply{Events: []openroutertest.StreamEvent{
+			openroutertest.ReasoningChunk("Look the order up."),
+			openroutertest.ToolCallChunk(0, "call_1", "lookup_order", `{"id":`),
+			openroutertest.ToolCallChunk(0, "", "", `"42"}`),
+			openroutertest.FinishChunk("tool_calls"),
+		}},
+		openroutertest.Reply{Events: []openroutertest.StreamEvent{
+			openroutertest.TextChunk("Your order "),
+			openroutertest.TextChunk("shipped."),
+			openroutertest.FinishChunk("stop"),
+		}},
+	)
+
+	// Events arrive in the order they were generated, across turns
+	var events []string
+	messages, err := agent.RunStream(context.Background(), openroutertest.NewMessages("Where is order 42?"), pkg.StreamOptions{
+		OnReasoning: func(delta string) { events = append(events, "reasoning: "+delta) },
+		OnText:      func(delta string) { events = append(events, "text: "+delta) },
+		OnToolCall: func(call models.ToolCall, result string) error {
+			events = append(events, "tool: "+call.Function.Name+call.Function.Arguments+" = "+result)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"reasoning: Look the order up.",
+		`tool: lookup_order{"id":"42"} = order 42 shipped`,
+		"text: Your order ",
+		"text: shipped.",
+	}, events)
+	final, _ := messages[len(messages)-1].GetTextContent()
+	assert.Equal(t, "Your order shipped.", final)
+	second, err := srv.Requests()[1].ChatRequest()
+	require.NoError(t, err)
+	result, _ := second.Messages[len(second.Messages)-1].GetTextContent()
+	assert.Equal(t, "order 42 shipped", result, "the second turn sees the tool result")
+}
+
+func TestAgentFromConfigNamespacedTool(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	registry := pkg.NewToolRegistry()
+	registry.Namespace("orders").RegisterFunc("lookup", func(call models.ToolCall) (string, error) {
+		return "order 42 shipped", nil
+	})
+	config, err := pkg.ParseAgentConfig([]byte(`{
+	  "model": "m",
+	  "tools": [{"name": "orders.lookup", "description": "Find an order"}],
+	  "tool_choice": "orders.lookup"
+	}`))
+	require.NoError(t, err)
+	agent, err := pkg.AgentFromConfig(srv.Client(), config, registry)
+	require.NoError(t, err)
+
+	srv.EnqueueChat(
+		openroutertest.ToolCallReply(openroutertest.NewToolCall("call_1", "orders__lookup", map[string]string{"id": "42"})),
+		openroutertest.TextReply("Your order shipped."),
+	)
+	messages, err := agent.Run(context.Background(), openroutertest.NewMessages("Where is order 42?"), pkg.RunOptions{})
+	require.NoError(t, err)
+	result, _ := messages[2].GetTextContent()
+	assert.Equal(t, "order 42 shipped", result)
+
+	// Tools and tool_choice go out under their wire names, which providers accept
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	require.Len(t, sent.Tools, 1)
+	assert.Equal(t, "orders__lookup", sent.Tools[0].Function.Name)
+	assert.NoError(t, sent.Validate())
+	assert.Contains(t, string(srv.Requests()[0].Body), `"tool_choice":{"type":"function","function":{"name":"orders__lookup"}}`)
+}
+
+func TestAgentFromConfigRequiredToolChoice(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	config, err := pkg.ParseAgentConfig([]byte(`{"model": "m", "tools": [{"name": "lookup_order"}], "tool_choice": "required"}`))
+	require.NoError(t, err)
+	agent, err := pkg.AgentFromConfig(srv.Client(), config, orderRegistry())
+	require.NoError(t, err)
+
+	srv.EnqueueChat(
+		openroutertest.ToolCallReply(openroutertest.NewToolCall("call_1", "lookup_order", map[string]string{"id": "42"})),
+		openroutertest.TextReply("Your order shipped."),
+	)
+	_, err = agent.Run(context.Background(), openroutertest.NewMessages("Where is order 42?"), pkg.RunOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, string(srv.Requests()[0].Body), `"tool_choice":"required"`)
+}
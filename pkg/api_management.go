@@ -2,7 +2,6 @@ package pkg
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"strings"
@@ -40,7 +39,7 @@ func (c *Client) ListAPIKeys(ctx context.Context, opts *ListAPIKeysOptions) (*mo
 	defer resp.Body.Close()
 
 	var result models.APIKeysResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decodeResponse(resp, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -56,7 +55,7 @@ func (c *Client) CreateAPIKey(ctx context.Context, req models.CreateAPIKeyReques
 	defer resp.Body.Close()
 
 	var result models.APIKey
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decodeResponse(resp, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -72,7 +71,7 @@ func (c *Client) GetAPIKey(ctx context.Context, keyHash string) (*models.APIKey,
 	defer resp.Body.Close()
 
 	var result models.APIKey
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decodeResponse(resp, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -87,7 +86,7 @@ func (c *Client) GetCurrentAPIKey(ctx context.Context) (*models.APIKey, error) {
 	defer resp.Body.Close()
 
 	var result models.APIKey
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decodeResponse(resp, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -103,7 +102,7 @@ func (c *Client) UpdateAPIKey(ctx context.Context, keyHash string, req models.Up
 	defer resp.Body.Close()
 
 	var result models.APIKey
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decodeResponse(resp, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -129,7 +128,35 @@ func (c *Client) GetCredits(ctx context.Context) (*models.CreditsResponse, error
 	defer resp.Body.Close()
 
 	var result models.CreditsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decodeResponse(resp, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ActivityOptions contains options for fetching activity
+type ActivityOptions struct {
+	// Date limits the activity to one UTC day, formatted as 2006-01-02. The API returns
+	// the last 30 completed days when it is empty.
+	Date string
+}
+
+// GetActivity returns the usage of the account per day, model and provider endpoint
+// Requires a Provisioning API key
+func (c *Client) GetActivity(ctx context.Context, opts *ActivityOptions) (*models.ActivityResponse, error) {
+	endpoint := "/api/v1/activity"
+	if opts != nil && opts.Date != "" {
+		endpoint += "?" + url.Values{"date": {opts.Date}}.Encode()
+	}
+
+	resp, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result models.ActivityResponse
+	if err := c.decodeResponse(resp, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -144,14 +171,15 @@ func (c *Client) ListProviders(ctx context.Context) (*models.ProvidersResponse,
 	defer resp.Body.Close()
 
 	var result models.ProvidersResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decodeResponse(resp, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
 // ListModelEndpoints returns the available endpoints/providers for a specific model
-// The model parameter should be in the format "author/slug" (e.g., "openai/gpt-4")
+// The model parameter should be in the format "author/slug" (e.g., "openai/gpt-4").
+// Quantization levels the endpoints report are registered with models.RegisterQuantizations.
 func (c *Client) ListModelEndpoints(ctx context.Context, model string) (*models.ModelEndpointsResponse, error) {
 	// Split the model into author and slug
 	parts := strings.Split(model, "/")
@@ -166,9 +194,12 @@ func (c *Client) ListModelEndpoints(ctx context.Context, model string) (*models.
 	defer resp.Body.Close()
 
 	var result models.ModelEndpointsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decodeResponse(resp, &result); err != nil {
 		return nil, err
 	}
+	for _, endpoint := range result.Data {
+		models.RegisterQuantizations(endpoint.Quantization)
+	}
 	return &result, nil
 }
 
@@ -181,7 +212,7 @@ func (c *Client) ExchangeAuthCodeForAPIKey(ctx context.Context, req models.Excha
 	defer resp.Body.Close()
 
 	var result models.ExchangeAuthCodeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decodeResponse(resp, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -196,7 +227,7 @@ func (c *Client) CreateCoinbaseCharge(ctx context.Context, req models.CreateCoin
 	defer resp.Body.Close()
 
 	var result models.CoinbaseChargeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decodeResponse(resp, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -0,0 +1,418 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+	"github.com/rizome-dev/go-openrouter/pkg/webhook"
+)
+
+// DefaultAsyncPollInterval is how often AsyncClient.Wait polls a pending job
+const DefaultAsyncPollInterval = 2 * time.Second
+
+// ErrJobNotFound is returned by a JobStore for unknown job IDs
+var ErrJobNotFound = stderrors.New("job not found")
+
+// JobStatus is the state of an async job
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// AsyncJob is the persisted record of a generation submitted with AsyncClient
+type AsyncJob struct {
+	ID      string                       `json:"id"`
+	Status  JobStatus                    `json:"status"`
+	Request models.ChatCompletionRequest `json:"request"`
+
+	// GenerationID is known once the first chunk of the response arrives
+	GenerationID string `json:"generation_id,omitempty"`
+
+	// Response is set when the job completes
+	Response *models.ChatCompletionResponse `json:"response,omitempty"`
+
+	// Generation is the latest metadata from GetGeneration, fetched by AsyncClient.Job
+	// while the job runs
+	Generation *models.Generation `json:"generation,omitempty"`
+
+	// Error is set when the job fails
+	Error string `json:"error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Done reports whether the job has completed or failed
+func (j *AsyncJob) Done() bool {
+	return j.Status == JobCompleted || j.Status == JobFailed
+}
+
+// JobStore persists async jobs. Implementations must be safe for concurrent use.
+type JobStore interface {
+	SaveJob(ctx context.Context, job *AsyncJob) error
+
+	// LoadJob returns ErrJobNotFound for unknown IDs
+	LoadJob(ctx context.Context, id string) (*AsyncJob, error)
+}
+
+// MemoryJobStore keeps jobs in memory
+type MemoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]AsyncJob
+}
+
+// NewMemoryJobStore creates an empty in-memory job store
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{jobs: make(map[string]AsyncJob)}
+}
+
+// SaveJob stores a copy of the job
+func (s *MemoryJobStore) SaveJob(ctx context.Context, job *AsyncJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+// LoadJob returns a copy of the job
+func (s *MemoryJobStore) LoadJob(ctx context.Context, id string) (*AsyncJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return &job, nil
+}
+
+// FileJobStore keeps each job in a JSON file named after its ID, so jobs survive restarts
+// and can be shared through a mounted volume
+type FileJobStore struct {
+	dir string
+}
+
+// NewFileJobStore creates a job store in dir, creating the directory if needed
+func NewFileJobStore(dir string) (*FileJobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+	return &FileJobStore{dir: dir}, nil
+}
+
+// SaveJob writes the job, replacing any previous version atomically
+func (s *FileJobStore) SaveJob(ctx context.Context, job *AsyncJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, job.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path(job.ID))
+}
+
+// LoadJob reads a job
+func (s *FileJobStore) LoadJob(ctx context.Context, id string) (*AsyncJob, error) {
+	data, err := os.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job: %w", err)
+	}
+
+	var job AsyncJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+	return &job, nil
+}
+
+func (s *FileJobStore) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
+
+// AsyncOptions configures an AsyncClient
+type AsyncOptions struct {
+	// WebhookURL receives each finished job as a JSON POST, e.g. an endpoint serving
+	// AsyncClient.WebhookHandler in the application that submitted it
+	WebhookURL string
+
+	// WebhookSecret, when set, signs webhook deliveries with webhook.Signer, using the job
+	// ID as the delivery ID. WebhookHandler requires it and rejects deliveries that aren't
+	// signed with it, stale or replayed. Share it between the submitter and the worker.
+	WebhookSecret []byte
+
+	// Detached only persists submitted jobs. A worker with a longer execution limit
+	// runs them with RunJob, sharing the JobStore or reporting through WebhookURL.
+	Detached bool
+
+	// PollInterval is how often Wait polls a pending job. Defaults to
+	// DefaultAsyncPollInterval.
+	PollInterval time.Duration
+}
+
+// AsyncClient runs generations in the background and records them as jobs in a JobStore,
+// for environments such as serverless functions whose execution limits are shorter than
+// a generation. Submit returns a job ID at once; the result is collected later with Job
+// or Wait, or delivered to a webhook.
+//
+// OpenRouter has no server-side job queue: a generation's output is only available to
+// the process streaming it, and GetGeneration reports its metadata but not its content.
+// The process running a job must therefore stay alive until it finishes; use Detached
+// with a long-lived worker where that isn't the submitting process.
+type AsyncClient struct {
+	client *Client
+	store  JobStore
+	opts   AsyncOptions
+
+	// Sign and verify webhook deliveries, nil without a WebhookSecret
+	signer   *webhook.Signer
+	verifier *webhook.Verifier
+}
+
+// NewAsyncClient creates an async client that records jobs in store
+func NewAsyncClient(client *Client, store JobStore, opts AsyncOptions) *AsyncClient {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultAsyncPollInterval
+	}
+	a := &AsyncClient{client: client, store: store, opts: opts}
+	if len(opts.WebhookSecret) > 0 {
+		a.signer = webhook.NewSigner(opts.WebhookSecret)
+		a.signer.Now = client.clock.Now
+		a.verifier = webhook.NewVerifier(opts.WebhookSecret)
+		a.verifier.Now = client.clock.Now
+		a.verifier.Replays = &webhook.MemoryReplayCache{Now: client.clock.Now}
+	}
+	return a
+}
+
+// Submit records a pending job for req and, unless the client is Detached, starts running
+// it in the background. The job keeps running after ctx is canceled.
+func (a *AsyncClient) Submit(ctx context.Context, req models.ChatCompletionRequest) (*AsyncJob, error) {
+	now := a.client.clock.Now()
+	job := &AsyncJob{
+		ID:        "job-" + NewIdempotencyKey(),
+		Status:    JobPending,
+		Request:   req,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := a.store.SaveJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to save job: %w", err)
+	}
+
+	if !a.opts.Detached {
+		submitted := *job
+		go a.run(context.WithoutCancel(ctx), &submitted) //nolint:errcheck // the outcome is recorded in the job
+	}
+	return job, nil
+}
+
+// RunJob runs a pending job to completion, e.g. in a worker processing Detached jobs. The
+// outcome is saved to the store and sent to the webhook; the returned error only reports
+// failures to load or save the job.
+func (a *AsyncClient) RunJob(ctx context.Context, id string) error {
+	job, err := a.store.LoadJob(ctx, id)
+	if err != nil {
+		return err
+	}
+	if job.Status != JobPending {
+		return fmt.Errorf("job %s is %s, not pending", id, job.Status)
+	}
+	return a.run(ctx, job)
+}
+
+// run streams the job's request, recording the generation ID as soon as it is known
+func (a *AsyncClient) run(ctx context.Context, job *AsyncJob) error {
+	job.Status = JobRunning
+	if err := a.save(ctx, job); err != nil {
+		return err
+	}
+
+	// The job's ID doubles as the idempotency key, so a rerun isn't billed twice
+	stream, err := a.client.CreateChatCompletionStream(WithIdempotencyKey(ctx, job.ID), job.Request)
+	if err != nil {
+		return a.finish(ctx, job, nil, err)
+	}
+	defer stream.Close()
+
+	acc := streaming.NewAccumulator()
+	for {
+		chunk, err := stream.Read()
+		if err == io.EOF {
+			return a.finish(ctx, job, acc.Response(), nil)
+		}
+		if err != nil {
+			return a.finish(ctx, job, nil, err)
+		}
+
+		acc.Add(chunk)
+		if job.GenerationID == "" && chunk.ID != "" {
+			job.GenerationID = chunk.ID
+			if err := a.save(ctx, job); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// finish records the outcome of a job and sends it to the webhook
+func (a *AsyncClient) finish(ctx context.Context, job *AsyncJob, resp *models.ChatCompletionResponse, runErr error) error {
+	if runErr != nil {
+		job.Status = JobFailed
+		job.Error = runErr.Error()
+	} else {
+		job.Status = JobCompleted
+		job.Response = resp
+	}
+	if err := a.save(ctx, job); err != nil {
+		return err
+	}
+
+	if a.opts.WebhookURL != "" {
+		if err := a.deliver(ctx, job); err != nil {
+			return fmt.Errorf("failed to deliver job %s: %w", job.ID, err)
+		}
+	}
+	return nil
+}
+
+func (a *AsyncClient) save(ctx context.Context, job *AsyncJob) error {
+	job.UpdatedAt = a.client.clock.Now()
+	if err := a.store.SaveJob(ctx, job); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
+
+// deliver posts a finished job to the webhook
+func (a *AsyncClient) deliver(ctx context.Context, job *AsyncJob) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.opts.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.signer != nil {
+		for name, values := range a.signer.HeadersWithID(job.ID, body) {
+			req.Header[name] = values
+		}
+	}
+
+	resp, err := a.client.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Job returns the current state of a job. While a job runs, its generation metadata is
+// refreshed from GetGeneration once the generation ID is known.
+func (a *AsyncClient) Job(ctx context.Context, id string) (*AsyncJob, error) {
+	job, err := a.store.LoadJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !job.Done() && job.GenerationID != "" {
+		// Generation metadata is often not available until shortly after it finishes
+		if gen, err := a.client.GetGeneration(ctx, job.GenerationID); err == nil {
+			job.Generation = &gen.Data
+		}
+	}
+	return job, nil
+}
+
+// Wait polls a job until it completes or fails, or ctx is done
+func (a *AsyncClient) Wait(ctx context.Context, id string) (*AsyncJob, error) {
+	for {
+		job, err := a.store.LoadJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.Done() {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-a.client.clock.After(a.opts.PollInterval):
+		}
+	}
+}
+
+// WebhookHandler returns a handler that records finished jobs posted by an AsyncClient's
+// webhook in this client's store, so Job and Wait see results from another process.
+// Deliveries must be signed with WebhookSecret and report a job already in the store;
+// without a secret every delivery is refused with 401, since anyone could post results.
+func (a *AsyncClient) WebhookHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if a.verifier == nil {
+			http.Error(w, "webhook secret is not configured", http.StatusUnauthorized)
+			return
+		}
+		if _, err := a.verifier.VerifyRequest(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var job AsyncJob
+		if err := json.NewDecoder(r.Body).Decode(&job); err != nil || job.ID == "" || !job.Done() {
+			http.Error(w, "invalid job", http.StatusBadRequest)
+			return
+		}
+		if _, err := a.store.LoadJob(r.Context(), job.ID); err != nil {
+			if stderrors.Is(err, ErrJobNotFound) {
+				http.Error(w, "unknown job", http.StatusNotFound)
+			} else {
+				http.Error(w, "failed to load job", http.StatusInternalServerError)
+			}
+			return
+		}
+		if err := a.store.SaveJob(r.Context(), &job); err != nil {
+			http.Error(w, "failed to save job", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
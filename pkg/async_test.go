@@ -0,0 +1,134 @@
+package pkg_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestAsyncClientSubmitAndWait(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("done in the background"))
+
+	store, err := pkg.NewFileJobStore(t.TempDir())
+	require.NoError(t, err)
+	async := pkg.NewAsyncClient(srv.Client(), store, pkg.AsyncOptions{PollInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	job, err := async.Submit(ctx, models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	assert.Equal(t, pkg.JobPending, job.Status)
+
+	done, err := async.Wait(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pkg.JobCompleted, done.Status)
+	assert.NotEmpty(t, done.GenerationID)
+	require.NotNil(t, done.Response)
+	text, err := done.Response.Choices[0].Message.GetTextContent()
+	require.NoError(t, err)
+	assert.Equal(t, "done in the background", text)
+
+	_, err = async.Job(ctx, "job-missing")
+	assert.ErrorIs(t, err, pkg.ErrJobNotFound)
+}
+
+func TestAsyncClientDetachedWebhook(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.ErrorReply(500, "upstream failed"))
+
+	// The submitting process receives results through its webhook
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	submitter := pkg.NewAsyncClient(srv.Client(), pkg.NewMemoryJobStore(), pkg.AsyncOptions{Detached: true, WebhookSecret: secret})
+	hook := httptest.NewServer(submitter.WebhookHandler())
+	defer hook.Close()
+
+	ctx := context.Background()
+	job, err := submitter.Submit(ctx, models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	assert.Empty(t, srv.Requests(), "detached jobs are not run on submit")
+
+	// A worker sharing the job record runs it and reports back
+	workerStore := pkg.NewMemoryJobStore()
+	require.NoError(t, workerStore.SaveJob(ctx, job))
+	worker := pkg.NewAsyncClient(srv.Client(), workerStore, pkg.AsyncOptions{WebhookURL: hook.URL, WebhookSecret: secret})
+	require.NoError(t, worker.RunJob(ctx, job.ID))
+	assert.Error(t, worker.RunJob(ctx, job.ID), "finished jobs can't be rerun")
+
+	got, err := submitter.Job(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pkg.JobFailed, got.Status)
+	assert.Contains(t, got.Error, "upstream failed")
+}
+
+func TestAsyncClientSignedWebhook(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	submitter := pkg.NewAsyncClient(srv.Client(), pkg.NewMemoryJobStore(), pkg.AsyncOptions{Detached: true, WebhookSecret: secret})
+	hook := httptest.NewServer(submitter.WebhookHandler())
+	defer hook.Close()
+
+	ctx := context.Background()
+	job, err := submitter.Submit(ctx, models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+
+	// Unsigned deliveries are rejected
+	forgedStore := pkg.NewMemoryJobStore()
+	require.NoError(t, forgedStore.SaveJob(ctx, job))
+	forger := pkg.NewAsyncClient(srv.Client(), forgedStore, pkg.AsyncOptions{WebhookURL: hook.URL})
+	assert.ErrorContains(t, forger.RunJob(ctx, job.ID), "status 401")
+	got, err := submitter.Job(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pkg.JobPending, got.Status)
+
+	workerStore := pkg.NewMemoryJobStore()
+	require.NoError(t, workerStore.SaveJob(ctx, job))
+	worker := pkg.NewAsyncClient(srv.Client(), workerStore, pkg.AsyncOptions{WebhookURL: hook.URL, WebhookSecret: secret})
+	require.NoError(t, worker.RunJob(ctx, job.ID))
+
+	got, err = submitter.Job(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pkg.JobCompleted, got.Status)
+
+	// Signed deliveries for jobs the submitter never created are rejected
+	srv.EnqueueChat(openroutertest.TextReply("done"))
+	stranger := &pkg.AsyncJob{ID: "job-stranger", Status: pkg.JobPending, Request: models.NewChatRequest("m", models.WithUserMessage("hi"))}
+	require.NoError(t, workerStore.SaveJob(ctx, stranger))
+	assert.ErrorContains(t, worker.RunJob(ctx, stranger.ID), "status 404")
+	_, err = submitter.Job(ctx, stranger.ID)
+	assert.ErrorIs(t, err, pkg.ErrJobNotFound)
+}
+
+func TestAsyncClientWebhookRequiresSecret(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+
+	submitter := pkg.NewAsyncClient(srv.Client(), pkg.NewMemoryJobStore(), pkg.AsyncOptions{Detached: true})
+	hook := httptest.NewServer(submitter.WebhookHandler())
+	defer hook.Close()
+
+	ctx := context.Background()
+	job, err := submitter.Submit(ctx, models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+
+	// Without a secret nothing is accepted, even results for known jobs
+	workerStore := pkg.NewMemoryJobStore()
+	require.NoError(t, workerStore.SaveJob(ctx, job))
+	worker := pkg.NewAsyncClient(srv.Client(), workerStore, pkg.AsyncOptions{WebhookURL: hook.URL})
+	assert.ErrorContains(t, worker.RunJob(ctx, job.ID), "status 401")
+	got, err := submitter.Job(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pkg.JobPending, got.Status)
+}
@@ -0,0 +1,405 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// AuditRecord is a complete request/response pair sent to an AuditSink. Headers are never
+// recorded and API keys in bodies are masked, e.g. in the responses that create keys, so
+// API keys do not reach the audit log.
+type AuditRecord struct {
+	Time      time.Time     `json:"time"`
+	Operation string        `json:"operation"`
+	Method    string        `json:"method"`
+	URL       string        `json:"url"`
+	Duration  time.Duration `json:"duration"`
+
+	// Request is the JSON request body, decompressed if it was sent compressed
+	Request json.RawMessage `json:"request,omitempty"`
+
+	StatusCode int `json:"status_code,omitempty"`
+
+	// Response is the JSON response body. Streamed chat completions are assembled into
+	// a single chat.completion response; other non-JSON bodies are recorded as a string.
+	Response json.RawMessage `json:"response,omitempty"`
+
+	// Error is set when the request failed before a response was received
+	Error string `json:"error,omitempty"`
+}
+
+// AuditSink receives audit records. Implementations must be safe for concurrent use.
+type AuditSink interface {
+	WriteAudit(record AuditRecord) error
+}
+
+// AuditRedactor modifies a record before it is written, e.g. to remove personal data
+type AuditRedactor func(record *AuditRecord)
+
+// AuditOptions configures audit logging
+type AuditOptions struct {
+	// Redactors run in order on every record before it reaches the sink
+	Redactors []AuditRedactor
+
+	// OnError is called when the sink fails to write a record
+	OnError func(err error)
+}
+
+// WithAuditSink sends every request and its response to sink once the response body has
+// been read. It wraps the HTTP transport, so pass it after WithHTTPClient.
+func WithAuditSink(sink AuditSink, opts AuditOptions) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		base := httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		httpClient.Transport = &auditTransport{base: base, sink: sink, opts: opts}
+		c.httpClient = &httpClient
+	}
+}
+
+// WriterAuditSink writes records as JSON lines to an io.Writer
+type WriterAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterAuditSink creates a sink that writes JSON lines to w
+func NewWriterAuditSink(w io.Writer) *WriterAuditSink {
+	return &WriterAuditSink{w: w}
+}
+
+// WriteAudit implements AuditSink
+func (s *WriterAuditSink) WriteAudit(record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// FileAuditSink appends records as JSON lines to a file
+type FileAuditSink struct {
+	*WriterAuditSink
+	file *os.File
+}
+
+// NewFileAuditSink opens path for appending, creating it if needed
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &FileAuditSink{WriterAuditSink: NewWriterAuditSink(file), file: file}, nil
+}
+
+// Close closes the audit log file
+func (s *FileAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// RedactAuditRequestField returns a redactor that replaces a top-level field of the
+// request body, e.g. RedactAuditRequestField("user", "REDACTED")
+func RedactAuditRequestField(field string, replacement interface{}) AuditRedactor {
+	return func(record *AuditRecord) {
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(record.Request, &body); err != nil {
+			return
+		}
+		if _, ok := body[field]; !ok {
+			return
+		}
+		value, err := json.Marshal(replacement)
+		if err != nil {
+			return
+		}
+		body[field] = value
+		if data, err := json.Marshal(body); err == nil {
+			record.Request = data
+		}
+	}
+}
+
+// RedactAuditContent returns a redactor that replaces the content of request messages and
+// response choices with a placeholder, keeping roles, models, tool names, and usage
+func RedactAuditContent() AuditRedactor {
+	const placeholder = `"[REDACTED]"`
+	return func(record *AuditRecord) {
+		var req map[string]json.RawMessage
+		if err := json.Unmarshal(record.Request, &req); err == nil {
+			var messages []map[string]json.RawMessage
+			if err := json.Unmarshal(req["messages"], &messages); err == nil {
+				for _, message := range messages {
+					redactMessageContent(message, placeholder)
+				}
+				req["messages"], _ = json.Marshal(messages)
+			}
+			if _, ok := req["prompt"]; ok {
+				req["prompt"] = json.RawMessage(placeholder)
+			}
+			if data, err := json.Marshal(req); err == nil {
+				record.Request = data
+			}
+		}
+
+		var resp map[string]json.RawMessage
+		if err := json.Unmarshal(record.Response, &resp); err == nil {
+			var choices []map[string]json.RawMessage
+			if err := json.Unmarshal(resp["choices"], &choices); err == nil {
+				for _, choice := range choices {
+					var message map[string]json.RawMessage
+					if err := json.Unmarshal(choice["message"], &message); err == nil {
+						redactMessageContent(message, placeholder)
+						choice["message"], _ = json.Marshal(message)
+					}
+					if _, ok := choice["text"]; ok {
+						choice["text"] = json.RawMessage(placeholder)
+					}
+				}
+				resp["choices"], _ = json.Marshal(choices)
+			}
+			if data, err := json.Marshal(resp); err == nil {
+				record.Response = data
+			}
+		}
+	}
+}
+
+// redactMessageContent replaces the content, reasoning, and tool arguments of a message
+func redactMessageContent(message map[string]json.RawMessage, placeholder string) {
+	for _, field := range []string{"content", "reasoning"} {
+		if value, ok := message[field]; ok && string(value) != "null" {
+			message[field] = json.RawMessage(placeholder)
+		}
+	}
+
+	var toolCalls []models.ToolCall
+	if err := json.Unmarshal(message["tool_calls"], &toolCalls); err == nil && len(toolCalls) > 0 {
+		for i := range toolCalls {
+			toolCalls[i].Function.Arguments = "[REDACTED]"
+		}
+		message["tool_calls"], _ = json.Marshal(toolCalls)
+	}
+}
+
+// auditTransport records requests and responses
+type auditTransport struct {
+	base http.RoundTripper
+	sink AuditSink
+	opts AuditOptions
+}
+
+func (t *auditTransport) unwrap() http.RoundTripper { return t.base }
+
+func (t *auditTransport) withBase(base http.RoundTripper) http.RoundTripper {
+	return &auditTransport{base: base, sink: t.sink, opts: t.opts}
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	record := AuditRecord{
+		Time:      start,
+		Operation: requestOperation(req),
+		Method:    req.Method,
+		URL:       req.URL.String(),
+		Request:   auditRequestBody(req),
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		record.Duration = time.Since(start)
+		record.Error = err.Error()
+		t.write(record)
+		return nil, err
+	}
+
+	// Decompress here so the audit log sees plain JSON; the client then skips decompression
+	if err := decompressResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	record.StatusCode = resp.StatusCode
+	stream := strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream")
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		done: func(body []byte, total int) {
+			record.Duration = time.Since(start)
+			record.Response = auditResponseBody(body, stream, record.Operation)
+			t.write(record)
+		},
+	}
+	return resp, nil
+}
+
+// write redacts a record and sends it to the sink
+func (t *auditTransport) write(record AuditRecord) {
+	record.Request = redactBodySecrets(record.Request)
+	record.Response = redactBodySecrets(record.Response)
+	for _, redact := range t.opts.Redactors {
+		redact(&record)
+	}
+	if err := t.sink.WriteAudit(record); err != nil && t.opts.OnError != nil {
+		t.opts.OnError(err)
+	}
+}
+
+// auditRequestBody returns the request body as JSON without consuming it
+func auditRequestBody(req *http.Request) json.RawMessage {
+	data := peekRequestBody(req)
+	if data == nil {
+		return nil
+	}
+	return auditJSON(data)
+}
+
+// redactBodySecrets masks API keys in a JSON body: strings that look like OpenRouter keys
+// anywhere, and every string "key" field, which is how key creation and auth code
+// exchange responses return new keys
+func redactBodySecrets(body json.RawMessage) json.RawMessage {
+	if len(body) == 0 {
+		return body
+	}
+	body = json.RawMessage(redactSecrets(string(body)))
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil || !maskKeyFields(value) {
+		return body
+	}
+	masked, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return masked
+}
+
+// maskKeyFields masks the string "key" fields in a decoded JSON value, reporting whether
+// it changed any
+func maskKeyFields(value interface{}) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if key, ok := field.(string); ok && name == "key" {
+				if masked := MaskAPIKey(key); masked != key {
+					v[name] = masked
+					changed = true
+				}
+				continue
+			}
+			if maskKeyFields(field) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if maskKeyFields(item) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// auditResponseBody converts a response body to JSON, assembling streamed chat completions
+func auditResponseBody(body []byte, stream bool, operation string) json.RawMessage {
+	if !stream || operation != opChatCompletionStream {
+		return auditJSON(body)
+	}
+
+	acc := streaming.NewAccumulator()
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), streaming.MaxLineSize)
+	for scanner.Scan() {
+		data, ok := bytes.CutPrefix(scanner.Bytes(), []byte("data:"))
+		if !ok {
+			continue
+		}
+		data = bytes.TrimSpace(data)
+		if bytes.Equal(data, doneMarker) {
+			continue
+		}
+		var chunk models.ChatCompletionResponse
+		if err := json.Unmarshal(data, &chunk); err == nil {
+			acc.Add(&chunk)
+		}
+	}
+
+	assembled, err := json.Marshal(acc.Response())
+	if err != nil {
+		return auditJSON(body)
+	}
+	return assembled
+}
+
+// auditJSON returns data if it is valid JSON and otherwise encodes it as a JSON string
+func auditJSON(data []byte) json.RawMessage {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return append(json.RawMessage(nil), data...)
+	}
+	encoded, _ := json.Marshal(string(data))
+	return encoded
+}
+
+// recordingBody captures what is read from a response body and reports it once
+// the body has been read to the end or closed
+type recordingBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+
+	// limit caps how many bytes are kept; zero keeps the whole body
+	limit int
+
+	// total counts every byte read, including those past the limit
+	total int
+
+	done func(body []byte, total int)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.total += n
+	if b.limit <= 0 {
+		b.buf.Write(p[:n])
+	} else if room := b.limit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *recordingBody) finish() {
+	b.once.Do(func() {
+		b.done(b.buf.Bytes(), b.total)
+	})
+}
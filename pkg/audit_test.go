@@ -0,0 +1,64 @@
+package pkg_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// auditRecords decodes the JSON lines written to an audit log
+func auditRecords(t *testing.T, log *bytes.Buffer) []pkg.AuditRecord {
+	t.Helper()
+	var records []pkg.AuditRecord
+	decoder := json.NewDecoder(log)
+	for decoder.More() {
+		var record pkg.AuditRecord
+		require.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAuditMasksCreatedKeys(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	var log bytes.Buffer
+	client := srv.Client(pkg.WithAuditSink(pkg.NewWriterAuditSink(&log), pkg.AuditOptions{}))
+
+	key, err := client.CreateAPIKey(context.Background(), models.CreateAPIKeyRequest{Name: "ci"})
+	require.NoError(t, err)
+	require.NotEmpty(t, key.Key)
+	assert.Contains(t, key.Key, "sk-or-", "the caller still gets the full key")
+
+	records := auditRecords(t, &log)
+	require.Len(t, records, 1)
+	assert.Equal(t, "api", records[0].Operation)
+	assert.NotContains(t, string(records[0].Response), key.Key)
+	assert.Contains(t, string(records[0].Response), pkg.MaskAPIKey(key.Key))
+}
+
+func TestAuditMasksKeyFields(t *testing.T) {
+	// Keys are masked by field name too, whatever their format
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.AddAuthCode("abc", "user-controlled-secret-1234")
+	var log bytes.Buffer
+	client := srv.Client(pkg.WithAuditSink(pkg.NewWriterAuditSink(&log), pkg.AuditOptions{}))
+
+	resp, err := client.ExchangeAuthCodeForAPIKey(context.Background(), models.ExchangeAuthCodeRequest{Code: "abc"})
+	require.NoError(t, err)
+	assert.Equal(t, "user-controlled-secret-1234", resp.Key)
+
+	records := auditRecords(t, &log)
+	require.Len(t, records, 1)
+	assert.JSONEq(t, `{"key":"user-c...1234","user_id":"user-test"}`, string(records[0].Response))
+	assert.JSONEq(t, `{"code":"abc"}`, string(records[0].Request))
+}
@@ -0,0 +1,71 @@
+package pkg_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// BenchmarkConcurrentThroughput measures requests per second through ConcurrentClient
+// against the mock server, so it reflects client overhead rather than network latency
+func BenchmarkConcurrentThroughput(b *testing.B) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetChatHandler(openroutertest.EchoHandler)
+
+	const batch = 64
+	requests := make([]models.ChatCompletionRequest, batch)
+	for i := range requests {
+		requests[i] = models.NewChatRequest("m", models.WithUserMessage(fmt.Sprintf("request %d of the batch", i)))
+	}
+
+	for _, concurrency := range []int{1, 8, 32} {
+		client := pkg.NewConcurrentClient("key", concurrency, pkg.WithBaseURL(srv.URL))
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, result := range client.CreateChatCompletionsConcurrent(context.Background(), requests) {
+					if result.Error != nil {
+						b.Fatal(result.Error)
+					}
+				}
+				srv.Reset() // drop recorded requests
+			}
+			b.ReportMetric(float64(b.N*batch)/b.Elapsed().Seconds(), "req/s")
+		})
+	}
+}
+
+// BenchmarkStreamEndToEnd measures a streamed completion of 1000 words from request to
+// collected response
+func BenchmarkStreamEndToEnd(b *testing.B) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	text := ""
+	for i := 0; i < 1000; i++ {
+		text += fmt.Sprintf("word%d ", i)
+	}
+	srv.SetChatHandler(func(models.ChatCompletionRequest) openroutertest.Reply {
+		return openroutertest.TextReply(text)
+	})
+	client := srv.Client()
+	req := models.NewChatRequest("m", models.WithUserMessage("hi"))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		stream, err := client.CreateChatCompletionStream(context.Background(), req)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := streaming.CollectStream(stream); err != nil {
+			b.Fatal(err)
+		}
+		stream.Close()
+		srv.Reset()
+	}
+}
@@ -0,0 +1,308 @@
+package pkg
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// DefaultCanarySteps are the fractions of traffic a canary shifts to the new model in turn
+var DefaultCanarySteps = []float64{0.01, 0.05, 0.25, 0.5, 1}
+
+// DefaultCanaryMinRequests is how many requests the new model serves in a step, by
+// default, before the step is judged
+const DefaultCanaryMinRequests = 100
+
+// CanaryPhase is the state of a canary rollout
+type CanaryPhase string
+
+const (
+	// CanaryRunning shifts traffic step by step while the new model meets the criteria
+	CanaryRunning CanaryPhase = "running"
+
+	// CanaryPromoted sends all traffic to the new model after every step passed
+	CanaryPromoted CanaryPhase = "promoted"
+
+	// CanaryRolledBack sends all traffic back to the old model after a step failed
+	CanaryRolledBack CanaryPhase = "rolled_back"
+)
+
+// CanaryCriteria are what the new model must meet in each step. Zero criteria aren't
+// checked.
+type CanaryCriteria struct {
+	// MaxErrorRate is the largest fraction of the new model's requests that may fail
+	MaxErrorRate float64
+
+	// MaxLatency is the largest mean latency of the new model's requests
+	MaxLatency time.Duration
+
+	// MaxLatencyRatio is the largest ratio of the new model's mean latency to the old
+	// model's in the same step, e.g. 1.2 to allow it to be 20% slower
+	MaxLatencyRatio float64
+
+	// MinScore is the lowest mean of the scores passed to RecordScore in a step, e.g.
+	// judge scores of shadow traffic. A step isn't judged until a score is recorded.
+	MinScore float64
+}
+
+// CanaryOptions configures a Canary
+type CanaryOptions struct {
+	// From is the model being replaced, and To the model replacing it. Requests for
+	// other models pass through unchanged. Required.
+	From string
+	To   string
+
+	// Steps are the increasing fractions of From's traffic sent to To, ending at 1.
+	// Defaults to DefaultCanarySteps.
+	Steps []float64
+
+	// MinRequests is how many requests To serves in a step before it is judged. Defaults
+	// to DefaultCanaryMinRequests.
+	MinRequests int
+
+	// MinStepDuration is how long each step lasts at least
+	MinStepDuration time.Duration
+
+	Criteria CanaryCriteria
+
+	// OnTransition, when set, is called with the status after every step change,
+	// promotion or rollback
+	OnTransition func(CanaryStatus)
+}
+
+// CanaryStatus describes a canary rollout
+type CanaryStatus struct {
+	Phase CanaryPhase
+
+	// Step is the index of the current step in CanaryOptions.Steps, and Weight the
+	// fraction of traffic sent to the new model
+	Step   int
+	Weight float64
+
+	// Reason explains a rollback
+	Reason string
+
+	// The current step's observations
+	Requests      int
+	Errors        int
+	Latency       time.Duration
+	BaseRequests  int
+	BaseLatency   time.Duration
+	Scores        int
+	MeanScore     float64
+	StepStartedAt time.Time
+}
+
+// Canary gradually shifts traffic from one model to another. Each step sends a larger
+// fraction of the old model's requests to the new one; once the new model has served
+// MinRequests in a step and met the criteria, the next step begins, until it serves
+// everything. A step that fails the criteria rolls all traffic back to the old model
+// at once. It is safe for concurrent use.
+type Canary struct {
+	client *Client
+	opts   CanaryOptions
+
+	mu     sync.Mutex
+	rand   *rand.Rand
+	status CanaryStatus
+	scores float64
+}
+
+// NewCanary starts a canary rollout of opts.To in place of opts.From
+func NewCanary(client *Client, opts CanaryOptions) (*Canary, error) {
+	if opts.From == "" || opts.To == "" {
+		return nil, stderrors.New("canary requires From and To models")
+	}
+	if len(opts.Steps) == 0 {
+		opts.Steps = DefaultCanarySteps
+	}
+	for i, step := range opts.Steps {
+		if step <= 0 || step > 1 || i > 0 && step <= opts.Steps[i-1] {
+			return nil, fmt.Errorf("canary steps must increase within (0, 1], got %v", opts.Steps)
+		}
+	}
+	if opts.MinRequests <= 0 {
+		opts.MinRequests = DefaultCanaryMinRequests
+	}
+
+	c := &Canary{client: client, opts: opts, rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	c.status = CanaryStatus{Phase: CanaryRunning, Weight: opts.Steps[0], StepStartedAt: client.clock.Now()}
+	return c, nil
+}
+
+// CreateChatCompletion creates a chat completion, sending requests for the old model to
+// the new one in proportion to the current step
+func (c *Canary) CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	canary, observed := c.route(&req)
+	start := c.client.clock.Now()
+	resp, err := c.client.CreateChatCompletion(ctx, req)
+	if observed {
+		c.observe(canary, c.client.clock.Now().Sub(start), err)
+	}
+	return resp, err
+}
+
+// CreateChatCompletionStream streams a chat completion, routed like CreateChatCompletion.
+// Streams are observed when they end, with their duration as latency.
+func (c *Canary) CreateChatCompletionStream(ctx context.Context, req models.ChatCompletionRequest) (*streaming.ChatCompletionStreamReader, error) {
+	canary, observed := c.route(&req)
+	stream, err := c.client.CreateChatCompletionStream(ctx, req)
+	if !observed {
+		return stream, err
+	}
+	if err != nil {
+		c.observe(canary, 0, err)
+		return nil, err
+	}
+	stream.OnComplete(func(summary streaming.StreamSummary) {
+		c.observe(canary, summary.Duration, nil)
+	})
+	return stream, nil
+}
+
+// RecordScore records a quality score of the new model's output for the current step,
+// e.g. a judge's score of a ShadowResult
+func (c *Canary) RecordScore(score float64) {
+	c.mu.Lock()
+	if c.status.Phase != CanaryRunning {
+		c.mu.Unlock()
+		return
+	}
+	c.status.Scores++
+	c.scores += score
+	c.status.MeanScore = c.scores / float64(c.status.Scores)
+	transition := c.judgeLocked()
+	status := c.status
+	c.mu.Unlock()
+	c.notify(transition, status)
+}
+
+// Rollback sends all traffic back to the old model, e.g. on an operator's decision
+func (c *Canary) Rollback(reason string) {
+	c.mu.Lock()
+	transition := c.status.Phase != CanaryRolledBack
+	c.rollbackLocked(reason)
+	status := c.status
+	c.mu.Unlock()
+	c.notify(transition, status)
+}
+
+// Status returns the rollout's state
+func (c *Canary) Status() CanaryStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// route picks the model for req, returning whether it goes to the new model and whether
+// its outcome counts towards the current step
+func (c *Canary) route(req *models.ChatCompletionRequest) (canary, observed bool) {
+	if req.Model != c.opts.From {
+		return false, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch c.status.Phase {
+	case CanaryPromoted:
+		req.Model = c.opts.To
+		return true, false
+	case CanaryRolledBack:
+		return false, false
+	}
+	if c.rand.Float64() < c.status.Weight {
+		req.Model = c.opts.To
+		return true, true
+	}
+	return false, true
+}
+
+// observe records the outcome of a request in the current step, and judges the step
+func (c *Canary) observe(canary bool, latency time.Duration, err error) {
+	c.mu.Lock()
+	if c.status.Phase != CanaryRunning {
+		c.mu.Unlock()
+		return
+	}
+	switch {
+	case !canary:
+		if err == nil {
+			c.status.BaseRequests++
+			c.status.BaseLatency += (latency - c.status.BaseLatency) / time.Duration(c.status.BaseRequests)
+		}
+	case err != nil:
+		c.status.Requests++
+		c.status.Errors++
+	default:
+		c.status.Requests++
+		succeeded := time.Duration(c.status.Requests - c.status.Errors)
+		c.status.Latency += (latency - c.status.Latency) / succeeded
+	}
+	transition := c.judgeLocked()
+	status := c.status
+	c.mu.Unlock()
+	c.notify(transition, status)
+}
+
+// judgeLocked advances or rolls back the rollout once the current step has enough
+// observations, reporting whether it did
+func (c *Canary) judgeLocked() bool {
+	s := &c.status
+	if s.Phase != CanaryRunning || s.Requests < c.opts.MinRequests {
+		return false
+	}
+	if reason := c.failureLocked(); reason != "" {
+		c.rollbackLocked(reason)
+		return true
+	}
+	now := c.client.clock.Now()
+	if c.opts.Criteria.MinScore > 0 && s.Scores == 0 || now.Sub(s.StepStartedAt) < c.opts.MinStepDuration {
+		return false
+	}
+
+	step := s.Step + 1
+	if step == len(c.opts.Steps) {
+		*s = CanaryStatus{Phase: CanaryPromoted, Step: s.Step, Weight: 1, StepStartedAt: now}
+		return true
+	}
+	*s = CanaryStatus{Phase: CanaryRunning, Step: step, Weight: c.opts.Steps[step], StepStartedAt: now}
+	c.scores = 0
+	return true
+}
+
+// failureLocked returns why the current step fails the criteria, or ""
+func (c *Canary) failureLocked() string {
+	s, criteria := c.status, c.opts.Criteria
+	if rate := float64(s.Errors) / float64(s.Requests); criteria.MaxErrorRate > 0 && rate > criteria.MaxErrorRate {
+		return fmt.Sprintf("error rate %.1f%% exceeds %.1f%%", rate*100, criteria.MaxErrorRate*100)
+	}
+	if criteria.MaxLatency > 0 && s.Latency > criteria.MaxLatency {
+		return fmt.Sprintf("mean latency %s exceeds %s", s.Latency, criteria.MaxLatency)
+	}
+	if criteria.MaxLatencyRatio > 0 && s.BaseRequests > 0 && s.BaseLatency > 0 {
+		if ratio := float64(s.Latency) / float64(s.BaseLatency); ratio > criteria.MaxLatencyRatio {
+			return fmt.Sprintf("mean latency is %.2fx the old model's, over %.2fx", ratio, criteria.MaxLatencyRatio)
+		}
+	}
+	if criteria.MinScore > 0 && s.Scores > 0 && s.MeanScore < criteria.MinScore {
+		return fmt.Sprintf("mean score %.2f is below %.2f", s.MeanScore, criteria.MinScore)
+	}
+	return ""
+}
+
+func (c *Canary) rollbackLocked(reason string) {
+	c.status = CanaryStatus{Phase: CanaryRolledBack, Step: c.status.Step, Reason: reason, StepStartedAt: c.client.clock.Now()}
+	c.scores = 0
+}
+
+// notify calls OnTransition outside the lock
+func (c *Canary) notify(transition bool, status CanaryStatus) {
+	if transition && c.opts.OnTransition != nil {
+		c.opts.OnTransition(status)
+	}
+}
@@ -0,0 +1,132 @@
+package pkg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+var oldModelRequest = models.NewChatRequest("old/model", models.WithUserMessage("Hi"))
+
+// sendUntil sends requests for the old model until done reports true, failing after 1000
+func sendUntil(t *testing.T, canary *pkg.Canary, done func(pkg.CanaryStatus) bool) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		if done(canary.Status()) {
+			return
+		}
+		canary.CreateChatCompletion(context.Background(), oldModelRequest)
+	}
+	t.Fatalf("canary stuck at %+v", canary.Status())
+}
+
+func TestCanaryPromotes(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetChatHandler(byModel)
+
+	var transitions []pkg.CanaryStatus
+	canary, err := pkg.NewCanary(srv.Client(), pkg.CanaryOptions{
+		From:         "old/model",
+		To:           "new/model",
+		Steps:        []float64{0.5, 1},
+		MinRequests:  3,
+		Criteria:     pkg.CanaryCriteria{MaxErrorRate: 0.1},
+		OnTransition: func(status pkg.CanaryStatus) { transitions = append(transitions, status) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, canary.Status().Weight)
+
+	sendUntil(t, canary, func(s pkg.CanaryStatus) bool { return s.Phase == pkg.CanaryPromoted })
+	require.Len(t, transitions, 2)
+	assert.Equal(t, 1, transitions[0].Step)
+	assert.Equal(t, pkg.CanaryPromoted, transitions[1].Phase)
+
+	// Requests for other models pass through, and the old model's go to the new one
+	resp, err := canary.CreateChatCompletion(context.Background(), hiRequest)
+	require.NoError(t, err)
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "answer from m", text)
+	resp, err = canary.CreateChatCompletion(context.Background(), oldModelRequest)
+	require.NoError(t, err)
+	text, _ = resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "answer from new/model", text)
+}
+
+func TestCanaryRollsBackOnErrors(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetChatHandler(func(req models.ChatCompletionRequest) openroutertest.Reply {
+		if req.Model == "new/model" {
+			return openroutertest.ErrorReply(400, "unsupported parameter")
+		}
+		return byModel(req)
+	})
+
+	canary, err := pkg.NewCanary(srv.Client(), pkg.CanaryOptions{
+		From:        "old/model",
+		To:          "new/model",
+		Steps:       []float64{0.5, 1},
+		MinRequests: 2,
+		Criteria:    pkg.CanaryCriteria{MaxErrorRate: 0.1},
+	})
+	require.NoError(t, err)
+
+	sendUntil(t, canary, func(s pkg.CanaryStatus) bool { return s.Phase == pkg.CanaryRolledBack })
+	status := canary.Status()
+	assert.Contains(t, status.Reason, "error rate 100.0% exceeds 10.0%")
+
+	for i := 0; i < 10; i++ {
+		resp, err := canary.CreateChatCompletion(context.Background(), oldModelRequest)
+		require.NoError(t, err, "all traffic is back on the old model")
+		text, _ := resp.Choices[0].Message.GetTextContent()
+		assert.Equal(t, "answer from old/model", text)
+	}
+}
+
+func TestCanaryScores(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetChatHandler(byModel)
+
+	canary, err := pkg.NewCanary(srv.Client(), pkg.CanaryOptions{
+		From:        "old/model",
+		To:          "new/model",
+		Steps:       []float64{0.5, 1},
+		MinRequests: 2,
+		Criteria:    pkg.CanaryCriteria{MinScore: 0.8},
+	})
+	require.NoError(t, err)
+
+	// Without scores the step can't pass
+	sendUntil(t, canary, func(s pkg.CanaryStatus) bool { return s.Requests >= 5 })
+	assert.Equal(t, 0, canary.Status().Step)
+
+	canary.RecordScore(0.9)
+	assert.Equal(t, 1, canary.Status().Step)
+
+	sendUntil(t, canary, func(s pkg.CanaryStatus) bool { return s.Requests >= 2 })
+	canary.RecordScore(0.5)
+	assert.Equal(t, pkg.CanaryRolledBack, canary.Status().Phase)
+	assert.Contains(t, canary.Status().Reason, "mean score 0.50 is below 0.80")
+}
+
+func TestCanaryOptionsValidation(t *testing.T) {
+	client := pkg.NewClient("key")
+	_, err := pkg.NewCanary(client, pkg.CanaryOptions{From: "a"})
+	assert.Error(t, err)
+	_, err = pkg.NewCanary(client, pkg.CanaryOptions{From: "a", To: "b", Steps: []float64{0.5, 0.25}})
+	assert.Error(t, err)
+
+	canary, err := pkg.NewCanary(client, pkg.CanaryOptions{From: "a", To: "b"})
+	require.NoError(t, err)
+	canary.Rollback("operator decision")
+	assert.Equal(t, pkg.CanaryRolledBack, canary.Status().Phase)
+	assert.Equal(t, "operator decision", canary.Status().Reason)
+}
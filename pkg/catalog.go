@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// DefaultCatalogTTL is how long a ModelCatalog keeps the model list before refetching it
+const DefaultCatalogTTL = time.Hour
+
+// ModelCatalog caches the model list so capability checks don't call the API on every
+// request. It is safe for concurrent use.
+type ModelCatalog struct {
+	client *Client
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	models  map[string]models.Model
+	fetched time.Time
+}
+
+// NewModelCatalog creates a catalog that refetches the model list after ttl, or after
+// DefaultCatalogTTL if ttl is zero
+func NewModelCatalog(client *Client, ttl time.Duration) *ModelCatalog {
+	if ttl <= 0 {
+		ttl = DefaultCatalogTTL
+	}
+	return &ModelCatalog{client: client, ttl: ttl}
+}
+
+// Model returns the catalog entry for a model ID. Variants such as "model:free" fall back
+// to the base model. It returns nil if the model isn't in the catalog.
+func (c *ModelCatalog) Model(ctx context.Context, id string) (*models.Model, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.models == nil || c.client.clock.Now().Sub(c.fetched) > c.ttl {
+		if err := c.refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if model, ok := c.models[id]; ok {
+		return &model, nil
+	}
+	if base, _, ok := strings.Cut(id, ":"); ok {
+		if model, ok := c.models[base]; ok {
+			return &model, nil
+		}
+	}
+	return nil, nil
+}
+
+// Refresh refetches the model list
+func (c *ModelCatalog) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refresh(ctx)
+}
+
+func (c *ModelCatalog) refresh(ctx context.Context) error {
+	resp, err := c.client.ListModels(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	c.models = make(map[string]models.Model, len(resp.Data))
+	for _, model := range resp.Data {
+		c.models[model.ID] = model
+	}
+	c.fetched = c.client.clock.Now()
+	return nil
+}
+
+// ValidateRequest checks that the request's model supports the parameters it sets, such
+// as tools, tool_choice, or parallel_tool_calls. Models missing from the catalog pass.
+func (c *ModelCatalog) ValidateRequest(ctx context.Context, req models.ChatCompletionRequest) error {
+	model, err := c.Model(ctx, req.Model)
+	if err != nil {
+		return fmt.Errorf("failed to load model catalog: %w", err)
+	}
+	if model == nil {
+		return nil
+	}
+	return req.ValidateForModel(*model)
+}
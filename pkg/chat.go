@@ -2,8 +2,8 @@ package pkg
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/rizome-dev/go-openrouter/pkg/models"
 	"github.com/rizome-dev/go-openrouter/pkg/streaming"
@@ -13,6 +13,20 @@ import (
 func (c *Client) CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
 	// Ensure streaming is disabled for non-streaming endpoint
 	req.Stream = false
+	req.StreamOptions = nil
+	c.applyDefaults(ctx, &req)
+	if err := c.decorate(ctx, &req); err != nil {
+		return nil, err
+	}
+	if err := c.systemPrompts.apply(ctx, &req); err != nil {
+		return nil, err
+	}
+	if err := c.validate(&req); err != nil {
+		return nil, err
+	}
+	if cached, ok := c.killSwitch.cached(req); ok {
+		return cached, nil
+	}
 
 	resp, err := c.doRequest(ctx, "POST", "/chat/completions", req)
 	if err != nil {
@@ -21,9 +35,13 @@ func (c *Client) CreateChatCompletion(ctx context.Context, req models.ChatComple
 	defer resp.Body.Close()
 
 	var completionResp models.ChatCompletionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&completionResp); err != nil {
+	if err := c.decodeResponse(resp, &completionResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	if err := c.PostProcess(&completionResp); err != nil {
+		return nil, err
+	}
+	c.killSwitch.remember(req, &completionResp)
 
 	return &completionResp, nil
 }
@@ -32,11 +50,40 @@ func (c *Client) CreateChatCompletion(ctx context.Context, req models.ChatComple
 func (c *Client) CreateChatCompletionStream(ctx context.Context, req models.ChatCompletionRequest) (*streaming.ChatCompletionStreamReader, error) {
 	// Ensure streaming is enabled
 	req.Stream = true
+	c.applyDefaults(ctx, &req)
+	if err := c.decorate(ctx, &req); err != nil {
+		return nil, err
+	}
+	if err := c.systemPrompts.apply(ctx, &req); err != nil {
+		return nil, err
+	}
+	if err := c.validate(&req); err != nil {
+		return nil, err
+	}
+	if cached, ok := c.killSwitch.cached(req); ok {
+		return c.cachedStream(cached)
+	}
 
+	start := time.Now()
 	resp, err := c.doRequest(ctx, "POST", "/chat/completions", req)
 	if err != nil {
 		return nil, err
 	}
 
-	return streaming.NewChatCompletionStreamReader(resp.Body), nil
+	stream := streaming.NewChatCompletionStreamReaderWithCodec(resp.Body, c.streamCodec())
+	stream.SetStartTime(start)
+	if c.metrics != nil {
+		stream.OnComplete(func(summary streaming.StreamSummary) {
+			recordStreamSummary(c.metrics, summary)
+		})
+	}
+	return stream, nil
+}
+
+// validate checks a request before it is sent, so bad requests fail without a round trip
+func (c *Client) validate(req *models.ChatCompletionRequest) error {
+	if c.skipValidation {
+		return nil
+	}
+	return req.Validate()
 }
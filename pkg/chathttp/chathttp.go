@@ -0,0 +1,330 @@
+// Package chathttp provides net/http handlers for chat backends: they accept a chat
+// request from a browser, authenticate the user, enforce per-user rate limits and
+// budgets, call OpenRouter, and return the completion as JSON or stream it as
+// server-sent events.
+//
+//	opts := chathttp.Options{
+//		Authenticate: func(r *http.Request) (string, error) { return sessionUser(r) },
+//		Limiters:     []chathttp.Limiter{chathttp.NewRateLimiter(20, time.Minute), chathttp.NewBudget(1, 24*time.Hour)},
+//		DefaultModel: "openai/gpt-4o-mini",
+//	}
+//	http.Handle("/api/chat", chathttp.ChatHandler(client, opts))
+//	http.Handle("/api/chat/stream", chathttp.SSEHandler(client, opts))
+package chathttp
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/errors"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// DefaultMaxBodyBytes is the largest request body the handlers accept by default
+const DefaultMaxBodyBytes = 1 << 20
+
+// Client is the part of a go-openrouter client the handlers use, implemented by
+// *pkg.Client and *pkg.ObservableClient
+type Client interface {
+	CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, req models.ChatCompletionRequest) (*streaming.ChatCompletionStreamReader, error)
+}
+
+// Request is the chat request accepted from browsers. Everything else, such as tools or
+// provider preferences, is set on the server with Options.Prepare.
+type Request struct {
+	Model       string           `json:"model,omitempty"`
+	Messages    []models.Message `json:"messages"`
+	MaxTokens   *int             `json:"max_tokens,omitempty"`
+	Temperature *float64         `json:"temperature,omitempty"`
+}
+
+// Authenticator identifies the user making a request, or rejects it with an error
+type Authenticator func(r *http.Request) (user string, err error)
+
+// Options configures the handlers
+type Options struct {
+	// Authenticate identifies users. Without it every request is anonymous and limits
+	// are shared by all users.
+	Authenticate Authenticator
+
+	// Limiters admit requests in order, and are charged with their usage
+	Limiters []Limiter
+
+	// DefaultModel is used when the request names none
+	DefaultModel string
+
+	// AllowedModels restricts the models browsers may request. Any model is allowed
+	// when empty.
+	AllowedModels []string
+
+	// MaxTokens caps the completion length, whether or not the request sets one
+	MaxTokens int
+
+	// Prepare adjusts the request before it is sent, e.g. to add a system prompt or
+	// tools. Returning an error rejects the request with 400 Bad Request.
+	Prepare func(r *http.Request, user string, req *models.ChatCompletionRequest) error
+
+	// MaxBodyBytes limits the request body. Defaults to DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+
+	// OnError is called with errors returned to the browser, e.g. for logging
+	OnError func(r *http.Request, err error)
+}
+
+// ErrorResponse is the body of error responses
+type ErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// badRequestError rejects a request the browser got wrong
+type badRequestError struct {
+	err error
+}
+
+func (e *badRequestError) Error() string { return e.err.Error() }
+func (e *badRequestError) Unwrap() error { return e.err }
+
+// unauthorizedError rejects a request Authenticate refused
+type unauthorizedError struct {
+	err error
+}
+
+func (e *unauthorizedError) Error() string { return e.err.Error() }
+func (e *unauthorizedError) Unwrap() error { return e.err }
+
+// ChatHandler returns a handler that answers POSTed Requests with a
+// models.ChatCompletionResponse
+func ChatHandler(client Client, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, user, req, err := opts.admit(r)
+		if err != nil {
+			opts.fail(w, r, err)
+			return
+		}
+
+		resp, err := client.CreateChatCompletion(ctx, req)
+		if err != nil {
+			opts.fail(w, r, err)
+			return
+		}
+		opts.record(ctx, user, resp.Usage)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// SSEHandler returns a handler that streams the completion of POSTed Requests as
+// server-sent events: one "data:" event per chunk, then "data: [DONE]". An error after
+// the stream started is sent as an "error" event carrying an ErrorResponse. The
+// upstream request is canceled when the browser disconnects.
+func SSEHandler(client Client, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, user, req, err := opts.admit(r)
+		if err != nil {
+			opts.fail(w, r, err)
+			return
+		}
+
+		stream, err := client.CreateChatCompletionStream(ctx, req)
+		if err != nil {
+			opts.fail(w, r, err)
+			return
+		}
+		defer stream.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher, _ := w.(http.Flusher)
+
+		for {
+			chunk, err := stream.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					if opts.OnError != nil {
+						opts.OnError(r, err)
+					}
+					_, body := errorBody(err)
+					data, _ := json.Marshal(body)
+					fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+				}
+				opts.record(ctx, user, stream.Summary().Usage)
+				return
+			}
+			data, err := json.Marshal(chunk)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				break // the browser went away
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		opts.record(ctx, user, stream.Summary().Usage)
+		fmt.Fprint(w, "data: [DONE]\n\n")
+		if flusher != nil {
+			flusher.Flush()
+		}
+	})
+}
+
+// admit authenticates, decodes, limits and prepares a request
+func (o Options) admit(r *http.Request) (context.Context, string, models.ChatCompletionRequest, error) {
+	var req models.ChatCompletionRequest
+	if r.Method != http.MethodPost {
+		return nil, "", req, &badRequestError{fmt.Errorf("method %s not allowed", r.Method)}
+	}
+
+	var user string
+	if o.Authenticate != nil {
+		var err error
+		if user, err = o.Authenticate(r); err != nil {
+			return nil, "", req, &unauthorizedError{err}
+		}
+	}
+
+	maxBody := o.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = DefaultMaxBodyBytes
+	}
+	var in Request
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBody)).Decode(&in); err != nil {
+		return nil, "", req, &badRequestError{fmt.Errorf("invalid request body: %w", err)}
+	}
+	if len(in.Messages) == 0 {
+		return nil, "", req, &badRequestError{fmt.Errorf("messages are required")}
+	}
+
+	model := in.Model
+	if model == "" {
+		model = o.DefaultModel
+	}
+	if !o.modelAllowed(model) {
+		return nil, "", req, &badRequestError{fmt.Errorf("model %q is not allowed", model)}
+	}
+
+	req = models.ChatCompletionRequest{
+		Model:       model,
+		Messages:    in.Messages,
+		MaxTokens:   in.MaxTokens,
+		Temperature: in.Temperature,
+	}
+	if o.MaxTokens > 0 && (req.MaxTokens == nil || *req.MaxTokens > o.MaxTokens) {
+		req.MaxTokens = models.Ptr(o.MaxTokens)
+	}
+	// Usage accounting reports the cost budgets are charged with
+	models.WithUsageAccounting()(&req)
+	if o.Prepare != nil {
+		if err := o.Prepare(r, user, &req); err != nil {
+			return nil, "", req, &badRequestError{err}
+		}
+	}
+
+	ctx := r.Context()
+	if user != "" {
+		ctx = pkg.ContextWithUser(ctx, user)
+	}
+	for _, limiter := range o.Limiters {
+		if err := limiter.Allow(ctx, user); err != nil {
+			return nil, "", req, err
+		}
+	}
+	return ctx, user, req, nil
+}
+
+func (o Options) modelAllowed(model string) bool {
+	if len(o.AllowedModels) == 0 {
+		return true
+	}
+	for _, allowed := range o.AllowedModels {
+		if model == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// record charges usage to every limiter
+func (o Options) record(ctx context.Context, user string, usage *models.Usage) {
+	// The request may have been canceled by the browser; the usage still counts
+	ctx = context.WithoutCancel(ctx)
+	for _, limiter := range o.Limiters {
+		limiter.Record(ctx, user, usage)
+	}
+}
+
+// fail writes err as an ErrorResponse
+func (o Options) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if o.OnError != nil {
+		o.OnError(r, err)
+	}
+	status, body := errorBody(err)
+	var limitErr *LimitError
+	if stderrors.As(err, &limitErr) && limitErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limitErr.RetryAfter.Seconds()))))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// errorBody maps err to a status and a message safe to show to browsers
+func errorBody(err error) (int, ErrorResponse) {
+	var body ErrorResponse
+	status := http.StatusBadGateway
+	message := "the model provider failed"
+
+	var badRequest *badRequestError
+	var unauthorized *unauthorizedError
+	var validation *models.ValidationError
+	var apiErr *errors.APIError
+	switch {
+	case stderrors.As(err, &badRequest), stderrors.As(err, &validation):
+		status, message = http.StatusBadRequest, err.Error()
+	case stderrors.As(err, &unauthorized):
+		status, message = http.StatusUnauthorized, "unauthorized"
+	case stderrors.Is(err, ErrRateLimited):
+		status, message = http.StatusTooManyRequests, err.Error()
+	case stderrors.Is(err, ErrBudgetExceeded):
+		status, message = http.StatusPaymentRequired, err.Error()
+	case stderrors.As(err, &apiErr):
+		switch apiErr.Code {
+		case errors.ErrorCodeBadRequest:
+			// Usually the browser's messages; the upstream message explains what's wrong
+			status, message = http.StatusBadRequest, apiErr.Message
+		case errors.ErrorCodeRateLimited:
+			status, message = http.StatusTooManyRequests, "the model is busy, try again later"
+		case errors.ErrorCodeTimeout, errors.ErrorCodeGatewayTimeout:
+			status, message = http.StatusGatewayTimeout, "the model timed out"
+		case errors.ErrorCodeForbidden:
+			status, message = http.StatusForbidden, "the request was flagged by moderation"
+		}
+	default:
+		var limitErr *LimitError
+		if stderrors.As(err, &limitErr) {
+			status, message = http.StatusTooManyRequests, err.Error()
+		}
+	}
+	body.Error.Code = status
+	body.Error.Message = message
+	return status, body
+}
@@ -0,0 +1,265 @@
+package chathttp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg/chathttp"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func costlyReply(text string, cost float64) openroutertest.Reply {
+	resp := openroutertest.NewTextResponse(text)
+	resp.Usage.Cost = cost
+	return openroutertest.Reply{Response: resp}
+}
+
+func post(t *testing.T, h http.Handler, body string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/chat", strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) chathttp.ErrorResponse {
+	t.Helper()
+	var body chathttp.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body
+}
+
+func headerAuth(r *http.Request) (string, error) {
+	user := r.Header.Get("X-User")
+	if user == "" {
+		return "", errors.New("no session")
+	}
+	return user, nil
+}
+
+const hello = `{"messages":[{"role":"user","content":"Hello"}]}`
+
+func TestChatHandler(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("Hi there"))
+
+	h := chathttp.ChatHandler(srv.Client(), chathttp.Options{
+		Authenticate: headerAuth,
+		DefaultModel: "openai/gpt-4o-mini",
+		MaxTokens:    256,
+		Prepare: func(r *http.Request, user string, req *models.ChatCompletionRequest) error {
+			req.Messages = append([]models.Message{models.System(models.Text("Be brief."))}, req.Messages...)
+			return nil
+		},
+	})
+	rec := post(t, h, `{"messages":[{"role":"user","content":"Hello"}],"max_tokens":100000}`, "X-User", "alice")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp models.ChatCompletionResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "Hi there", text)
+
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o-mini", sent.Model)
+	assert.Equal(t, "alice", sent.User)
+	assert.Equal(t, 256, *sent.MaxTokens)
+	require.Len(t, sent.Messages, 2)
+	assert.Equal(t, models.RoleSystem, sent.Messages[0].Role)
+	require.NotNil(t, sent.Usage)
+	assert.True(t, sent.Usage.Include)
+}
+
+func TestChatHandlerRejectsRequests(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	h := chathttp.ChatHandler(srv.Client(), chathttp.Options{
+		Authenticate:  headerAuth,
+		DefaultModel:  "openai/gpt-4o-mini",
+		AllowedModels: []string{"openai/gpt-4o-mini"},
+	})
+
+	rec := post(t, h, hello)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "unauthorized", decodeError(t, rec).Error.Message)
+
+	rec = post(t, h, `{"model":"openai/o1","messages":[{"role":"user","content":"Hello"}]}`, "X-User", "alice")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, decodeError(t, rec).Error.Message, "not allowed")
+
+	rec = post(t, h, `{"messages":[]}`, "X-User", "alice")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = post(t, h, `not json`, "X-User", "alice")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	assert.Empty(t, srv.Requests())
+}
+
+func TestChatHandlerMapsAPIErrors(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	h := chathttp.ChatHandler(srv.Client(), chathttp.Options{DefaultModel: "openai/gpt-4o-mini"})
+
+	srv.EnqueueChat(openroutertest.ErrorReply(http.StatusUnauthorized, "invalid api key sk-or-secret"))
+	rec := post(t, h, hello)
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "sk-or-secret")
+
+	srv.EnqueueChat(openroutertest.ErrorReply(http.StatusBadRequest, "context too long"))
+	rec = post(t, h, hello)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "context too long", decodeError(t, rec).Error.Message)
+}
+
+func TestRateLimiter(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	clock := openroutertest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := chathttp.NewRateLimiter(2, time.Minute)
+	limiter.SetClock(clock)
+	h := chathttp.ChatHandler(srv.Client(), chathttp.Options{
+		Authenticate: headerAuth,
+		Limiters:     []chathttp.Limiter{limiter},
+		DefaultModel: "openai/gpt-4o-mini",
+	})
+
+	assert.Equal(t, http.StatusOK, post(t, h, hello, "X-User", "alice").Code)
+	assert.Equal(t, http.StatusOK, post(t, h, hello, "X-User", "alice").Code)
+	rec := post(t, h, hello, "X-User", "alice")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+
+	// Other users have their own bucket
+	assert.Equal(t, http.StatusOK, post(t, h, hello, "X-User", "bob").Code)
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, http.StatusOK, post(t, h, hello, "X-User", "alice").Code)
+}
+
+func TestBudget(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	clock := openroutertest.NewFakeClock(time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC))
+	budget := chathttp.NewBudget(0.01, 24*time.Hour)
+	budget.SetClock(clock)
+	h := chathttp.ChatHandler(srv.Client(), chathttp.Options{
+		Authenticate: headerAuth,
+		Limiters:     []chathttp.Limiter{budget},
+		DefaultModel: "openai/gpt-4o-mini",
+	})
+
+	srv.EnqueueChat(costlyReply("one", 0.006))
+	srv.EnqueueChat(costlyReply("two", 0.006))
+	assert.Equal(t, http.StatusOK, post(t, h, hello, "X-User", "alice").Code)
+	assert.Equal(t, http.StatusOK, post(t, h, hello, "X-User", "alice").Code)
+	assert.InDelta(t, 0.012, budget.Spent("alice"), 1e-9)
+
+	rec := post(t, h, hello, "X-User", "alice")
+	assert.Equal(t, http.StatusPaymentRequired, rec.Code)
+	assert.Equal(t, "21600", rec.Header().Get("Retry-After"))
+	assert.Len(t, srv.Requests(), 2)
+
+	// The budget resets at midnight
+	clock.Advance(6 * time.Hour)
+	assert.Zero(t, budget.Spent("alice"))
+	assert.Equal(t, http.StatusOK, post(t, h, hello, "X-User", "alice").Code)
+}
+
+// readEvents returns the data of each server-sent event, keyed by event type
+func readEvents(t *testing.T, body string) (data []string, events []string) {
+	t.Helper()
+	event := "message"
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, strings.TrimPrefix(line, "data: "))
+			events = append(events, event)
+			event = "message"
+		}
+	}
+	return data, events
+}
+
+func TestSSEHandler(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(costlyReply("Hello from the stream", 0.002))
+	budget := chathttp.NewBudget(1, 24*time.Hour)
+
+	h := chathttp.SSEHandler(srv.Client(), chathttp.Options{
+		Authenticate: headerAuth,
+		Limiters:     []chathttp.Limiter{budget},
+		DefaultModel: "openai/gpt-4o-mini",
+	})
+	rec := post(t, h, hello, "X-User", "alice")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+	data, _ := readEvents(t, rec.Body.String())
+	require.NotEmpty(t, data)
+	assert.Equal(t, "[DONE]", data[len(data)-1])
+
+	var text strings.Builder
+	for _, d := range data[:len(data)-1] {
+		var chunk models.ChatCompletionResponse
+		require.NoError(t, json.Unmarshal([]byte(d), &chunk))
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta != nil {
+			content, _ := chunk.Choices[0].Delta.GetTextContent()
+			text.WriteString(content)
+		}
+	}
+	assert.Equal(t, "Hello from the stream", text.String())
+	assert.InDelta(t, 0.002, budget.Spent("alice"), 1e-9)
+
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.True(t, sent.Stream)
+}
+
+func TestSSEHandlerErrors(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	h := chathttp.SSEHandler(srv.Client(), chathttp.Options{DefaultModel: "openai/gpt-4o-mini"})
+
+	// Errors before the stream starts are plain JSON responses
+	srv.EnqueueChat(openroutertest.ErrorReply(http.StatusTooManyRequests, "slow down"))
+	rec := post(t, h, hello)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	// Errors after it started are error events
+	reply := openroutertest.TextReply("partial")
+	reply.Error = &openroutertest.Error{Code: http.StatusBadGateway, Message: "provider crashed", MidStream: true}
+	srv.EnqueueChat(reply)
+	rec = post(t, h, hello)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	data, events := readEvents(t, rec.Body.String())
+	require.NotEmpty(t, data)
+	assert.Equal(t, "error", events[len(events)-1])
+	assert.NotContains(t, data, "[DONE]")
+
+	var body chathttp.ErrorResponse
+	require.NoError(t, json.Unmarshal([]byte(data[len(data)-1]), &body))
+	assert.Equal(t, http.StatusBadGateway, body.Error.Code)
+}
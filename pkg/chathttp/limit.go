@@ -0,0 +1,173 @@
+package chathttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+var (
+	// ErrRateLimited rejects a request from a user who sent too many
+	ErrRateLimited = errors.New("rate limit exceeded")
+
+	// ErrBudgetExceeded rejects a request from a user who spent their budget
+	ErrBudgetExceeded = errors.New("budget exceeded")
+)
+
+// LimitError is returned by a Limiter to reject a request
+type LimitError struct {
+	// Err is ErrRateLimited, ErrBudgetExceeded or a custom reason
+	Err error
+
+	// RetryAfter is when the user may try again, sent as the Retry-After header
+	RetryAfter time.Duration
+}
+
+// Error describes the limit
+func (e *LimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%v, retry in %s", e.Err, e.RetryAfter.Round(time.Second))
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// Limiter enforces a per-user limit. Implementations must be safe for concurrent use.
+type Limiter interface {
+	// Allow admits a request from user, or rejects it with an error, usually a *LimitError
+	Allow(ctx context.Context, user string) error
+
+	// Record charges the usage of a completed request to user. usage is nil when the
+	// response reported none.
+	Record(ctx context.Context, user string, usage *models.Usage)
+}
+
+// RateLimiter limits each user to a number of requests per period, with a token bucket
+// that allows bursts up to the full number. It remembers every user it has seen.
+type RateLimiter struct {
+	requests int
+	per      time.Duration
+	clock    pkg.Clock
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing requests per period to each user
+func NewRateLimiter(requests int, per time.Duration) *RateLimiter {
+	return &RateLimiter{requests: requests, per: per, clock: pkg.SystemClock, buckets: make(map[string]*bucket)}
+}
+
+// SetClock replaces the clock, e.g. with a fake one in tests
+func (l *RateLimiter) SetClock(clock pkg.Clock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clock = clock
+}
+
+// Allow implements Limiter
+func (l *RateLimiter) Allow(ctx context.Context, user string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	rate := float64(l.requests) / float64(l.per)
+
+	b, ok := l.buckets[user]
+	if !ok {
+		b = &bucket{tokens: float64(l.requests), last: now}
+		l.buckets[user] = b
+	}
+	b.tokens = min(float64(l.requests), b.tokens+float64(now.Sub(b.last))*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return &LimitError{Err: ErrRateLimited, RetryAfter: time.Duration((1 - b.tokens) / rate)}
+	}
+	b.tokens--
+	return nil
+}
+
+// Record implements Limiter; requests are counted when they are allowed
+func (l *RateLimiter) Record(ctx context.Context, user string, usage *models.Usage) {}
+
+// Budget limits the cost each user may spend per period, e.g. $1 a day. Periods are
+// whole multiples of the period since the zero time, so daily budgets reset at midnight
+// UTC. A request is admitted while the user is under budget, so the last one may
+// overspend it.
+type Budget struct {
+	limit  float64
+	period time.Duration
+	clock  pkg.Clock
+
+	mu    sync.Mutex
+	spent map[string]*spending
+}
+
+type spending struct {
+	period time.Time
+	cost   float64
+}
+
+// NewBudget creates a limiter allowing each user to spend limit credits per period
+func NewBudget(limit float64, period time.Duration) *Budget {
+	return &Budget{limit: limit, period: period, clock: pkg.SystemClock, spent: make(map[string]*spending)}
+}
+
+// SetClock replaces the clock, e.g. with a fake one in tests
+func (b *Budget) SetClock(clock pkg.Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clock = clock
+}
+
+// Spent returns what user has spent in the current period
+func (b *Budget) Spent(user string) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.current(user).cost
+}
+
+// Allow implements Limiter
+func (b *Budget) Allow(ctx context.Context, user string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.current(user)
+	if s.cost >= b.limit {
+		return &LimitError{Err: ErrBudgetExceeded, RetryAfter: s.period.Add(b.period).Sub(b.clock.Now())}
+	}
+	return nil
+}
+
+// Record implements Limiter, charging the request's cost
+func (b *Budget) Record(ctx context.Context, user string, usage *models.Usage) {
+	if usage == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current(user).cost += usage.Cost
+}
+
+// current returns user's spending in the current period
+func (b *Budget) current(user string) *spending {
+	period := b.clock.Now().Truncate(b.period)
+	s, ok := b.spent[user]
+	if !ok || !s.period.Equal(period) {
+		s = &spending{period: period}
+		b.spent[user] = s
+	}
+	return s
+}
@@ -3,13 +3,17 @@ package pkg
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/rizome-dev/go-openrouter/pkg/codec"
 	"github.com/rizome-dev/go-openrouter/pkg/errors"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
 )
 
 const (
@@ -18,6 +22,12 @@ const (
 
 	// DefaultTimeout is the default timeout for HTTP requests
 	DefaultTimeout = 2 * time.Minute
+
+	// DefaultMaxIdleConnsPerHost is the default number of idle connections kept per host
+	DefaultMaxIdleConnsPerHost = 32
+
+	// DefaultIdleConnTimeout is how long idle connections stay in the pool
+	DefaultIdleConnTimeout = 90 * time.Second
 )
 
 // Client is the main client for interacting with the OpenRouter API
@@ -32,6 +42,40 @@ type Client struct {
 
 	// User agent for requests
 	userAgent string
+
+	// Request body compression, nil when disabled
+	compression *CompressionConfig
+
+	// JSON codec for request and response bodies
+	codec codec.Codec
+
+	// Reject or record response fields the SDK's types don't declare
+	strictDecoding bool
+	unknownFields  *UnknownFieldRecorder
+
+	// Metrics for wrappers such as RetryClient and CircuitBreaker, nil when disabled
+	metrics MetricsCollector
+
+	// Clock used by wrappers that wait or measure time
+	clock Clock
+
+	// Skips ChatCompletionRequest.Validate before sending
+	skipValidation bool
+
+	// Rewrites system messages for models that reject them, nil when disabled
+	systemPrompts *SystemPromptCompat
+
+	// Default provider preferences, merged under each request's own
+	providerPrefs *models.ProviderPreferences
+
+	// Rewrite chat requests before they are sent
+	decorators []RequestDecorator
+
+	// Transform completion text before it is returned
+	postProcessors []PostProcessor
+
+	// Fails or serves requests from cache during incidents, nil when disabled
+	killSwitch *KillSwitch
 }
 
 // Option is a function that configures the client
@@ -43,8 +87,11 @@ func NewClient(apiKey string, opts ...Option) *Client {
 		baseURL:   DefaultBaseURL,
 		apiKey:    apiKey,
 		userAgent: "openroutergo/1.0.0",
+		codec:     codec.Std,
+		clock:     SystemClock,
 		httpClient: &http.Client{
-			Timeout: DefaultTimeout,
+			Timeout:   DefaultTimeout,
+			Transport: NewDefaultTransport(),
 		},
 	}
 
@@ -62,10 +109,59 @@ func WithBaseURL(baseURL string) Option {
 	}
 }
 
-// WithHTTPClient sets a custom HTTP client
+// WithHTTPClient sets a custom HTTP client. A nil client is ignored.
 func WithHTTPClient(httpClient *http.Client) Option {
 	return func(c *Client) {
-		c.httpClient = httpClient
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithProxy routes requests through the given proxy URL (e.g. "http://proxy.corp:8080").
+// It can come before or after options that wrap the transport, such as WithDebug. It has
+// no effect if a custom HTTP client with a non-*http.Transport transport is used.
+func WithProxy(proxyURL string) Option {
+	return func(c *Client) {
+		u, err := url.Parse(proxyURL)
+		if err == nil && (u.Scheme == "" || u.Host == "") {
+			err = fmt.Errorf("proxy URL must include scheme and host")
+		}
+
+		transport := c.transport()
+		if transport == nil {
+			return
+		}
+		if err != nil {
+			// Surface the configuration error on every request instead of silently bypassing the proxy
+			proxyErr := fmt.Errorf("invalid proxy URL %q: %w", proxyURL, err)
+			transport.Proxy = func(*http.Request) (*url.URL, error) {
+				return nil, proxyErr
+			}
+			return
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+}
+
+// WithTLSConfig sets the TLS configuration, e.g. for mTLS client certificates or custom root CAs.
+// Like WithProxy, it can come before or after options that wrap the transport. It has no
+// effect if a custom HTTP client with a non-*http.Transport transport is used.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *Client) {
+		if transport := c.transport(); transport != nil {
+			transport.TLSClientConfig = tlsConfig
+		}
+	}
+}
+
+// WithJSONCodec sets the JSON codec used for request and response bodies,
+// including streamed chunks. Use it to plug in a faster encoder.
+func WithJSONCodec(jsonCodec codec.Codec) Option {
+	return func(c *Client) {
+		if jsonCodec != nil {
+			c.codec = jsonCodec
+		}
 	}
 }
 
@@ -97,17 +193,128 @@ func WithUserAgent(userAgent string) Option {
 	}
 }
 
+// WithMetrics sets the collector that RetryClient, CircuitBreaker, and ConcurrentClient
+// report to. Collectors implementing ResilienceMetricsCollector also receive retry,
+// circuit breaker, and queue metrics.
+func WithMetrics(collector MetricsCollector) Option {
+	return func(c *Client) {
+		c.metrics = collector
+	}
+}
+
+// WithProviderPreferences sets provider preferences for every request, such as a preset
+// like models.PreferencesPrivacyStrict(). Fields a request sets in its own Provider, or
+// that come from ContextWithProvider, take precedence.
+func WithProviderPreferences(prefs *models.ProviderPreferences) Option {
+	return func(c *Client) {
+		c.providerPrefs = prefs
+	}
+}
+
+// WithoutRequestValidation disables the local ChatCompletionRequest.Validate check, sending
+// requests as they are and leaving validation to the API
+func WithoutRequestValidation() Option {
+	return func(c *Client) {
+		c.skipValidation = true
+	}
+}
+
+// NewDefaultTransport returns the transport used by NewClient. Connection pooling and
+// keep-alive are tuned for long-lived streaming responses to a single API host.
+func NewDefaultTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   DefaultMaxIdleConnsPerHost,
+		IdleConnTimeout:       DefaultIdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// transport returns a private copy of the HTTP client's transport so options can adjust it
+// without mutating a client or transport shared with other code. Transports installed by
+// options such as WithDebug, WithAuditSink, and WithFailover are copied too, so the
+// *http.Transport underneath them is returned whatever order the options came in. It
+// returns nil when the HTTP client uses a custom RoundTripper that is not an *http.Transport.
+func (c *Client) transport() *http.Transport {
+	roundTripper, transport := cloneTransport(c.httpClient.Transport)
+	if transport == nil {
+		return nil
+	}
+
+	httpClient := *c.httpClient
+	httpClient.Transport = roundTripper
+	c.httpClient = &httpClient
+	return transport
+}
+
+// wrappingTransport is implemented by the transports that options wrap around the HTTP
+// client's transport
+type wrappingTransport interface {
+	// unwrap returns the wrapped transport, or nil if it must not be replaced
+	unwrap() http.RoundTripper
+
+	// withBase returns a copy wrapping base instead
+	withBase(base http.RoundTripper) http.RoundTripper
+}
+
+// cloneTransport copies rt and any wrapping transports down to its *http.Transport. It
+// returns the copy and the *http.Transport within it, or a nil *http.Transport if there is
+// none.
+func cloneTransport(rt http.RoundTripper) (http.RoundTripper, *http.Transport) {
+	switch t := rt.(type) {
+	case nil:
+		transport := NewDefaultTransport()
+		return transport, transport
+	case *http.Transport:
+		transport := t.Clone()
+		return transport, transport
+	case wrappingTransport:
+		if inner := t.unwrap(); inner != nil {
+			base, transport := cloneTransport(inner)
+			if transport != nil {
+				return t.withBase(base), transport
+			}
+		}
+	}
+	return rt, nil
+}
+
 // doRequest performs an HTTP request with the given context
 func (c *Client) doRequest(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
+	if err := c.killSwitch.allow(); err != nil {
+		return nil, err
+	}
 	url := c.baseURL + endpoint
 
 	var reqBody io.Reader
+	contentEncoding := ""
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		jsonBody, err := c.codec.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonBody)
+
+		if c.compression != nil && c.compression.err != nil {
+			return nil, c.compression.err
+		}
+		if c.compression != nil && len(jsonBody) >= c.compression.MinSize {
+			compressed, err := c.compression.compress(jsonBody)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compress request body: %w", err)
+			}
+			jsonBody = compressed
+			contentEncoding = c.compression.Encoding
+		}
+		reqBody = bytes.NewReader(jsonBody)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
@@ -119,6 +326,18 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body in
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", c.userAgent)
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+
+	// Non-idempotent requests carry a key so retries of the same logical request can be deduplicated
+	if method == http.MethodPost {
+		if key, ok := IdempotencyKeyFromContext(ctx); ok {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		} else if key := NewIdempotencyKey(); key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+	}
 
 	// Set optional headers
 	if c.httpReferer != "" {
@@ -132,6 +351,12 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body in
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform request: %w", err)
 	}
+	captureResponseMetadata(ctx, resp)
+
+	if err := decompressResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
 
 	// Check for errors
 	if resp.StatusCode >= 400 {
@@ -142,6 +367,15 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body in
 	return resp, nil
 }
 
+// decodeResponse decodes a JSON response body into v using the client's codec
+func (c *Client) decodeResponse(resp *http.Response, v interface{}) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return c.responseCodec().Unmarshal(body, v)
+}
+
 // parseError parses an error response from the API
 func (c *Client) parseError(resp *http.Response) error {
 	body, err := io.ReadAll(resp.Body)
@@ -150,7 +384,7 @@ func (c *Client) parseError(resp *http.Response) error {
 	}
 
 	var errResp errors.ErrorResponse
-	if err := json.Unmarshal(body, &errResp); err != nil {
+	if err := c.codec.Unmarshal(body, &errResp); err != nil {
 		return fmt.Errorf("failed to parse error response: %w", err)
 	}
 
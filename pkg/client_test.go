@@ -0,0 +1,112 @@
+package pkg_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+func TestTransportOptionsAnyOrder(t *testing.T) {
+	// The proxy answers every request itself, recording the host it was meant for
+	var hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.URL.Host)
+		w.Write([]byte(`{"id":"gen-1","choices":[]}`))
+	}))
+	defer proxy.Close()
+
+	wrappers := map[string]pkg.Option{
+		"debug":     pkg.WithDebug(io.Discard),
+		"audit":     pkg.WithAuditSink(pkg.NewWriterAuditSink(io.Discard), pkg.AuditOptions{}),
+		"failover":  pkg.WithFailover(pkg.FailoverConfig{Secondary: []string{"http://gateway.invalid/api/v1"}}),
+		"fault":     pkg.WithFaultInjection(pkg.FaultConfig{}),
+		"no-op nil": pkg.WithHTTPClient(nil),
+	}
+	for name, wrap := range wrappers {
+		for _, proxyFirst := range []bool{true, false} {
+			opts := []pkg.Option{pkg.WithBaseURL("http://openrouter.invalid/api/v1"), wrap}
+			if proxyFirst {
+				opts = []pkg.Option{opts[0], pkg.WithProxy(proxy.URL), wrap}
+			} else {
+				opts = append(opts, pkg.WithProxy(proxy.URL))
+			}
+			hosts = nil
+			_, err := pkg.NewClient("key", opts...).CreateChatCompletion(context.Background(), hiRequest)
+			require.NoError(t, err, "%s, proxy first: %t", name, proxyFirst)
+			assert.Equal(t, []string{"openrouter.invalid"}, hosts, "%s, proxy first: %t", name, proxyFirst)
+		}
+	}
+}
+
+func TestTLSConfigAfterDebug(t *testing.T) {
+	tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"gen-1","choices":[]}`))
+	}))
+	tlsServer.Config.ErrorLog = log.New(io.Discard, "", 0)
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	// Without the server's certificate the handshake fails
+	_, err := pkg.NewClient("key", pkg.WithBaseURL(tlsServer.URL), pkg.WithDebug(io.Discard)).
+		CreateChatCompletion(context.Background(), hiRequest)
+	assert.ErrorContains(t, err, "certificate")
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tlsServer.Certificate())
+	client := pkg.NewClient("key",
+		pkg.WithBaseURL(tlsServer.URL),
+		pkg.WithDebug(io.Discard),
+		pkg.WithTLSConfig(&tls.Config{RootCAs: roots}),
+	)
+	resp, err := client.CreateChatCompletion(context.Background(), hiRequest)
+	require.NoError(t, err)
+	assert.Equal(t, "gen-1", resp.ID)
+}
+
+func TestChatCompletionStreamUsageOptIn(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	// Streams don't ask for a usage chunk unless told to, so every chunk has a choice
+	stream, err := client.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	for {
+		chunk, err := stream.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.NotEmpty(t, chunk.Choices)
+	}
+	stream.Close()
+	assert.NotContains(t, string(srv.Requests()[0].Body), "stream_options")
+
+	srv.EnqueueChat(openroutertest.Reply{Events: []openroutertest.StreamEvent{
+		openroutertest.TextChunk("Hello"),
+		openroutertest.FinishChunk("stop"),
+		openroutertest.UsageChunk(5, 1),
+	}})
+	req := models.NewChatRequest("m", models.WithUserMessage("Hi"), models.WithStreamUsage())
+	stream, err = client.CreateChatCompletionStream(ctx, req)
+	require.NoError(t, err)
+	resp, err := streaming.CollectStream(stream)
+	require.NoError(t, err)
+	assert.Contains(t, string(srv.Requests()[1].Body), `"stream_options":{"include_usage":true}`)
+	require.NotNil(t, resp.Usage)
+	assert.Equal(t, 6, resp.Usage.TotalTokens)
+}
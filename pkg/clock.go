@@ -0,0 +1,33 @@
+package pkg
+
+import "time"
+
+// Clock tells time and waits. RetryClient, CircuitBreaker, and ObservableClient cost
+// tracking use the client's clock, so tests can replace it with a fake one such as
+// openroutertest.FakeClock instead of sleeping.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock backed by the time package
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// WithClock sets the clock used for retry backoff, circuit breaker timeouts, and cost tracking
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		if clock != nil {
+			c.clock = clock
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Package codec abstracts JSON encoding so faster encoders can replace encoding/json
+package codec
+
+import "encoding/json"
+
+// Codec marshals and unmarshals JSON.
+//
+// Drop-in encoders such as github.com/bytedance/sonic (sonic.ConfigStd) and
+// github.com/goccy/go-json satisfy this interface directly or with a thin adapter.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Std is the encoding/json codec used by default
+var Std Codec = stdCodec{}
+
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Funcs adapts a pair of marshal/unmarshal functions into a Codec, e.g.
+// codec.Funcs{MarshalFunc: gojson.Marshal, UnmarshalFunc: gojson.Unmarshal}
+type Funcs struct {
+	MarshalFunc   func(v interface{}) ([]byte, error)
+	UnmarshalFunc func(data []byte, v interface{}) error
+}
+
+// Marshal implements Codec
+func (f Funcs) Marshal(v interface{}) ([]byte, error) {
+	return f.MarshalFunc(v)
+}
+
+// Unmarshal implements Codec
+func (f Funcs) Unmarshal(data []byte, v interface{}) error {
+	return f.UnmarshalFunc(data, v)
+}
@@ -0,0 +1,95 @@
+package codec_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg/codec"
+)
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type response struct {
+	ID      string             `json:"id"`
+	Choices []message          `json:"choices"`
+	Meta    map[string]message `json:"meta"`
+	Created time.Time          `json:"created"`
+	Ignored string             `json:"-"`
+	Raw     json.RawMessage    `json:"raw"`
+}
+
+func TestFuncs(t *testing.T) {
+	var marshaled, unmarshaled interface{}
+	c := codec.Funcs{
+		MarshalFunc: func(v interface{}) ([]byte, error) {
+			marshaled = v
+			return []byte(`"custom"`), nil
+		},
+		UnmarshalFunc: func(data []byte, v interface{}) error {
+			unmarshaled = v
+			return json.Unmarshal(data, v)
+		},
+	}
+
+	data, err := c.Marshal(42)
+	require.NoError(t, err)
+	assert.Equal(t, `"custom"`, string(data))
+	assert.Equal(t, 42, marshaled)
+
+	var s string
+	require.NoError(t, c.Unmarshal(data, &s))
+	assert.Equal(t, "custom", s)
+	assert.Same(t, &s, unmarshaled)
+}
+
+func TestStrict(t *testing.T) {
+	var m message
+	require.NoError(t, codec.Strict(nil).Unmarshal([]byte(`{"role":"user","content":"hi"}`), &m))
+	assert.Equal(t, message{Role: "user", Content: "hi"}, m)
+
+	err := codec.Strict(nil).Unmarshal([]byte(`{"role":"user","audio":{}}`), &m)
+	assert.ErrorContains(t, err, `unknown field "audio"`)
+
+	err = codec.Strict(codec.Std).Unmarshal([]byte(`{"role":"user"} {}`), &m)
+	assert.ErrorContains(t, err, "after top-level value")
+
+	// Marshaling goes through the wrapped codec
+	strict := codec.Strict(codec.Funcs{MarshalFunc: func(interface{}) ([]byte, error) { return []byte("{}"), nil }})
+	data, err := strict.Marshal(m)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+}
+
+func TestUnknownFields(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"none", `{"id":"gen-1","choices":[{"role":"assistant","content":"hi"}]}`, nil},
+		{"top level", `{"id":"gen-1","service_tier":"default","usage":{}}`, []string{"service_tier", "usage"}},
+		{"array element", `{"choices":[{"role":"assistant","audio":{}},{"refusal":null}]}`, []string{"choices[].audio", "choices[].refusal"}},
+		{"map value", `{"meta":{"a":{"role":"user","name":"x"}}}`, []string{"meta.*.name"}},
+		{"case-insensitive match", `{"ID":"gen-1","Choices":[]}`, nil},
+		{"skipped field", `{"Ignored":"x"}`, []string{"Ignored"}},
+		{"own UnmarshalJSON", `{"created":"2024-01-01T00:00:00Z","raw":{"anything":1}}`, nil},
+		{"invalid JSON", `{"id":`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := codec.UnknownFields([]byte(tt.data), &response{})
+			if tt.want == nil {
+				assert.Empty(t, got)
+			} else {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
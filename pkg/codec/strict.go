@@ -0,0 +1,143 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Strict returns a codec that marshals with c and unmarshals with encoding/json's
+// DisallowUnknownFields, failing on JSON object keys the destination type doesn't
+// declare. Types with their own UnmarshalJSON decide for themselves.
+func Strict(c Codec) Codec {
+	if c == nil {
+		c = Std
+	}
+	return strictCodec{c}
+}
+
+type strictCodec struct {
+	Codec
+}
+
+func (strictCodec) Unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("invalid character after top-level value")
+	}
+	return nil
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// UnknownFields returns the sorted paths of the JSON object keys in data that v's type
+// doesn't declare, e.g. "choices[].message.audio". Array elements appear as "[]" and
+// map values as "*". Values decoded by a type's own UnmarshalJSON are not inspected.
+// Invalid JSON has no unknown fields.
+func UnknownFields(data []byte, v interface{}) []string {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	unknownFields(value, reflect.TypeOf(v), "", seen)
+
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func unknownFields(value interface{}, t reflect.Type, path string, seen map[string]bool) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := structFields(t)
+		for key, v := range object {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			field, ok := fields[key]
+			if !ok {
+				// encoding/json falls back to a case-insensitive match
+				for name, f := range fields {
+					if strings.EqualFold(name, key) {
+						field, ok = f, true
+						break
+					}
+				}
+			}
+			if !ok {
+				seen[fieldPath] = true
+				continue
+			}
+			unknownFields(v, field, fieldPath, seen)
+		}
+	case reflect.Slice, reflect.Array:
+		if elements, ok := value.([]interface{}); ok {
+			for _, v := range elements {
+				unknownFields(v, t.Elem(), path+"[]", seen)
+			}
+		}
+	case reflect.Map:
+		if object, ok := value.(map[string]interface{}); ok {
+			for _, v := range object {
+				unknownFields(v, t.Elem(), path+".*", seen)
+			}
+		}
+	}
+}
+
+// structFields returns the JSON names of t's fields and their types, including the fields
+// promoted from embedded structs
+func structFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for embedded, ft := range structFields(fieldType) {
+				if _, shadowed := fields[embedded]; !shadowed {
+					fields[embedded] = ft
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
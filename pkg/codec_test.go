@@ -0,0 +1,78 @@
+package pkg_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/codec"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// countingCodec wraps encoding/json, counting calls
+type countingCodec struct {
+	marshals, unmarshals atomic.Int32
+}
+
+func (c *countingCodec) funcs() codec.Funcs {
+	return codec.Funcs{
+		MarshalFunc: func(v interface{}) ([]byte, error) {
+			c.marshals.Add(1)
+			return json.Marshal(v)
+		},
+		UnmarshalFunc: func(data []byte, v interface{}) error {
+			c.unmarshals.Add(1)
+			return json.Unmarshal(data, v)
+		},
+	}
+}
+
+func TestWithJSONCodec(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	counts := &countingCodec{}
+	client := srv.Client(pkg.WithJSONCodec(counts.funcs()))
+	ctx := context.Background()
+
+	// One call each for the request and the response
+	resp, err := client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Choices)
+	assert.Equal(t, int32(1), counts.marshals.Load())
+	assert.Equal(t, int32(1), counts.unmarshals.Load())
+
+	// Every streamed chunk is decoded with it
+	srv.EnqueueChat(openroutertest.TextReply("one two three"))
+	stream, err := client.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	defer stream.Close()
+	chunks := 0
+	for {
+		_, err := stream.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		chunks++
+	}
+	assert.Greater(t, chunks, 1)
+	assert.Equal(t, int32(2), counts.marshals.Load())
+	assert.Equal(t, int32(1+chunks), counts.unmarshals.Load())
+
+	// A nil codec keeps the one set before
+	client = srv.Client(pkg.WithJSONCodec(counts.funcs()), pkg.WithJSONCodec(nil))
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), counts.marshals.Load())
+	assert.Equal(t, int32(2+chunks), counts.unmarshals.Load())
+
+	// and alone leaves encoding/json in place
+	_, err = srv.Client(pkg.WithJSONCodec(nil)).CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+}
@@ -2,7 +2,6 @@ package pkg
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/rizome-dev/go-openrouter/pkg/models"
@@ -13,6 +12,10 @@ import (
 func (c *Client) CreateCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
 	// Ensure streaming is disabled for non-streaming endpoint
 	req.Stream = false
+	c.applyDefaults(ctx, &req)
+	if err := c.validate(&req); err != nil {
+		return nil, err
+	}
 
 	// For legacy completions endpoint, use the prompt field instead of messages
 	resp, err := c.doRequest(ctx, "POST", "/completions", req)
@@ -22,7 +25,7 @@ func (c *Client) CreateCompletion(ctx context.Context, req models.ChatCompletion
 	defer resp.Body.Close()
 
 	var completionResp models.ChatCompletionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&completionResp); err != nil {
+	if err := c.decodeResponse(resp, &completionResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -33,11 +36,15 @@ func (c *Client) CreateCompletion(ctx context.Context, req models.ChatCompletion
 func (c *Client) CreateCompletionStream(ctx context.Context, req models.ChatCompletionRequest) (*streaming.ChatCompletionStreamReader, error) {
 	// Ensure streaming is enabled
 	req.Stream = true
+	c.applyDefaults(ctx, &req)
+	if err := c.validate(&req); err != nil {
+		return nil, err
+	}
 
 	resp, err := c.doRequest(ctx, "POST", "/completions", req)
 	if err != nil {
 		return nil, err
 	}
 
-	return streaming.NewChatCompletionStreamReader(resp.Body), nil
+	return streaming.NewChatCompletionStreamReaderWithCodec(resp.Body, c.streamCodec()), nil
 }
@@ -0,0 +1,58 @@
+package pkg_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestPromptCompressionDecorator(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(
+		openroutertest.TextReply("Q3 revenue 4.2M, up 12%"),
+		openroutertest.TextReply("Revenue grew 12%."),
+	)
+
+	var saved []*pkg.CompressionResult
+	compressor := pkg.NewPromptCompressor(srv.Client())
+	client := srv.Client(pkg.WithRequestDecorators(compressor.Decorator(pkg.CompressionOptions{
+		MinLength:  100,
+		OnCompress: func(result *pkg.CompressionResult) { saved = append(saved, result) },
+	})))
+
+	report := strings.Repeat("In the third quarter, revenue came to 4.2 million, which is up 12 percent. ", 10)
+	messages := []models.Message{
+		models.NewTextMessage(models.RoleUser, report),
+		models.NewTextMessage(models.RoleUser, "How much did revenue grow?"),
+	}
+	_, err := client.CreateChatCompletion(context.Background(), models.ChatCompletionRequest{Model: "m", Messages: messages})
+	require.NoError(t, err)
+
+	requests := srv.Requests()
+	require.Len(t, requests, 2)
+	sent, err := requests[1].ChatRequest()
+	require.NoError(t, err)
+	compressed, err := sent.Messages[0].GetTextContent()
+	require.NoError(t, err)
+	assert.Equal(t, "Q3 revenue 4.2M, up 12%", compressed)
+	question, err := sent.Messages[1].GetTextContent()
+	require.NoError(t, err)
+	assert.Equal(t, "How much did revenue grow?", question, "the last message is kept")
+
+	original, err := messages[0].GetTextContent()
+	require.NoError(t, err)
+	assert.Equal(t, report, original, "the caller's messages must not be modified")
+
+	require.Len(t, saved, 1)
+	assert.Equal(t, 5, saved[0].CompressedTokens)
+	assert.Greater(t, saved[0].SavedTokens(), 100)
+	assert.Less(t, saved[0].Ratio(), 0.1)
+}
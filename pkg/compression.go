@@ -0,0 +1,118 @@
+package pkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// CompressionGzip is the gzip content encoding
+	CompressionGzip = "gzip"
+
+	// DefaultCompressionMinSize is the minimum request body size that gets compressed
+	DefaultCompressionMinSize = 8 * 1024
+)
+
+// CompressionConfig configures request body compression
+type CompressionConfig struct {
+	// Encoding is the Content-Encoding sent with compressed bodies. Defaults to gzip.
+	Encoding string
+
+	// Level is the gzip compression level, e.g. models.Ptr(gzip.BestSpeed).
+	// Defaults to gzip.DefaultCompression when nil.
+	Level *int
+
+	// MinSize is the minimum body size in bytes before compression is applied.
+	// Small bodies are sent uncompressed since compression would only add overhead.
+	MinSize int
+
+	// NewWriter creates a compressing writer for custom encodings such as zstd.
+	// When nil, gzip is used, and Encoding must be gzip.
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+
+	// err is a configuration error returned by every request with a body
+	err error
+}
+
+// WithCompression enables request body compression. Large multimodal payloads
+// (base64 images and PDFs) are compressed before being sent. Compressed responses
+// are always decompressed transparently. An Encoding other than gzip requires
+// NewWriter; without one, requests with a body fail instead of being mislabeled.
+func WithCompression(config CompressionConfig) Option {
+	return func(c *Client) {
+		if config.Encoding == "" {
+			config.Encoding = CompressionGzip
+		}
+		if config.Encoding != CompressionGzip && config.NewWriter == nil {
+			config.err = fmt.Errorf("compression encoding %q requires NewWriter", config.Encoding)
+		}
+		if config.MinSize <= 0 {
+			config.MinSize = DefaultCompressionMinSize
+		}
+		c.compression = &config
+	}
+}
+
+// compress compresses data with the configured encoding
+func (cfg *CompressionConfig) compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(data) / 2)
+
+	var w io.WriteCloser
+	var err error
+	if cfg.NewWriter != nil {
+		w, err = cfg.NewWriter(&buf)
+	} else {
+		level := gzip.DefaultCompression
+		if cfg.Level != nil {
+			level = *cfg.Level
+		}
+		w, err = gzip.NewWriterLevel(&buf, level)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressResponse wraps the response body with a decompressing reader when the
+// transport didn't already decode it (e.g. DisableCompression or a custom RoundTripper)
+func decompressResponse(resp *http.Response) error {
+	if resp.Header.Get("Content-Encoding") != CompressionGzip {
+		return nil
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to decompress response: %w", err)
+	}
+
+	resp.Body = &gzipReadCloser{Reader: gz, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// gzipReadCloser closes both the gzip reader and the underlying body
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (g *gzipReadCloser) Close() error {
+	g.Reader.Close()
+	return g.body.Close()
+}
@@ -0,0 +1,217 @@
+package pkg
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// pdfRequest builds a chat request carrying a base64 encoded PDF-like document.
+// The document mixes text streams with binary data, similar to real PDFs.
+func pdfRequest(size int) models.ChatCompletionRequest {
+	rng := rand.New(rand.NewSource(1))
+	var doc strings.Builder
+	doc.WriteString("%PDF-1.7\n")
+	for doc.Len() < size {
+		fmt.Fprintf(&doc, "%d 0 obj\n<< /Type /Page /Parent 2 0 R /Contents %d 0 R >>\nstream\n", doc.Len(), doc.Len()+1)
+		doc.WriteString("BT /F1 12 Tf 72 712 Td (Quarterly revenue increased across all regions.) Tj ET\n")
+		binary := make([]byte, 256)
+		rng.Read(binary)
+		doc.Write(binary)
+		doc.WriteString("\nendstream\nendobj\n")
+	}
+
+	message, _ := models.NewMultiContentMessage(models.RoleUser,
+		models.TextContent{Type: models.ContentTypeText, Text: "Summarize this document"},
+		models.FileContent{
+			Type: models.ContentTypeFile,
+			File: models.File{
+				Filename: "report.pdf",
+				FileData: "data:application/pdf;base64," + base64.StdEncoding.EncodeToString([]byte(doc.String())),
+			},
+		},
+	)
+
+	return models.ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []models.Message{message},
+	}
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	var received models.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != CompressionGzip {
+			t.Errorf("expected gzip request, got %q", r.Header.Get("Content-Encoding"))
+		}
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read gzip body: %v", err)
+		}
+		if err := json.NewDecoder(gz).Decode(&received); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+
+		w.Header().Set("Content-Encoding", CompressionGzip)
+		zw := gzip.NewWriter(w)
+		json.NewEncoder(zw).Encode(models.ChatCompletionResponse{ID: "gen-1"})
+		zw.Close()
+	}))
+	defer server.Close()
+
+	// Disable transport-level decompression so the client must decode the response itself
+	client := NewClient("test-key",
+		WithBaseURL(server.URL),
+		WithHTTPClient(&http.Client{Transport: &http.Transport{DisableCompression: true}}),
+		WithCompression(CompressionConfig{}),
+	)
+
+	resp, err := client.CreateChatCompletion(context.Background(), pdfRequest(64*1024))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.ID != "gen-1" {
+		t.Errorf("expected response ID gen-1, got %q", resp.ID)
+	}
+	if len(received.Messages) != 1 {
+		t.Errorf("expected 1 message, got %d", len(received.Messages))
+	}
+}
+
+func BenchmarkCompressPDFRequest(b *testing.B) {
+	for _, size := range []int{64 * 1024, 1024 * 1024} {
+		body, err := json.Marshal(pdfRequest(size))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for _, level := range []int{gzip.BestSpeed, gzip.DefaultCompression} {
+			cfg := &CompressionConfig{Level: models.Ptr(level)}
+			b.Run(fmt.Sprintf("size=%dKB/level=%d", size/1024, level), func(b *testing.B) {
+				b.SetBytes(int64(len(body)))
+				var compressed []byte
+				for i := 0; i < b.N; i++ {
+					compressed, err = cfg.compress(body)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(len(compressed))/float64(len(body)), "ratio")
+				b.ReportMetric(float64(len(body)-len(compressed)), "saved-bytes")
+			})
+		}
+	}
+}
+
+func BenchmarkRequestWithCompression(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"id":"gen-1"}`))
+	}))
+	defer server.Close()
+
+	req := pdfRequest(512 * 1024)
+	for _, compress := range []bool{false, true} {
+		opts := []Option{WithBaseURL(server.URL)}
+		if compress {
+			opts = append(opts, WithCompression(CompressionConfig{}))
+		}
+		client := NewClient("test-key", opts...)
+
+		b.Run(fmt.Sprintf("compress=%t", compress), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCompressionLevel(t *testing.T) {
+	body, err := json.Marshal(pdfRequest(64 * 1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := map[string]int{}
+	for name, cfg := range map[string]*CompressionConfig{
+		"default": {},
+		"none":    {Level: models.Ptr(gzip.NoCompression)},
+	} {
+		compressed, err := cfg.compress(body)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		gz, err := gzip.NewReader(strings.NewReader(string(compressed)))
+		if err != nil {
+			t.Fatalf("%s: failed to read gzip: %v", name, err)
+		}
+		decompressed, _ := io.ReadAll(gz)
+		if string(decompressed) != string(body) {
+			t.Errorf("%s: body did not round trip", name)
+		}
+		sizes[name] = len(compressed)
+	}
+
+	if sizes["none"] <= len(body) {
+		t.Errorf("expected NoCompression to store the body, got %d bytes for %d", sizes["none"], len(body))
+	}
+	if sizes["default"] >= sizes["none"] {
+		t.Errorf("expected the default level to compress, got %d bytes", sizes["default"])
+	}
+}
+
+func TestCompressionEncodingRequiresWriter(t *testing.T) {
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		w.Write([]byte(`{"id":"gen-1"}`))
+	}))
+	defer server.Close()
+
+	// A zstd label on a gzip body would be rejected or misread by the server
+	client := NewClient("test-key", WithBaseURL(server.URL), WithCompression(CompressionConfig{Encoding: "zstd"}))
+	_, err := client.CreateChatCompletion(context.Background(), pdfRequest(64*1024))
+	if err == nil || !strings.Contains(err.Error(), `compression encoding "zstd" requires NewWriter`) {
+		t.Fatalf("expected a configuration error, got %v", err)
+	}
+	if len(encodings) != 0 {
+		t.Fatalf("expected no request to be sent, got %d", len(encodings))
+	}
+
+	// Small bodies fail too, so the misconfiguration shows up right away
+	_, err = client.CreateChatCompletion(context.Background(), pdfRequest(16))
+	if err == nil {
+		t.Fatal("expected a configuration error for a small body")
+	}
+
+	client = NewClient("test-key", WithBaseURL(server.URL), WithCompression(CompressionConfig{
+		Encoding: "identity-test",
+		MinSize:  1,
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return nopWriteCloser{w}, nil
+		},
+	}))
+	if _, err := client.CreateChatCompletion(context.Background(), pdfRequest(16)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(encodings) != 1 || encodings[0] != "identity-test" {
+		t.Errorf("expected the custom encoding to be sent, got %v", encodings)
+	}
+}
+
+// nopWriteCloser passes writes through unchanged
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
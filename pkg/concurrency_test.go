@@ -0,0 +1,61 @@
+package pkg_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// TestConcurrentUse exercises the wrapper clients from many goroutines. It finds
+// data races when run with -race, as `make test-unit` and CI do.
+func TestConcurrentUse(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	req := models.NewChatRequest("m", models.WithUserMessage("hi"))
+	metrics := pkg.NewSimpleMetricsCollector()
+
+	observable := pkg.NewObservableClient("sk-or-test", pkg.ObservabilityOptions{Metrics: metrics},
+		pkg.WithBaseURL(srv.URL), pkg.WithMetrics(metrics))
+	breaker := pkg.NewCircuitBreaker(observable.Client, 1000, time.Minute)
+	registry := pkg.NewToolRegistry()
+
+	const workers = 16
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			observable.AddResponseHook(func(context.Context, string, interface{}, interface{}, error) {})
+			_, err := observable.CreateChatCompletion(ctx, req)
+			assert.NoError(t, err)
+
+			_, err = breaker.CreateChatCompletion(ctx, req)
+			assert.NoError(t, err)
+
+			name := fmt.Sprintf("tool_%d", i)
+			registry.RegisterFunc(name, func(models.ToolCall) (string, error) { return "ok", nil })
+			_, err = registry.Execute(models.ToolCall{Function: models.FunctionCall{Name: name}})
+			assert.NoError(t, err)
+
+			metrics.RecordRetry("chat_completion", 1, nil, nil)
+			metrics.GetSummary()
+		}()
+	}
+	wg.Wait()
+
+	summary := metrics.GetSummary()
+	assert.Equal(t, workers, summary["retries"].(map[string]int)["chat_completion"])
+	assert.Len(t, srv.Requests(), 2*workers)
+}
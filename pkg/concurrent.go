@@ -2,16 +2,24 @@ package pkg
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/sink"
 )
 
-// ConcurrentClient wraps Client with concurrent execution capabilities
+// ConcurrentClient wraps Client with concurrent execution capabilities. It is safe for
+// concurrent use; at most maxConcurrency requests run at once across all callers.
 type ConcurrentClient struct {
 	*Client
 	maxConcurrency int
 	semaphore      chan struct{}
+
+	// queued counts requests waiting for a semaphore slot
+	queued atomic.Int64
 }
 
 // NewConcurrentClient creates a new concurrent client
@@ -27,6 +35,40 @@ func NewConcurrentClient(apiKey string, maxConcurrency int, opts ...Option) *Con
 	}
 }
 
+// MaxConcurrency returns the maximum number of requests the client runs at once
+func (c *ConcurrentClient) MaxConcurrency() int {
+	return c.maxConcurrency
+}
+
+// acquire waits for a concurrency slot, recording queue depth and wait time
+func (c *ConcurrentClient) acquire(ctx context.Context) error {
+	metrics := c.resilienceMetrics()
+	start := time.Now()
+	if metrics != nil {
+		metrics.RecordQueueDepth(int(c.queued.Add(1)), nil)
+	}
+
+	var err error
+	select {
+	case c.semaphore <- struct{}{}:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if metrics != nil {
+		metrics.RecordQueueDepth(int(c.queued.Add(-1)), nil)
+		if err == nil {
+			metrics.RecordLimiterWait(time.Since(start), nil)
+		}
+	}
+	return err
+}
+
+// release frees a concurrency slot
+func (c *ConcurrentClient) release() {
+	<-c.semaphore
+}
+
 // ChatCompletionResult represents the result of a concurrent chat completion
 type ChatCompletionResult struct {
 	Response *models.ChatCompletionResponse
@@ -45,16 +87,14 @@ func (c *ConcurrentClient) CreateChatCompletionsConcurrent(ctx context.Context,
 			defer wg.Done()
 
 			// Acquire semaphore
-			select {
-			case c.semaphore <- struct{}{}:
-				defer func() { <-c.semaphore }()
-			case <-ctx.Done():
+			if err := c.acquire(ctx); err != nil {
 				results[index] = ChatCompletionResult{
-					Error: ctx.Err(),
+					Error: err,
 					Index: index,
 				}
 				return
 			}
+			defer c.release()
 
 			// Execute request
 			resp, err := c.CreateChatCompletion(ctx, request)
@@ -92,17 +132,15 @@ func (c *ConcurrentClient) CreateChatCompletionsStreamConcurrent(ctx context.Con
 				defer wg.Done()
 
 				// Acquire semaphore
-				select {
-				case c.semaphore <- struct{}{}:
-					defer func() { <-c.semaphore }()
-				case <-ctx.Done():
+				if err := c.acquire(ctx); err != nil {
 					resultChan <- StreamingResult{
-						Error: ctx.Err(),
+						Error: err,
 						Index: index,
 						Final: true,
 					}
 					return
 				}
+				defer c.release()
 
 				// Create stream
 				stream, err := c.CreateChatCompletionStream(ctx, request)
@@ -192,3 +230,42 @@ func (p *BatchProcessor) ProcessBatch(ctx context.Context, requests []models.Cha
 
 	return nil
 }
+
+// ProcessBatchToSink processes requests in batches and writes each result to s, with
+// source "batch" and IDs "batch-<run>-<index>", where run is unique to each call and index
+// is the position in requests, so receivers deduplicating by ID keep every run's results.
+// It stops at the first failed write, returning its error; results are delivered at least
+// once if the caller reprocesses the remaining requests.
+func (p *BatchProcessor) ProcessBatchToSink(ctx context.Context, requests []models.ChatCompletionRequest, s sink.Sink) error {
+	run := NewIdempotencyKey()
+	for i := 0; i < len(requests); i += p.batchSize {
+		end := i + p.batchSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		results := p.client.CreateChatCompletionsConcurrent(ctx, requests[i:end])
+		for _, result := range results {
+			index := i + result.Index
+			record := sink.Record{
+				ID:       fmt.Sprintf("batch-%s-%d", run, index),
+				Source:   "batch",
+				Request:  &requests[index],
+				Response: result.Response,
+				Time:     time.Now(),
+			}
+			if result.Error != nil {
+				record.Error = result.Error.Error()
+			}
+			if err := s.Write(ctx, record); err != nil {
+				return fmt.Errorf("failed to write result %d: %w", index, err)
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// DefaultSelfConsistencyTemperature is used for samples when the request sets no temperature,
+// since identical samples defeat the purpose of voting
+const DefaultSelfConsistencyTemperature = 0.7
+
+// Aggregator picks the best of several sampled answers
+type Aggregator interface {
+	// Aggregate returns the index of the chosen answer
+	Aggregate(ctx context.Context, question string, answers []string) (int, error)
+}
+
+// AggregatorFunc adapts a function to the Aggregator interface
+type AggregatorFunc func(ctx context.Context, question string, answers []string) (int, error)
+
+// Aggregate implements Aggregator
+func (f AggregatorFunc) Aggregate(ctx context.Context, question string, answers []string) (int, error) {
+	return f(ctx, question, answers)
+}
+
+// SelfConsistencyResult is the outcome of SelfConsistency
+type SelfConsistencyResult struct {
+	// Answer is the chosen answer and Response the sample it came from
+	Answer   string
+	Response *models.ChatCompletionResponse
+
+	// Samples holds every sampled completion, including failed ones
+	Samples []ChatCompletionResult
+
+	// Agreement is the fraction of successful samples that agree with the chosen answer
+	Agreement float64
+}
+
+// SelfConsistency samples n completions of req concurrently and aggregates their answers.
+// A nil aggregator uses MajorityVote(nil). Samples that fail are skipped; an error is
+// returned only if every sample fails.
+func (c *ConcurrentClient) SelfConsistency(ctx context.Context, req models.ChatCompletionRequest, n int, aggregator Aggregator) (*SelfConsistencyResult, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("n must be positive")
+	}
+	if aggregator == nil {
+		aggregator = MajorityVote(nil)
+	}
+
+	requests := make([]models.ChatCompletionRequest, n)
+	for i := range requests {
+		sample := req
+		if sample.Temperature == nil {
+			sample.Temperature = models.Ptr(DefaultSelfConsistencyTemperature)
+		}
+		if req.Seed != nil {
+			// The same seed would produce the same sample
+			sample.Seed = models.Ptr(*req.Seed + i)
+		}
+		requests[i] = sample
+	}
+
+	samples := c.CreateChatCompletionsConcurrent(ctx, requests)
+
+	var answers []string
+	var responses []*models.ChatCompletionResponse
+	var lastErr error
+	for _, sample := range samples {
+		if sample.Error != nil {
+			lastErr = sample.Error
+			continue
+		}
+		answer, err := responseText(sample.Response)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		answers = append(answers, answer)
+		responses = append(responses, sample.Response)
+	}
+	if len(answers) == 0 {
+		return nil, fmt.Errorf("all %d samples failed: %w", n, lastErr)
+	}
+
+	chosen, err := aggregator.Aggregate(ctx, lastUserText(req.Messages), answers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate answers: %w", err)
+	}
+	if chosen < 0 || chosen >= len(answers) {
+		return nil, fmt.Errorf("aggregator chose answer %d of %d", chosen, len(answers))
+	}
+
+	key := NormalizeAnswer
+	if vote, ok := aggregator.(*majorityVote); ok {
+		key = vote.key
+	}
+	agreeing := 0
+	for _, answer := range answers {
+		if key(answer) == key(answers[chosen]) {
+			agreeing++
+		}
+	}
+
+	return &SelfConsistencyResult{
+		Answer:    answers[chosen],
+		Response:  responses[chosen],
+		Samples:   samples,
+		Agreement: float64(agreeing) / float64(len(answers)),
+	}, nil
+}
+
+// NormalizeAnswer lowercases an answer and collapses whitespace so trivially different
+// answers compare equal
+func NormalizeAnswer(answer string) string {
+	return strings.ToLower(strings.Join(strings.Fields(answer), " "))
+}
+
+type majorityVote struct {
+	key func(string) string
+}
+
+// MajorityVote returns an aggregator that picks the most common answer. key maps an
+// answer to the value that is voted on, such as the number after "Answer:"; nil uses
+// NormalizeAnswer. Ties go to the answer seen first.
+func MajorityVote(key func(answer string) string) Aggregator {
+	if key == nil {
+		key = NormalizeAnswer
+	}
+	return &majorityVote{key: key}
+}
+
+// Aggregate implements Aggregator
+func (m *majorityVote) Aggregate(ctx context.Context, question string, answers []string) (int, error) {
+	if len(answers) == 0 {
+		return 0, fmt.Errorf("no answers to vote on")
+	}
+
+	keys := make([]string, len(answers))
+	counts := make(map[string]int, len(answers))
+	for i, answer := range answers {
+		keys[i] = m.key(answer)
+		counts[keys[i]]++
+	}
+
+	// Scanning in order and requiring a strictly higher count keeps the first seen on ties
+	best := 0
+	for i, k := range keys {
+		if counts[k] > counts[keys[best]] {
+			best = i
+		}
+	}
+	return best, nil
+}
+
+// JudgeAggregator returns an aggregator that asks a judge model to pick the best answer
+func JudgeAggregator(client *Client, model string) Aggregator {
+	return AggregatorFunc(func(ctx context.Context, question string, answers []string) (int, error) {
+		var prompt strings.Builder
+		if question != "" {
+			fmt.Fprintf(&prompt, "Question:\n%s\n\n", question)
+		}
+		prompt.WriteString("Candidate answers:")
+		for i, answer := range answers {
+			fmt.Fprintf(&prompt, "\n\n[%d]\n%s", i+1, answer)
+		}
+
+		req := models.NewChatRequest(model,
+			models.WithSystemMessage("You are a careful judge. Pick the candidate answer most likely to be correct. "+
+				"Prefer the answer most candidates agree on unless its reasoning is flawed."),
+			models.WithUserMessage(prompt.String()),
+			models.WithTemperature(0),
+		)
+
+		var verdict struct {
+			Choice    int    `json:"choice" description:"Number of the best candidate answer"`
+			Rationale string `json:"rationale" description:"Brief reason for the choice"`
+		}
+		resp, err := NewStructuredOutput(client).CreateWithSchema(ctx, req, "judge_choice", verdict)
+		if err != nil {
+			return 0, err
+		}
+		if err := ParseStructuredResponse(resp, &verdict); err != nil {
+			return 0, err
+		}
+		return verdict.Choice - 1, nil
+	})
+}
+
+// responseText returns the text of the first choice of resp
+func responseText(resp *models.ChatCompletionResponse) (string, error) {
+	if resp == nil || len(resp.Choices) == 0 || resp.Choices[0].Message == nil {
+		return "", fmt.Errorf("no message in response")
+	}
+	return resp.Choices[0].Message.GetTextContent()
+}
+
+// lastUserText returns the text of the last user message
+func lastUserText(messages []models.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == models.RoleUser {
+			text, _ := messages[i].GetTextContent()
+			return text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package pkg_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// sampleServer answers each sample by its seed; an empty answer fails the sample
+func sampleServer(t *testing.T, answers map[int]string) (*openroutertest.Server, *pkg.ConcurrentClient) {
+	t.Helper()
+	srv := openroutertest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetChatHandler(func(req models.ChatCompletionRequest) openroutertest.Reply {
+		answer := answers[*req.Seed]
+		if answer == "" {
+			return openroutertest.ErrorReply(400, "sample failed")
+		}
+		return openroutertest.TextReply(answer)
+	})
+	return srv, pkg.NewConcurrentClient("key", 3, pkg.WithBaseURL(srv.URL))
+}
+
+func TestSelfConsistency(t *testing.T) {
+	srv, client := sampleServer(t, map[int]string{10: "41", 11: "42", 12: " 42 ", 13: "43", 14: "42"})
+
+	req := models.NewChatRequest("m", models.WithUserMessage("What is 6 x 7?"), models.WithSeed(10))
+	result, err := client.SelfConsistency(context.Background(), req, 5, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "42", result.Answer)
+	assert.Equal(t, 0.6, result.Agreement)
+	text, _ := result.Response.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "42", text, "the response is the chosen sample's")
+	assert.Len(t, result.Samples, 5)
+
+	// Samples differ in seed and are not deterministic
+	seeds := map[int]bool{}
+	for _, request := range srv.Requests() {
+		sent, err := request.ChatRequest()
+		require.NoError(t, err)
+		seeds[*sent.Seed] = true
+		assert.Equal(t, pkg.DefaultSelfConsistencyTemperature, *sent.Temperature)
+	}
+	assert.Len(t, seeds, 5)
+
+	// A temperature on the request is kept
+	req.Temperature = models.Ptr(1.2)
+	_, err = client.SelfConsistency(context.Background(), req, 1, nil)
+	require.NoError(t, err)
+	sent, err := srv.Requests()[5].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, 1.2, *sent.Temperature)
+}
+
+func TestSelfConsistencyPartialFailures(t *testing.T) {
+	_, client := sampleServer(t, map[int]string{0: "Paris", 3: "paris"})
+	req := models.NewChatRequest("m", models.WithUserMessage("Capital of France?"), models.WithSeed(0))
+
+	result, err := client.SelfConsistency(context.Background(), req, 4, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Paris", result.Answer)
+	assert.Equal(t, 1.0, result.Agreement, "failed samples do not count against agreement")
+	require.Len(t, result.Samples, 4)
+	failed := 0
+	for _, sample := range result.Samples {
+		if sample.Error != nil {
+			failed++
+		}
+	}
+	assert.Equal(t, 2, failed)
+
+	req.Seed = models.Ptr(100)
+	_, err = client.SelfConsistency(context.Background(), req, 3, nil)
+	assert.ErrorContains(t, err, "all 3 samples failed")
+	assert.ErrorContains(t, err, "sample failed")
+
+	_, err = client.SelfConsistency(context.Background(), req, 0, nil)
+	assert.ErrorContains(t, err, "n must be positive")
+}
+
+func TestSelfConsistencyAggregator(t *testing.T) {
+	_, client := sampleServer(t, map[int]string{0: "Reasoning... Answer: 12", 1: "Other steps. Answer: 12", 2: "Answer: 13"})
+	req := models.NewChatRequest("m", models.WithUserMessage("How many?"), models.WithSeed(0))
+
+	// The vote key decides which answers agree
+	finalNumber := func(answer string) string {
+		_, after, _ := strings.Cut(answer, "Answer:")
+		return strings.TrimSpace(after)
+	}
+	result, err := client.SelfConsistency(context.Background(), req, 3, pkg.MajorityVote(finalNumber))
+	require.NoError(t, err)
+	assert.Equal(t, "Reasoning... Answer: 12", result.Answer)
+	assert.InDelta(t, 2.0/3, result.Agreement, 1e-9)
+
+	var question string
+	_, err = client.SelfConsistency(context.Background(), req, 3, pkg.AggregatorFunc(func(ctx context.Context, q string, answers []string) (int, error) {
+		question = q
+		return len(answers), nil
+	}))
+	assert.ErrorContains(t, err, "aggregator chose answer 3 of 3")
+	assert.Equal(t, "How many?", question)
+}
+
+func TestMajorityVote(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers []string
+		want    int
+	}{
+		{"majority", []string{"b", "a", "a"}, 1},
+		{"normalized", []string{"Yes", "no", " yes.", "YES"}, 0},
+		{"tie goes to first seen", []string{"b", "a", "a", "b"}, 0},
+		{"all different", []string{"x", "y", "z"}, 0},
+		{"late majority", []string{"x", "y", "y", "z", "z", "z"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, err := pkg.MajorityVote(nil).Aggregate(context.Background(), "", tt.answers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, chosen)
+		})
+	}
+
+	_, err := pkg.MajorityVote(nil).Aggregate(context.Background(), "", nil)
+	assert.ErrorContains(t, err, "no answers")
+}
@@ -0,0 +1,206 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// ConstraintViolationError is returned by CreateConstrainedCompletion when no attempt
+// produced output matching the constraint
+type ConstraintViolationError struct {
+	// Output is the output of the last attempt, cut short if it was aborted
+	Output   string
+	Attempts int
+	Err      error
+}
+
+// Error returns the reason the last attempt was rejected
+func (e *ConstraintViolationError) Error() string {
+	return fmt.Sprintf("output violates constraint after %d attempts: %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns the reason the last attempt was rejected
+func (e *ConstraintViolationError) Unwrap() error {
+	return e.Err
+}
+
+// ConstrainedOptions configures CreateConstrainedCompletion
+type ConstrainedOptions struct {
+	// Pattern is a regular expression, in Go syntax, that the whole output must match.
+	// Streamed output is checked as it arrives, and a generation is aborted as soon as
+	// it can no longer match.
+	Pattern string
+
+	// Grammar is a GBNF grammar sent to providers that support it. It can't be checked
+	// on the client; set Validate to check output against it.
+	Grammar string
+
+	// Native sends the constraint to the provider as a "regex" or "grammar" response
+	// format, in addition to checking it on the client
+	Native bool
+
+	// Validate checks the complete output, e.g. by parsing it
+	Validate func(output string) error
+
+	// MaxAttempts defaults to 3
+	MaxAttempts int
+}
+
+// CreateConstrainedCompletion generates output matching a regular expression or passing
+// a validator, for models and providers without native constrained decoding. Each attempt
+// is streamed and aborted as soon as the output can no longer match Pattern, saving the
+// rest of its tokens; rejected attempts are retried up to MaxAttempts times.
+func (c *Client) CreateConstrainedCompletion(ctx context.Context, req models.ChatCompletionRequest, opts ConstrainedOptions) (*models.ChatCompletionResponse, error) {
+	if opts.Pattern == "" && opts.Validate == nil && !(opts.Native && opts.Grammar != "") {
+		return nil, fmt.Errorf("a pattern, a validator, or a native grammar is required")
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+
+	var full *regexp.Regexp
+	var parsed *syntax.Regexp
+	if opts.Pattern != "" {
+		var err error
+		if full, err = regexp.Compile(`^(?:` + opts.Pattern + `)$`); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		if parsed, err = syntax.Parse(opts.Pattern, syntax.Perl); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	if opts.Native {
+		switch {
+		case opts.Grammar != "":
+			models.WithGrammar(opts.Grammar)(&req)
+		case opts.Pattern != "":
+			models.WithRegex(opts.Pattern)(&req)
+		}
+	}
+
+	violation := &ConstraintViolationError{}
+	for attempt := 1; attempt <= opts.MaxAttempts; attempt++ {
+		stream, err := c.CreateChatCompletionStream(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if parsed != nil {
+			matcher, err := newPrefixMatcher(parsed)
+			if err != nil {
+				stream.Close()
+				return nil, fmt.Errorf("invalid pattern: %w", err)
+			}
+			stream.StopWhen(func(text string) bool { return !matcher.feed(text) })
+		}
+
+		resp, err := streaming.CollectStream(stream)
+		stream.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		output := ""
+		if len(resp.Choices) > 0 && resp.Choices[0].Message != nil {
+			output, _ = resp.Choices[0].Message.GetTextContent()
+		}
+		violation.Output = output
+		violation.Attempts = attempt
+
+		switch {
+		case stream.Stopped():
+			violation.Err = fmt.Errorf("output can no longer match pattern")
+		case full != nil && !full.MatchString(output):
+			violation.Err = fmt.Errorf("output doesn't match pattern")
+		case opts.Validate != nil:
+			violation.Err = opts.Validate(output)
+		default:
+			violation.Err = nil
+		}
+		if violation.Err == nil {
+			return resp, nil
+		}
+	}
+	return nil, violation
+}
+
+// prefixMatcher runs a regular expression over growing text, reporting whether the text
+// so far is still a prefix of some match. Zero-width assertions are assumed to hold, so
+// it may keep accepting text that can't match; the complete output is checked separately.
+type prefixMatcher struct {
+	prog *syntax.Prog
+	pcs  []uint32
+	pos  int
+}
+
+func newPrefixMatcher(re *syntax.Regexp) (*prefixMatcher, error) {
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return nil, err
+	}
+	m := &prefixMatcher{prog: prog}
+	m.pcs = m.closure([]uint32{uint32(prog.Start)})
+	return m, nil
+}
+
+// feed consumes the text added since the last call, reporting whether a match is still
+// possible
+func (m *prefixMatcher) feed(text string) bool {
+	for m.pos < len(text) && len(m.pcs) > 0 {
+		r, size := utf8.DecodeRuneInString(text[m.pos:])
+		m.pos += size
+
+		var next []uint32
+		for _, pc := range m.pcs {
+			inst := &m.prog.Inst[pc]
+			var ok bool
+			switch inst.Op {
+			case syntax.InstRune, syntax.InstRune1:
+				ok = inst.MatchRune(r)
+			case syntax.InstRuneAny:
+				ok = true
+			case syntax.InstRuneAnyNotNL:
+				ok = r != '\n'
+			}
+			if ok {
+				next = append(next, inst.Out)
+			}
+		}
+		m.pcs = m.closure(next)
+	}
+	return len(m.pcs) > 0
+}
+
+// closure follows empty transitions from pcs, returning the instructions that consume
+// input or match
+func (m *prefixMatcher) closure(pcs []uint32) []uint32 {
+	seen := make(map[uint32]bool)
+	var out []uint32
+	for len(pcs) > 0 {
+		pc := pcs[len(pcs)-1]
+		pcs = pcs[:len(pcs)-1]
+		if seen[pc] {
+			continue
+		}
+		seen[pc] = true
+
+		inst := &m.prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			pcs = append(pcs, inst.Out, inst.Arg)
+		case syntax.InstCapture, syntax.InstNop, syntax.InstEmptyWidth:
+			pcs = append(pcs, inst.Out)
+		case syntax.InstFail:
+		default:
+			out = append(out, pc)
+		}
+	}
+	return out
+}
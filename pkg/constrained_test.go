@@ -0,0 +1,58 @@
+package pkg_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestCreateConstrainedCompletionRetriesViolations(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(
+		openroutertest.TextReply("Sure! The number is 555-1234"),
+		openroutertest.TextReply("555-12345"),
+		openroutertest.TextReply("555-1234"),
+	)
+
+	client := srv.Client()
+	req := models.NewChatRequest("m", models.WithUserMessage("Give me a phone number"))
+	resp, err := client.CreateConstrainedCompletion(context.Background(), req, pkg.ConstrainedOptions{
+		Pattern: `\d{3}-\d{4}`,
+		Native:  true,
+	})
+	require.NoError(t, err)
+	text, err := resp.Choices[0].Message.GetTextContent()
+	require.NoError(t, err)
+	assert.Equal(t, "555-1234", text)
+
+	require.Len(t, srv.Requests(), 3)
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	require.NotNil(t, sent.ResponseFormat)
+	assert.Equal(t, "regex", sent.ResponseFormat.Type)
+}
+
+func TestCreateConstrainedCompletionGivesUp(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetChatHandler(func(req models.ChatCompletionRequest) openroutertest.Reply {
+		return openroutertest.TextReply("maybe later, let me think")
+	})
+
+	_, err := srv.Client().CreateConstrainedCompletion(context.Background(),
+		models.NewChatRequest("m", models.WithUserMessage("yes or no?")),
+		pkg.ConstrainedOptions{Pattern: `yes|no`, MaxAttempts: 2})
+
+	var violation *pkg.ConstraintViolationError
+	require.True(t, errors.As(err, &violation), "err = %v", err)
+	assert.Equal(t, 2, violation.Attempts)
+	assert.Equal(t, "maybe ", violation.Output, "the stream is cut off at the first chunk that can't match")
+}
@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+type (
+	modelContextKey    struct{}
+	userContextKey     struct{}
+	providerContextKey struct{}
+)
+
+// ContextWithModel returns a context whose model is used for requests that don't set one.
+// This lets HTTP middleware choose a model far from where requests are constructed.
+func ContextWithModel(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, modelContextKey{}, model)
+}
+
+// ContextWithUser returns a context whose user ID is used for requests that don't set one
+func ContextWithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// ContextWithProvider returns a context whose provider preferences are used for requests that don't set any
+func ContextWithProvider(ctx context.Context, provider *models.ProviderPreferences) context.Context {
+	return context.WithValue(ctx, providerContextKey{}, provider)
+}
+
+// ModelFromContext returns the default model stored in the context, if any
+func ModelFromContext(ctx context.Context) (string, bool) {
+	model, ok := ctx.Value(modelContextKey{}).(string)
+	return model, ok && model != ""
+}
+
+// UserFromContext returns the default user ID stored in the context, if any
+func UserFromContext(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(userContextKey{}).(string)
+	return user, ok && user != ""
+}
+
+// ProviderFromContext returns the default provider preferences stored in the context, if any
+func ProviderFromContext(ctx context.Context) (*models.ProviderPreferences, bool) {
+	provider, ok := ctx.Value(providerContextKey{}).(*models.ProviderPreferences)
+	return provider, ok && provider != nil
+}
+
+// applyDefaults fills empty request fields from context defaults, then fills unset
+// provider preferences from the client's
+func (c *Client) applyDefaults(ctx context.Context, req *models.ChatCompletionRequest) {
+	applyContextDefaults(ctx, req)
+	if c.providerPrefs != nil {
+		req.Provider = c.providerPrefs.Merge(req.Provider)
+	}
+}
+
+// applyContextDefaults fills empty request fields from context defaults.
+// Fields set on the request always take precedence.
+func applyContextDefaults(ctx context.Context, req *models.ChatCompletionRequest) {
+	if req.Model == "" && len(req.Models) == 0 {
+		if model, ok := ModelFromContext(ctx); ok {
+			req.Model = model
+		}
+	}
+	if req.User == "" {
+		if user, ok := UserFromContext(ctx); ok {
+			req.User = user
+		}
+	}
+	if req.Provider == nil {
+		if provider, ok := ProviderFromContext(ctx); ok {
+			req.Provider = provider
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package pkg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestContextDefaults(t *testing.T) {
+	fromContext := &models.ProviderPreferences{Order: []string{"context"}, DataCollection: "deny"}
+	fromRequest := &models.ProviderPreferences{Order: []string{"request"}}
+	fromClient := &models.ProviderPreferences{Order: []string{"client"}, AllowFallbacks: models.Ptr(false)}
+
+	defaults := func(ctx context.Context) context.Context {
+		ctx = pkg.ContextWithModel(ctx, "context-model")
+		ctx = pkg.ContextWithUser(ctx, "context-user")
+		return pkg.ContextWithProvider(ctx, fromContext)
+	}
+
+	tests := []struct {
+		name      string
+		ctx       func(context.Context) context.Context
+		request   func(*models.ChatCompletionRequest)
+		prefs     *models.ProviderPreferences
+		wantModel string
+		wantUser  string
+		wantPrefs *models.ProviderPreferences
+	}{
+		{
+			name:      "fills empty fields",
+			ctx:       defaults,
+			wantModel: "context-model",
+			wantUser:  "context-user",
+			wantPrefs: fromContext,
+		},
+		{
+			name: "request fields win",
+			ctx:  defaults,
+			request: func(req *models.ChatCompletionRequest) {
+				req.Model = "request-model"
+				req.User = "request-user"
+				req.Provider = fromRequest
+			},
+			wantModel: "request-model",
+			wantUser:  "request-user",
+			wantPrefs: fromRequest,
+		},
+		{
+			name:      "fallback models count as a model",
+			ctx:       defaults,
+			request:   func(req *models.ChatCompletionRequest) { req.Models = []string{"a", "b"} },
+			wantUser:  "context-user",
+			wantPrefs: fromContext,
+		},
+		{
+			name: "empty context values are ignored",
+			ctx: func(ctx context.Context) context.Context {
+				return pkg.ContextWithUser(pkg.ContextWithModel(ctx, ""), "")
+			},
+			request:   func(req *models.ChatCompletionRequest) { req.Model = "request-model" },
+			wantModel: "request-model",
+		},
+		{
+			name:      "client preferences fill in under context preferences",
+			ctx:       defaults,
+			prefs:     fromClient,
+			wantModel: "context-model",
+			wantUser:  "context-user",
+			wantPrefs: &models.ProviderPreferences{Order: []string{"context"}, DataCollection: "deny", AllowFallbacks: models.Ptr(false)},
+		},
+		{
+			name:      "client preferences fill in under request preferences",
+			ctx:       defaults,
+			request:   func(req *models.ChatCompletionRequest) { req.Provider = fromRequest },
+			prefs:     fromClient,
+			wantModel: "context-model",
+			wantUser:  "context-user",
+			wantPrefs: &models.ProviderPreferences{Order: []string{"request"}, AllowFallbacks: models.Ptr(false)},
+		},
+		{
+			name:      "client preferences alone",
+			ctx:       func(ctx context.Context) context.Context { return ctx },
+			request:   func(req *models.ChatCompletionRequest) { req.Model = "request-model" },
+			prefs:     fromClient,
+			wantModel: "request-model",
+			wantPrefs: fromClient,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := openroutertest.NewServer()
+			defer srv.Close()
+			client := srv.Client(pkg.WithProviderPreferences(tt.prefs))
+			ctx := tt.ctx(context.Background())
+
+			req := models.NewChatRequest("", models.WithUserMessage("hi"))
+			if tt.request != nil {
+				tt.request(&req)
+			}
+			_, err := client.CreateChatCompletion(ctx, req)
+			require.NoError(t, err)
+			stream, err := client.CreateChatCompletionStream(ctx, req)
+			require.NoError(t, err)
+			stream.Close()
+
+			requests := srv.Requests()
+			require.Len(t, requests, 2)
+			for _, request := range requests {
+				sent, err := request.ChatRequest()
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantModel, sent.Model)
+				assert.Equal(t, tt.wantUser, sent.User)
+				assert.Equal(t, tt.wantPrefs, sent.Provider)
+			}
+		})
+	}
+
+	// Defaults don't leak into the caller's preferences
+	assert.Equal(t, &models.ProviderPreferences{Order: []string{"context"}, DataCollection: "deny"}, fromContext)
+	assert.Equal(t, &models.ProviderPreferences{Order: []string{"request"}}, fromRequest)
+}
@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// ConversationOptions configures a Conversation
+type ConversationOptions struct {
+	// Request is the template for every request, e.g. the model and sampling parameters.
+	// Its messages are ignored.
+	Request models.ChatCompletionRequest
+
+	// MaxTokens is the history budget, as estimated by EstimateTokens. When the history
+	// exceeds it before a request, it is packed to fit. Zero disables packing.
+	MaxTokens int
+
+	// Packer defaults to KeepLastPacker
+	Packer HistoryPacker
+}
+
+// Conversation keeps the history of a chat session and sends each turn with it, packing
+// the history with a HistoryPacker once it outgrows its budget. It is safe for concurrent
+// use; turns are sent one at a time.
+type Conversation struct {
+	client *Client
+	opts   ConversationOptions
+
+	mu       sync.Mutex
+	messages []models.Message
+}
+
+// NewConversation creates a conversation starting with messages, e.g. a system prompt
+func NewConversation(client *Client, opts ConversationOptions, messages ...models.Message) *Conversation {
+	if opts.Packer == nil {
+		opts.Packer = KeepLastPacker{}
+	}
+	return &Conversation{
+		client:   client,
+		opts:     opts,
+		messages: append([]models.Message(nil), messages...),
+	}
+}
+
+// Messages returns a copy of the history
+func (c *Conversation) Messages() []models.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]models.Message(nil), c.messages...)
+}
+
+// Add appends messages to the history without sending them, e.g. tool results
+func (c *Conversation) Add(messages ...models.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, messages...)
+}
+
+// Send appends msg to the history, sends the history, and appends the reply. If the
+// request fails, msg is kept so the turn can be retried with Resend.
+func (c *Conversation) Send(ctx context.Context, msg models.Message) (*models.ChatCompletionResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, msg)
+	return c.send(ctx)
+}
+
+// Resend sends the history as it is and appends the reply
+func (c *Conversation) Resend(ctx context.Context) (*models.ChatCompletionResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.send(ctx)
+}
+
+// Stream is Send with a streamed reply: fn receives each chunk as it arrives, and the
+// assembled reply is appended to the history once the stream ends. If the stream fails,
+// msg is kept and the partial reply is dropped, so the turn can be retried with Resend.
+func (c *Conversation) Stream(ctx context.Context, msg models.Message, fn func(chunk *models.ChatCompletionResponse)) (*models.ChatCompletionResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, msg)
+
+	req, err := c.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := c.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	acc := streaming.NewAccumulator()
+	for {
+		chunk, err := stream.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		acc.Add(chunk)
+		if fn != nil {
+			fn(chunk)
+		}
+	}
+	c.messages = append(c.messages, acc.Message())
+	return acc.Response(), nil
+}
+
+// Pack packs the history to fit MaxTokens now
+func (c *Conversation) Pack(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pack(ctx)
+}
+
+func (c *Conversation) send(ctx context.Context) (*models.ChatCompletionResponse, error) {
+	req, err := c.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) > 0 && resp.Choices[0].Message != nil {
+		c.messages = append(c.messages, *resp.Choices[0].Message)
+	}
+	return resp, nil
+}
+
+// request packs the history if needed and builds the next request from it
+func (c *Conversation) request(ctx context.Context) (models.ChatCompletionRequest, error) {
+	if c.opts.MaxTokens > 0 && EstimateTokens(c.messages) > c.opts.MaxTokens {
+		if err := c.pack(ctx); err != nil {
+			return models.ChatCompletionRequest{}, err
+		}
+	}
+
+	req := c.opts.Request
+	req.Messages = append([]models.Message(nil), c.messages...)
+	return req, nil
+}
+
+func (c *Conversation) pack(ctx context.Context) error {
+	packed, err := c.opts.Packer.Pack(ctx, c.messages, c.opts.MaxTokens)
+	if err != nil {
+		return fmt.Errorf("failed to pack conversation: %w", err)
+	}
+	c.messages = packed
+	return nil
+}
@@ -0,0 +1,146 @@
+package pkg_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func messageTexts(t *testing.T, messages []models.Message) []string {
+	t.Helper()
+	out := make([]string, len(messages))
+	for i, msg := range messages {
+		text, err := msg.GetTextContent()
+		require.NoError(t, err)
+		out[i] = string(msg.Role) + ":" + text
+	}
+	return out
+}
+
+// topicEmbedder embeds texts by whether they mention each topic
+type topicEmbedder []string
+
+func (e topicEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float64, len(e))
+		for j, topic := range e {
+			if strings.Contains(text, topic) {
+				vectors[i][j] = 1
+			}
+		}
+	}
+	return vectors, nil
+}
+
+func TestConversationPacksHistory(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetChatHandler(func(req models.ChatCompletionRequest) openroutertest.Reply {
+		return openroutertest.TextReply(strings.Repeat("reply ", 10))
+	})
+
+	conv := pkg.NewConversation(srv.Client(), pkg.ConversationOptions{
+		Request:   models.ChatCompletionRequest{Model: "m"},
+		MaxTokens: 60,
+		Packer:    pkg.KeepLastPacker{N: 3},
+	}, models.NewTextMessage(models.RoleSystem, "Be brief."))
+
+	ctx := context.Background()
+	for _, q := range []string{"one", "two", "three"} {
+		_, err := conv.Send(ctx, models.NewTextMessage(models.RoleUser, q))
+		require.NoError(t, err)
+	}
+
+	sent, err := srv.Requests()[2].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system:Be brief.", "user:two", "assistant:" + strings.Repeat("reply ", 10), "user:three"}, messageTexts(t, sent.Messages))
+	assert.Len(t, conv.Messages(), 5, "the packed history is kept")
+}
+
+func TestConversationStream(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("Hello there"))
+	srv.EnqueueChat(openroutertest.ErrorReply(500, "boom"))
+
+	conv := pkg.NewConversation(srv.Client(), pkg.ConversationOptions{Request: models.ChatCompletionRequest{Model: "m"}})
+	ctx := context.Background()
+
+	var streamed strings.Builder
+	resp, err := conv.Stream(ctx, models.NewTextMessage(models.RoleUser, "Hi"), func(chunk *models.ChatCompletionResponse) {
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta != nil {
+			text, _ := chunk.Choices[0].Delta.GetTextContent()
+			streamed.WriteString(text)
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello there", streamed.String())
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "Hello there", text)
+	assert.Equal(t, []string{"user:Hi", "assistant:Hello there"}, messageTexts(t, conv.Messages()))
+
+	_, err = conv.Stream(ctx, models.NewTextMessage(models.RoleUser, "Again"), nil)
+	require.Error(t, err)
+	assert.Equal(t, []string{"user:Hi", "assistant:Hello there", "user:Again"}, messageTexts(t, conv.Messages()))
+}
+
+func TestSummarizePacker(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("The user likes tea."))
+
+	history := []models.Message{
+		models.NewTextMessage(models.RoleSystem, "Be brief."),
+		models.NewTextMessage(models.RoleUser, "I like tea."),
+		models.NewTextMessage(models.RoleAssistant, "Noted."),
+		models.NewTextMessage(models.RoleUser, "What should I drink?"),
+	}
+	packed, err := pkg.SummarizePacker{Client: srv.Client(), KeepLast: 1}.Pack(context.Background(), history, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"system:Be brief.",
+		"system:Summary of the earlier conversation:\nThe user likes tea.",
+		"user:What should I drink?",
+	}, messageTexts(t, packed))
+
+	transcript, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	prompt, err := transcript.Messages[1].GetTextContent()
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "user: I like tea.")
+}
+
+func TestImportancePackerKeepsRelevantHistory(t *testing.T) {
+	call := models.ToolCall{ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "weather", Arguments: `{"city":"Oslo"}`}}
+	assistant := models.NewTextMessage(models.RoleAssistant, "")
+	assistant.ToolCalls = []models.ToolCall{call}
+
+	history := []models.Message{
+		models.NewTextMessage(models.RoleUser, "My dog is called Rex."),
+		models.NewTextMessage(models.RoleUser, "What's the weather in Oslo?"),
+		assistant,
+		models.NewToolMessage("call_1", "weather", "Oslo weather: rain"),
+		models.NewTextMessage(models.RoleUser, "Tell me a joke."),
+		models.NewTextMessage(models.RoleUser, "What food suits my dog?"),
+	}
+
+	packer := pkg.ImportancePacker{Embedder: topicEmbedder{"dog", "weather", "joke"}, KeepLast: 1}
+	budget := pkg.EstimateTokens(history[:1]) + pkg.EstimateTokens(history[5:])
+	packed, err := packer.Pack(context.Background(), history, budget)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:My dog is called Rex.", "user:What food suits my dog?"}, messageTexts(t, packed))
+
+	// With room for more, the tool call is kept together with its result
+	packed, err = packer.Pack(context.Background(), history, pkg.EstimateTokens(history)-pkg.EstimateTokens(history[4:5]))
+	require.NoError(t, err)
+	require.NoError(t, models.ValidateConversation(packed))
+	assert.Len(t, packed, 5)
+}
@@ -0,0 +1,405 @@
+// Package costreport aggregates token usage and cost into per-tag, per-model and per-day
+// reports, exports them as CSV or JSON, and reconciles them against OpenRouter's activity
+// API to detect drift between what the application recorded and what was billed.
+//
+// A Report is fed from audit logs, either live as a pkg.AuditSink or by reading a log
+// written by pkg.FileAuditSink, or from an ObservableClient as a pkg.MetricsCollector.
+// Feed each request from only one source, or it is counted twice.
+package costreport
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// DefaultTagLabel is the metrics label used as the tag of metrics
+const DefaultTagLabel = "tag"
+
+// DayFormat is the format of Row.Day, matching the dates of the activity API
+const DayFormat = "2006-01-02"
+
+// Dimension is a field rows are grouped by
+type Dimension string
+
+const (
+	ByDay   Dimension = "day"
+	ByModel Dimension = "model"
+	ByTag   Dimension = "tag"
+)
+
+// TagFunc returns the tag an audit record is attributed to, e.g. a team or feature
+type TagFunc func(record pkg.AuditRecord) string
+
+// UserTag attributes audit records to the user field of their request
+func UserTag(record pkg.AuditRecord) string {
+	var req struct {
+		User string `json:"user"`
+	}
+	json.Unmarshal(record.Request, &req)
+	return req.User
+}
+
+// Entry is the usage of one or more requests
+type Entry struct {
+	Time  time.Time
+	Model string
+	Tag   string
+
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+
+	// Cost is in USD
+	Cost float64
+}
+
+// Row is the aggregated usage of one group. Fields the rows aren't grouped by are empty.
+type Row struct {
+	Day   string `json:"day,omitempty"`
+	Model string `json:"model,omitempty"`
+	Tag   string `json:"tag,omitempty"`
+
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+func (r *Row) add(other Row) {
+	r.Requests += other.Requests
+	r.PromptTokens += other.PromptTokens
+	r.CompletionTokens += other.CompletionTokens
+	r.Cost += other.Cost
+}
+
+// Options configures a Report
+type Options struct {
+	// Tag attributes audit records to tags. Defaults to UserTag.
+	Tag TagFunc
+
+	// TagLabel is the metrics label used as the tag of metrics. Defaults to
+	// DefaultTagLabel.
+	TagLabel string
+
+	// Location sets the day boundaries. Defaults to UTC, which Reconcile requires.
+	Location *time.Location
+
+	// Clock timestamps metrics. Defaults to pkg.SystemClock.
+	Clock pkg.Clock
+}
+
+type rowKey struct {
+	day, model, tag string
+}
+
+// Report aggregates usage by day, model and tag. It is safe for concurrent use.
+type Report struct {
+	opts Options
+
+	mu   sync.Mutex
+	rows map[rowKey]*Row
+}
+
+// New creates an empty report
+func New(opts Options) *Report {
+	if opts.Tag == nil {
+		opts.Tag = UserTag
+	}
+	if opts.TagLabel == "" {
+		opts.TagLabel = DefaultTagLabel
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	if opts.Clock == nil {
+		opts.Clock = pkg.SystemClock
+	}
+	return &Report{opts: opts, rows: make(map[rowKey]*Row)}
+}
+
+// Add adds usage to the report
+func (r *Report) Add(entry Entry) {
+	key := rowKey{day: entry.Time.In(r.opts.Location).Format(DayFormat), model: entry.Model, tag: entry.Tag}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.rows[key]
+	if !ok {
+		row = &Row{Day: key.day, Model: key.model, Tag: key.tag}
+		r.rows[key] = row
+	}
+	row.add(Row{
+		Requests:         entry.Requests,
+		PromptTokens:     entry.PromptTokens,
+		CompletionTokens: entry.CompletionTokens,
+		Cost:             entry.Cost,
+	})
+}
+
+// WriteAudit implements pkg.AuditSink, adding the usage of completions as they are made.
+// Records without a model or usage, such as failed requests, are skipped.
+func (r *Report) WriteAudit(record pkg.AuditRecord) error {
+	if record.Error != "" || record.StatusCode >= 400 || len(record.Response) == 0 {
+		return nil
+	}
+	var resp struct {
+		Model string        `json:"model"`
+		Usage *models.Usage `json:"usage"`
+	}
+	if err := json.Unmarshal(record.Response, &resp); err != nil || resp.Model == "" || resp.Usage == nil {
+		return nil
+	}
+	r.Add(Entry{
+		Time:             record.Time,
+		Model:            resp.Model,
+		Tag:              r.opts.Tag(record),
+		Requests:         1,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		Cost:             resp.Usage.Cost,
+	})
+	return nil
+}
+
+// ReadAuditLog adds the usage of every record of an audit log written as JSON lines,
+// e.g. by pkg.FileAuditSink
+func (r *Report) ReadAuditLog(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), streaming.MaxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record pkg.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed to parse audit record on line %d: %w", line, err)
+		}
+		r.WriteAudit(record)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return nil
+}
+
+// RecordLatency implements pkg.MetricsCollector, counting a request
+func (r *Report) RecordLatency(operation string, duration time.Duration, labels map[string]string) {
+	r.Add(r.metricsEntry(labels, Entry{Requests: 1}))
+}
+
+// RecordTokens implements pkg.MetricsCollector
+func (r *Report) RecordTokens(promptTokens, completionTokens int, labels map[string]string) {
+	r.Add(r.metricsEntry(labels, Entry{PromptTokens: promptTokens, CompletionTokens: completionTokens}))
+}
+
+// RecordCost implements pkg.MetricsCollector
+func (r *Report) RecordCost(cost float64, labels map[string]string) {
+	r.Add(r.metricsEntry(labels, Entry{Cost: cost}))
+}
+
+// RecordError implements pkg.MetricsCollector. Failed requests aren't reported.
+func (r *Report) RecordError(operation string, err error, labels map[string]string) {}
+
+func (r *Report) metricsEntry(labels map[string]string, entry Entry) Entry {
+	entry.Time = r.opts.Clock.Now()
+	entry.Model = labels["model"]
+	entry.Tag = labels[r.opts.TagLabel]
+	return entry
+}
+
+// Rows returns the usage grouped by the given dimensions, sorted by day, model and tag.
+// Without dimensions it returns a single row with the totals.
+func (r *Report) Rows(by ...Dimension) []Row {
+	group := make(map[Dimension]bool, len(by))
+	for _, d := range by {
+		group[d] = true
+	}
+
+	r.mu.Lock()
+	grouped := make(map[rowKey]*Row)
+	for key, row := range r.rows {
+		var k rowKey
+		if group[ByDay] {
+			k.day = key.day
+		}
+		if group[ByModel] {
+			k.model = key.model
+		}
+		if group[ByTag] {
+			k.tag = key.tag
+		}
+		g, ok := grouped[k]
+		if !ok {
+			g = &Row{Day: k.day, Model: k.model, Tag: k.tag}
+			grouped[k] = g
+		}
+		g.add(*row)
+	}
+	r.mu.Unlock()
+
+	rows := make([]Row, 0, len(grouped))
+	for _, row := range grouped {
+		rows = append(rows, *row)
+	}
+	if len(rows) == 0 && len(by) == 0 {
+		rows = append(rows, Row{})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return a.Tag < b.Tag
+	})
+	return rows
+}
+
+// WriteCSV writes rows as CSV with a header line
+func WriteCSV(w io.Writer, rows []Row) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"day", "model", "tag", "requests", "prompt_tokens", "completion_tokens", "cost"})
+	for _, row := range rows {
+		cw.Write([]string{
+			row.Day,
+			row.Model,
+			row.Tag,
+			strconv.Itoa(row.Requests),
+			strconv.Itoa(row.PromptTokens),
+			strconv.Itoa(row.CompletionTokens),
+			strconv.FormatFloat(row.Cost, 'f', -1, 64),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON writes rows as an indented JSON array
+func WriteJSON(w io.Writer, rows []Row) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rows); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// Drift is a day and model whose recorded usage differs from the activity API's
+type Drift struct {
+	Day   string `json:"day"`
+	Model string `json:"model"`
+
+	ReportedRequests int     `json:"reported_requests"`
+	ActualRequests   int     `json:"actual_requests"`
+	ReportedCost     float64 `json:"reported_cost"`
+	ActualCost       float64 `json:"actual_cost"`
+}
+
+// Difference is the actual cost minus the reported cost: positive when the report
+// missed usage
+func (d Drift) Difference() float64 {
+	return d.ActualCost - d.ReportedCost
+}
+
+// ReconcileActivity compares the report's usage per day and model with activity items,
+// returning the pairs whose request counts differ or whose costs differ by more than
+// tolerance USD. Activity items match a model by name or permaslug. Only days present in
+// items are compared.
+func (r *Report) ReconcileActivity(items []models.ActivityItem, tolerance float64) []Drift {
+	days := make(map[string]bool)
+	for _, item := range items {
+		days[item.Date] = true
+	}
+	return r.reconcile(items, days, tolerance)
+}
+
+// reconcile compares the report's usage on days with activity items
+func (r *Report) reconcile(items []models.ActivityItem, days map[string]bool, tolerance float64) []Drift {
+	type activityKey struct{ day, model string }
+	actual := make(map[activityKey]*Drift)
+	aliases := make(map[string]string)
+	for _, item := range items {
+		if item.ModelPermaslug != "" {
+			aliases[item.ModelPermaslug] = item.Model
+		}
+		key := activityKey{item.Date, item.Model}
+		d, ok := actual[key]
+		if !ok {
+			d = &Drift{Day: item.Date, Model: item.Model}
+			actual[key] = d
+		}
+		d.ActualRequests += item.Requests
+		d.ActualCost += item.Usage
+	}
+
+	for _, row := range r.Rows(ByDay, ByModel) {
+		if !days[row.Day] {
+			continue
+		}
+		model := row.Model
+		if alias, ok := aliases[model]; ok {
+			model = alias
+		}
+		key := activityKey{row.Day, model}
+		d, ok := actual[key]
+		if !ok {
+			d = &Drift{Day: row.Day, Model: model}
+			actual[key] = d
+		}
+		d.ReportedRequests += row.Requests
+		d.ReportedCost += row.Cost
+	}
+
+	var drifts []Drift
+	for _, d := range actual {
+		if d.ReportedRequests != d.ActualRequests || math.Abs(d.Difference()) > tolerance {
+			drifts = append(drifts, *d)
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Day != drifts[j].Day {
+			return drifts[i].Day < drifts[j].Day
+		}
+		return drifts[i].Model < drifts[j].Model
+	})
+	return drifts
+}
+
+// Reconcile fetches the activity of every day in the report and compares it with
+// ReconcileActivity. The activity API only covers completed UTC days, so the report must
+// use UTC days and include whole days. client needs a provisioning key.
+func (r *Report) Reconcile(ctx context.Context, client *pkg.Client, tolerance float64) ([]Drift, error) {
+	var items []models.ActivityItem
+	days := make(map[string]bool)
+	for _, row := range r.Rows(ByDay) {
+		days[row.Day] = true
+		activity, err := client.GetActivity(ctx, &pkg.ActivityOptions{Date: row.Day})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get activity for %s: %w", row.Day, err)
+		}
+		for _, item := range activity.Data {
+			if item.Date == row.Day {
+				items = append(items, item)
+			}
+		}
+	}
+	return r.reconcile(items, days, tolerance), nil
+}
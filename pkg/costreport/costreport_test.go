@@ -0,0 +1,129 @@
+package costreport_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/costreport"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func usageReply(model string, prompt, completion int, cost float64) openroutertest.Reply {
+	reply := openroutertest.TextReply("ok")
+	reply.Response.Model = model
+	reply.Response.Usage = &models.Usage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion, Cost: cost}
+	return reply
+}
+
+func TestReportFromAuditSink(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(
+		usageReply("openai/gpt-4o", 10, 5, 0.01),
+		usageReply("openai/gpt-4o", 20, 10, 0.02),
+		usageReply("anthropic/claude-3.5-sonnet", 30, 15, 0.05),
+		openroutertest.ErrorReply(500, "boom"),
+	)
+
+	report := costreport.New(costreport.Options{})
+	client := srv.Client(pkg.WithAuditSink(report, pkg.AuditOptions{}))
+	ctx := context.Background()
+	for _, call := range []struct{ model, user string }{
+		{"openai/gpt-4o", "search"},
+		{"openai/gpt-4o", "chat"},
+		{"anthropic/claude-3.5-sonnet", "search"},
+		{"openai/gpt-4o", "search"},
+	} {
+		client.CreateChatCompletion(ctx, models.NewChatRequest(call.model, models.WithUserMessage("hi"), models.WithUser(call.user)))
+	}
+
+	total := report.Rows()
+	require.Len(t, total, 1)
+	assert.Equal(t, 3, total[0].Requests)
+	assert.Equal(t, 60, total[0].PromptTokens)
+	assert.InDelta(t, 0.08, total[0].Cost, 1e-9)
+
+	byTag := report.Rows(costreport.ByTag)
+	require.Len(t, byTag, 2)
+	assert.Equal(t, "chat", byTag[0].Tag)
+	assert.Equal(t, 1, byTag[0].Requests)
+	assert.Equal(t, "search", byTag[1].Tag)
+	assert.InDelta(t, 0.06, byTag[1].Cost, 1e-9)
+
+	byModel := report.Rows(costreport.ByDay, costreport.ByModel)
+	require.Len(t, byModel, 2)
+	today := time.Now().UTC().Format(costreport.DayFormat)
+	assert.Equal(t, costreport.Row{Day: today, Model: "anthropic/claude-3.5-sonnet", Requests: 1, PromptTokens: 30, CompletionTokens: 15, Cost: 0.05}, byModel[0])
+}
+
+func TestReportExportAndAuditLog(t *testing.T) {
+	var log bytes.Buffer
+	sink := pkg.NewWriterAuditSink(&log)
+	day := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, sink.WriteAudit(pkg.AuditRecord{
+		Time:       day,
+		StatusCode: 200,
+		Request:    []byte(`{"model":"m","user":"team-a"}`),
+		Response:   []byte(`{"model":"m","usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5,"cost":0.5}}`),
+	}))
+	require.NoError(t, sink.WriteAudit(pkg.AuditRecord{Time: day, Error: "connection refused"}))
+
+	report := costreport.New(costreport.Options{})
+	require.NoError(t, report.ReadAuditLog(&log))
+	rows := report.Rows(costreport.ByDay, costreport.ByModel, costreport.ByTag)
+
+	var csv bytes.Buffer
+	require.NoError(t, costreport.WriteCSV(&csv, rows))
+	assert.Equal(t, "day,model,tag,requests,prompt_tokens,completion_tokens,cost\n2025-03-01,m,team-a,1,3,2,0.5\n", csv.String())
+
+	var out bytes.Buffer
+	require.NoError(t, costreport.WriteJSON(&out, rows))
+	assert.JSONEq(t, `[{"day":"2025-03-01","model":"m","tag":"team-a","requests":1,"prompt_tokens":3,"completion_tokens":2,"cost":0.5}]`, out.String())
+}
+
+func TestReportMetricsCollector(t *testing.T) {
+	clock := openroutertest.NewFakeClock(time.Date(2025, 3, 1, 23, 0, 0, 0, time.UTC))
+	report := costreport.New(costreport.Options{TagLabel: "team", Clock: clock})
+	labels := map[string]string{"model": "m", "team": "ml"}
+	report.RecordLatency("chat_completion", time.Second, labels)
+	report.RecordTokens(100, 50, labels)
+	report.RecordCost(0.25, labels)
+
+	assert.Equal(t, []costreport.Row{{Tag: "ml", Requests: 1, PromptTokens: 100, CompletionTokens: 50, Cost: 0.25}}, report.Rows(costreport.ByTag))
+}
+
+func TestReconcile(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetActivity(
+		models.ActivityItem{Date: "2025-03-01", Model: "openai/gpt-4o", ModelPermaslug: "openai/gpt-4o-2024-08-06", Requests: 2, Usage: 0.03},
+		models.ActivityItem{Date: "2025-03-01", Model: "anthropic/claude-3.5-sonnet", Requests: 1, Usage: 0.09},
+		models.ActivityItem{Date: "2025-03-02", Model: "openai/gpt-4o", Requests: 1, Usage: 1},
+	)
+
+	report := costreport.New(costreport.Options{})
+	day := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	report.Add(costreport.Entry{Time: day, Model: "openai/gpt-4o-2024-08-06", Requests: 2, Cost: 0.03})
+	report.Add(costreport.Entry{Time: day, Model: "anthropic/claude-3.5-sonnet", Requests: 1, Cost: 0.05})
+	report.Add(costreport.Entry{Time: day.AddDate(0, 0, 2), Model: "openai/gpt-4o", Requests: 1, Cost: 0.01})
+
+	drifts, err := report.Reconcile(context.Background(), srv.Client(), 0.001)
+	require.NoError(t, err)
+	require.Len(t, drifts, 2)
+	assert.Equal(t, "anthropic/claude-3.5-sonnet", drifts[0].Model)
+	assert.InDelta(t, 0.04, drifts[0].Difference(), 1e-9)
+	assert.Equal(t, costreport.Drift{Day: "2025-03-03", Model: "openai/gpt-4o", ReportedRequests: 1, ReportedCost: 0.01}, drifts[1])
+
+	var dates []string
+	for _, req := range srv.Requests() {
+		dates = append(dates, req.Query)
+	}
+	assert.Equal(t, []string{"date=2025-03-01", "date=2025-03-03"}, dates)
+}
@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// DefaultCritiqueRubric is used when CritiqueOptions does not set a rubric
+const DefaultCritiqueRubric = "Correctness, completeness, clarity, and whether the answer addresses the request directly."
+
+// CritiqueOptions represents options for CritiqueAndRevise
+type CritiqueOptions struct {
+	// Rubric is what the judge scores the answer against
+	Rubric string
+
+	// ApprovalScore stops the loop early once the judge scores an answer at least this
+	// high (out of 10). Zero runs every round.
+	ApprovalScore int
+}
+
+// Critique is a judge model's assessment of a draft
+type Critique struct {
+	Score       int      `json:"score" description:"Score from 1 to 10 against the rubric"`
+	Issues      []string `json:"issues" description:"Specific problems with the answer, empty if none"`
+	Suggestions string   `json:"suggestions" description:"Concrete instructions for improving the answer"`
+
+	Response *models.ChatCompletionResponse `json:"-"`
+}
+
+// CritiqueRound is one critique of a draft and the revision it produced
+type CritiqueRound struct {
+	Draft    *models.ChatCompletionResponse
+	Critique *Critique
+
+	// Revision is nil when the loop stopped because the draft was approved
+	Revision *models.ChatCompletionResponse
+}
+
+// CritiqueResult holds the final answer and every intermediate artifact
+type CritiqueResult struct {
+	Final  *models.ChatCompletionResponse
+	Rounds []CritiqueRound
+
+	// Usage sums the token usage of every generation, critique, and revision.
+	// Usage.Cost is the total cost in credits.
+	Usage models.Usage
+}
+
+// CritiqueAndRevise generates an answer to req, has judgeModel critique it against a rubric,
+// and revises it, for up to rounds rounds. Usage accounting is enabled on every request so
+// the result reports the total cost.
+func (c *Client) CritiqueAndRevise(ctx context.Context, req models.ChatCompletionRequest, judgeModel string, rounds int, opts *CritiqueOptions) (*CritiqueResult, error) {
+	if rounds <= 0 {
+		return nil, fmt.Errorf("rounds must be positive")
+	}
+	if opts == nil {
+		opts = &CritiqueOptions{}
+	}
+	rubric := opts.Rubric
+	if rubric == "" {
+		rubric = DefaultCritiqueRubric
+	}
+
+	req.Usage = &models.UsageConfig{Include: true}
+	result := &CritiqueResult{}
+
+	draft, err := c.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate draft: %w", err)
+	}
+	result.addUsage(draft.Usage)
+
+	for i := 0; i < rounds; i++ {
+		answer, err := responseText(draft)
+		if err != nil {
+			return nil, err
+		}
+
+		critique, err := c.critique(ctx, judgeModel, rubric, req.Messages, answer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to critique round %d: %w", i+1, err)
+		}
+		result.addUsage(critique.Response.Usage)
+
+		round := CritiqueRound{Draft: draft, Critique: critique}
+		if opts.ApprovalScore > 0 && critique.Score >= opts.ApprovalScore {
+			result.Rounds = append(result.Rounds, round)
+			break
+		}
+
+		revisionReq := req
+		revisionReq.Messages = append(append([]models.Message(nil), req.Messages...),
+			models.NewTextMessage(models.RoleAssistant, answer),
+			models.NewTextMessage(models.RoleUser, revisionPrompt(critique)),
+		)
+		revision, err := c.CreateChatCompletion(ctx, revisionReq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to revise round %d: %w", i+1, err)
+		}
+		result.addUsage(revision.Usage)
+
+		round.Revision = revision
+		result.Rounds = append(result.Rounds, round)
+		draft = revision
+	}
+
+	result.Final = draft
+	return result, nil
+}
+
+// critique asks the judge model to score answer against rubric
+func (c *Client) critique(ctx context.Context, judgeModel, rubric string, conversation []models.Message, answer string) (*Critique, error) {
+	var prompt strings.Builder
+	if question := lastUserText(conversation); question != "" {
+		fmt.Fprintf(&prompt, "Request:\n%s\n\n", question)
+	}
+	fmt.Fprintf(&prompt, "Answer:\n%s", answer)
+
+	req := models.NewChatRequest(judgeModel,
+		models.WithSystemMessage("You are a strict reviewer. Critique the answer to the request against this rubric:\n"+rubric),
+		models.WithUserMessage(prompt.String()),
+		models.WithTemperature(0),
+		models.WithUsageAccounting(),
+	)
+
+	var critique Critique
+	resp, err := NewStructuredOutput(c).CreateWithSchema(ctx, req, "critique", critique)
+	if err != nil {
+		return nil, err
+	}
+	if err := ParseStructuredResponse(resp, &critique); err != nil {
+		return nil, err
+	}
+	critique.Response = resp
+	return &critique, nil
+}
+
+// revisionPrompt asks for a revision addressing a critique
+func revisionPrompt(critique *Critique) string {
+	var b strings.Builder
+	b.WriteString("A reviewer critiqued your answer. Revise it to address the critique and reply with the complete revised answer only.")
+	if len(critique.Issues) > 0 {
+		b.WriteString("\n\nIssues:")
+		for _, issue := range critique.Issues {
+			fmt.Fprintf(&b, "\n- %s", issue)
+		}
+	}
+	if critique.Suggestions != "" {
+		fmt.Fprintf(&b, "\n\nSuggestions:\n%s", critique.Suggestions)
+	}
+	return b.String()
+}
+
+// addUsage adds a response's usage to the total
+func (r *CritiqueResult) addUsage(usage *models.Usage) {
+	if usage == nil {
+		return
+	}
+	r.Usage.PromptTokens += usage.PromptTokens
+	r.Usage.CompletionTokens += usage.CompletionTokens
+	r.Usage.TotalTokens += usage.TotalTokens
+	r.Usage.Cost += usage.Cost
+}
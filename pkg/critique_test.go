@@ -0,0 +1,147 @@
+package pkg_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// critiqueServer answers with numbered drafts, and as the "judge" model with the given
+// scores in turn. Every response costs 0.01 credits.
+func critiqueServer(t *testing.T, scores ...int) *openroutertest.Server {
+	t.Helper()
+	srv := openroutertest.NewServer()
+	t.Cleanup(srv.Close)
+	drafts, critiques := 0, 0
+	srv.SetChatHandler(func(req models.ChatCompletionRequest) openroutertest.Reply {
+		var reply openroutertest.Reply
+		if req.Model == "judge" {
+			reply = openroutertest.TextReply(fmt.Sprintf(`{"score":%d,"issues":["too vague"],"suggestions":"Give a number."}`, scores[critiques]))
+			critiques++
+		} else {
+			drafts++
+			reply = openroutertest.TextReply(fmt.Sprintf("draft %d", drafts))
+		}
+		reply.Response.Usage.Cost = 0.01
+		return reply
+	})
+	return srv
+}
+
+func TestCritiqueAndRevise(t *testing.T) {
+	srv := critiqueServer(t, 4, 6)
+
+	req := models.NewChatRequest("m", models.WithUserMessage("How tall is Everest?"))
+	result, err := srv.Client().CritiqueAndRevise(context.Background(), req, "judge", 2, nil)
+	require.NoError(t, err)
+	final, _ := result.Final.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "draft 3", final)
+	require.Len(t, result.Rounds, 2)
+	assert.Equal(t, 4, result.Rounds[0].Critique.Score)
+	assert.Equal(t, []string{"too vague"}, result.Rounds[0].Critique.Issues)
+	assert.Same(t, result.Rounds[0].Revision, result.Rounds[1].Draft)
+	assert.Same(t, result.Final, result.Rounds[1].Revision)
+
+	// Draft, then a critique and revision per round, each with usage accounting
+	requests := srv.Requests()
+	require.Len(t, requests, 5)
+	assert.InDelta(t, 0.05, result.Usage.Cost, 1e-9)
+	assert.Equal(t, 50, result.Usage.PromptTokens)
+	for _, request := range requests {
+		sent, err := request.ChatRequest()
+		require.NoError(t, err)
+		require.NotNil(t, sent.Usage)
+		assert.True(t, sent.Usage.Include)
+	}
+
+	// The judge sees the rubric, the request and the draft
+	judged, err := requests[1].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, *judged.Temperature)
+	system, _ := judged.Messages[0].GetTextContent()
+	assert.Contains(t, system, pkg.DefaultCritiqueRubric)
+	prompt, _ := judged.Messages[1].GetTextContent()
+	assert.Equal(t, "Request:\nHow tall is Everest?\n\nAnswer:\ndraft 1", prompt)
+
+	// The reviser sees its draft and the critique
+	revised, err := requests[2].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "m", revised.Model)
+	require.Len(t, revised.Messages, 3)
+	draft, _ := revised.Messages[1].GetTextContent()
+	assert.Equal(t, "draft 1", draft)
+	critique, _ := revised.Messages[2].GetTextContent()
+	assert.Contains(t, critique, "Issues:\n- too vague")
+	assert.Contains(t, critique, "Suggestions:\nGive a number.")
+	assert.Len(t, req.Messages, 1, "the request is not modified")
+}
+
+func TestCritiqueAndReviseStops(t *testing.T) {
+	tests := []struct {
+		name     string
+		scores   []int
+		rounds   int
+		final    string
+		requests int
+	}{
+		{"approved first draft", []int{9}, 3, "draft 1", 2},
+		{"approved revision", []int{5, 8}, 3, "draft 2", 4},
+		{"max rounds", []int{5, 6}, 2, "draft 3", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := critiqueServer(t, tt.scores...)
+
+			req := models.NewChatRequest("m", models.WithUserMessage("How tall is Everest?"))
+			result, err := srv.Client().CritiqueAndRevise(context.Background(), req, "judge", tt.rounds, &pkg.CritiqueOptions{
+				Rubric:        "Gives the height in metres.",
+				ApprovalScore: 8,
+			})
+			require.NoError(t, err)
+			final, _ := result.Final.Choices[0].Message.GetTextContent()
+			assert.Equal(t, tt.final, final)
+			assert.Len(t, srv.Requests(), tt.requests)
+			require.Len(t, result.Rounds, len(tt.scores))
+			last := result.Rounds[len(result.Rounds)-1]
+			assert.Equal(t, last.Critique.Score < 8, last.Revision != nil, "an approved draft is not revised")
+
+			judged, err := srv.Requests()[1].ChatRequest()
+			require.NoError(t, err)
+			system, _ := judged.Messages[0].GetTextContent()
+			assert.Contains(t, system, "Gives the height in metres.")
+		})
+	}
+}
+
+func TestCritiqueAndReviseErrors(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	req := models.NewChatRequest("m", models.WithUserMessage("How tall is Everest?"))
+
+	_, err := client.CritiqueAndRevise(context.Background(), req, "judge", 0, nil)
+	assert.ErrorContains(t, err, "rounds must be positive")
+
+	srv.EnqueueChat(openroutertest.ErrorReply(400, "no draft"))
+	_, err = client.CritiqueAndRevise(context.Background(), req, "judge", 1, nil)
+	assert.ErrorContains(t, err, "failed to generate draft")
+
+	srv.EnqueueChat(openroutertest.TextReply("draft 1"), openroutertest.TextReply("not a critique"))
+	_, err = client.CritiqueAndRevise(context.Background(), req, "judge", 1, nil)
+	assert.ErrorContains(t, err, "failed to critique round 1")
+
+	srv.EnqueueChat(
+		openroutertest.TextReply("draft 1"),
+		openroutertest.TextReply(`{"score":3,"issues":[],"suggestions":"More detail."}`),
+		openroutertest.ErrorReply(400, "no revision"),
+	)
+	_, err = client.CritiqueAndRevise(context.Background(), req, "judge", 1, nil)
+	assert.ErrorContains(t, err, "failed to revise round 1")
+}
@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDebugBodyLength is the number of body bytes WithDebug dumps before truncating
+const DefaultDebugBodyLength = 4096
+
+// debugRedactionSlack is kept past DefaultDebugBodyLength so an API key crossing the cut
+// is still recognized and masked
+const debugRedactionSlack = 256
+
+// WithDebug dumps every request and response to w: headers with the API key masked and
+// bodies truncated to DefaultDebugBodyLength. Failed requests are followed by a curl
+// command that reproduces them, reading the key from $OPENROUTER_API_KEY. Dumps may
+// contain prompts and completions, so don't enable it in production. It wraps the HTTP
+// transport, so pass it after WithHTTPClient.
+func WithDebug(w io.Writer) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		base := httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		httpClient.Transport = &debugTransport{base: base, w: w}
+		c.httpClient = &httpClient
+	}
+}
+
+// CurlCommand returns a curl command that sends req again, with the Authorization header
+// reading the key from $OPENROUTER_API_KEY. The body is read without consuming it and
+// sent uncompressed.
+func CurlCommand(req *http.Request) string {
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "curl -X %s %s", req.Method, shellQuote(req.URL.String()))
+	for _, name := range sortedHeaderNames(req.Header) {
+		switch name {
+		case "Authorization":
+			cmd.WriteString(` \` + "\n" + `  -H "Authorization: Bearer $OPENROUTER_API_KEY"`)
+			continue
+		case "Content-Encoding", "Accept-Encoding":
+			continue
+		}
+		for _, value := range req.Header[name] {
+			cmd.WriteString(" \\\n  -H " + shellQuote(name+": "+redactSecrets(value)))
+		}
+	}
+	if body := peekRequestBody(req); len(body) > 0 {
+		cmd.WriteString(" \\\n  --data-raw " + shellQuote(redactSecrets(string(body))))
+	}
+	return cmd.String()
+}
+
+// debugTransport dumps requests and responses
+type debugTransport struct {
+	base http.RoundTripper
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (t *debugTransport) unwrap() http.RoundTripper { return t.base }
+
+func (t *debugTransport) withBase(base http.RoundTripper) http.RoundTripper {
+	return &debugTransport{base: base, w: t.w}
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	var dump bytes.Buffer
+	fmt.Fprintf(&dump, "--> %s %s\n", req.Method, req.URL)
+	writeDebugHeaders(&dump, req.Header)
+	reqBody := peekRequestBody(req)
+	writeDebugBody(&dump, reqBody, len(reqBody))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(&dump, "<-- error after %s: %v\n", time.Since(start).Round(time.Millisecond), err)
+		fmt.Fprintf(&dump, "# reproduce with:\n%s\n\n", CurlCommand(req))
+		t.write(dump.Bytes())
+		return nil, err
+	}
+
+	// Decompress here so the dump shows plain text; the client then skips decompression
+	if err := decompressResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// Only the start of the body is printed, so long streams are not buffered whole
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		limit:      DefaultDebugBodyLength + debugRedactionSlack,
+		done: func(body []byte, total int) {
+			fmt.Fprintf(&dump, "<-- %s (%s)\n", resp.Status, time.Since(start).Round(time.Millisecond))
+			writeDebugHeaders(&dump, resp.Header)
+			writeDebugBody(&dump, body, total)
+			if resp.StatusCode >= 400 {
+				fmt.Fprintf(&dump, "# reproduce with:\n%s\n\n", CurlCommand(req))
+			}
+			t.write(dump.Bytes())
+		},
+	}
+	return resp, nil
+}
+
+// write sends one complete dump to the writer, so concurrent dumps don't interleave
+func (t *debugTransport) write(dump []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.w.Write(dump)
+}
+
+// writeDebugHeaders writes headers sorted by name with secrets masked
+func writeDebugHeaders(w *bytes.Buffer, header http.Header) {
+	for _, name := range sortedHeaderNames(header) {
+		for _, value := range header[name] {
+			if name == "Authorization" {
+				scheme, key, ok := strings.Cut(value, " ")
+				if ok {
+					value = scheme + " " + MaskAPIKey(key)
+				} else {
+					value = MaskAPIKey(value)
+				}
+			}
+			fmt.Fprintf(w, "%s: %s\n", name, redactSecrets(value))
+		}
+	}
+	w.WriteString("\n")
+}
+
+// writeDebugBody writes the start of a body of total bytes, truncated to
+// DefaultDebugBodyLength, with secrets masked
+func writeDebugBody(w *bytes.Buffer, body []byte, total int) {
+	if len(body) == 0 {
+		return
+	}
+	text := redactSecrets(string(body))
+	if len(text) > DefaultDebugBodyLength || total > len(body) {
+		fmt.Fprintf(w, "%s\n... (truncated, %d bytes total)\n\n", text[:min(len(text), DefaultDebugBodyLength)], total)
+		return
+	}
+	w.WriteString(strings.TrimRight(text, "\n") + "\n\n")
+}
+
+func sortedHeaderNames(header http.Header) []string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,81 @@
+package pkg_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+func TestWithDebugDumpsRequests(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("hello there"), openroutertest.ErrorReply(400, "it's invalid"))
+
+	var dump bytes.Buffer
+	client := pkg.NewClient("sk-or-v1-secretsecretsecret1234", pkg.WithBaseURL(srv.URL), pkg.WithDebug(&dump))
+	ctx := context.Background()
+
+	_, err := client.CreateChatCompletion(ctx, models.NewChatRequest("m", models.WithUserMessage(strings.Repeat("long ", 2000))))
+	require.NoError(t, err)
+	out := dump.String()
+	assert.Contains(t, out, "--> POST "+srv.URL+"/chat/completions")
+	assert.Contains(t, out, "Authorization: Bearer sk-or-...1234")
+	assert.Contains(t, out, "... (truncated, ")
+	assert.Contains(t, out, "<-- 200 OK")
+	assert.Contains(t, out, "hello there")
+	assert.NotContains(t, out, "secretsecret")
+	assert.NotContains(t, out, "curl")
+
+	dump.Reset()
+	_, err = client.CreateChatCompletion(ctx, models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.Error(t, err)
+	out = dump.String()
+	assert.Contains(t, out, "<-- 400 Bad Request")
+	assert.Contains(t, out, "# reproduce with:\ncurl -X POST '"+srv.URL+"/chat/completions'")
+	assert.Contains(t, out, `-H "Authorization: Bearer $OPENROUTER_API_KEY"`)
+	assert.Contains(t, out, `--data-raw '{"messages":[{"role":"user","content":"hi"}],"model":"m"}'`)
+	assert.NotContains(t, out, "secretsecret")
+}
+
+func TestWithDebugTruncatesStreams(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	long := strings.Repeat("word ", 20000)
+	srv.EnqueueChat(openroutertest.TextReply(long))
+
+	var dump bytes.Buffer
+	client := pkg.NewClient("sk-or-test", pkg.WithBaseURL(srv.URL), pkg.WithDebug(&dump))
+	stream, err := client.CreateChatCompletionStream(context.Background(), hiRequest)
+	require.NoError(t, err)
+	resp, err := streaming.CollectStream(stream)
+	require.NoError(t, err)
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, long, text, "the stream itself is not truncated")
+
+	// Only the start of the stream is dumped, with the size of the whole
+	out := dump.String()
+	assert.Contains(t, out, "<-- 200 OK")
+	assert.Contains(t, out, "data: ")
+	assert.Regexp(t, `\.\.\. \(truncated, \d{6,} bytes total\)`, out)
+	assert.Less(t, dump.Len(), 3*pkg.DefaultDebugBodyLength)
+}
+
+func TestCurlCommandQuotes(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://openrouter.ai/api/v1/chat/completions", strings.NewReader(`{"content":"it's"}`))
+	require.NoError(t, err)
+	req.Header.Set("X-Title", "My App")
+
+	assert.Equal(t, `curl -X POST 'https://openrouter.ai/api/v1/chat/completions' \
+  -H 'X-Title: My App' \
+  --data-raw '{"content":"it'\''s"}'`, pkg.CurlCommand(req))
+}
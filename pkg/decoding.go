@@ -0,0 +1,145 @@
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rizome-dev/go-openrouter/pkg/codec"
+)
+
+// WithStrictDecoding makes responses carrying fields the SDK's types don't declare an
+// error, using encoding/json's DisallowUnknownFields, so tests and staging catch API
+// changes early. Responses, including streamed chunks, are then decoded with
+// encoding/json whatever WithJSONCodec sets; error responses stay lenient. By default
+// unknown fields are ignored.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
+
+// WithUnknownFieldRecorder records the fields of responses that the SDK's types don't
+// declare, without rejecting them: a diagnostic mode to run in production to learn what
+// the API sends that the SDK drops.
+func WithUnknownFieldRecorder(recorder *UnknownFieldRecorder) Option {
+	return func(c *Client) {
+		c.unknownFields = recorder
+	}
+}
+
+// UnknownFieldRecorder counts the unknown fields seen in responses, keyed by the Go type
+// decoded and the field's path, e.g. "models.ChatCompletionResponse choices[].message.audio".
+// It is safe for concurrent use.
+type UnknownFieldRecorder struct {
+	// Logger, when set, logs a warning the first time each field is seen
+	Logger Logger
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// Fields returns how many responses carried each unknown field
+func (r *UnknownFieldRecorder) Fields() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fields := make(map[string]int, len(r.counts))
+	for field, n := range r.counts {
+		fields[field] = n
+	}
+	return fields
+}
+
+// String lists the unknown fields seen, one per line with its count
+func (r *UnknownFieldRecorder) String() string {
+	fields := r.Fields()
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s %d\n", name, fields[name])
+	}
+	return b.String()
+}
+
+// record counts the unknown fields of a response decoded into typ
+func (r *UnknownFieldRecorder) record(typ string, paths []string) {
+	r.mu.Lock()
+	if r.counts == nil {
+		r.counts = make(map[string]int)
+	}
+	var first []string
+	for _, path := range paths {
+		key := typ + " " + path
+		if r.counts[key] == 0 {
+			first = append(first, path)
+		}
+		r.counts[key]++
+	}
+	r.mu.Unlock()
+
+	if r.Logger != nil && len(first) > 0 {
+		r.Logger.Warn("Unknown fields in response", F("type", typ), F("fields", first))
+	}
+}
+
+// responseCodec returns the codec responses are decoded with
+func (c *Client) responseCodec() codec.Codec {
+	if !c.strictDecoding && c.unknownFields == nil {
+		return c.codec
+	}
+	return responseCodec{Codec: c.codec, strict: c.strictDecoding, recorder: c.unknownFields}
+}
+
+// streamCodec returns the codec streamed chunks are decoded with
+func (c *Client) streamCodec() codec.Codec {
+	if !c.strictDecoding && c.unknownFields == nil {
+		return c.codec
+	}
+	// Chunks are decoded into an internal type wrapping a ChatCompletionResponse
+	return responseCodec{Codec: c.codec, strict: c.strictDecoding, recorder: c.unknownFields, name: "models.ChatCompletionResponse"}
+}
+
+// responseCodec records unknown fields before decoding, strictly or not
+type responseCodec struct {
+	codec.Codec
+	strict   bool
+	recorder *UnknownFieldRecorder
+
+	// name overrides the type name fields are recorded under
+	name string
+}
+
+func (rc responseCodec) Unmarshal(data []byte, v interface{}) error {
+	if rc.recorder != nil {
+		if paths := codec.UnknownFields(data, v); len(paths) > 0 {
+			name := rc.name
+			if name == "" {
+				name = typeName(v)
+			}
+			rc.recorder.record(name, paths)
+		}
+	}
+	if rc.strict {
+		return codec.Strict(rc.Codec).Unmarshal(data, v)
+	}
+	return rc.Codec.Unmarshal(data, v)
+}
+
+// typeName names the type a response is decoded into, e.g. "models.ChatCompletionResponse"
+func typeName(v interface{}) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "<nil>"
+	}
+	return t.String()
+}
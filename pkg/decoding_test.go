@@ -0,0 +1,153 @@
+package pkg_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/codec"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// evolvedAPI answers like an API that has added fields the SDK doesn't know yet
+func evolvedAPI(t *testing.T) *openroutertest.Server {
+	t.Helper()
+	srv := openroutertest.NewServer()
+	srv.SetChatHandler(func(req models.ChatCompletionRequest) openroutertest.Reply {
+		return openroutertest.Reply{
+			Body: `{"id":"gen-1","model":"m","service_tier":"default","choices":[{"index":0,"message":{"role":"assistant","content":"Hi","audio":null}}]}`,
+			Events: []openroutertest.StreamEvent{
+				{Raw: `{"id":"gen-1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hi","audio":null}}],"service_tier":"default"}`},
+			},
+		}
+	})
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// recordingLogger keeps warnings
+type recordingLogger struct {
+	mu   sync.Mutex
+	warn []string
+}
+
+func (l *recordingLogger) Debug(msg string, fields ...pkg.Field) {}
+func (l *recordingLogger) Info(msg string, fields ...pkg.Field)  {}
+func (l *recordingLogger) Error(msg string, fields ...pkg.Field) {}
+
+func (l *recordingLogger) Warn(msg string, fields ...pkg.Field) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warn = append(l.warn, msg)
+}
+
+func (l *recordingLogger) warnings() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.warn...)
+}
+
+var hiRequest = models.NewChatRequest("m", models.WithUserMessage("Hi"))
+
+func TestStrictDecodingRejectsUnknownFields(t *testing.T) {
+	srv := evolvedAPI(t)
+	ctx := context.Background()
+
+	// Lenient by default
+	_, err := srv.Client().CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+
+	strict := srv.Client(pkg.WithStrictDecoding())
+	_, err = strict.CreateChatCompletion(ctx, hiRequest)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown field "service_tier"`)
+
+	stream, err := strict.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	defer stream.Close()
+	_, err = stream.Read()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown field")
+}
+
+func TestStrictDecodingAcceptsKnownResponses(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client(pkg.WithStrictDecoding())
+	ctx := context.Background()
+
+	_, err := client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+
+	stream, err := client.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	defer stream.Close()
+	for {
+		_, err := stream.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	// Error responses are never rejected for their fields
+	srv.EnqueueChat(openroutertest.ErrorReply(http.StatusTooManyRequests, "slow down"))
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	assert.Contains(t, err.Error(), "slow down")
+}
+
+func TestUnknownFieldRecorder(t *testing.T) {
+	srv := evolvedAPI(t)
+	ctx := context.Background()
+	logger := &recordingLogger{}
+	recorder := &pkg.UnknownFieldRecorder{Logger: logger}
+	client := srv.Client(pkg.WithUnknownFieldRecorder(recorder))
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.CreateChatCompletion(ctx, hiRequest)
+		require.NoError(t, err, "the recorder doesn't reject responses")
+		assert.Equal(t, "gen-1", resp.ID)
+	}
+	stream, err := client.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	_, err = stream.Read()
+	require.NoError(t, err)
+	stream.Close()
+
+	assert.Equal(t, map[string]int{
+		"models.ChatCompletionResponse choices[].delta.audio":   1,
+		"models.ChatCompletionResponse choices[].message.audio": 2,
+		"models.ChatCompletionResponse service_tier":            3,
+	}, recorder.Fields())
+	assert.Contains(t, recorder.String(), "models.ChatCompletionResponse service_tier 3\n")
+	assert.Len(t, logger.warnings(), 2, "each new set of fields is logged once")
+}
+
+func TestUnknownFields(t *testing.T) {
+	type inner struct {
+		Name string `json:"name"`
+	}
+	type base struct {
+		ID string `json:"id"`
+	}
+	type outer struct {
+		base
+		Items  []inner              `json:"items"`
+		ByName map[string]inner     `json:"by_name"`
+		Any    interface{}          `json:"any"`
+		Stop   models.StopSequences `json:"stop"`
+		Skip   string               `json:"-"`
+		Plain  int
+	}
+
+	data := `{"id":"x","ID2":1,"plain":2,"Skip":"y","items":[{"name":"a","extra":1}],"by_name":{"k":{"other":true}},"any":{"free":1},"stop":"END"}`
+	assert.Equal(t, []string{"ID2", "Skip", "by_name.*.other", "items[].extra"}, codec.UnknownFields([]byte(data), &outer{}))
+	assert.Empty(t, codec.UnknownFields([]byte(`not json`), &outer{}))
+}
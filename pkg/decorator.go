@@ -0,0 +1,41 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// RequestDecorator rewrites a chat request before it is validated and sent, e.g. to
+// compress long context. It must not modify slices or maps shared with the caller's
+// request; copy them before changing their elements.
+type RequestDecorator func(ctx context.Context, req *models.ChatCompletionRequest) error
+
+type skipDecoratorsContextKey struct{}
+
+// WithRequestDecorators adds decorators applied, in order, to every chat completion
+// request
+func WithRequestDecorators(decorators ...RequestDecorator) Option {
+	return func(c *Client) {
+		c.decorators = append(c.decorators, decorators...)
+	}
+}
+
+// withoutDecorators returns a context whose requests skip the client's decorators, for
+// requests that decorators make themselves
+func withoutDecorators(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipDecoratorsContextKey{}, true)
+}
+
+// decorate applies the client's decorators to req
+func (c *Client) decorate(ctx context.Context, req *models.ChatCompletionRequest) error {
+	if skip, _ := ctx.Value(skipDecoratorsContextKey{}).(bool); skip {
+		return nil
+	}
+	for _, decorator := range c.decorators {
+		if err := decorator(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// DefaultDriftThreshold is the similarity score below which an output has drifted, by
+// default
+const DefaultDriftThreshold = 0.9
+
+// DriftComparator scores how similar a live output is to its baseline, from 0 (unrelated)
+// to 1 (equivalent)
+type DriftComparator interface {
+	Similarity(ctx context.Context, baseline, output string) (float64, error)
+}
+
+// DriftComparatorFunc adapts a function to DriftComparator
+type DriftComparatorFunc func(ctx context.Context, baseline, output string) (float64, error)
+
+// Similarity calls f
+func (f DriftComparatorFunc) Similarity(ctx context.Context, baseline, output string) (float64, error) {
+	return f(ctx, baseline, output)
+}
+
+// ExactComparator scores outputs 1 when they are equal after trimming whitespace, and 0
+// otherwise
+func ExactComparator() DriftComparator {
+	return DriftComparatorFunc(func(ctx context.Context, baseline, output string) (float64, error) {
+		if strings.TrimSpace(baseline) == strings.TrimSpace(output) {
+			return 1, nil
+		}
+		return 0, nil
+	})
+}
+
+// EmbeddingComparator scores outputs by the cosine similarity of their embeddings, so
+// rewordings with the same meaning score close to 1
+func EmbeddingComparator(embedder Embedder) DriftComparator {
+	return DriftComparatorFunc(func(ctx context.Context, baseline, output string) (float64, error) {
+		vectors, err := embedder.Embed(ctx, []string{baseline, output})
+		if err != nil {
+			return 0, fmt.Errorf("failed to embed outputs: %w", err)
+		}
+		if len(vectors) != 2 {
+			return 0, fmt.Errorf("expected 2 embeddings, got %d", len(vectors))
+		}
+		return cosineSimilarity(vectors[0], vectors[1]), nil
+	})
+}
+
+// JudgeComparator asks a judge model how equivalent the outputs are, for outputs whose
+// wording may legitimately vary
+func JudgeComparator(client *Client, model string) DriftComparator {
+	return DriftComparatorFunc(func(ctx context.Context, baseline, output string) (float64, error) {
+		req := models.NewChatRequest(model,
+			models.WithSystemMessage("You are a careful judge. Rate how equivalent the new answer is to the baseline "+
+				"answer in meaning, correctness and format, from 0 (unrelated or contradictory) to 1 (equivalent). "+
+				"Ignore differences in wording alone."),
+			models.WithUserMessage(fmt.Sprintf("Baseline answer:\n%s\n\nNew answer:\n%s", baseline, output)),
+			models.WithTemperature(0),
+		)
+
+		var verdict struct {
+			Score     float64 `json:"score" description:"Equivalence from 0 to 1"`
+			Rationale string  `json:"rationale" description:"Brief reason for the score"`
+		}
+		resp, err := NewStructuredOutput(client).CreateWithSchema(ctx, req, "drift_verdict", verdict)
+		if err != nil {
+			return 0, err
+		}
+		if err := ParseStructuredResponse(resp, &verdict); err != nil {
+			return 0, err
+		}
+		return min(max(verdict.Score, 0), 1), nil
+	})
+}
+
+// DriftMetricsCollector is a MetricsCollector that also records drift scores. Drift
+// detectors report to it when the client's metrics implement it.
+type DriftMetricsCollector interface {
+	MetricsCollector
+
+	// RecordDriftScore reports the similarity of a replayed output to its baseline
+	RecordDriftScore(score float64, labels map[string]string)
+}
+
+// DriftOptions configures a DriftDetector
+type DriftOptions struct {
+	// Baselines are the saved prompts and their recorded outputs, e.g. loaded with
+	// LoadReproBundle. Required.
+	Baselines []*models.ReproBundle
+
+	// Comparator scores live outputs against the baselines. Defaults to ExactComparator.
+	Comparator DriftComparator
+
+	// Threshold is the score below which an output has drifted. Defaults to
+	// DefaultDriftThreshold.
+	Threshold float64
+
+	// Logger, when set, warns about drifted outputs and failed replays
+	Logger Logger
+
+	// OnDrift, when set, is called with every check that found drift
+	OnDrift func(DriftReport)
+}
+
+// DriftResult is the outcome of replaying one baseline
+type DriftResult struct {
+	Baseline *models.ReproBundle
+	Response *models.ChatCompletionResponse
+
+	// Diff reports which metadata changed, e.g. the system fingerprint
+	Diff models.ReproDiff
+
+	// Score is the comparator's similarity, and Drifted whether it is below the threshold
+	Score   float64
+	Drifted bool
+
+	// Err is set when the replay or the comparison failed
+	Err error
+}
+
+// DriftReport is the outcome of a check of every baseline
+type DriftReport struct {
+	Results   []DriftResult
+	Drifted   int
+	Failed    int
+	CheckedAt time.Time
+}
+
+// DriftDetector replays a saved prompt set and compares the live outputs against their
+// recorded baselines, alerting through the logger, the client's metrics and OnDrift when
+// a model's behavior shifts. Replays are pinned to the model version and provider that
+// produced each baseline.
+type DriftDetector struct {
+	client *Client
+	opts   DriftOptions
+}
+
+// NewDriftDetector creates a drift detector replaying baselines with client
+func NewDriftDetector(client *Client, opts DriftOptions) (*DriftDetector, error) {
+	if len(opts.Baselines) == 0 {
+		return nil, fmt.Errorf("drift detector requires baselines")
+	}
+	if opts.Comparator == nil {
+		opts.Comparator = ExactComparator()
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultDriftThreshold
+	}
+	return &DriftDetector{client: client, opts: opts}, nil
+}
+
+// Check replays every baseline once and reports the results. Failed replays are
+// reported, not returned as errors.
+func (d *DriftDetector) Check(ctx context.Context) DriftReport {
+	report := DriftReport{CheckedAt: d.client.clock.Now()}
+	for _, baseline := range d.opts.Baselines {
+		result := d.check(ctx, baseline)
+		switch {
+		case result.Err != nil:
+			report.Failed++
+		case result.Drifted:
+			report.Drifted++
+		}
+		report.Results = append(report.Results, result)
+	}
+	if report.Drifted > 0 && d.opts.OnDrift != nil {
+		d.opts.OnDrift(report)
+	}
+	return report
+}
+
+// Run checks the baselines now and then every interval, until ctx is done. Run it in its
+// own goroutine:
+//
+//	go detector.Run(ctx, time.Hour)
+func (d *DriftDetector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		d.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check replays and scores one baseline, and reports the outcome
+func (d *DriftDetector) check(ctx context.Context, baseline *models.ReproBundle) DriftResult {
+	result := DriftResult{Baseline: baseline}
+	labels := map[string]string{"model": baseline.Model, "prompt": shortHash(baseline.RequestHash)}
+
+	result.Response, result.Diff, result.Err = d.client.Replay(ctx, baseline)
+	if result.Err == nil {
+		var expected, output string
+		expected, result.Err = responseText(baseline.Response)
+		if result.Err == nil {
+			output, result.Err = responseText(result.Response)
+		}
+		if result.Err == nil {
+			result.Score, result.Err = d.opts.Comparator.Similarity(ctx, expected, output)
+		}
+	}
+
+	metrics := d.client.metrics
+	if result.Err != nil {
+		if d.opts.Logger != nil && ctx.Err() == nil {
+			d.opts.Logger.Warn("Drift check failed", F("model", baseline.Model), F("prompt", labels["prompt"]), F("error", result.Err))
+		}
+		if metrics != nil {
+			metrics.RecordError("drift_check", result.Err, labels)
+		}
+		return result
+	}
+
+	result.Drifted = result.Score < d.opts.Threshold
+	if driftMetrics, ok := metrics.(DriftMetricsCollector); ok {
+		driftMetrics.RecordDriftScore(result.Score, labels)
+	}
+	if result.Drifted {
+		err := fmt.Errorf("output drifted: similarity %.2f is below %.2f", result.Score, d.opts.Threshold)
+		if d.opts.Logger != nil {
+			d.opts.Logger.Warn("Model output drifted",
+				F("model", baseline.Model),
+				F("prompt", labels["prompt"]),
+				F("score", result.Score),
+				F("fingerprint_changed", result.Diff.FingerprintChanged),
+			)
+		}
+		if metrics != nil {
+			metrics.RecordError("drift", err, labels)
+		}
+	}
+	return result
+}
+
+// shortHash abbreviates a request hash for labels and logs
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
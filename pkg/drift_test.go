@@ -0,0 +1,100 @@
+package pkg_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// baseline records a bundle whose output is text
+func baseline(t *testing.T, client *pkg.Client, srv *openroutertest.Server, prompt, text string) *models.ReproBundle {
+	t.Helper()
+	srv.EnqueueChat(openroutertest.TextReply(text))
+	_, bundle, err := client.CreateReproducibleCompletion(context.Background(),
+		models.NewChatRequest("m", models.WithUserMessage(prompt), models.WithReproducibility(1)))
+	require.NoError(t, err)
+	return bundle
+}
+
+type embedderFunc func(ctx context.Context, texts []string) ([][]float64, error)
+
+func (f embedderFunc) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	return f(ctx, texts)
+}
+
+func TestDriftDetectorExact(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	metrics := pkg.NewSimpleMetricsCollector()
+	client := srv.Client(pkg.WithMetrics(metrics))
+	stable := baseline(t, client, srv, "capital of France?", "Paris")
+	shifted := baseline(t, client, srv, "capital of Australia?", "Canberra")
+
+	var alerts []pkg.DriftReport
+	detector, err := pkg.NewDriftDetector(client, pkg.DriftOptions{
+		Baselines: []*models.ReproBundle{stable, shifted},
+		OnDrift:   func(report pkg.DriftReport) { alerts = append(alerts, report) },
+	})
+	require.NoError(t, err)
+
+	srv.EnqueueChat(openroutertest.TextReply("Paris"), openroutertest.TextReply("Sydney"))
+	report := detector.Check(context.Background())
+	require.Len(t, report.Results, 2)
+	assert.False(t, report.Results[0].Drifted)
+	assert.Equal(t, 1.0, report.Results[0].Score)
+	assert.True(t, report.Results[1].Drifted)
+	assert.Equal(t, 1, report.Drifted)
+	require.Len(t, alerts, 1)
+
+	summary := metrics.GetSummary()
+	assert.Equal(t, 1, summary["errors"].(map[string]int)["drift_"+openroutertest.DefaultModel])
+	assert.Equal(t, 0.5, summary["avg_drift_score"].(map[string]float64)[openroutertest.DefaultModel])
+
+	// Failed replays are reported without alerting
+	srv.EnqueueChat(openroutertest.ErrorReply(500, "down"), openroutertest.TextReply("Canberra"))
+	report = detector.Check(context.Background())
+	assert.Equal(t, 1, report.Failed)
+	assert.Zero(t, report.Drifted)
+	assert.Len(t, alerts, 1)
+}
+
+func TestDriftDetectorComparators(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	bundle := baseline(t, client, srv, "greet", "Hello there!")
+
+	// A comparator that tolerates case differences
+	caseless := pkg.DriftComparatorFunc(func(ctx context.Context, baseline, output string) (float64, error) {
+		if strings.EqualFold(baseline, output) {
+			return 1, nil
+		}
+		return 0, nil
+	})
+	detector, err := pkg.NewDriftDetector(client, pkg.DriftOptions{Baselines: []*models.ReproBundle{bundle}, Comparator: caseless})
+	require.NoError(t, err)
+	srv.EnqueueChat(openroutertest.TextReply("HELLO THERE!"))
+	assert.Zero(t, detector.Check(context.Background()).Drifted)
+
+	srv.EnqueueChat(openroutertest.TextReply(`{"score": 0.4, "rationale": "different greeting"}`))
+	score, err := pkg.JudgeComparator(client, "judge").Similarity(context.Background(), "Hello there!", "Go away")
+	require.NoError(t, err)
+	assert.Equal(t, 0.4, score)
+
+	embedder := embedderFunc(func(ctx context.Context, texts []string) ([][]float64, error) {
+		return [][]float64{{1, 0}, {1, 1}}, nil
+	})
+	score, err = pkg.EmbeddingComparator(embedder).Similarity(context.Background(), "a", "b")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.7071, score, 0.001)
+
+	_, err = pkg.NewDriftDetector(client, pkg.DriftOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,28 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// CreateEmbeddings creates embeddings for the request input
+func (c *Client) CreateEmbeddings(ctx context.Context, req models.EmbeddingRequest) (*models.EmbeddingResponse, error) {
+	if req.Input == nil {
+		return nil, fmt.Errorf("input is required")
+	}
+
+	resp, err := c.doRequest(ctx, "POST", "/embeddings", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var embeddingResp models.EmbeddingResponse
+	if err := c.decodeResponse(resp, &embeddingResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &embeddingResp, nil
+}
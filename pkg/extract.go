@@ -0,0 +1,270 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// DefaultConfidenceThreshold is the field confidence below which an extraction needs review
+const DefaultConfidenceThreshold = 0.8
+
+// ExtractDocument is the source an extraction reads from. Any combination of text,
+// images, and PDFs can be given.
+type ExtractDocument struct {
+	Text   string
+	Images []ImageInput
+	PDFs   []PDFInput
+}
+
+// ExtractOptions configures Extract
+type ExtractOptions struct {
+	Model string
+
+	// Instructions are added to the extraction prompt, e.g. "Dates are in DD/MM/YYYY format."
+	Instructions string
+
+	// ConfidenceThreshold marks fields with lower confidence for review.
+	// Defaults to DefaultConfidenceThreshold.
+	ConfidenceThreshold float64
+
+	// Validate checks the extracted record. A validation error marks the extraction
+	// for review rather than failing it. Records implementing Validator are also validated.
+	Validate func(record interface{}) error
+
+	// Request is used as the base request, e.g. to set provider preferences
+	Request *models.ChatCompletionRequest
+
+	// MaxAttempts bounds how often the document is sent when the model's output isn't
+	// valid JSON, e.g. because it was cut off. API errors are not retried. Defaults to 2.
+	MaxAttempts int
+}
+
+// Validator is implemented by extraction targets that validate themselves
+type Validator interface {
+	Validate() error
+}
+
+// Extraction is a typed record extracted from a document
+type Extraction[T any] struct {
+	Data T
+
+	// Confidence is the model's confidence from 0 to 1 for each top-level field, by JSON name
+	Confidence map[string]float64
+
+	// NeedsReview is set when a field is below the confidence threshold or validation failed
+	NeedsReview   bool
+	ReviewReasons []string
+
+	Response *models.ChatCompletionResponse
+}
+
+// LowConfidenceFields returns the fields below threshold, sorted by name
+func (e *Extraction[T]) LowConfidenceFields(threshold float64) []string {
+	var fields []string
+	for field, confidence := range e.Confidence {
+		if confidence < threshold {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Extract pulls a record of type T, such as an invoice, resume, or receipt, out of a
+// document. T must be a struct; its schema is generated from its json and description tags.
+//
+//	invoice, err := pkg.Extract[Invoice](ctx, client, pkg.ExtractDocument{
+//		PDFs: []pkg.PDFInput{{Path: "invoice.pdf"}},
+//	}, pkg.ExtractOptions{Model: "openai/gpt-4o"})
+//	if invoice.NeedsReview {
+//		// route to a human
+//	}
+func Extract[T any](ctx context.Context, client *Client, document ExtractDocument, opts ExtractOptions) (*Extraction[T], error) {
+	var zero T
+	dataSchema, err := GenerateSchema(zero)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate schema: %w", err)
+	}
+	fields := schemaFields(dataSchema)
+
+	schema, err := json.Marshal(extractionSchema(dataSchema, fields))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	message, err := extractionMessage(client, document, opts.Instructions)
+	if err != nil {
+		return nil, err
+	}
+
+	var req models.ChatCompletionRequest
+	if opts.Request != nil {
+		req = *opts.Request
+	}
+	if opts.Model != "" {
+		req.Model = opts.Model
+	}
+	req.Messages = append(append([]models.Message(nil), req.Messages...), message)
+	req.ResponseFormat = &models.ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &models.JSONSchema{
+			Name:   "extraction",
+			Strict: true,
+			Schema: schema,
+		},
+	}
+	for _, pdf := range document.PDFs {
+		if pdf.Engine != "" {
+			req.Plugins = append(req.Plugins, *models.NewPDFPlugin(pdf.Engine))
+			break
+		}
+	}
+
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 2
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := client.CreateChatCompletion(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Data       T                  `json:"data"`
+			Confidence map[string]float64 `json:"confidence"`
+		}
+		if err := ParseStructuredResponse(resp, &result); err != nil {
+			if attempt == opts.MaxAttempts {
+				return nil, fmt.Errorf("invalid extraction after %d attempts: %w", attempt, err)
+			}
+			continue
+		}
+
+		extraction := &Extraction[T]{
+			Data:       result.Data,
+			Confidence: result.Confidence,
+			Response:   resp,
+		}
+		extraction.review(fields, opts)
+		return extraction, nil
+	}
+}
+
+// review flags low-confidence fields and validation failures
+func (e *Extraction[T]) review(fields []string, opts ExtractOptions) {
+	threshold := opts.ConfidenceThreshold
+	if threshold <= 0 {
+		threshold = DefaultConfidenceThreshold
+	}
+
+	for _, field := range fields {
+		confidence, ok := e.Confidence[field]
+		switch {
+		case !ok:
+			e.ReviewReasons = append(e.ReviewReasons, fmt.Sprintf("no confidence reported for %s", field))
+		case confidence < threshold:
+			e.ReviewReasons = append(e.ReviewReasons, fmt.Sprintf("low confidence for %s: %.2f", field, confidence))
+		}
+	}
+
+	var record interface{} = e.Data
+	if _, ok := record.(Validator); !ok {
+		record = &e.Data
+	}
+	if v, ok := record.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			e.ReviewReasons = append(e.ReviewReasons, fmt.Sprintf("validation failed: %v", err))
+		}
+	}
+	if opts.Validate != nil {
+		if err := opts.Validate(e.Data); err != nil {
+			e.ReviewReasons = append(e.ReviewReasons, fmt.Sprintf("validation failed: %v", err))
+		}
+	}
+
+	e.NeedsReview = len(e.ReviewReasons) > 0
+}
+
+// extractionSchema wraps the record schema with a confidence score per field
+func extractionSchema(dataSchema map[string]interface{}, fields []string) map[string]interface{} {
+	confidence := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		confidence[field] = map[string]interface{}{
+			"type":        "number",
+			"description": "Confidence from 0 to 1 that " + field + " was read correctly",
+		}
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"data": dataSchema,
+			"confidence": map[string]interface{}{
+				"type":                 "object",
+				"properties":           confidence,
+				"required":             fields,
+				"additionalProperties": false,
+			},
+		},
+		"required":             []string{"data", "confidence"},
+		"additionalProperties": false,
+	}
+}
+
+// schemaFields returns the sorted property names of an object schema
+func schemaFields(schema map[string]interface{}) []string {
+	properties, _ := schema["properties"].(map[string]interface{})
+	fields := make([]string, 0, len(properties))
+	for field := range properties {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// extractionMessage builds the user message holding the instructions and document
+func extractionMessage(client *Client, document ExtractDocument, instructions string) (models.Message, error) {
+	var prompt strings.Builder
+	prompt.WriteString("Extract the requested record from the document. ")
+	prompt.WriteString("Use null or empty values for fields the document does not contain; do not guess. ")
+	prompt.WriteString("For each field, report your confidence from 0 to 1 that it was read correctly.")
+	if instructions != "" {
+		prompt.WriteString("\n\n")
+		prompt.WriteString(instructions)
+	}
+	if document.Text != "" {
+		prompt.WriteString("\n\nDocument:\n")
+		prompt.WriteString(document.Text)
+	}
+
+	if len(document.Images) == 0 && len(document.PDFs) == 0 {
+		if document.Text == "" {
+			return models.Message{}, fmt.Errorf("document is empty")
+		}
+		return models.NewTextMessage(models.RoleUser, prompt.String()), nil
+	}
+
+	helper := NewMultiModalHelper(client)
+	contents := []models.Content{models.Text(prompt.String())}
+	for _, image := range document.Images {
+		content, err := helper.prepareImageContent(image)
+		if err != nil {
+			return models.Message{}, err
+		}
+		contents = append(contents, content)
+	}
+	for _, pdf := range document.PDFs {
+		content, err := helper.preparePDFContent(pdf)
+		if err != nil {
+			return models.Message{}, err
+		}
+		contents = append(contents, content)
+	}
+	return models.NewMultiContentMessage(models.RoleUser, contents...)
+}
@@ -0,0 +1,124 @@
+package pkg_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+type receipt struct {
+	Merchant string  `json:"merchant" description:"Store name"`
+	Total    float64 `json:"total" description:"Amount paid"`
+}
+
+func (r receipt) Validate() error {
+	if r.Total < 0 {
+		return errors.New("total is negative")
+	}
+	return nil
+}
+
+var receiptDocument = pkg.ExtractDocument{Text: "CORNER SHOP\nTOTAL 12.50"}
+
+func TestExtract(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply(`{"data":{"merchant":"Corner Shop","total":12.5},"confidence":{"merchant":0.95,"total":0.99}}`))
+
+	base := models.NewChatRequest("", models.WithSystemMessage("You read receipts."))
+	base.Provider = &models.ProviderPreferences{Order: []string{"openai"}}
+	extraction, err := pkg.Extract[receipt](context.Background(), srv.Client(), receiptDocument, pkg.ExtractOptions{
+		Model:        "openai/gpt-4o",
+		Instructions: "Totals are in EUR.",
+		Request:      &base,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, receipt{Merchant: "Corner Shop", Total: 12.5}, extraction.Data)
+	assert.Equal(t, 0.99, extraction.Confidence["total"])
+	assert.False(t, extraction.NeedsReview)
+	assert.Empty(t, extraction.ReviewReasons)
+
+	// The request asks for the record and a confidence per field, on top of the base request
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o", sent.Model)
+	assert.Equal(t, []string{"openai"}, sent.Provider.Order)
+	require.Len(t, sent.Messages, 2)
+	prompt, _ := sent.Messages[1].GetTextContent()
+	assert.Contains(t, prompt, "Totals are in EUR.")
+	assert.Contains(t, prompt, "TOTAL 12.50")
+	require.NotNil(t, sent.ResponseFormat)
+	assert.Equal(t, "json_schema", sent.ResponseFormat.Type)
+	assert.True(t, sent.ResponseFormat.JSONSchema.Strict)
+	var schema struct {
+		Properties struct {
+			Confidence struct {
+				Required []string `json:"required"`
+			} `json:"confidence"`
+		} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(sent.ResponseFormat.JSONSchema.Schema, &schema))
+	assert.Equal(t, []string{"merchant", "total"}, schema.Properties.Confidence.Required)
+	assert.Len(t, base.Messages, 1, "the base request is not modified")
+}
+
+func TestExtractNeedsReview(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply(`{"data":{"merchant":"Corner Shop","total":-12.5},"confidence":{"total":0.4}}`))
+
+	extraction, err := pkg.Extract[receipt](context.Background(), srv.Client(), receiptDocument, pkg.ExtractOptions{
+		Model: "m",
+		Validate: func(record interface{}) error {
+			return errors.New("merchant is not on the allow list")
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, extraction.NeedsReview)
+	assert.Equal(t, []string{
+		"no confidence reported for merchant",
+		"low confidence for total: 0.40",
+		"validation failed: total is negative",
+		"validation failed: merchant is not on the allow list",
+	}, extraction.ReviewReasons)
+	assert.Equal(t, []string{"total"}, extraction.LowConfidenceFields(pkg.DefaultConfidenceThreshold))
+}
+
+func TestExtractRetriesMalformedJSON(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	valid := `{"data":{"merchant":"Corner Shop","total":12.5},"confidence":{"merchant":0.9,"total":0.9}}`
+	srv.EnqueueChat(openroutertest.TextReply(`{"data":{"merchant":"Corner`), openroutertest.TextReply(valid))
+
+	extraction, err := pkg.Extract[receipt](context.Background(), srv.Client(), receiptDocument, pkg.ExtractOptions{Model: "m"})
+	require.NoError(t, err)
+	assert.Equal(t, "Corner Shop", extraction.Data.Merchant)
+	assert.Len(t, srv.Requests(), 2)
+
+	// Output that stays malformed fails after MaxAttempts
+	srv.Reset()
+	srv.EnqueueChat(openroutertest.TextReply("not json"), openroutertest.TextReply("still not json"), openroutertest.TextReply(valid))
+	_, err = pkg.Extract[receipt](context.Background(), srv.Client(), receiptDocument, pkg.ExtractOptions{Model: "m"})
+	assert.ErrorContains(t, err, "invalid extraction after 2 attempts")
+	assert.Len(t, srv.Requests(), 2)
+
+	// API errors are returned without retrying
+	srv.Reset()
+	srv.EnqueueChat(openroutertest.ErrorReply(400, "bad schema"))
+	_, err = pkg.Extract[receipt](context.Background(), srv.Client(), receiptDocument, pkg.ExtractOptions{Model: "m", MaxAttempts: 3})
+	assert.ErrorContains(t, err, "bad schema")
+	assert.Len(t, srv.Requests(), 1)
+}
+
+func TestExtractEmptyDocument(t *testing.T) {
+	_, err := pkg.Extract[receipt](context.Background(), pkg.NewClient("key"), pkg.ExtractDocument{}, pkg.ExtractOptions{Model: "m"})
+	assert.ErrorContains(t, err, "document is empty")
+}
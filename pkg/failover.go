@@ -0,0 +1,292 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHealthCheckInterval is how often FailoverTransport probes a failed base URL
+	DefaultHealthCheckInterval = 30 * time.Second
+
+	// DefaultHealthCheckPath is the endpoint FailoverTransport probes, relative to a base URL
+	DefaultHealthCheckPath = "/models"
+)
+
+// FailoverConfig configures a FailoverTransport
+type FailoverConfig struct {
+	// Secondary lists the base URLs used, in order, while the ones before them are down,
+	// e.g. DefaultBaseURL behind a corporate gateway
+	Secondary []string
+
+	// HealthCheckInterval is how often a failed base URL is probed. Defaults to
+	// DefaultHealthCheckInterval.
+	HealthCheckInterval time.Duration
+
+	// HealthCheckPath is requested with GET, without the API key, to probe a base URL,
+	// which is healthy again when it answers with a 2xx status. Defaults to
+	// DefaultHealthCheckPath, which OpenRouter serves without authentication.
+	HealthCheckPath string
+
+	// FailoverStatuses are the response statuses that mark a base URL as down, in
+	// addition to connection errors. Defaults to 502, 503 and 504, which gateways return
+	// when they can't reach their backend.
+	FailoverStatuses []int
+}
+
+// BaseURLStatus is the health of one base URL of a FailoverTransport
+type BaseURLStatus struct {
+	URL     string
+	Healthy bool
+
+	// LastError is the failure that marked the URL as down
+	LastError error
+}
+
+// baseURLState is the health of one base URL
+type baseURLState struct {
+	url       string
+	healthy   bool
+	lastError error
+	nextProbe time.Time
+	probing   bool
+}
+
+// FailoverTransport sends requests to the first healthy of several base URLs, such as a
+// corporate gateway and OpenRouter itself. Requests are addressed to the primary base URL
+// and rewritten to the active one. A base URL that fails with a connection error or a
+// failover status is marked down, the request is retried on the next one, and the failed
+// URL is probed in the background until it recovers. It is safe for concurrent use.
+type FailoverTransport struct {
+	base     http.RoundTripper
+	config   FailoverConfig
+	statuses map[int]bool
+	now      func() time.Time
+
+	// fromOption is set when WithFailover installed the transport, so it belongs to the
+	// client and may be copied by later options
+	fromOption bool
+
+	mu   sync.Mutex
+	urls []*baseURLState
+}
+
+// NewFailoverTransport creates a transport sending requests for primary to the healthy
+// base URLs in order. base defaults to http.DefaultTransport.
+func NewFailoverTransport(base http.RoundTripper, primary string, config FailoverConfig) *FailoverTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if config.HealthCheckInterval <= 0 {
+		config.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+	if config.HealthCheckPath == "" {
+		config.HealthCheckPath = DefaultHealthCheckPath
+	}
+	if config.FailoverStatuses == nil {
+		config.FailoverStatuses = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	}
+
+	t := &FailoverTransport{
+		base:     base,
+		config:   config,
+		statuses: make(map[int]bool, len(config.FailoverStatuses)),
+		now:      time.Now,
+	}
+	for _, status := range config.FailoverStatuses {
+		t.statuses[status] = true
+	}
+	for _, u := range append([]string{primary}, config.Secondary...) {
+		t.urls = append(t.urls, &baseURLState{url: strings.TrimSuffix(u, "/"), healthy: true})
+	}
+	return t
+}
+
+// WithFailover sends requests to secondary base URLs while the client's base URL is down.
+// It wraps the HTTP client's transport, so it must come after WithBaseURL and
+// WithHTTPClient. Use NewFailoverTransport directly to inspect the URLs' health.
+func WithFailover(config FailoverConfig) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		transport := NewFailoverTransport(httpClient.Transport, c.baseURL, config)
+		transport.now = func() time.Time { return c.clock.Now() }
+		transport.fromOption = true
+		httpClient.Transport = transport
+		c.httpClient = &httpClient
+	}
+}
+
+// unwrap returns nil for a FailoverTransport passed in with WithHTTPClient, so the caller's
+// handle on its health stays valid
+func (t *FailoverTransport) unwrap() http.RoundTripper {
+	if !t.fromOption {
+		return nil
+	}
+	return t.base
+}
+
+func (t *FailoverTransport) withBase(base http.RoundTripper) http.RoundTripper {
+	clone := NewFailoverTransport(base, t.urls[0].url, t.config)
+	clone.now = t.now
+	clone.fromOption = true
+	return clone
+}
+
+// Status returns the health of every base URL, primary first
+func (t *FailoverTransport) Status() []BaseURLStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	statuses := make([]BaseURLStatus, len(t.urls))
+	for i, u := range t.urls {
+		statuses[i] = BaseURLStatus{URL: u.url, Healthy: u.healthy, LastError: u.lastError}
+	}
+	return statuses
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *FailoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path, ok := t.relativePath(req.URL.String())
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	var lastErr error
+	tried := 0
+	for _, u := range t.candidates() {
+		if tried > 0 && req.Body != nil && req.GetBody == nil {
+			break // the body can't be sent again
+		}
+		attempt, err := t.rewrite(req, u.url+path, tried > 0)
+		if err != nil {
+			return nil, err
+		}
+		tried++
+
+		resp, err := t.base.RoundTrip(attempt)
+		if req.Context().Err() != nil {
+			return resp, err
+		}
+		switch {
+		case err != nil:
+			lastErr = err
+		case t.statuses[resp.StatusCode]:
+			lastErr = fmt.Errorf("%s answered %s", u.url, resp.Status)
+			if tried < len(t.urls) {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+		default:
+			t.markHealthy(u)
+			return resp, nil
+		}
+
+		t.markDown(u, lastErr)
+		if err == nil && tried == len(t.urls) {
+			return resp, nil // nothing left to try; return the failover status itself
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no base URL available")
+	}
+	return nil, fmt.Errorf("all base URLs failed: %w", lastErr)
+}
+
+// relativePath returns the part of rawURL after the longest base URL it is addressed to
+func (t *FailoverTransport) relativePath(rawURL string) (string, bool) {
+	base := ""
+	for _, u := range t.urls {
+		if strings.HasPrefix(rawURL, u.url) && len(u.url) > len(base) {
+			base = u.url
+		}
+	}
+	return rawURL[len(base):], base != ""
+}
+
+// candidates returns the healthy base URLs in order, followed by the unhealthy ones as a
+// last resort, and starts probes of unhealthy URLs that are due
+func (t *FailoverTransport) candidates() []*baseURLState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+
+	var healthy, unhealthy []*baseURLState
+	for _, u := range t.urls {
+		if u.healthy {
+			healthy = append(healthy, u)
+			continue
+		}
+		unhealthy = append(unhealthy, u)
+		if !u.probing && !now.Before(u.nextProbe) {
+			u.probing = true
+			go t.probe(u)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// rewrite returns a copy of req addressed to target, with a fresh body for retries
+func (t *FailoverTransport) rewrite(req *http.Request, target string, retry bool) (*http.Request, error) {
+	attempt := req.Clone(req.Context())
+	u, err := req.URL.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	attempt.URL = u
+	attempt.Host = ""
+	if retry && req.GetBody != nil {
+		if attempt.Body, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+	}
+	return attempt, nil
+}
+
+// probe checks whether a failed base URL has recovered
+func (t *FailoverTransport) probe(u *baseURLState) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u.url+t.config.HealthCheckPath, nil)
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		// A gateway that rejects every request, e.g. with 401, is not healthy either
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			err = fmt.Errorf("health check answered %s", resp.Status)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u.probing = false
+	if err != nil {
+		u.lastError = err
+		u.nextProbe = t.now().Add(t.config.HealthCheckInterval)
+		return
+	}
+	u.healthy = true
+	u.lastError = nil
+}
+
+func (t *FailoverTransport) markDown(u *baseURLState, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if u.healthy {
+		u.healthy = false
+		u.nextProbe = t.now().Add(t.config.HealthCheckInterval)
+	}
+	u.lastError = err
+}
+
+func (t *FailoverTransport) markHealthy(u *baseURLState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u.healthy = true
+	u.lastError = nil
+}
@@ -0,0 +1,121 @@
+package pkg_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestFailoverTransport(t *testing.T) {
+	secondary := openroutertest.NewServer()
+	defer secondary.Close()
+
+	// The gateway is down until it is switched back on
+	gateway := openroutertest.NewServer()
+	defer gateway.Close()
+	gateway.SetOutage(&openroutertest.Error{Code: http.StatusBadGateway, Message: "upstream unavailable"})
+	gateway.SetChatHandler(func(req models.ChatCompletionRequest) openroutertest.Reply {
+		resp := openroutertest.NewTextResponse("from gateway")
+		resp.Model = "gateway"
+		return openroutertest.Reply{Response: resp}
+	})
+
+	transport := pkg.NewFailoverTransport(nil, gateway.URL, pkg.FailoverConfig{
+		Secondary:           []string{secondary.URL},
+		HealthCheckInterval: time.Millisecond,
+	})
+	client := pkg.NewClient("key", pkg.WithBaseURL(gateway.URL), pkg.WithHTTPClient(&http.Client{Transport: transport}))
+	ctx := context.Background()
+	req := models.NewChatRequest("m", models.WithUserMessage("hi"))
+
+	// The request fails over to the secondary with its body intact
+	secondary.EnqueueChat(openroutertest.TextReply("from secondary"))
+	resp, err := client.CreateChatCompletion(ctx, req)
+	require.NoError(t, err)
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "from secondary", text)
+	sent, err := secondary.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "m", sent.Model)
+
+	status := transport.Status()
+	assert.False(t, status[0].Healthy)
+	assert.ErrorContains(t, status[0].LastError, "502")
+	assert.True(t, status[1].Healthy)
+
+	// Once the gateway recovers, a probe returns traffic to it
+	gateway.SetOutage(nil)
+	require.Eventually(t, func() bool {
+		_, err := client.CreateChatCompletion(ctx, req)
+		return err == nil && transport.Status()[0].Healthy
+	}, time.Second, 5*time.Millisecond)
+
+	resp, err = client.CreateChatCompletion(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "gateway", resp.Model)
+}
+
+func TestFailoverProbeRequiresSuccess(t *testing.T) {
+	secondary := openroutertest.NewServer()
+	defer secondary.Close()
+	secondary.SetChatHandler(func(req models.ChatCompletionRequest) openroutertest.Reply {
+		return openroutertest.TextReply("from secondary")
+	})
+
+	gateway := openroutertest.NewServer()
+	defer gateway.Close()
+	gateway.SetOutage(&openroutertest.Error{Code: http.StatusBadGateway, Message: "upstream unavailable"})
+
+	transport := pkg.NewFailoverTransport(nil, gateway.URL, pkg.FailoverConfig{
+		Secondary:           []string{secondary.URL},
+		HealthCheckInterval: time.Millisecond,
+	})
+	client := pkg.NewClient("key", pkg.WithBaseURL(gateway.URL), pkg.WithHTTPClient(&http.Client{Transport: transport}))
+	ctx := context.Background()
+	req := models.NewChatRequest("m", models.WithUserMessage("hi"))
+	_, err := client.CreateChatCompletion(ctx, req)
+	require.NoError(t, err)
+
+	// The gateway comes back rejecting every request, which is no recovery
+	gateway.SetOutage(&openroutertest.Error{Code: http.StatusUnauthorized, Message: "invalid credentials"})
+	probes := func() int {
+		n := 0
+		for _, request := range gateway.Requests() {
+			if request.Method == http.MethodGet && request.Path == pkg.DefaultHealthCheckPath {
+				n++
+			}
+		}
+		return n
+	}
+	require.Eventually(t, func() bool {
+		_, err := client.CreateChatCompletion(ctx, req)
+		return err == nil && probes() >= 3
+	}, time.Second, 5*time.Millisecond)
+
+	status := transport.Status()
+	assert.False(t, status[0].Healthy)
+	assert.ErrorContains(t, status[0].LastError, "401")
+}
+
+func TestWithFailoverConnectionError(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	client := pkg.NewClient("key", pkg.WithBaseURL(down.URL), pkg.WithFailover(pkg.FailoverConfig{
+		Secondary: []string{srv.URL},
+	}))
+	_, err := client.CreateChatCompletion(context.Background(), models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	assert.Len(t, srv.Requests(), 1)
+}
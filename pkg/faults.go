@@ -0,0 +1,338 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/errors"
+)
+
+// Operation names used to configure faults per endpoint
+const (
+	FaultOpChatCompletion       = opChatCompletion
+	FaultOpChatCompletionStream = opChatCompletionStream
+	FaultOpCompletion           = opCompletion
+	FaultOpCompletionStream     = opCompletionStream
+	FaultOpModels               = opModels
+	FaultOpGeneration           = opGeneration
+
+	// FaultOpAPI covers every other endpoint, e.g. key management and credits
+	FaultOpAPI = opAPI
+)
+
+// ErrInjectedFault is wrapped by transport errors produced by fault injection
+var ErrInjectedFault = stderrors.New("injected fault")
+
+// Fault describes the faults injected into a single operation. Rates are probabilities
+// between 0 and 1.
+type Fault struct {
+	// ErrorRate is the probability of answering with an API error instead of calling the API
+	ErrorRate float64
+
+	// ErrorCodes are the API error codes to choose from. Defaults to 503.
+	ErrorCodes []errors.ErrorCode
+
+	// TransportErrorRate is the probability of failing as if the connection was reset
+	TransportErrorRate float64
+
+	// Latency is added before every request, plus a random duration up to LatencyJitter
+	Latency       time.Duration
+	LatencyJitter time.Duration
+
+	// TruncateRate is the probability of a stream ending abruptly after TruncateAfter events
+	TruncateRate  float64
+	TruncateAfter int
+
+	// MalformedRate is the probability of each stream chunk being replaced with invalid JSON
+	MalformedRate float64
+}
+
+// FaultConfig configures fault injection
+type FaultConfig struct {
+	// Default applies to operations without an entry in Operations
+	Default Fault
+
+	// Operations overrides Default per operation, keyed by the FaultOp constants
+	Operations map[string]Fault
+
+	// Seed makes the injected faults reproducible. Zero uses a time-based seed.
+	Seed int64
+}
+
+// FaultStats counts the faults injected so far
+type FaultStats struct {
+	Requests        int
+	Errors          int
+	TransportErrors int
+	Truncated       int
+	Malformed       int
+}
+
+// FaultInjectingClient wraps a client with configurable faults for chaos testing,
+// e.g. to validate retry and circuit breaker settings before production. It is safe for
+// concurrent use.
+type FaultInjectingClient struct {
+	*Client
+	injector *faultInjector
+}
+
+// NewFaultInjectingClient creates a client that injects the configured faults into its requests
+func NewFaultInjectingClient(apiKey string, config FaultConfig, opts ...Option) *FaultInjectingClient {
+	injector := newFaultInjector(config)
+	opts = append(opts, withFaultInjector(injector))
+
+	return &FaultInjectingClient{
+		Client:   NewClient(apiKey, opts...),
+		injector: injector,
+	}
+}
+
+// WithFaultInjection injects the configured faults into the requests of any client,
+// such as the one embedded in a RetryClient. It wraps the HTTP client's transport,
+// so it must come after WithHTTPClient.
+func WithFaultInjection(config FaultConfig) Option {
+	return withFaultInjector(newFaultInjector(config))
+}
+
+func withFaultInjector(injector *faultInjector) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		base := httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		httpClient.Transport = &faultTransport{base: base, injector: injector}
+		c.httpClient = &httpClient
+	}
+}
+
+// SetFaults replaces the fault configuration
+func (f *FaultInjectingClient) SetFaults(config FaultConfig) {
+	f.injector.configure(config)
+}
+
+// Stats returns the number of faults injected so far
+func (f *FaultInjectingClient) Stats() FaultStats {
+	f.injector.mu.Lock()
+	defer f.injector.mu.Unlock()
+	return f.injector.stats
+}
+
+// faultInjector holds the configuration and random source shared by all requests
+type faultInjector struct {
+	mu     sync.Mutex
+	config FaultConfig
+	rng    *rand.Rand
+	stats  FaultStats
+}
+
+func newFaultInjector(config FaultConfig) *faultInjector {
+	injector := &faultInjector{}
+	injector.configure(config)
+	return injector
+}
+
+func (i *faultInjector) configure(config FaultConfig) {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.config = config
+	i.rng = rand.New(rand.NewSource(seed))
+}
+
+// fault returns the fault configured for an operation
+func (i *faultInjector) fault(operation string) Fault {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if fault, ok := i.config.Operations[operation]; ok {
+		return fault
+	}
+	return i.config.Default
+}
+
+// roll reports whether an event with the given probability happens
+func (i *faultInjector) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.rng.Float64() < rate
+}
+
+// jitter returns a random duration up to max
+func (i *faultInjector) jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return time.Duration(i.rng.Int63n(int64(max)))
+}
+
+// errorCode picks one of the fault's error codes
+func (i *faultInjector) errorCode(fault Fault) errors.ErrorCode {
+	if len(fault.ErrorCodes) == 0 {
+		return errors.ErrorCodeServiceUnavailable
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return fault.ErrorCodes[i.rng.Intn(len(fault.ErrorCodes))]
+}
+
+// count updates the stats
+func (i *faultInjector) count(update func(*FaultStats)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	update(&i.stats)
+}
+
+// faultTransport injects faults into requests before passing them to the base transport
+type faultTransport struct {
+	base     http.RoundTripper
+	injector *faultInjector
+}
+
+func (t *faultTransport) unwrap() http.RoundTripper { return t.base }
+
+func (t *faultTransport) withBase(base http.RoundTripper) http.RoundTripper {
+	return &faultTransport{base: base, injector: t.injector}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	operation := requestOperation(req)
+	fault := t.injector.fault(operation)
+	t.injector.count(func(s *FaultStats) { s.Requests++ })
+
+	if delay := fault.Latency + t.injector.jitter(fault.LatencyJitter); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	if t.injector.roll(fault.TransportErrorRate) {
+		t.injector.count(func(s *FaultStats) { s.TransportErrors++ })
+		return nil, fmt.Errorf("%w: connection reset during %s", ErrInjectedFault, operation)
+	}
+
+	if t.injector.roll(fault.ErrorRate) {
+		t.injector.count(func(s *FaultStats) { s.Errors++ })
+		return faultErrorResponse(req, t.injector.errorCode(fault), operation), nil
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") &&
+		(fault.TruncateRate > 0 || fault.MalformedRate > 0) {
+		body := &faultStreamBody{
+			ReadCloser: resp.Body,
+			reader:     bufio.NewReader(resp.Body),
+			fault:      fault,
+			injector:   t.injector,
+		}
+		if t.injector.roll(fault.TruncateRate) {
+			body.truncateAfter = fault.TruncateAfter
+			t.injector.count(func(s *FaultStats) { s.Truncated++ })
+		} else {
+			body.truncateAfter = -1
+		}
+		resp.Body = body
+	}
+
+	return resp, nil
+}
+
+// faultErrorResponse builds an OpenRouter error response
+func faultErrorResponse(req *http.Request, code errors.ErrorCode, operation string) *http.Response {
+	var errResp errors.ErrorResponse
+	errResp.Error.Code = int(code)
+	errResp.Error.Message = fmt.Sprintf("injected fault during %s", operation)
+	body, _ := json.Marshal(errResp)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(int(code))),
+		StatusCode:    int(code),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// faultStreamBody corrupts or truncates a server-sent event stream line by line
+type faultStreamBody struct {
+	io.ReadCloser
+	reader   *bufio.Reader
+	fault    Fault
+	injector *faultInjector
+
+	// truncateAfter is the number of events passed through before the stream is cut, -1 for never
+	truncateAfter int
+	events        int
+	pending       []byte
+}
+
+func (b *faultStreamBody) Read(p []byte) (int, error) {
+	for len(b.pending) == 0 {
+		line, err := b.reader.ReadBytes('\n')
+		if len(line) > 0 {
+			b.pending = b.corrupt(line)
+		}
+		if b.truncateAfter >= 0 && b.events > b.truncateAfter {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			if len(b.pending) == 0 {
+				return 0, err
+			}
+			break
+		}
+	}
+
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+// corrupt counts data lines and replaces some of them with malformed JSON
+func (b *faultStreamBody) corrupt(line []byte) []byte {
+	if !bytes.HasPrefix(line, []byte("data:")) || bytes.Contains(line, doneMarker) {
+		return line
+	}
+
+	b.events++
+	if b.truncateAfter >= 0 && b.events > b.truncateAfter {
+		return nil
+	}
+	if b.injector.roll(b.fault.MalformedRate) {
+		b.injector.count(func(s *FaultStats) { s.Malformed++ })
+		return []byte("data: {\"id\":\"malformed\",\"choices\":[{\n")
+	}
+	return line
+}
+
+var doneMarker = []byte("[DONE]")
@@ -0,0 +1,147 @@
+package pkg_test
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/errors"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+func TestFaultInjectionIsSeeded(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	const seed, rate, calls = 42, 0.5, 20
+
+	// With only an error rate configured, call n fails when the seed's nth roll is below it
+	rng := rand.New(rand.NewSource(seed))
+	expected := make([]bool, calls)
+	for i := range expected {
+		expected[i] = rng.Float64() < rate
+	}
+
+	client := pkg.NewFaultInjectingClient("sk-or-test", pkg.FaultConfig{
+		Default: pkg.Fault{ErrorRate: rate},
+		Seed:    seed,
+	}, pkg.WithBaseURL(srv.URL))
+	failed := make([]bool, calls)
+	for i := range failed {
+		_, err := client.CreateChatCompletion(context.Background(), hiRequest)
+		if failed[i] = err != nil; failed[i] {
+			var apiErr *errors.APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, errors.ErrorCodeServiceUnavailable, apiErr.Code)
+		}
+	}
+	assert.Equal(t, expected, failed)
+
+	stats := client.Stats()
+	assert.Equal(t, calls, stats.Requests)
+	assert.Equal(t, calls-len(srv.Requests()), stats.Errors, "failed calls never reach the API")
+
+	// Resetting the seed replays the same faults
+	client.SetFaults(pkg.FaultConfig{Default: pkg.Fault{ErrorRate: rate}, Seed: seed})
+	for i := range expected {
+		_, err := client.CreateChatCompletion(context.Background(), hiRequest)
+		assert.Equal(t, expected[i], err != nil, "call %d", i)
+	}
+}
+
+func TestFaultInjectionPerOperation(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := pkg.NewFaultInjectingClient("sk-or-test", pkg.FaultConfig{
+		Operations: map[string]pkg.Fault{
+			pkg.FaultOpModels:         {TransportErrorRate: 1},
+			pkg.FaultOpChatCompletion: {ErrorRate: 0},
+		},
+		Default: pkg.Fault{ErrorRate: 1, ErrorCodes: []errors.ErrorCode{errors.ErrorCodeRateLimited}},
+		Seed:    1,
+	}, pkg.WithBaseURL(srv.URL))
+	ctx := context.Background()
+
+	_, err := client.ListModels(ctx, nil)
+	assert.ErrorIs(t, err, pkg.ErrInjectedFault)
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	assert.NoError(t, err)
+	_, err = client.GetCredits(ctx)
+	var apiErr *errors.APIError
+	require.ErrorAs(t, err, &apiErr, "other operations use the default")
+	assert.Equal(t, errors.ErrorCodeRateLimited, apiErr.Code)
+
+	assert.Equal(t, pkg.FaultStats{Requests: 3, Errors: 1, TransportErrors: 1}, client.Stats())
+}
+
+func TestFaultInjectionLatency(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := pkg.NewFaultInjectingClient("sk-or-test", pkg.FaultConfig{
+		Default: pkg.Fault{Latency: 50 * time.Millisecond},
+		Seed:    1,
+	}, pkg.WithBaseURL(srv.URL))
+
+	start := time.Now()
+	_, err := client.CreateChatCompletion(context.Background(), hiRequest)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// The delay respects cancellation, and the request is never sent
+	client.SetFaults(pkg.FaultConfig{Default: pkg.Fault{Latency: time.Hour}, Seed: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, srv.Requests(), 1)
+}
+
+func TestFaultInjectionTruncatesStreams(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := pkg.NewFaultInjectingClient("sk-or-test", pkg.FaultConfig{
+		Operations: map[string]pkg.Fault{
+			pkg.FaultOpChatCompletionStream: {TruncateRate: 1, TruncateAfter: 2},
+		},
+		Seed: 1,
+	}, pkg.WithBaseURL(srv.URL))
+	srv.EnqueueChat(openroutertest.TextReply("one two three four"))
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), hiRequest)
+	require.NoError(t, err)
+	defer stream.Close()
+	stream.KeepPartial()
+	for i := 0; i < 2; i++ {
+		_, err := stream.Read()
+		require.NoError(t, err, "events before the cut arrive intact")
+	}
+	_, err = stream.Read()
+	var interrupted *streaming.StreamInterruptedError
+	require.ErrorAs(t, err, &interrupted)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	text, _ := interrupted.Partial.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "one two ", text)
+	assert.Equal(t, 1, client.Stats().Truncated)
+}
+
+func TestFaultInjectionMalformsChunks(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := pkg.NewFaultInjectingClient("sk-or-test", pkg.FaultConfig{
+		Default: pkg.Fault{MalformedRate: 1},
+		Seed:    1,
+	}, pkg.WithBaseURL(srv.URL))
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), hiRequest)
+	require.NoError(t, err)
+	defer stream.Close()
+	_, err = stream.Read()
+	assert.Error(t, err)
+	assert.Equal(t, 1, client.Stats().Malformed)
+}
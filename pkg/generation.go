@@ -2,7 +2,6 @@ package pkg
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
 
@@ -26,7 +25,7 @@ func (c *Client) GetGeneration(ctx context.Context, generationID string) (*model
 	defer resp.Body.Close()
 
 	var generationResp models.GenerationResponse
-	if err := json.NewDecoder(resp.Body).Decode(&generationResp); err != nil {
+	if err := c.decodeResponse(resp, &generationResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// IdempotencyKeyHeader is the header used to send idempotency keys
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKeyContextKey struct{}
+
+// NewIdempotencyKey generates a new random idempotency key
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	// Format as a version 4 UUID
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf)
+}
+
+// WithIdempotencyKey returns a context that sends the given idempotency key with requests.
+// Reuse the same context when retrying a logical request manually so the provider can
+// deduplicate it.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key stored in the context, if any
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key, ok && key != ""
+}
+
+// ensureIdempotencyKey returns a context carrying an idempotency key, generating one if needed
+func ensureIdempotencyKey(ctx context.Context) context.Context {
+	if _, ok := IdempotencyKeyFromContext(ctx); ok {
+		return ctx
+	}
+	return WithIdempotencyKey(ctx, NewIdempotencyKey())
+}
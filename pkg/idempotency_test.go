@@ -0,0 +1,91 @@
+package pkg_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// idempotencyKeys returns the Idempotency-Key header of each recorded request
+func idempotencyKeys(srv *openroutertest.Server) []string {
+	var keys []string
+	for _, req := range srv.Requests() {
+		keys = append(keys, req.Header.Get(pkg.IdempotencyKeyHeader))
+	}
+	return keys
+}
+
+func TestIdempotencyKeyHeader(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	// Each POST gets its own generated key
+	_, err := client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+	keys := idempotencyKeys(srv)
+	require.Len(t, keys, 2)
+	assert.Len(t, keys[0], 36)
+	assert.NotEmpty(t, keys[1])
+	assert.NotEqual(t, keys[0], keys[1])
+
+	// A key on the context is sent as is
+	_, err = client.CreateChatCompletion(pkg.WithIdempotencyKey(ctx, "job-42"), hiRequest)
+	require.NoError(t, err)
+	assert.Equal(t, "job-42", idempotencyKeys(srv)[2])
+
+	// GETs are idempotent already
+	_, err = client.ListModels(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, idempotencyKeys(srv)[3])
+}
+
+func TestRetryClientReusesIdempotencyKey(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.ErrorReply(503, "no available provider"), openroutertest.TextReply("ok"))
+
+	config := pkg.DefaultRetryConfig()
+	config.InitialDelay = time.Millisecond
+	client := pkg.NewRetryClient("sk-or-test", config, pkg.WithBaseURL(srv.URL))
+
+	_, err := client.CreateChatCompletion(context.Background(), hiRequest)
+	require.NoError(t, err)
+	keys := idempotencyKeys(srv)
+	require.Len(t, keys, 2)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1], "the retry reuses the first attempt's key")
+
+	// A caller's key is kept across retries
+	srv.EnqueueChat(openroutertest.ErrorReply(503, "no available provider"), openroutertest.TextReply("ok"))
+	_, err = client.CreateChatCompletion(pkg.WithIdempotencyKey(context.Background(), "job-42"), hiRequest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job-42", "job-42"}, idempotencyKeys(srv)[2:])
+}
+
+func TestMultiKeyClientReusesIdempotencyKey(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.ErrorReply(429, "rate limited"), openroutertest.TextReply("ok"))
+
+	client, err := pkg.NewMultiKeyClient([]pkg.APIKeyConfig{{Key: "key-a"}, {Key: "key-b"}},
+		pkg.MultiKeyOptions{ClientOptions: []pkg.Option{pkg.WithBaseURL(srv.URL)}})
+	require.NoError(t, err)
+
+	_, err = client.CreateChatCompletion(context.Background(), hiRequest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key-a", "key-b"}, usedKeys(srv))
+	keys := idempotencyKeys(srv)
+	require.Len(t, keys, 2)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1], "the failover reuses the first key's idempotency key")
+}
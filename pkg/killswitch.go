@@ -0,0 +1,345 @@
+package pkg
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// ErrDisabled is returned, without contacting the API, for requests made while a
+// KillSwitch is off, and for requests without a cached response in cache-only mode
+var ErrDisabled = stderrors.New("openrouter client disabled by kill switch")
+
+// KillSwitchMode is the state of a KillSwitch
+type KillSwitchMode int32
+
+const (
+	// KillSwitchEnabled sends requests as usual, caching chat responses
+	KillSwitchEnabled KillSwitchMode = iota
+
+	// KillSwitchDisabled fails every request with ErrDisabled
+	KillSwitchDisabled
+
+	// KillSwitchCacheOnly answers chat requests from the cache and fails everything
+	// else with ErrDisabled
+	KillSwitchCacheOnly
+)
+
+// String returns the mode's name, as accepted by ParseKillSwitchMode
+func (m KillSwitchMode) String() string {
+	switch m {
+	case KillSwitchEnabled:
+		return "enabled"
+	case KillSwitchDisabled:
+		return "disabled"
+	case KillSwitchCacheOnly:
+		return "cache-only"
+	}
+	return fmt.Sprintf("KillSwitchMode(%d)", int32(m))
+}
+
+// ParseKillSwitchMode parses "enabled", "disabled" or "cache-only", ignoring case and
+// surrounding space
+func ParseKillSwitchMode(s string) (KillSwitchMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "enabled", "on":
+		return KillSwitchEnabled, nil
+	case "disabled", "off":
+		return KillSwitchDisabled, nil
+	case "cache-only", "cache_only", "cached":
+		return KillSwitchCacheOnly, nil
+	}
+	return KillSwitchEnabled, fmt.Errorf("unknown kill switch mode %q", s)
+}
+
+// KillSwitch lets operators cut API spend at runtime, during an incident, without a
+// redeploy: switched off, clients fail fast with ErrDisabled; in cache-only mode they
+// answer chat requests with responses cached while enabled. Set the mode directly, e.g.
+// from an admin endpoint, or Poll it from remote config. It is safe for concurrent use.
+type KillSwitch struct {
+	mode  atomic.Int32
+	cache ResponseCache
+
+	// Logger, when set, logs mode changes and failed polls
+	Logger Logger
+}
+
+// NewKillSwitch creates an enabled kill switch. Successful chat responses are stored in
+// cache while it is enabled and served from it in cache-only mode; with a nil cache,
+// cache-only mode fails every request like disabled.
+func NewKillSwitch(cache ResponseCache) *KillSwitch {
+	return &KillSwitch{cache: cache}
+}
+
+// WithKillSwitch gates the client's requests on a kill switch, which may be shared by
+// many clients
+func WithKillSwitch(killSwitch *KillSwitch) Option {
+	return func(c *Client) {
+		c.killSwitch = killSwitch
+	}
+}
+
+// Mode returns the current mode
+func (k *KillSwitch) Mode() KillSwitchMode {
+	return KillSwitchMode(k.mode.Load())
+}
+
+// Set changes the mode, taking effect for requests started from now on
+func (k *KillSwitch) Set(mode KillSwitchMode) {
+	old := KillSwitchMode(k.mode.Swap(int32(mode)))
+	if old != mode && k.Logger != nil {
+		k.Logger.Warn("Kill switch mode changed", F("from", old.String()), F("to", mode.String()))
+	}
+}
+
+// Enable sends requests as usual
+func (k *KillSwitch) Enable() {
+	k.Set(KillSwitchEnabled)
+}
+
+// Disable fails every request with ErrDisabled
+func (k *KillSwitch) Disable() {
+	k.Set(KillSwitchDisabled)
+}
+
+// CacheOnly answers chat requests from the cache only
+func (k *KillSwitch) CacheOnly() {
+	k.Set(KillSwitchCacheOnly)
+}
+
+// ModeSource fetches the kill switch mode from remote config
+type ModeSource func(ctx context.Context) (KillSwitchMode, error)
+
+// Poll sets the mode from source now and then every interval, until ctx is done. Failed
+// fetches keep the current mode. Run it in its own goroutine:
+//
+//	go killSwitch.Poll(ctx, 10*time.Second, pkg.HTTPModeSource(nil, configURL))
+func (k *KillSwitch) Poll(ctx context.Context, interval time.Duration, source ModeSource) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		mode, err := source(ctx)
+		switch {
+		case err == nil:
+			k.Set(mode)
+		case ctx.Err() == nil && k.Logger != nil:
+			k.Logger.Warn("Failed to poll kill switch mode", F("error", err), F("mode", k.Mode().String()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// HTTPModeSource fetches the mode from url, whose body must be a mode accepted by
+// ParseKillSwitchMode. httpClient defaults to http.DefaultClient.
+func HTTPModeSource(httpClient *http.Client, url string) ModeSource {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return func(ctx context.Context) (KillSwitchMode, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return KillSwitchEnabled, fmt.Errorf("failed to create request: %w", err)
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return KillSwitchEnabled, fmt.Errorf("failed to fetch kill switch mode: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return KillSwitchEnabled, fmt.Errorf("failed to fetch kill switch mode: status %d", resp.StatusCode)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if err != nil {
+			return KillSwitchEnabled, fmt.Errorf("failed to read kill switch mode: %w", err)
+		}
+		return ParseKillSwitchMode(string(body))
+	}
+}
+
+// allow returns ErrDisabled unless requests may be sent. A nil kill switch allows all.
+func (k *KillSwitch) allow() error {
+	if k != nil && k.Mode() != KillSwitchEnabled {
+		return ErrDisabled
+	}
+	return nil
+}
+
+// cached returns the cached response to req in cache-only mode
+func (k *KillSwitch) cached(req models.ChatCompletionRequest) (*models.ChatCompletionResponse, bool) {
+	if k == nil || k.cache == nil || k.Mode() != KillSwitchCacheOnly {
+		return nil, false
+	}
+	key, err := models.HashRequest(req)
+	if err != nil {
+		return nil, false
+	}
+	return k.cache.Get(key)
+}
+
+// remember caches the response to req
+func (k *KillSwitch) remember(req models.ChatCompletionRequest, resp *models.ChatCompletionResponse) {
+	if k == nil || k.cache == nil {
+		return
+	}
+	if key, err := models.HashRequest(req); err == nil {
+		k.cache.Set(key, resp)
+	}
+}
+
+// cachedStream replays a cached response as a single-chunk stream
+func (c *Client) cachedStream(resp *models.ChatCompletionResponse) (*streaming.ChatCompletionStreamReader, error) {
+	chunk := *resp
+	chunk.Object = "chat.completion.chunk"
+	chunk.Choices = make([]models.Choice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		choice.Delta, choice.Message = choice.Message, nil
+		chunk.Choices[i] = choice
+	}
+	data, err := c.codec.Marshal(chunk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cached response: %w", err)
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "data: %s\n\ndata: [DONE]\n\n", data)
+	return streaming.NewChatCompletionStreamReaderWithCodec(io.NopCloser(&body), c.codec), nil
+}
+
+// ResponseCache stores chat responses by request hash, see models.HashRequest
+type ResponseCache interface {
+	Get(key string) (*models.ChatCompletionResponse, bool)
+	Set(key string, resp *models.ChatCompletionResponse)
+}
+
+const (
+	// DefaultResponseCacheTTL is how long a MemoryResponseCache keeps responses by default
+	DefaultResponseCacheTTL = time.Hour
+
+	// DefaultResponseCacheMaxEntries is how many responses a MemoryResponseCache holds by
+	// default
+	DefaultResponseCacheMaxEntries = 1000
+)
+
+// MemoryResponseCache is an in-memory ResponseCache safe for concurrent use. It holds at
+// most MaxEntries responses, evicting the least recently used, and stores and returns
+// copies, so callers may modify the responses they pass and get.
+type MemoryResponseCache struct {
+	// MaxEntries bounds the cache. Defaults to DefaultResponseCacheMaxEntries.
+	MaxEntries int
+
+	// Clock expires entries. Defaults to SystemClock.
+	Clock Clock
+
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*list.Element
+
+	// order lists entries from most to least recently used
+	order *list.List
+}
+
+type responseCacheEntry struct {
+	key     string
+	data    []byte
+	expires time.Time
+}
+
+// NewMemoryResponseCache creates an in-memory response cache. Entries expire after ttl,
+// or DefaultResponseCacheTTL when ttl is zero.
+func NewMemoryResponseCache(ttl time.Duration) *MemoryResponseCache {
+	if ttl <= 0 {
+		ttl = DefaultResponseCacheTTL
+	}
+	return &MemoryResponseCache{
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get implements ResponseCache
+func (c *MemoryResponseCache) Get(key string) (*models.ChatCompletionResponse, bool) {
+	c.mu.Lock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil, false
+	}
+	entry := element.Value.(*responseCacheEntry)
+	if c.now().After(entry.expires) {
+		c.removeLocked(element)
+		c.mu.Unlock()
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	data := entry.data
+	c.mu.Unlock()
+
+	var resp models.ChatCompletionResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, false
+	}
+	return &resp, true
+}
+
+// Set implements ResponseCache
+func (c *MemoryResponseCache) Set(key string, resp *models.ChatCompletionResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	entry := &responseCacheEntry{key: key, data: data, expires: c.now().Add(c.ttl)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+
+	maxEntries := c.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultResponseCacheMaxEntries
+	}
+	for c.order.Len() > maxEntries {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+// Len returns the number of cached responses, including expired ones not yet evicted
+func (c *MemoryResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *MemoryResponseCache) removeLocked(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*responseCacheEntry).key)
+}
+
+func (c *MemoryResponseCache) now() time.Time {
+	if c.Clock != nil {
+		return c.Clock.Now()
+	}
+	return SystemClock.Now()
+}
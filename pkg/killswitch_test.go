@@ -0,0 +1,145 @@
+package pkg_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+func TestKillSwitchDisabled(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	killSwitch := pkg.NewKillSwitch(nil)
+	client := srv.Client(pkg.WithKillSwitch(killSwitch))
+	ctx := context.Background()
+
+	_, err := client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+
+	killSwitch.Disable()
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	assert.ErrorIs(t, err, pkg.ErrDisabled)
+	_, err = client.CreateChatCompletionStream(ctx, hiRequest)
+	assert.ErrorIs(t, err, pkg.ErrDisabled)
+	_, err = client.ListModels(ctx, nil)
+	assert.ErrorIs(t, err, pkg.ErrDisabled)
+
+	// Without a cache, cache-only mode serves nothing either
+	killSwitch.CacheOnly()
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	assert.ErrorIs(t, err, pkg.ErrDisabled)
+	assert.Len(t, srv.Requests(), 1, "disabled requests never reach the API")
+
+	killSwitch.Enable()
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	assert.NoError(t, err)
+}
+
+func TestKillSwitchCacheOnly(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("Cached answer"))
+	killSwitch := pkg.NewKillSwitch(pkg.NewMemoryResponseCache(0))
+	client := srv.Client(pkg.WithKillSwitch(killSwitch))
+	ctx := context.Background()
+
+	_, err := client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+
+	killSwitch.CacheOnly()
+	resp, err := client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "Cached answer", text)
+
+	stream, err := client.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	collected, err := streaming.CollectStream(stream)
+	require.NoError(t, err)
+	text, _ = collected.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "Cached answer", text)
+
+	other := models.NewChatRequest("m", models.WithUserMessage("Something else"))
+	_, err = client.CreateChatCompletion(ctx, other)
+	assert.ErrorIs(t, err, pkg.ErrDisabled)
+	assert.Len(t, srv.Requests(), 1)
+}
+
+func TestKillSwitchPoll(t *testing.T) {
+	var mode atomic.Value
+	mode.Store("enabled")
+	config := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(mode.Load().(string) + "\n"))
+	}))
+	defer config.Close()
+
+	killSwitch := pkg.NewKillSwitch(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go killSwitch.Poll(ctx, 5*time.Millisecond, pkg.HTTPModeSource(nil, config.URL))
+
+	mode.Store("disabled")
+	assert.Eventually(t, func() bool { return killSwitch.Mode() == pkg.KillSwitchDisabled }, time.Second, time.Millisecond)
+
+	// Bad config keeps the current mode
+	mode.Store("maybe")
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, pkg.KillSwitchDisabled, killSwitch.Mode())
+
+	mode.Store("cache-only")
+	assert.Eventually(t, func() bool { return killSwitch.Mode() == pkg.KillSwitchCacheOnly }, time.Second, time.Millisecond)
+}
+
+func TestParseKillSwitchMode(t *testing.T) {
+	for _, mode := range []pkg.KillSwitchMode{pkg.KillSwitchEnabled, pkg.KillSwitchDisabled, pkg.KillSwitchCacheOnly} {
+		parsed, err := pkg.ParseKillSwitchMode(" " + mode.String() + "\n")
+		require.NoError(t, err)
+		assert.Equal(t, mode, parsed)
+	}
+	_, err := pkg.ParseKillSwitchMode("maybe")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, pkg.ErrDisabled))
+}
+
+func TestMemoryResponseCache(t *testing.T) {
+	clock := openroutertest.NewFakeClock(time.Unix(1700000000, 0))
+	cache := pkg.NewMemoryResponseCache(time.Minute)
+	cache.Clock = clock
+	cache.MaxEntries = 2
+
+	cache.Set("a", openroutertest.NewTextResponse("A"))
+	cache.Set("b", openroutertest.NewTextResponse("B"))
+	_, ok := cache.Get("a")
+	require.True(t, ok)
+	cache.Set("c", openroutertest.NewTextResponse("C"))
+	assert.Equal(t, 2, cache.Len())
+	_, ok = cache.Get("b")
+	assert.False(t, ok, "the least recently used entry is evicted")
+
+	// Mutating a returned response doesn't change the cached one
+	resp, ok := cache.Get("a")
+	require.True(t, ok)
+	resp.Choices[0].Message.Content = []byte(`"changed"`)
+	resp.Choices[0].Message.ToolCalls = append(resp.Choices[0].Message.ToolCalls, models.ToolCall{ID: "x"})
+	resp, _ = cache.Get("a")
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "A", text)
+	assert.Empty(t, resp.Choices[0].Message.ToolCalls)
+
+	clock.Advance(2 * time.Minute)
+	_, ok = cache.Get("a")
+	assert.False(t, ok, "entries expire by the cache's clock")
+	assert.Equal(t, 1, cache.Len())
+}
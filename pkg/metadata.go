@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ResponseMetadata is the HTTP metadata of a response: request ID, rate limits and cache
+// status. Pass one with ContextWithResponseMetadata to capture it.
+type ResponseMetadata struct {
+	StatusCode int
+
+	// RequestID identifies the request when reporting an issue to OpenRouter
+	RequestID string
+
+	RateLimit RateLimit
+
+	// CacheStatus is the edge cache status, e.g. HIT or MISS, if the response has one
+	CacheStatus string
+
+	// Header holds every response header
+	Header http.Header
+}
+
+// RateLimit is the rate-limit state reported with a response. Fields are zero when
+// the response doesn't report them.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+
+	// Reset is when the current rate-limit window ends
+	Reset time.Time
+}
+
+type responseMetadataContextKey struct{}
+
+// ContextWithResponseMetadata returns a context that captures the metadata of the
+// responses of requests made with it into meta. Responses are captured for every
+// endpoint, including streams and error responses; when a request is retried, meta holds
+// the last attempt. Use a separate meta for concurrent requests.
+//
+//	var meta pkg.ResponseMetadata
+//	resp, err := client.CreateChatCompletion(pkg.ContextWithResponseMetadata(ctx, &meta), req)
+//	log.Printf("request %s, %d requests left", meta.RequestID, meta.RateLimit.Remaining)
+func ContextWithResponseMetadata(ctx context.Context, meta *ResponseMetadata) context.Context {
+	return context.WithValue(ctx, responseMetadataContextKey{}, meta)
+}
+
+// captureResponseMetadata stores resp's metadata in the context's ResponseMetadata, if any
+func captureResponseMetadata(ctx context.Context, resp *http.Response) {
+	meta, ok := ctx.Value(responseMetadataContextKey{}).(*ResponseMetadata)
+	if !ok || meta == nil {
+		return
+	}
+	*meta = parseResponseMetadata(resp)
+}
+
+// parseResponseMetadata extracts the metadata of resp
+func parseResponseMetadata(resp *http.Response) ResponseMetadata {
+	header := resp.Header
+	meta := ResponseMetadata{
+		StatusCode:  resp.StatusCode,
+		RequestID:   firstHeader(header, "X-Request-Id", "X-Generation-Id", "Cf-Ray"),
+		CacheStatus: firstHeader(header, "Cf-Cache-Status", "X-Cache"),
+		Header:      header.Clone(),
+	}
+	meta.RateLimit.Limit, _ = strconv.Atoi(header.Get("X-Ratelimit-Limit"))
+	meta.RateLimit.Remaining, _ = strconv.Atoi(header.Get("X-Ratelimit-Remaining"))
+	if reset, err := strconv.ParseInt(header.Get("X-Ratelimit-Reset"), 10, 64); err == nil && reset > 0 {
+		// OpenRouter reports milliseconds since the epoch; accept seconds too
+		if reset < 1e12 {
+			meta.RateLimit.Reset = time.Unix(reset, 0)
+		} else {
+			meta.RateLimit.Reset = time.UnixMilli(reset)
+		}
+	}
+	return meta
+}
+
+// firstHeader returns the value of the first of names that is set
+func firstHeader(header http.Header, names ...string) string {
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
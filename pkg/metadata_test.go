@@ -0,0 +1,68 @@
+package pkg_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestResponseMetadata(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	reply := openroutertest.TextReply("hi")
+	reply.Header = http.Header{
+		"X-Request-Id":          {"req-123"},
+		"X-Ratelimit-Limit":     {"200"},
+		"X-Ratelimit-Remaining": {"199"},
+		"X-Ratelimit-Reset":     {"1767225600000"},
+		"Cf-Cache-Status":       {"MISS"},
+	}
+	srv.EnqueueChat(reply)
+
+	var meta pkg.ResponseMetadata
+	ctx := pkg.ContextWithResponseMetadata(context.Background(), &meta)
+	_, err := client.CreateChatCompletion(ctx, models.NewChatRequest("m", models.WithUserMessage("hello")))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, meta.StatusCode)
+	assert.Equal(t, "req-123", meta.RequestID)
+	assert.Equal(t, "MISS", meta.CacheStatus)
+	assert.Equal(t, pkg.RateLimit{Limit: 200, Remaining: 199, Reset: time.UnixMilli(1767225600000)}, meta.RateLimit)
+	assert.Equal(t, "application/json", meta.Header.Get("Content-Type"))
+}
+
+func TestResponseMetadataOnErrorsAndStreams(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	failed := openroutertest.ErrorReply(429, "slow down")
+	failed.Header = http.Header{"X-Request-Id": {"req-429"}, "X-Ratelimit-Remaining": {"0"}}
+	streamed := openroutertest.TextReply("hi")
+	streamed.Header = http.Header{"X-Request-Id": {"req-stream"}}
+	srv.EnqueueChat(failed, streamed)
+
+	var meta pkg.ResponseMetadata
+	ctx := pkg.ContextWithResponseMetadata(context.Background(), &meta)
+	_, err := client.CreateChatCompletion(ctx, models.NewChatRequest("m", models.WithUserMessage("hello")))
+	require.Error(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, meta.StatusCode)
+	assert.Equal(t, "req-429", meta.RequestID)
+	assert.Equal(t, 0, meta.RateLimit.Remaining)
+
+	stream, err := client.CreateChatCompletionStream(ctx, models.NewChatRequest("m", models.WithUserMessage("hello")))
+	require.NoError(t, err)
+	defer stream.Close()
+	assert.Equal(t, "req-stream", meta.RequestID)
+	assert.Equal(t, "text/event-stream", meta.Header.Get("Content-Type"))
+}
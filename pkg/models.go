@@ -2,7 +2,6 @@ package pkg
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
 
@@ -31,7 +30,7 @@ func (c *Client) ListModels(ctx context.Context, opts *ListModelsOptions) (*mode
 	defer resp.Body.Close()
 
 	var modelsResp models.ModelsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&modelsResp); err != nil {
+	if err := c.decodeResponse(resp, &modelsResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -0,0 +1,27 @@
+package models
+
+// ActivityItem is the usage of one model endpoint on one day
+type ActivityItem struct {
+	// Date is the UTC day, formatted as 2006-01-02
+	Date string `json:"date"`
+
+	Model          string `json:"model"`
+	ModelPermaslug string `json:"model_permaslug,omitempty"`
+	EndpointID     string `json:"endpoint_id,omitempty"`
+	ProviderName   string `json:"provider_name,omitempty"`
+
+	// Usage is the cost in USD, and BYOKUsageInference the cost of requests made with
+	// the user's own provider keys
+	Usage              float64 `json:"usage"`
+	BYOKUsageInference float64 `json:"byok_usage_inference,omitempty"`
+
+	Requests         int `json:"requests"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	ReasoningTokens  int `json:"reasoning_tokens,omitempty"`
+}
+
+// ActivityResponse represents the response from the activity endpoint
+type ActivityResponse struct {
+	Data []ActivityItem `json:"data"`
+}
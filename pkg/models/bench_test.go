@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// benchmarkRequest is a named request shape for the marshaling benchmarks
+type benchmarkRequest struct {
+	name string
+	req  ChatCompletionRequest
+}
+
+func benchmarkRequests(b *testing.B) []benchmarkRequest {
+	tool, err := NewTool("get_weather", "Get the current weather for a location", map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"location": map[string]interface{}{"type": "string"},
+			"unit":     map[string]interface{}{"type": "string", "enum": []string{"celsius", "fahrenheit"}},
+		},
+		"required": []string{"location"},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	history := NewChatRequest("openai/gpt-4o", WithSystemMessage("You are a helpful assistant."))
+	for i := 0; i < 50; i++ {
+		history.Messages = append(history.Messages,
+			NewTextMessage(RoleUser, fmt.Sprintf("Question %d about the quarterly report and its figures?", i)),
+			NewTextMessage(RoleAssistant, fmt.Sprintf("Answer %d: revenue grew, costs fell, and margins improved.", i)))
+	}
+
+	return []benchmarkRequest{
+		{"short", NewChatRequest("openai/gpt-4o", WithUserMessage("Hello"), WithTemperature(0.7))},
+		{"tools", NewChatRequest("openai/gpt-4o", WithUserMessage("Weather in Paris?"),
+			WithTools(*tool), WithToolChoice(ToolChoiceAuto))},
+		{"history-100", history},
+	}
+}
+
+func BenchmarkMarshalChatRequest(b *testing.B) {
+	for _, bench := range benchmarkRequests(b) {
+		req := bench.req
+		b.Run(bench.name, func(b *testing.B) {
+			data, _ := json.Marshal(req)
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := json.Marshal(req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUnmarshalChatRequest(b *testing.B) {
+	for _, bench := range benchmarkRequests(b) {
+		data, err := json.Marshal(bench.req)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(bench.name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var decoded ChatCompletionRequest
+				if err := json.Unmarshal(data, &decoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 )
@@ -127,6 +128,11 @@ func NewToolMessage(toolCallID, name, content string) Message {
 
 // GetTextContent attempts to get the text content from a message
 func (m Message) GetTextContent() (string, error) {
+	// Messages that only carry tool calls may omit content entirely
+	if len(bytes.TrimSpace(m.Content)) == 0 {
+		return "", nil
+	}
+
 	// First try to unmarshal as string
 	var text string
 	if err := json.Unmarshal(m.Content, &text); err == nil {
@@ -186,6 +192,17 @@ func (m Message) GetTextContent() (string, error) {
 
 // GetMultiContent attempts to get multi-part content from a message
 func (m Message) GetMultiContent() ([]Content, error) {
+	trimmed := bytes.TrimSpace(m.Content)
+	if len(trimmed) == 0 || string(trimmed) == "null" {
+		return nil, nil
+	}
+
+	// Plain string content is a single text part
+	var text string
+	if err := json.Unmarshal(m.Content, &text); err == nil {
+		return []Content{TextContent{Type: ContentTypeText, Text: text}}, nil
+	}
+
 	var rawParts []json.RawMessage
 	if err := json.Unmarshal(m.Content, &rawParts); err != nil {
 		return nil, err
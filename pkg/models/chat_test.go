@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGetTextContentWithoutContent(t *testing.T) {
+	msg := Message{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "call_1"}}}
+
+	text, err := msg.GetTextContent()
+	if err != nil || text != "" {
+		t.Errorf("expected empty text without error, got %q, %v", text, err)
+	}
+
+	parts, err := msg.GetMultiContent()
+	if err != nil || parts != nil {
+		t.Errorf("expected no parts without error, got %v, %v", parts, err)
+	}
+}
+
+func TestGetMultiContentFromString(t *testing.T) {
+	parts, err := NewTextMessage(RoleUser, "hello").GetMultiContent()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parts) != 1 || parts[0] != (TextContent{Type: ContentTypeText, Text: "hello"}) {
+		t.Errorf("expected a single text part, got %v", parts)
+	}
+}
+
+func FuzzGetTextContent(f *testing.F) {
+	f.Add([]byte(`"hello"`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(``))
+	f.Add([]byte(`12345678901234567890`))
+	f.Add([]byte(`1e308`))
+	f.Add([]byte(`{"a":[1,2,{"b":null}]}`))
+	f.Add([]byte(`[[["nested"]],{"type":"text","text":"x"}]`))
+	f.Add([]byte("\"\xff\xfe\""))
+
+	f.Fuzz(func(t *testing.T, content []byte) {
+		msg := Message{Role: RoleAssistant, Content: content}
+
+		text, err := msg.GetTextContent()
+
+		var want string
+		if json.Unmarshal(content, &want) == nil {
+			if err != nil {
+				t.Fatalf("unexpected error for string content %q: %v", content, err)
+			}
+			if text != want {
+				t.Fatalf("expected %q, got %q", want, text)
+			}
+		}
+
+		msg.GetMultiContent()
+	})
+}
+
+func FuzzMultiContentRoundTrip(f *testing.F) {
+	f.Add("Describe this image", "https://example.com/cat.png", "doc.pdf", "data:application/pdf;base64,AAAA")
+	f.Add("", "", "", "")
+	f.Add("\xff\xfe invalid", "\x00", " ", "\"quoted\"")
+
+	f.Fuzz(func(t *testing.T, text, imageURL, filename, fileData string) {
+		want := []Content{
+			TextContent{Type: ContentTypeText, Text: text},
+			ImageContent{Type: ContentTypeImageURL, ImageURL: ImageURL{URL: imageURL}},
+			FileContent{Type: ContentTypeFile, File: File{Filename: filename, FileData: fileData}},
+		}
+
+		msg, err := NewMultiContentMessage(RoleUser, want...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Invalid UTF-8 is replaced with U+FFFD byte by byte when encoding
+		want[0] = TextContent{Type: ContentTypeText, Text: string([]rune(text))}
+		want[1] = ImageContent{Type: ContentTypeImageURL, ImageURL: ImageURL{URL: string([]rune(imageURL))}}
+		want[2] = FileContent{Type: ContentTypeFile, File: File{Filename: string([]rune(filename)), FileData: string([]rune(fileData))}}
+
+		got, err := msg.GetMultiContent()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("expected %d parts, got %d", len(want), len(got))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("part %d: expected %#v, got %#v", i, want[i], got[i])
+			}
+		}
+
+		if _, err := msg.GetTextContent(); err == nil {
+			t.Error("expected an error converting multi-part content to text")
+		}
+	})
+}
@@ -2,6 +2,10 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 // ChatCompletionRequest represents a request to the chat completions endpoint
@@ -20,6 +24,7 @@ type ChatCompletionRequest struct {
 	// Response configuration
 	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
 	Stream         bool            `json:"stream,omitempty"`
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`
 
 	// LLM Parameters
 	MaxTokens         *int               `json:"max_tokens,omitempty"`
@@ -30,7 +35,7 @@ type ChatCompletionRequest struct {
 	PresencePenalty   *float64           `json:"presence_penalty,omitempty"`
 	RepetitionPenalty *float64           `json:"repetition_penalty,omitempty"`
 	Seed              *int               `json:"seed,omitempty"`
-	Stop              []string           `json:"stop,omitempty"`
+	Stop              StopSequences      `json:"stop,omitempty"`
 	LogitBias         map[string]float64 `json:"logit_bias,omitempty"`
 	TopLogprobs       *int               `json:"top_logprobs,omitempty"`
 	MinP              *float64           `json:"min_p,omitempty"`
@@ -40,6 +45,10 @@ type ChatCompletionRequest struct {
 	Tools      []Tool     `json:"tools,omitempty"`
 	ToolChoice ToolChoice `json:"tool_choice,omitempty"`
 
+	// ParallelToolCalls allows or forbids several tool calls in one response. The
+	// provider default, usually true, applies when nil.
+	ParallelToolCalls *bool `json:"parallel_tool_calls,omitempty"`
+
 	// Predicted outputs for latency optimization
 	Prediction *Prediction `json:"prediction,omitempty"`
 
@@ -56,12 +65,123 @@ type ChatCompletionRequest struct {
 
 	// Reasoning configuration
 	Reasoning *ReasoningConfig `json:"reasoning,omitempty"`
+
+	// Usage accounting
+	Usage *UsageConfig `json:"usage,omitempty"`
+
+	// Extra holds provider-specific parameters OpenRouter forwards but this package has
+	// no field for yet, e.g. {"safe_prompt": true}. They are merged into the request
+	// JSON. A key naming one of the fields above is an error; set the field instead.
+	// Decoding collects unknown keys here.
+	Extra map[string]any `json:"-"`
+}
+
+// requestFields are the JSON names of the ChatCompletionRequest fields
+var requestFields = jsonFieldNames(reflect.TypeOf(ChatCompletionRequest{}))
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// extraCollisions returns the sorted Extra keys that name typed fields
+func (r *ChatCompletionRequest) extraCollisions() []string {
+	var keys []string
+	for key := range r.Extra {
+		if requestFields[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MarshalJSON encodes a request, merging Extra into the typed fields
+func (r ChatCompletionRequest) MarshalJSON() ([]byte, error) {
+	type plain ChatCompletionRequest
+	data, err := json.Marshal(plain(r))
+	if err != nil || len(r.Extra) == 0 {
+		return data, err
+	}
+
+	if collisions := r.extraCollisions(); len(collisions) > 0 {
+		return nil, fmt.Errorf("extra parameters %s collide with request fields", strings.Join(collisions, ", "))
+	}
+	extra, err := json.Marshal(r.Extra)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal extra parameters: %w", err)
+	}
+	if len(data) == 2 { // {}
+		return extra, nil
+	}
+	// Splice the extra object's members after the typed ones
+	merged := make([]byte, 0, len(data)+len(extra))
+	merged = append(merged, data[:len(data)-1]...)
+	merged = append(merged, ',')
+	return append(merged, extra[1:]...), nil
+}
+
+// UnmarshalJSON decodes a request, resolving tool_choice to its concrete type and
+// collecting unknown keys in Extra
+func (r *ChatCompletionRequest) UnmarshalJSON(data []byte) error {
+	type plain ChatCompletionRequest
+	aux := struct {
+		*plain
+		ToolChoice json.RawMessage `json:"tool_choice,omitempty"`
+	}{plain: (*plain)(r)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	toolChoice, err := unmarshalToolChoice(aux.ToolChoice)
+	if err != nil {
+		return err
+	}
+	r.ToolChoice = toolChoice
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for key, raw := range fields {
+		if requestFields[key] {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		if r.Extra == nil {
+			r.Extra = make(map[string]any)
+		}
+		r.Extra[key] = value
+	}
+	return nil
+}
+
+// StreamOptions configures a streaming response
+type StreamOptions struct {
+	// IncludeUsage asks for a final chunk carrying token usage
+	IncludeUsage bool `json:"include_usage"`
 }
 
 // ResponseFormat represents the desired response format
 type ResponseFormat struct {
 	Type       string      `json:"type"`
 	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+
+	// Grammar is a GBNF grammar for the "grammar" type
+	Grammar string `json:"grammar,omitempty"`
+
+	// Pattern is a regular expression for the "regex" type
+	Pattern string `json:"pattern,omitempty"`
 }
 
 // JSONSchema represents a JSON schema for structured outputs
@@ -93,15 +213,27 @@ type ReasoningConfig struct {
 	Enabled *bool `json:"enabled,omitempty"` // Enable reasoning with defaults
 }
 
+// UsageConfig represents usage accounting options
+type UsageConfig struct {
+	// Include adds cost and detailed token counts to the response usage
+	Include bool `json:"include"`
+}
+
 // ChatCompletionResponse represents a response from the chat completions endpoint
 type ChatCompletionResponse struct {
-	ID                string   `json:"id"`
-	Object            string   `json:"object"`
-	Created           int64    `json:"created"`
-	Model             string   `json:"model"`
-	Choices           []Choice `json:"choices"`
-	Usage             *Usage   `json:"usage,omitempty"`
-	SystemFingerprint string   `json:"system_fingerprint,omitempty"`
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []Choice `json:"choices"`
+	Usage   *Usage   `json:"usage,omitempty"`
+
+	// Provider is the name of the provider that served the request, e.g. "OpenAI".
+	// It is also set on every streamed chunk.
+	Provider string `json:"provider,omitempty"`
+
+	// SystemFingerprint identifies the backend configuration the provider ran with
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
 }
 
 // Choice represents a completion choice
@@ -114,7 +246,8 @@ type Choice struct {
 	// For streaming responses
 	Delta *Message `json:"delta,omitempty"`
 
-	// Finish reasons
+	// FinishReason is normalized across providers: "stop", "length", "tool_calls",
+	// "content_filter" or "error". NativeFinishReason is the provider's raw reason.
 	FinishReason       string `json:"finish_reason,omitempty"`
 	NativeFinishReason string `json:"native_finish_reason,omitempty"`
 
@@ -137,6 +270,9 @@ type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+
+	// Cost is the request cost in credits, reported when usage accounting is enabled
+	Cost float64 `json:"cost,omitempty"`
 }
 
 // LogProbs represents log probability information
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExtraIsMergedIntoJSON(t *testing.T) {
+	req := NewChatRequest("mistralai/mistral-large",
+		WithUserMessage("Hi"),
+		WithExtra("safe_prompt", true),
+		WithExtra("top_n_sigma", 0.5),
+	)
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"messages":[{"role":"user","content":"Hi"}],"model":"mistralai/mistral-large","safe_prompt":true,"top_n_sigma":0.5}`
+	if string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+
+	var decoded ChatCompletionRequest
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Extra["safe_prompt"] != true || decoded.Extra["top_n_sigma"] != 0.5 || len(decoded.Extra) != 2 {
+		t.Errorf("decoded Extra = %v", decoded.Extra)
+	}
+	if decoded.Model != "mistralai/mistral-large" {
+		t.Errorf("decoded Model = %q", decoded.Model)
+	}
+
+	// Only extra parameters
+	data, err = json.Marshal(ChatCompletionRequest{Extra: map[string]any{"a": 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"a":1}` {
+		t.Errorf("Marshal = %s", data)
+	}
+}
+
+func TestExtraCollisions(t *testing.T) {
+	req := NewChatRequest("openai/gpt-4o", WithUserMessage("Hi"), WithExtra("temperature", 0.2), WithExtra("model", "x"))
+
+	_, err := json.Marshal(req)
+	if err == nil || !strings.Contains(err.Error(), "model, temperature") {
+		t.Errorf("Marshal error = %v, want collision on model and temperature", err)
+	}
+
+	err = req.Validate()
+	if err == nil || !strings.Contains(err.Error(), "extra parameter temperature collides") {
+		t.Errorf("Validate() = %v, want temperature collision", err)
+	}
+}
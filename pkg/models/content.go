@@ -0,0 +1,108 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// User creates a user message from content parts:
+//
+//	msg := models.User(
+//		models.Text("What's in this image?"),
+//		models.Image("https://example.com/cat.jpg", "high"),
+//	)
+//
+// A message with a single text part is sent as plain string content.
+func User(parts ...Content) Message {
+	return newPartsMessage(RoleUser, parts)
+}
+
+// System creates a system message from content parts
+func System(parts ...Content) Message {
+	return newPartsMessage(RoleSystem, parts)
+}
+
+// Assistant creates an assistant message from content parts
+func Assistant(parts ...Content) Message {
+	return newPartsMessage(RoleAssistant, parts)
+}
+
+// newPartsMessage builds a message, using plain string content for a single text part
+func newPartsMessage(role Role, parts []Content) Message {
+	if len(parts) == 1 {
+		if text, ok := parts[0].(TextContent); ok {
+			return NewTextMessage(role, text.Text)
+		}
+	}
+
+	// Marshaling cannot fail: content parts only contain strings
+	msg, _ := NewMultiContentMessage(role, parts...)
+	return msg
+}
+
+// Text creates a text content part
+func Text(text string) TextContent {
+	return TextContent{Type: ContentTypeText, Text: text}
+}
+
+// Image creates an image content part from a URL or data URL, with an optional
+// detail level ("auto", "low", or "high")
+func Image(url string, detail ...string) ImageContent {
+	image := ImageContent{
+		Type:     ContentTypeImageURL,
+		ImageURL: ImageURL{URL: url},
+	}
+	if len(detail) > 0 {
+		image.ImageURL.Detail = detail[0]
+	}
+	return image
+}
+
+// ImageData creates an image content part from raw image bytes. The image type is
+// detected from the data.
+func ImageData(data []byte, detail ...string) ImageContent {
+	return Image(dataURL(http.DetectContentType(data), data), detail...)
+}
+
+// ImageFile creates an image content part from a file
+func ImageFile(path string, detail ...string) (ImageContent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ImageContent{}, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return Image(dataURL(contentType, data), detail...), nil
+}
+
+// PDF creates a file content part from PDF bytes
+func PDF(filename string, data []byte) FileContent {
+	return FileContent{
+		Type: ContentTypeFile,
+		File: File{
+			Filename: filename,
+			FileData: dataURL("application/pdf", data),
+		},
+	}
+}
+
+// PDFFile creates a file content part from a PDF file
+func PDFFile(path string) (FileContent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FileContent{}, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	return PDF(filepath.Base(path), data), nil
+}
+
+// dataURL encodes data as a base64 data URL
+func dataURL(contentType string, data []byte) string {
+	return fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(data))
+}
@@ -0,0 +1,99 @@
+package models
+
+import "strings"
+
+// ValidateConversation checks the ordering constraints providers enforce on a
+// conversation: at most one system message, at the start; every assistant tool call
+// answered by a tool message with its ID before the next turn; and no tool messages
+// that don't answer a pending call. It returns a *ValidationError listing every problem
+// found, or nil.
+//
+// ChatCompletionRequest.Validate checks the tool call ordering but allows several
+// system messages, which most providers accept.
+func ValidateConversation(messages []Message) error {
+	v := &validator{}
+
+	systemMessages := 0
+	for i, msg := range messages {
+		if msg.Role != RoleSystem {
+			continue
+		}
+		systemMessages++
+		switch {
+		case i > 0 && systemMessages == 1:
+			v.addf("message %d: the system message must be the first message", i)
+		case systemMessages > 1:
+			v.addf("message %d: only one system message is allowed", i)
+		}
+	}
+
+	validateToolSequence(v, messages)
+	return v.err()
+}
+
+// validateToolSequence checks that tool messages answer the tool calls of the preceding
+// assistant message, and that every call is answered before the conversation moves on
+func validateToolSequence(v *validator, messages []Message) {
+	var pending []string
+	answered := make(map[string]bool)
+
+	unanswered := func(i int) {
+		var ids []string
+		for _, id := range pending {
+			if !answered[id] {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			return
+		}
+		if i < len(messages) {
+			v.addf("message %d: tool calls %s have no tool response", i, strings.Join(ids, ", "))
+		} else {
+			v.addf("conversation ends with unanswered tool calls %s", strings.Join(ids, ", "))
+		}
+	}
+
+	for i, msg := range messages {
+		if msg.Role == RoleTool {
+			switch {
+			case msg.ToolCallID == "":
+				// Reported by Validate
+			case answered[msg.ToolCallID]:
+				v.addf("message %d: tool call %s already has a response", i, msg.ToolCallID)
+			case !contains(pending, msg.ToolCallID):
+				v.addf("message %d: tool response %s doesn't answer a tool call of the preceding assistant message", i, msg.ToolCallID)
+			default:
+				answered[msg.ToolCallID] = true
+			}
+			continue
+		}
+
+		unanswered(i)
+		pending = pending[:0]
+		answered = make(map[string]bool)
+
+		if msg.Role == RoleAssistant {
+			for _, call := range msg.ToolCalls {
+				if call.ID == "" {
+					continue
+				}
+				if contains(pending, call.ID) {
+					v.addf("message %d: tool call id %s is repeated", i, call.ID)
+					continue
+				}
+				pending = append(pending, call.ID)
+			}
+		}
+	}
+	unanswered(len(messages))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
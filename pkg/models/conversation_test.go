@@ -0,0 +1,50 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateConversation(t *testing.T) {
+	system := NewTextMessage(RoleSystem, "Be brief.")
+	user := NewTextMessage(RoleUser, "Weather in Paris and Rome?")
+	calls := Message{Role: RoleAssistant, ToolCalls: []ToolCall{
+		{ID: "call_1", Type: "function", Function: FunctionCall{Name: "weather", Arguments: `{"city":"Paris"}`}},
+		{ID: "call_2", Type: "function", Function: FunctionCall{Name: "weather", Arguments: `{"city":"Rome"}`}},
+	}}
+	result := func(id string) Message {
+		msg := NewTextMessage(RoleTool, "sunny")
+		msg.ToolCallID = id
+		return msg
+	}
+	answer := NewTextMessage(RoleAssistant, "Sunny in both.")
+
+	tests := []struct {
+		name     string
+		messages []Message
+		want     string
+	}{
+		{"valid", []Message{system, user, calls, result("call_2"), result("call_1"), answer}, ""},
+		{"late system message", []Message{user, system}, "the system message must be the first message"},
+		{"two system messages", []Message{system, system, user}, "only one system message is allowed"},
+		{"missing response", []Message{user, calls, result("call_1"), answer}, "message 3: tool calls call_2 have no tool response"},
+		{"orphaned response", []Message{user, result("call_1")}, "tool response call_1 doesn't answer a tool call"},
+		{"duplicate response", []Message{user, calls, result("call_1"), result("call_1"), result("call_2")}, "tool call call_1 already has a response"},
+		{"unanswered at end", []Message{user, calls}, "conversation ends with unanswered tool calls call_1, call_2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConversation(tt.messages)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("ValidateConversation() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("ValidateConversation() = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+// EmbeddingRequest represents a request to the embeddings endpoint
+type EmbeddingRequest struct {
+	Model string `json:"model"`
+
+	// Input is a string or a slice of strings to embed
+	Input interface{} `json:"input"`
+
+	// Optional parameters
+	EncodingFormat string               `json:"encoding_format,omitempty"` // "float" or "base64"
+	Dimensions     *int                 `json:"dimensions,omitempty"`
+	User           string               `json:"user,omitempty"`
+	Provider       *ProviderPreferences `json:"provider,omitempty"`
+}
+
+// EmbeddingResponse represents a response from the embeddings endpoint
+type EmbeddingResponse struct {
+	ID     string      `json:"id,omitempty"`
+	Object string      `json:"object"`
+	Data   []Embedding `json:"data"`
+	Model  string      `json:"model"`
+	Usage  *Usage      `json:"usage,omitempty"`
+}
+
+// Embedding is the embedding of a single input
+type Embedding struct {
+	Object    string    `json:"object"`
+	Embedding []float64 `json:"embedding"`
+	Index     int       `json:"index"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import "strings"
+
+// RouteFallback is the route that tries a request's models in order until one succeeds
+const RouteFallback = "fallback"
+
+// WithFallbackModels sets the models to try, in order, until one succeeds. The first one
+// is the primary model: it becomes Model if none is set, and if Model is set to another
+// model, that model stays primary and all of these are fallbacks. Models repeating the
+// primary or each other are dropped, and Route is set to "fallback".
+//
+//	req := models.NewChatRequest("", models.WithUserMessage("Hi"))
+//	req.WithFallbackModels("openai/gpt-4o", "anthropic/claude-3.5-sonnet")
+func (r *ChatCompletionRequest) WithFallbackModels(models ...string) *ChatCompletionRequest {
+	if len(models) == 0 {
+		return r
+	}
+	if r.Model == "" {
+		r.Model = models[0]
+	}
+
+	seen := map[string]bool{r.Model: true}
+	r.Models = nil
+	for _, model := range models {
+		if model == "" || seen[model] {
+			continue
+		}
+		seen[model] = true
+		r.Models = append(r.Models, model)
+	}
+	if len(r.Models) > 0 {
+		r.Route = RouteFallback
+	}
+	return r
+}
+
+// ModelChain returns the models a request may be served by, primary first
+func (r *ChatCompletionRequest) ModelChain() []string {
+	var chain []string
+	if r.Model != "" {
+		chain = append(chain, r.Model)
+	}
+	for _, model := range r.Models {
+		if model != r.Model {
+			chain = append(chain, model)
+		}
+	}
+	return chain
+}
+
+// ModelSelection reports which of a request's models served a response
+type ModelSelection struct {
+	// Model is the model that served the response, as reported by the response
+	Model string
+
+	// Provider is the provider that served the response
+	Provider string
+
+	// Index is the position of Model in the request's ModelChain: 0 for the primary
+	// model, or -1 if the response names a model the request didn't list
+	Index int
+}
+
+// Fallback reports whether a fallback model served the response instead of the primary
+func (s ModelSelection) Fallback() bool {
+	return s.Index > 0
+}
+
+// ServedBy reports which of req's models served the response. Responses may name a dated
+// version of a model, e.g. "openai/gpt-4o-2024-08-06" for "openai/gpt-4o", and drop a
+// variant suffix such as ":free"; both still match.
+func (r *ChatCompletionResponse) ServedBy(req *ChatCompletionRequest) ModelSelection {
+	selection := ModelSelection{Model: r.Model, Provider: r.Provider, Index: -1}
+	chain := req.ModelChain()
+	for i, model := range chain {
+		if model == r.Model {
+			selection.Index = i
+			return selection
+		}
+	}
+	for i, model := range chain {
+		if base, _, _ := strings.Cut(model, ":"); r.Model == base || strings.HasPrefix(r.Model, base+"-") {
+			selection.Index = i
+			return selection
+		}
+	}
+	return selection
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithFallbackModels(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      string
+		fallbacks  []string
+		wantModel  string
+		wantModels []string
+	}{
+		{"first becomes primary", "", []string{"a", "b", "c"}, "a", []string{"b", "c"}},
+		{"primary already set", "a", []string{"a", "b"}, "a", []string{"b"}},
+		{"other primary kept", "x", []string{"a", "b"}, "x", []string{"a", "b"}},
+		{"duplicates dropped", "", []string{"a", "b", "a", "", "b"}, "a", []string{"b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := NewChatRequest(tt.model, WithUserMessage("hi"))
+			req.WithFallbackModels(tt.fallbacks...)
+			if req.Model != tt.wantModel || !reflect.DeepEqual(req.Models, tt.wantModels) {
+				t.Errorf("Model, Models = %q, %q, want %q, %q", req.Model, req.Models, tt.wantModel, tt.wantModels)
+			}
+			if req.Route != RouteFallback {
+				t.Errorf("Route = %q, want %q", req.Route, RouteFallback)
+			}
+			if err := req.Validate(); err != nil {
+				t.Errorf("Validate() = %v", err)
+			}
+		})
+	}
+}
+
+func TestServedBy(t *testing.T) {
+	req := NewChatRequest("openai/gpt-4o", WithUserMessage("hi"),
+		WithFallbackModels("anthropic/claude-3.5-sonnet", "meta-llama/llama-3.1-8b-instruct:free"))
+
+	tests := []struct {
+		served       string
+		wantIndex    int
+		wantFallback bool
+	}{
+		{"openai/gpt-4o", 0, false},
+		{"openai/gpt-4o-2024-08-06", 0, false},
+		{"anthropic/claude-3.5-sonnet", 1, true},
+		{"meta-llama/llama-3.1-8b-instruct", 2, true},
+		{"mistralai/mistral-7b", -1, false},
+	}
+
+	for _, tt := range tests {
+		resp := &ChatCompletionResponse{Model: tt.served, Provider: "P"}
+		got := resp.ServedBy(&req)
+		if got.Index != tt.wantIndex || got.Fallback() != tt.wantFallback || got.Model != tt.served || got.Provider != "P" {
+			t.Errorf("ServedBy() for %s = %+v, want index %d", tt.served, got, tt.wantIndex)
+		}
+	}
+}
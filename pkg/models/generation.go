@@ -1,5 +1,12 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 // GenerationResponse represents the response from the generation endpoint
 type GenerationResponse struct {
 	Data Generation `json:"data"`
@@ -10,8 +17,8 @@ type Generation struct {
 	ID                string            `json:"id"`
 	Model             string            `json:"model"`
 	Object            string            `json:"object"`
-	Created           int64             `json:"created"`
-	Usage             interface{}       `json:"usage"`
+	Created           UnixTime          `json:"created"`
+	Usage             *GenerationUsage  `json:"usage"`
 	NativeTokenCounts NativeTokenCounts `json:"native_token_counts"`
 	Metrics           GenerationMetrics `json:"metrics"`
 	Provider          string            `json:"provider"`
@@ -19,9 +26,77 @@ type Generation struct {
 	Moderation        *ModerationInfo   `json:"moderation,omitempty"`
 	Transforms        []string          `json:"transforms,omitempty"`
 	Origin            interface{}       `json:"origin,omitempty"`
+
+	// Quantization is the precision of the weights that served the generation, when
+	// the provider reports it
+	Quantization QuantizationLevel `json:"quantization,omitempty"`
+
+	// TotalCost is the cost in credits, also reported in Usage; see Cost
+	TotalCost float64 `json:"total_cost,omitempty"`
+
+	// ProviderName is the provider that served the generation, e.g. "OpenAI"
+	ProviderName string `json:"provider_name,omitempty"`
+
+	// UpstreamID is the provider's own ID for the generation
+	UpstreamID string `json:"upstream_id,omitempty"`
+
+	// LatencyMS is the provider's latency to the first token, ModerationLatencyMS the
+	// time spent in moderation, and GenerationTimeMS the total generation time, all in
+	// milliseconds
+	LatencyMS           float64 `json:"latency,omitempty"`
+	ModerationLatencyMS float64 `json:"moderation_latency,omitempty"`
+	GenerationTimeMS    float64 `json:"generation_time,omitempty"`
+
+	// FinishReason is normalized across providers; NativeFinishReason is the upstream
+	// provider's raw reason
+	FinishReason       string `json:"finish_reason,omitempty"`
+	NativeFinishReason string `json:"native_finish_reason,omitempty"`
+
+	// Streamed is set for streamed generations, and Cancelled for ones the client
+	// aborted
+	Streamed  bool `json:"streamed,omitempty"`
+	Cancelled bool `json:"cancelled,omitempty"`
+
+	// IsBYOK is set when the generation ran on the account's own provider key
+	IsBYOK bool `json:"is_byok,omitempty"`
+
+	// Token counts, normalized and as counted by the provider's tokenizer
+	TokensPrompt           int `json:"tokens_prompt,omitempty"`
+	TokensCompletion       int `json:"tokens_completion,omitempty"`
+	NativeTokensPrompt     int `json:"native_tokens_prompt,omitempty"`
+	NativeTokensCompletion int `json:"native_tokens_completion,omitempty"`
+	NativeTokensReasoning  int `json:"native_tokens_reasoning,omitempty"`
+}
+
+// Cost returns the generation's cost in credits, from whichever field reports it
+func (g *Generation) Cost() float64 {
+	if g.TotalCost != 0 {
+		return g.TotalCost
+	}
+	if g.Usage != nil {
+		return g.Usage.TotalCost
+	}
+	return 0
+}
+
+// ProviderLatency returns the provider's latency to the first token, or zero when it
+// is not reported
+func (g *Generation) ProviderLatency() time.Duration {
+	ms := g.LatencyMS
+	if ms == 0 {
+		ms = float64(g.Metrics.LatencyMS)
+	}
+	return time.Duration(ms * float64(time.Millisecond))
 }
 
-// GenerationUsage represents token usage with costs
+// ModerationLatency returns the time spent in moderation, or zero when it is not
+// reported
+func (g *Generation) ModerationLatency() time.Duration {
+	return time.Duration(g.ModerationLatencyMS * float64(time.Millisecond))
+}
+
+// GenerationUsage represents token usage with costs. The API reports usage either as
+// this object or as a bare number, the cost, and both decode into it.
 type GenerationUsage struct {
 	PromptTokens     int     `json:"prompt_tokens"`
 	CompletionTokens int     `json:"completion_tokens"`
@@ -29,6 +104,21 @@ type GenerationUsage struct {
 	TotalCost        float64 `json:"total_cost"`
 }
 
+// UnmarshalJSON decodes usage from an object or from a number holding the cost
+func (u *GenerationUsage) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] != '{' && trimmed[0] != 'n' {
+		var cost float64
+		if err := json.Unmarshal(trimmed, &cost); err != nil {
+			return fmt.Errorf("invalid generation usage %s", data)
+		}
+		*u = GenerationUsage{TotalCost: cost}
+		return nil
+	}
+	type plain GenerationUsage
+	return json.Unmarshal(data, (*plain)(u))
+}
+
 // NativeTokenCounts represents the actual token counts from the provider
 type NativeTokenCounts struct {
 	PromptTokens     int `json:"prompt_tokens"`
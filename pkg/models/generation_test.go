@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestGenerationUsageVariants(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *GenerationUsage
+	}{
+		{`0.0042`, &GenerationUsage{TotalCost: 0.0042}},
+		{`{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15,"total_cost":0.0042}`,
+			&GenerationUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, TotalCost: 0.0042}},
+		{`null`, nil},
+	}
+	for _, tt := range tests {
+		var gen Generation
+		if err := json.Unmarshal([]byte(`{"id":"gen-1","usage":`+tt.in+`}`), &gen); err != nil {
+			t.Fatalf("Unmarshal(%s) = %v", tt.in, err)
+		}
+		if (gen.Usage == nil) != (tt.want == nil) || gen.Usage != nil && *gen.Usage != *tt.want {
+			t.Errorf("Unmarshal(%s) usage = %+v, want %+v", tt.in, gen.Usage, tt.want)
+		}
+	}
+
+	var gen Generation
+	if err := json.Unmarshal([]byte(`{"usage":"lots"}`), &gen); err == nil {
+		t.Error("Unmarshal accepted a string usage")
+	}
+}
+
+func TestGenerationTypedFields(t *testing.T) {
+	data := `{
+		"id": "gen-1",
+		"total_cost": 0.01,
+		"usage": 0.01,
+		"provider_name": "OpenAI",
+		"upstream_id": "chatcmpl-1",
+		"latency": 412.5,
+		"moderation_latency": 30,
+		"generation_time": 1800,
+		"finish_reason": "stop",
+		"native_finish_reason": "end_turn",
+		"streamed": true,
+		"cancelled": true,
+		"is_byok": true,
+		"tokens_prompt": 12,
+		"native_tokens_reasoning": 64
+	}`
+	var gen Generation
+	if err := json.Unmarshal([]byte(data), &gen); err != nil {
+		t.Fatal(err)
+	}
+
+	if gen.Cost() != 0.01 {
+		t.Errorf("Cost() = %v", gen.Cost())
+	}
+	if gen.ProviderLatency() != 412500*time.Microsecond {
+		t.Errorf("ProviderLatency() = %v", gen.ProviderLatency())
+	}
+	if gen.ModerationLatency() != 30*time.Millisecond {
+		t.Errorf("ModerationLatency() = %v", gen.ModerationLatency())
+	}
+	if gen.NativeFinishReason != "end_turn" || !gen.Cancelled || !gen.IsBYOK || !gen.Streamed {
+		t.Errorf("flags not decoded: %+v", gen)
+	}
+	if gen.TokensPrompt != 12 || gen.NativeTokensReasoning != 64 || gen.UpstreamID != "chatcmpl-1" {
+		t.Errorf("token counts not decoded: %+v", gen)
+	}
+
+	// Older responses only report the cost in usage, and latency in metrics
+	gen = Generation{Usage: &GenerationUsage{TotalCost: 0.5}, Metrics: GenerationMetrics{LatencyMS: 200}}
+	if gen.Cost() != 0.5 || gen.ProviderLatency() != 200*time.Millisecond {
+		t.Errorf("fallbacks: Cost() = %v, ProviderLatency() = %v", gen.Cost(), gen.ProviderLatency())
+	}
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// hashVersion is mixed into every hash. Bump it only when canonicalization must change,
+// since doing so invalidates every stored hash.
+const hashVersion = "openrouter-request-v1\n"
+
+// ignoredHashFields do not change what a model generates
+var ignoredHashFields = []string{"user", "stream", "stream_options", "usage"}
+
+// HashRequest returns a stable hex-encoded SHA-256 hash of the semantically relevant
+// fields of req. Requests that differ only in the end-user ID, streaming options, usage
+// accounting, JSON formatting, or whether single-part text content is a string or a
+// part list hash the same. Hashes are stable across releases, so they can key caches,
+// deduplication, and experiment buckets.
+func HashRequest(req ChatCompletionRequest) (string, error) {
+	canonical, err := CanonicalRequest(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(hashVersion), canonical...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// CanonicalRequest returns the canonical JSON encoding hashed by HashRequest, with
+// object keys sorted and fields that do not affect generation removed
+func CanonicalRequest(req ChatCompletionRequest) ([]byte, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var value map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	for _, field := range ignoredHashFields {
+		delete(value, field)
+	}
+	if messages, ok := value["messages"].([]interface{}); ok {
+		for _, message := range messages {
+			if m, ok := message.(map[string]interface{}); ok {
+				canonicalMessage(m)
+			}
+		}
+	}
+
+	// Maps are encoded with sorted keys
+	return json.Marshal(value)
+}
+
+// canonicalMessage normalizes equivalent encodings of a message in place
+func canonicalMessage(message map[string]interface{}) {
+	// A single text part is equivalent to string content
+	if parts, ok := message["content"].([]interface{}); ok && len(parts) == 1 {
+		if part, ok := parts[0].(map[string]interface{}); ok && part["type"] == string(ContentTypeText) {
+			if text, ok := part["text"].(string); ok && len(part) == 2 {
+				message["content"] = text
+			}
+		}
+	}
+
+	toolCalls, _ := message["tool_calls"].([]interface{})
+	for _, toolCall := range toolCalls {
+		call, ok := toolCall.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// Indexes only identify streamed fragments
+		delete(call, "index")
+
+		function, _ := call["function"].(map[string]interface{})
+		if args, ok := function["arguments"].(string); ok {
+			function["arguments"] = canonicalJSONString(args)
+		}
+	}
+}
+
+// canonicalJSONString re-encodes a string holding JSON with sorted keys and no
+// whitespace, returning it unchanged if it is not valid JSON
+func canonicalJSONString(s string) string {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(s)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return s
+	}
+	return string(data)
+}
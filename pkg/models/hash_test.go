@@ -0,0 +1,127 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func hashFixture() ChatCompletionRequest {
+	return NewChatRequest("openai/gpt-4o",
+		WithSystemMessage("You are terse."),
+		WithUserMessage("What's the weather in Paris?"),
+		WithTemperature(0.2),
+		WithMaxTokens(100),
+		WithTools(Tool{
+			Type: "function",
+			Function: FunctionDescription{
+				Name:       "get_weather",
+				Parameters: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}}}`),
+			},
+		}),
+	)
+}
+
+// TestHashRequestGolden pins hashes so they stay stable across releases. If this test
+// fails, a change altered canonicalization and would invalidate stored hashes.
+func TestHashRequestGolden(t *testing.T) {
+	toolCallReq := hashFixture()
+	toolCallReq.Messages = append(toolCallReq.Messages,
+		Message{
+			Role:      RoleAssistant,
+			Content:   json.RawMessage(`null`),
+			ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}}},
+		},
+		NewToolMessage("call_1", "get_weather", `{"temp":21}`),
+	)
+
+	tests := []struct {
+		name string
+		req  ChatCompletionRequest
+		want string
+	}{
+		{"minimal", NewChatRequest("openai/gpt-4o-mini", WithUserMessage("Hi")), "46d8c166cb600c670ed16ea9989a297a8de464f48650d1ffd04dfce1997e081d"},
+		{"full", hashFixture(), "f81e0d17fd537dbcb23a3ebaff53b3a0d059b19fefe1a251bd39a5275f66a789"},
+		{"tool calls", toolCallReq, "7c70954426117001b75ca74eaa98e0139a4bc2a53bdd59d44d40616baf1689a2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HashRequest(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("HashRequest() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashRequestIgnoresIrrelevantDifferences(t *testing.T) {
+	base := hashFixture()
+	want, err := HashRequest(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withUser := hashFixture()
+	withUser.User = "user-123"
+	withUser.Stream = true
+	withUser.Usage = &UsageConfig{Include: true}
+
+	parts := hashFixture()
+	parts.Messages[1] = User(Text("What's the weather in Paris?"))
+	parts.Messages[1].Content = json.RawMessage(`[{"type":"text","text":"What's the weather in Paris?"}]`)
+
+	formatted := hashFixture()
+	formatted.Messages[0].Content = json.RawMessage(`  "You are terse."  `)
+	formatted.Tools[0].Function.Parameters = json.RawMessage(`{
+		"properties": {"city": {"type": "string"}},
+		"type": "object"
+	}`)
+
+	for name, req := range map[string]ChatCompletionRequest{
+		"user, stream, and usage": withUser,
+		"text part list":          parts,
+		"JSON formatting":         formatted,
+	} {
+		got, err := HashRequest(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s: hash changed", name)
+		}
+	}
+}
+
+func TestHashRequestDetectsRelevantDifferences(t *testing.T) {
+	base := hashFixture()
+	want, err := HashRequest(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := hashFixture()
+	model.Model = "openai/gpt-4o-mini"
+
+	temperature := hashFixture()
+	temperature.Temperature = Ptr(0.3)
+
+	message := hashFixture()
+	message.Messages[1] = NewTextMessage(RoleUser, "What's the weather in Lyon?")
+
+	for name, req := range map[string]ChatCompletionRequest{
+		"model":       model,
+		"temperature": temperature,
+		"message":     message,
+	} {
+		got, err := HashRequest(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == want {
+			t.Errorf("%s: hash did not change", name)
+		}
+	}
+}
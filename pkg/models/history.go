@@ -0,0 +1,99 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// HistoryVersion is the current version of the conversation history format
+const HistoryVersion = 1
+
+// ErrUnsupportedHistoryVersion is returned for history written by a newer SDK
+var ErrUnsupportedHistoryVersion = errors.New("unsupported history version")
+
+// History is the serialized form of a conversation. Messages use the same JSON
+// representation as API requests, so tool calls, annotations, reasoning, and
+// multi-part content are preserved as sent or received.
+type History struct {
+	Version  int       `json:"version"`
+	Messages []Message `json:"messages"`
+}
+
+// historyMigrations upgrade serialized history from the keyed version to the next one
+var historyMigrations = map[int]func(json.RawMessage) (json.RawMessage, error){
+	0: migrateHistoryV0,
+}
+
+// MarshalHistory serializes a conversation for persistence
+func MarshalHistory(messages []Message) ([]byte, error) {
+	if messages == nil {
+		messages = []Message{}
+	}
+	return json.Marshal(History{Version: HistoryVersion, Messages: messages})
+}
+
+// UnmarshalHistory restores a conversation saved with MarshalHistory by this or an
+// earlier version of the SDK, migrating older formats as needed
+func UnmarshalHistory(data []byte) ([]Message, error) {
+	raw := json.RawMessage(bytes.TrimSpace(data))
+	version, err := historyVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if version > HistoryVersion {
+		return nil, fmt.Errorf("%w: %d (latest supported is %d)", ErrUnsupportedHistoryVersion, version, HistoryVersion)
+	}
+
+	for ; version < HistoryVersion; version++ {
+		migrate, ok := historyMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: no migration from version %d", ErrUnsupportedHistoryVersion, version)
+		}
+		if raw, err = migrate(raw); err != nil {
+			return nil, fmt.Errorf("failed to migrate history from version %d: %w", version, err)
+		}
+	}
+
+	var history History
+	if err := json.Unmarshal(raw, &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal history: %w", err)
+	}
+	return history.Messages, nil
+}
+
+// historyVersion returns the version of serialized history. A bare message array,
+// as produced by json.Marshal before the envelope existed, is version 0.
+func historyVersion(raw json.RawMessage) (int, error) {
+	if len(raw) > 0 && raw[0] == '[' {
+		return 0, nil
+	}
+
+	var envelope struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal history: %w", err)
+	}
+	if envelope.Version == nil {
+		return 0, fmt.Errorf("failed to unmarshal history: missing version")
+	}
+	return *envelope.Version, nil
+}
+
+// migrateHistoryV0 wraps a bare message array in the versioned envelope
+func migrateHistoryV0(raw json.RawMessage) (json.RawMessage, error) {
+	var messages []json.RawMessage
+	if err := json.Unmarshal(raw, &messages); err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		messages = []json.RawMessage{}
+	}
+	return json.Marshal(map[string]interface{}{
+		"version":  1,
+		"messages": messages,
+	})
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func historyFixture() []Message {
+	args := `{"city":"Paris"}`
+	refusal := "I can't help with that."
+	return []Message{
+		NewTextMessage(RoleSystem, "You are a helpful assistant."),
+		User(Text("What's in this image?"), Image("https://example.com/cat.jpg", "high")),
+		{
+			Role:      RoleAssistant,
+			Content:   json.RawMessage(`null`),
+			ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: args}}},
+			Reasoning: "The user wants the weather.",
+		},
+		NewToolMessage("call_1", "get_weather", `{"temp":21}`),
+		{
+			Role:    RoleAssistant,
+			Content: json.RawMessage(`"It's 21°C in Paris."`),
+			Annotations: []Annotation{{
+				Type:        AnnotationTypeURLCitation,
+				URLCitation: &URLCitation{URL: "https://example.com", Title: "Weather", StartIndex: 0, EndIndex: 10},
+			}},
+			Refusal: &refusal,
+		},
+	}
+}
+
+func TestHistoryRoundTrip(t *testing.T) {
+	messages := historyFixture()
+
+	data, err := MarshalHistory(messages)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	restored, err := UnmarshalHistory(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(restored, messages) {
+		t.Errorf("history changed in round trip:\nwant %+v\ngot  %+v", messages, restored)
+	}
+}
+
+func TestUnmarshalHistoryMigratesBareArray(t *testing.T) {
+	messages := historyFixture()
+	data, _ := json.Marshal(messages)
+
+	restored, err := UnmarshalHistory(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(restored, messages) {
+		t.Errorf("history changed in migration:\nwant %+v\ngot  %+v", messages, restored)
+	}
+}
+
+func TestUnmarshalHistoryRejectsNewerVersion(t *testing.T) {
+	_, err := UnmarshalHistory([]byte(`{"version":99,"messages":[]}`))
+	if !errors.Is(err, ErrUnsupportedHistoryVersion) {
+		t.Errorf("expected ErrUnsupportedHistoryVersion, got %v", err)
+	}
+}
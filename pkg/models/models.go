@@ -9,7 +9,7 @@ type ModelsResponse struct {
 type Model struct {
 	ID                  string       `json:"id"`
 	Name                string       `json:"name"`
-	CreatedAt           int64        `json:"created"`
+	CreatedAt           UnixTime     `json:"created"`
 	Description         string       `json:"description,omitempty"`
 	ContextLength       int          `json:"context_length"`
 	Pricing             Pricing      `json:"pricing"`
@@ -40,3 +40,14 @@ type Architecture struct {
 	Tokenizer    string `json:"tokenizer,omitempty"`
 	InstructType string `json:"instruct_type,omitempty"`
 }
+
+// SupportsParameter reports whether the model lists name, e.g. "tools" or
+// "response_format", among its supported parameters
+func (m Model) SupportsParameter(name string) bool {
+	for _, param := range m.SupportedParams {
+		if param == name {
+			return true
+		}
+	}
+	return false
+}
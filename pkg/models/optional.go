@@ -0,0 +1,20 @@
+package models
+
+// Optional request parameters are pointers so an unset field is omitted from the
+// request while an explicit zero, such as a temperature of 0, is still sent.
+
+// Ptr returns a pointer to v, for setting optional fields inline:
+//
+//	req.Temperature = models.Ptr(0.0)
+//	req.MaxTokens = models.Ptr(300)
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// Value returns the value p points to, or def when p is nil
+func Value[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
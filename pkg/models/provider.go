@@ -1,5 +1,10 @@
 package models
 
+import (
+	"sort"
+	"sync"
+)
+
 // ProviderPreferences represents provider routing preferences
 type ProviderPreferences struct {
 	// List of provider slugs to try in order
@@ -14,6 +19,9 @@ type ProviderPreferences struct {
 	// Data collection policy
 	DataCollection DataCollectionPolicy `json:"data_collection,omitempty"`
 
+	// Only use endpoints with a zero data retention policy
+	ZDR *bool `json:"zdr,omitempty"`
+
 	// List of provider slugs to allow
 	Only []string `json:"only,omitempty"`
 
@@ -53,6 +61,47 @@ const (
 	QuantizationUnknown QuantizationLevel = "unknown"
 )
 
+var (
+	quantizationsMu sync.RWMutex
+	quantizations   = map[QuantizationLevel]bool{
+		QuantizationInt4: true, QuantizationInt8: true, QuantizationFP4: true,
+		QuantizationFP6: true, QuantizationFP8: true, QuantizationFP16: true,
+		QuantizationBF16: true, QuantizationFP32: true, QuantizationUnknown: true,
+	}
+)
+
+// KnownQuantizations returns the quantization levels Validate accepts, sorted
+func KnownQuantizations() []QuantizationLevel {
+	quantizationsMu.RLock()
+	defer quantizationsMu.RUnlock()
+	levels := make([]QuantizationLevel, 0, len(quantizations))
+	for level := range quantizations {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+	return levels
+}
+
+// RegisterQuantizations adds levels to the ones Validate accepts. Levels reported by
+// model endpoints are registered as they are listed, so new levels OpenRouter
+// introduces are accepted without an update.
+func RegisterQuantizations(levels ...QuantizationLevel) {
+	quantizationsMu.Lock()
+	defer quantizationsMu.Unlock()
+	for _, level := range levels {
+		if level != "" {
+			quantizations[level] = true
+		}
+	}
+}
+
+// IsKnown reports whether the level is known to Validate
+func (q QuantizationLevel) IsKnown() bool {
+	quantizationsMu.RLock()
+	defer quantizationsMu.RUnlock()
+	return quantizations[q]
+}
+
 // SortStrategy represents how to sort providers
 type SortStrategy string
 
@@ -62,6 +111,15 @@ const (
 	SortByLatency    SortStrategy = "latency"
 )
 
+// IsKnown reports whether the strategy is one OpenRouter accepts
+func (s SortStrategy) IsKnown() bool {
+	switch s {
+	case SortByPrice, SortByThroughput, SortByLatency:
+		return true
+	}
+	return false
+}
+
 // MaxPrice represents maximum price limits
 type MaxPrice struct {
 	// Maximum price per million prompt tokens
@@ -106,6 +164,12 @@ func (p *ProviderPreferences) WithDataCollection(policy DataCollectionPolicy) *P
 	return p
 }
 
+// WithZDR sets whether to require zero data retention endpoints
+func (p *ProviderPreferences) WithZDR(require bool) *ProviderPreferences {
+	p.ZDR = &require
+	return p
+}
+
 // WithOnly sets the list of allowed providers
 func (p *ProviderPreferences) WithOnly(providers ...string) *ProviderPreferences {
 	p.Only = providers
@@ -138,3 +202,50 @@ func (p *ProviderPreferences) WithMaxPrice(prompt, completion float64) *Provider
 	}
 	return p
 }
+
+// Merge returns a copy of p with every field set in override replacing p's value, so
+// override can adjust a preset for one request. Either may be nil.
+func (p *ProviderPreferences) Merge(override *ProviderPreferences) *ProviderPreferences {
+	if p == nil && override == nil {
+		return nil
+	}
+	merged := ProviderPreferences{}
+	if p != nil {
+		merged = *p
+	}
+	if override == nil {
+		return &merged
+	}
+
+	if override.Order != nil {
+		merged.Order = override.Order
+	}
+	if override.AllowFallbacks != nil {
+		merged.AllowFallbacks = override.AllowFallbacks
+	}
+	if override.RequireParameters != nil {
+		merged.RequireParameters = override.RequireParameters
+	}
+	if override.DataCollection != "" {
+		merged.DataCollection = override.DataCollection
+	}
+	if override.ZDR != nil {
+		merged.ZDR = override.ZDR
+	}
+	if override.Only != nil {
+		merged.Only = override.Only
+	}
+	if override.Ignore != nil {
+		merged.Ignore = override.Ignore
+	}
+	if override.Quantizations != nil {
+		merged.Quantizations = override.Quantizations
+	}
+	if override.Sort != "" {
+		merged.Sort = override.Sort
+	}
+	if override.MaxPrice != nil {
+		merged.MaxPrice = override.MaxPrice
+	}
+	return &merged
+}
@@ -0,0 +1,49 @@
+package models
+
+// EUProviders lists the slugs of providers that serve requests from the EU. It is used by
+// PreferencesEUOnly and can be changed to match a compliance review. pkg.RegionRouter
+// checks each model's endpoints instead.
+var EUProviders = []string{"mistral", "nebius", "scaleway"}
+
+// Provider preference presets return new preferences for common routing policies. Each
+// call returns a fresh value, so the result can be changed or merged freely:
+//
+//	prefs := models.PreferencesCheapest().WithMaxPrice(1, 2)
+
+// PreferencesPrivacyStrict routes only to endpoints that neither store nor train on
+// prompts: data collection is denied and zero data retention is required
+func PreferencesPrivacyStrict() *ProviderPreferences {
+	return NewProviderPreferences().
+		WithDataCollection(DataCollectionDeny).
+		WithZDR(true)
+}
+
+// PreferencesCheapest routes to the lowest priced provider first, falling back to others
+func PreferencesCheapest() *ProviderPreferences {
+	return NewProviderPreferences().
+		WithSort(SortByPrice).
+		WithFallbacks(true)
+}
+
+// PreferencesLowLatency routes to the provider with the lowest latency first, falling
+// back to others
+func PreferencesLowLatency() *ProviderPreferences {
+	return NewProviderPreferences().
+		WithSort(SortByLatency).
+		WithFallbacks(true)
+}
+
+// PreferencesFullPrecision routes only to endpoints serving unquantized weights
+func PreferencesFullPrecision() *ProviderPreferences {
+	return NewProviderPreferences().
+		WithQuantizations(QuantizationFP16, QuantizationBF16, QuantizationFP32)
+}
+
+// PreferencesEUOnly routes only to EUProviders, with data collection denied and no
+// fallback to other providers
+func PreferencesEUOnly() *ProviderPreferences {
+	return NewProviderPreferences().
+		WithOnly(append([]string(nil), EUProviders...)...).
+		WithDataCollection(DataCollectionDeny).
+		WithFallbacks(false)
+}
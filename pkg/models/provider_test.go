@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProviderPreferencesMerge(t *testing.T) {
+	base := PreferencesPrivacyStrict().WithSort(SortByPrice)
+	override := NewProviderPreferences().WithSort(SortByLatency).WithOrder("together")
+
+	merged := base.Merge(override)
+	if merged.Sort != SortByLatency || !reflect.DeepEqual(merged.Order, []string{"together"}) {
+		t.Errorf("override fields not applied: %+v", merged)
+	}
+	if merged.DataCollection != DataCollectionDeny || merged.ZDR == nil || !*merged.ZDR {
+		t.Errorf("base fields not kept: %+v", merged)
+	}
+	if base.Sort != SortByPrice || base.Order != nil {
+		t.Errorf("Merge modified the base: %+v", base)
+	}
+
+	var nilPrefs *ProviderPreferences
+	if nilPrefs.Merge(nil) != nil {
+		t.Error("Merge of nil preferences should be nil")
+	}
+	if got := nilPrefs.Merge(override); !reflect.DeepEqual(got, override) || got == override {
+		t.Errorf("Merge onto nil = %+v, want a copy of the override", got)
+	}
+}
+
+func TestProviderPresets(t *testing.T) {
+	tests := []struct {
+		prefs *ProviderPreferences
+		want  string
+	}{
+		{PreferencesPrivacyStrict(), `{"data_collection":"deny","zdr":true}`},
+		{PreferencesCheapest(), `{"allow_fallbacks":true,"sort":"price"}`},
+		{PreferencesLowLatency(), `{"allow_fallbacks":true,"sort":"latency"}`},
+		{PreferencesFullPrecision(), `{"quantizations":["fp16","bf16","fp32"]}`},
+		{PreferencesEUOnly(), `{"allow_fallbacks":false,"data_collection":"deny","only":["mistral","nebius","scaleway"]}`},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.prefs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("preset = %s, want %s", data, tt.want)
+		}
+	}
+
+	PreferencesEUOnly().Only[0] = "changed"
+	if EUProviders[0] == "changed" {
+		t.Error("PreferencesEUOnly shares EUProviders")
+	}
+}
+
+func TestModelEndpointInRegion(t *testing.T) {
+	tests := []struct {
+		region, want string
+		in           bool
+	}{
+		{"eu-west-1", RegionEU, true},
+		{"Europe-North1", RegionEU, true},
+		{"us-east-1", RegionEU, false},
+		{"us-east-1", RegionUS, true},
+		{"", RegionUS, false},
+		{"us", "", false},
+	}
+	for _, tt := range tests {
+		if got := (ModelEndpoint{Region: tt.region}).InRegion(tt.want); got != tt.in {
+			t.Errorf("InRegion(%q) for %q = %v, want %v", tt.want, tt.region, got, tt.in)
+		}
+	}
+}
+
+func TestValidateProviderEnums(t *testing.T) {
+	req := NewChatRequest("m", WithUserMessage("hi"), WithProvider(NewProviderPreferences().
+		WithQuantizations(QuantizationFP8, "fp3").
+		WithSort("cheapest")))
+
+	err := req.Validate()
+	if err == nil || !strings.Contains(err.Error(), `unknown provider quantization "fp3"`) ||
+		!strings.Contains(err.Error(), `unknown provider sort "cheapest"`) {
+		t.Fatalf("Validate() = %v, want unknown quantization and sort", err)
+	}
+
+	RegisterQuantizations("fp3")
+	req.Provider.Sort = SortByThroughput
+	if err := req.Validate(); err != nil {
+		t.Errorf("Validate() after registering = %v", err)
+	}
+}
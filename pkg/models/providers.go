@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 // Provider represents an AI provider
 type Provider struct {
 	ID          string   `json:"id"`
@@ -24,6 +26,23 @@ type ModelEndpoint struct {
 	Latency    int    `json:"latency"`    // milliseconds
 	Throughput int    `json:"throughput"` // requests per second
 	Status     string `json:"status"`     // healthy, degraded, unhealthy
+
+	// Quantization is the precision of the weights the endpoint serves
+	Quantization QuantizationLevel `json:"quantization,omitempty"`
+}
+
+// Regions accepted by ModelEndpoint.InRegion
+const (
+	RegionEU = "eu"
+	RegionUS = "us"
+)
+
+// InRegion reports whether the endpoint is hosted in region, such as RegionEU. Regions
+// match by prefix regardless of case, so "eu" matches "eu-west-1" and "europe-west4".
+// Endpoints without region information are in no region.
+func (e ModelEndpoint) InRegion(region string) bool {
+	region = strings.ToLower(region)
+	return region != "" && strings.HasPrefix(strings.ToLower(e.Region), region)
 }
 
 // ModelEndpointsResponse represents the response from listing model endpoints
@@ -0,0 +1,142 @@
+package models
+
+import "time"
+
+// reproBundleVersion is the format version written into every ReproBundle
+const reproBundleVersion = 1
+
+// WithReproducibility makes sampling as repeatable as providers allow: it sets the seed,
+// a temperature of 0 and a top_p of 1. Providers don't guarantee identical output even
+// so; capture a ReproBundle to detect when it changes.
+func WithReproducibility(seed int) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		temperature, topP := 0.0, 1.0
+		r.Seed = &seed
+		r.Temperature = &temperature
+		r.TopP = &topP
+	}
+}
+
+// ReproBundle records everything needed to replay a request and tell whether the result
+// drifted: the full request and the model version, provider and system fingerprint that
+// served it. It is plain JSON, so it can be saved next to a test fixture or a bug report.
+type ReproBundle struct {
+	Version int `json:"version"`
+
+	// Request is the request as sent
+	Request ChatCompletionRequest `json:"request"`
+
+	// RequestHash is HashRequest of Request
+	RequestHash string `json:"request_hash"`
+
+	// Model is the exact model version that served the request, which may be more
+	// specific than Request.Model
+	Model             string `json:"model"`
+	Provider          string `json:"provider,omitempty"`
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
+
+	Response   *ChatCompletionResponse `json:"response"`
+	CapturedAt time.Time               `json:"captured_at"`
+}
+
+// NewReproBundle captures a request and its response
+func NewReproBundle(req ChatCompletionRequest, resp *ChatCompletionResponse) (*ReproBundle, error) {
+	hash, err := HashRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return &ReproBundle{
+		Version:           reproBundleVersion,
+		Request:           req,
+		RequestHash:       hash,
+		Model:             resp.Model,
+		Provider:          resp.Provider,
+		SystemFingerprint: resp.SystemFingerprint,
+		Response:          resp,
+		CapturedAt:        time.Now().UTC(),
+	}, nil
+}
+
+// ReplayRequest returns the bundled request pinned to the model version and provider
+// that originally served it, without fallbacks
+func (b *ReproBundle) ReplayRequest() ChatCompletionRequest {
+	req := b.Request
+	if b.Model != "" {
+		req.Model, req.Models, req.Route = b.Model, nil, ""
+	}
+	if b.Provider != "" {
+		prefs := ProviderPreferences{}
+		if req.Provider != nil {
+			prefs = *req.Provider
+		}
+		allowFallbacks := false
+		prefs.Order = []string{b.Provider}
+		prefs.AllowFallbacks = &allowFallbacks
+		req.Provider = &prefs
+	}
+	return req
+}
+
+// ReproDiff describes how a replayed response differs from the bundled one
+type ReproDiff struct {
+	ModelChanged       bool
+	ProviderChanged    bool
+	FingerprintChanged bool
+
+	// ContentChanged reports whether the text or tool calls of any choice differ
+	ContentChanged bool
+}
+
+// Drifted reports whether anything differs
+func (d ReproDiff) Drifted() bool {
+	return d.ModelChanged || d.ProviderChanged || d.FingerprintChanged || d.ContentChanged
+}
+
+// Compare compares a replayed response with the bundled one. Fingerprints are only
+// compared when both responses have one.
+func (b *ReproBundle) Compare(resp *ChatCompletionResponse) ReproDiff {
+	diff := ReproDiff{
+		ModelChanged:    resp.Model != b.Model,
+		ProviderChanged: b.Provider != "" && resp.Provider != b.Provider,
+	}
+	if b.SystemFingerprint != "" && resp.SystemFingerprint != "" {
+		diff.FingerprintChanged = resp.SystemFingerprint != b.SystemFingerprint
+	}
+	if b.Response != nil {
+		diff.ContentChanged = !sameChoices(b.Response.Choices, resp.Choices)
+	}
+	return diff
+}
+
+// sameChoices compares the generated text and tool calls of two sets of choices
+func sameChoices(a, b []Choice) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if (a[i].Message == nil) != (b[i].Message == nil) {
+			return false
+		}
+		if a[i].Message == nil {
+			continue
+		}
+		textA, _ := a[i].Message.GetTextContent()
+		textB, _ := b[i].Message.GetTextContent()
+		if textA != textB || !sameToolCalls(a[i].Message.ToolCalls, b[i].Message.ToolCalls) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameToolCalls(a, b []ToolCall) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Function.Name != b[i].Function.Name || a[i].Function.Arguments != b[i].Function.Arguments {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package models
+
+import "testing"
+
+func TestWithReproducibility(t *testing.T) {
+	req := NewChatRequest("m", WithUserMessage("Hi"), WithTemperature(0.9), WithReproducibility(42))
+	if req.Seed == nil || *req.Seed != 42 {
+		t.Errorf("seed = %v, want 42", req.Seed)
+	}
+	if req.Temperature == nil || *req.Temperature != 0 {
+		t.Errorf("temperature = %v, want 0", req.Temperature)
+	}
+	if req.TopP == nil || *req.TopP != 1 {
+		t.Errorf("top_p = %v, want 1", req.TopP)
+	}
+}
+
+func TestReproBundleReplayAndCompare(t *testing.T) {
+	req := NewChatRequest("openai/gpt-4o", WithUserMessage("Hi"), WithFallbackModels("a", "b"))
+	resp := &ChatCompletionResponse{
+		Model:             "openai/gpt-4o-2024-08-06",
+		Provider:          "OpenAI",
+		SystemFingerprint: "fp_1",
+		Choices:           []Choice{{Message: &Message{Role: RoleAssistant, Content: []byte(`"Hello"`)}}},
+	}
+	bundle, err := NewReproBundle(req, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replay := bundle.ReplayRequest()
+	if replay.Model != "openai/gpt-4o-2024-08-06" || replay.Models != nil {
+		t.Errorf("replay model = %q %v, want the served version only", replay.Model, replay.Models)
+	}
+	if replay.Provider == nil || len(replay.Provider.Order) != 1 || replay.Provider.Order[0] != "OpenAI" ||
+		replay.Provider.AllowFallbacks == nil || *replay.Provider.AllowFallbacks {
+		t.Errorf("replay provider = %+v, want pinned to OpenAI", replay.Provider)
+	}
+	if req.Provider != nil {
+		t.Error("ReplayRequest modified the bundled request")
+	}
+
+	if diff := bundle.Compare(resp); diff.Drifted() {
+		t.Errorf("Compare(same) = %+v, want no drift", diff)
+	}
+	changed := *resp
+	changed.SystemFingerprint = "fp_2"
+	changed.Choices = []Choice{{Message: &Message{Role: RoleAssistant, Content: []byte(`"Hi there"`)}}}
+	diff := bundle.Compare(&changed)
+	if !diff.FingerprintChanged || !diff.ContentChanged || diff.ModelChanged || diff.ProviderChanged {
+		t.Errorf("Compare(changed) = %+v", diff)
+	}
+}
@@ -0,0 +1,278 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// RequestOption configures a request built with NewChatRequest
+type RequestOption func(*ChatCompletionRequest)
+
+// NewChatRequest builds a chat completion request for model. The struct can still be
+// modified directly for parameters without an option.
+//
+//	req := models.NewChatRequest("openai/gpt-4o",
+//		models.WithMessages(models.NewTextMessage(models.RoleUser, "Hi")),
+//		models.WithTemperature(0.2),
+//		models.WithMaxTokens(300),
+//	)
+func NewChatRequest(model string, opts ...RequestOption) ChatCompletionRequest {
+	req := ChatCompletionRequest{Model: model}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	return req
+}
+
+// WithMessages appends messages to the conversation
+func WithMessages(messages ...Message) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Messages = append(r.Messages, messages...)
+	}
+}
+
+// WithSystemMessage prepends a system message to the conversation
+func WithSystemMessage(text string) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Messages = append([]Message{NewTextMessage(RoleSystem, text)}, r.Messages...)
+	}
+}
+
+// WithUserMessage appends a user message to the conversation
+func WithUserMessage(text string) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Messages = append(r.Messages, NewTextMessage(RoleUser, text))
+	}
+}
+
+// WithFallbackModels sets the models to try, in order, if the primary model is
+// unavailable. See ChatCompletionRequest.WithFallbackModels.
+func WithFallbackModels(models ...string) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.WithFallbackModels(models...)
+	}
+}
+
+// WithProvider sets the provider routing preferences
+func WithProvider(provider *ProviderPreferences) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Provider = provider
+	}
+}
+
+// WithMaxTokens sets the maximum number of tokens to generate
+func WithMaxTokens(maxTokens int) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.MaxTokens = &maxTokens
+	}
+}
+
+// WithTemperature sets the sampling temperature
+func WithTemperature(temperature float64) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Temperature = &temperature
+	}
+}
+
+// WithTopP sets nucleus sampling
+func WithTopP(topP float64) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.TopP = &topP
+	}
+}
+
+// WithTopK sets top-k sampling
+func WithTopK(topK int) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.TopK = &topK
+	}
+}
+
+// WithFrequencyPenalty sets the frequency penalty
+func WithFrequencyPenalty(penalty float64) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.FrequencyPenalty = &penalty
+	}
+}
+
+// WithPresencePenalty sets the presence penalty
+func WithPresencePenalty(penalty float64) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.PresencePenalty = &penalty
+	}
+}
+
+// WithRepetitionPenalty sets the repetition penalty
+func WithRepetitionPenalty(penalty float64) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.RepetitionPenalty = &penalty
+	}
+}
+
+// WithSeed sets the seed for deterministic sampling
+func WithSeed(seed int) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Seed = &seed
+	}
+}
+
+// WithStop sets the stop sequences
+func WithStop(stop ...string) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Stop = stop
+	}
+}
+
+// WithTools sets the tools the model may call
+func WithTools(tools ...Tool) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Tools = tools
+	}
+}
+
+// WithToolChoice sets how the model chooses tools
+func WithToolChoice(choice ToolChoice) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.ToolChoice = choice
+	}
+}
+
+// WithParallelToolCalls allows or forbids several tool calls in one response
+func WithParallelToolCalls(enabled bool) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.ParallelToolCalls = &enabled
+	}
+}
+
+// WithPlugins sets the plugins to enable, e.g. NewWebPlugin()
+func WithPlugins(plugins ...Plugin) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Plugins = plugins
+	}
+}
+
+// WithReasoning sets the reasoning configuration
+func WithReasoning(reasoning *ReasoningConfig) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Reasoning = reasoning
+	}
+}
+
+// WithUser sets the end-user identifier
+func WithUser(user string) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.User = user
+	}
+}
+
+// WithTransforms sets the prompt transforms, e.g. "middle-out"
+func WithTransforms(transforms ...string) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Transforms = transforms
+	}
+}
+
+// WithExtra sets a provider-specific parameter that has no field, see
+// ChatCompletionRequest.Extra
+func WithExtra(key string, value any) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		if r.Extra == nil {
+			r.Extra = make(map[string]any)
+		}
+		r.Extra[key] = value
+	}
+}
+
+// WithUsageAccounting asks for cost and detailed token counts in the response usage
+func WithUsageAccounting() RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.Usage = &UsageConfig{Include: true}
+	}
+}
+
+// WithStreamUsage asks a stream for a final chunk carrying token usage. The chunk has no
+// choices, so readers must check len(chunk.Choices) before indexing.
+func WithStreamUsage() RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+}
+
+// WithJSONMode asks for a JSON object response without a schema
+func WithJSONMode() RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.ResponseFormat = &ResponseFormat{Type: "json_object"}
+	}
+}
+
+// WithGrammar constrains the response to a GBNF grammar. Only some providers, such as
+// Fireworks, support grammars; route to them with provider preferences.
+func WithGrammar(grammar string) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.ResponseFormat = &ResponseFormat{Type: "grammar", Grammar: grammar}
+	}
+}
+
+// WithRegex constrains the response to match a regular expression. Only some providers
+// support it; pkg.Client.CreateConstrainedCompletion checks the output on the client too.
+func WithRegex(pattern string) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.ResponseFormat = &ResponseFormat{Type: "regex", Pattern: pattern}
+	}
+}
+
+// WithJSONSchema asks for a response matching a strict JSON schema. The schema can be a
+// Go value whose type describes the response, such as WeatherInfo{}, a map, or raw JSON.
+// The schema is named after the Go type.
+func WithJSONSchema(schema interface{}) RequestOption {
+	return WithNamedJSONSchema(schemaName(schema), schema)
+}
+
+// WithNamedJSONSchema is like WithJSONSchema with an explicit schema name
+func WithNamedJSONSchema(name string, schema interface{}) RequestOption {
+	return func(r *ChatCompletionRequest) {
+		r.ResponseFormat = &ResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &JSONSchema{
+				Name:   name,
+				Strict: true,
+				Schema: schemaJSON(schema),
+			},
+		}
+	}
+}
+
+// schemaName derives a schema name from the type of v, falling back to "response"
+func schemaName(v interface{}) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Name() == "" || t.Kind() == reflect.Map || t == reflect.TypeOf(json.RawMessage(nil)) {
+		return "response"
+	}
+	return strings.ToLower(t.Name())
+}
+
+// schemaJSON encodes a schema given as raw JSON, a map, or a Go value to generate it from
+func schemaJSON(v interface{}) json.RawMessage {
+	switch s := v.(type) {
+	case json.RawMessage:
+		return s
+	case []byte:
+		return s
+	case string:
+		return json.RawMessage(s)
+	case map[string]interface{}:
+		data, _ := json.Marshal(s)
+		return data
+	}
+
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return json.RawMessage(`{"type":"object"}`)
+	}
+	data, _ := json.Marshal(generateFieldSchema(t))
+	return data
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GenerateSchema generates a JSON schema from a Go struct
+func GenerateSchema(v interface{}) (map[string]interface{}, error) {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("input must be a struct or pointer to struct")
+	}
+
+	return generateSchemaFromType(t), nil
+}
+
+func generateSchemaFromType(t reflect.Type) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           make(map[string]interface{}),
+		"required":             []string{},
+		"additionalProperties": false,
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported fields
+		if field.PkgPath != "" {
+			continue
+		}
+
+		// Get JSON tag
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+
+		fieldName := field.Name
+		if jsonTag != "" {
+			fieldName = jsonTag
+			// Handle omitempty
+			if idx := len(fieldName); idx > 10 && fieldName[idx-10:] == ",omitempty" {
+				fieldName = fieldName[:idx-10]
+			} else {
+				required = append(required, fieldName)
+			}
+		} else {
+			required = append(required, fieldName)
+		}
+
+		// Get description from tag
+		description := field.Tag.Get("description")
+
+		// Generate schema for field
+		fieldSchema := generateFieldSchema(field.Type)
+		if description != "" {
+			fieldSchema["description"] = description
+		}
+
+		properties[fieldName] = fieldSchema
+	}
+
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+func generateFieldSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": generateFieldSchema(t.Elem()),
+		}
+	case reflect.Struct:
+		return generateSchemaFromType(t)
+	case reflect.Ptr:
+		// For pointers, generate schema for the element type
+		return generateFieldSchema(t.Elem())
+	default:
+		return map[string]interface{}{"type": "object"}
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StopSequences are the sequences at which generation stops. A single sequence is sent
+// as a plain string, which every provider accepts; more are sent as an array. Both
+// forms are accepted when decoding.
+type StopSequences []string
+
+// stopSequenceLimits caps the number of stop sequences per model family, matched by
+// model ID prefix. Families not listed accept any number.
+var stopSequenceLimits = []struct {
+	prefix string
+	max    int
+}{
+	{"openai/", 4},
+	{"x-ai/", 4},
+	{"google/", 5},
+	{"deepseek/", 16},
+}
+
+// MarshalJSON encodes a single sequence as a string and several as an array
+func (s StopSequences) MarshalJSON() ([]byte, error) {
+	if len(s) == 1 {
+		return json.Marshal(s[0])
+	}
+	return json.Marshal([]string(s))
+}
+
+// UnmarshalJSON decodes a string or an array of strings
+func (s *StopSequences) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = nil
+		return nil
+	}
+
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = StopSequences{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("invalid stop: %w", err)
+	}
+	*s = list
+	return nil
+}
+
+// MaxStopSequences returns the number of stop sequences model accepts, or 0 if it has no
+// known limit
+func MaxStopSequences(model string) int {
+	model = strings.ToLower(model)
+	for _, limit := range stopSequenceLimits {
+		if strings.HasPrefix(model, limit.prefix) {
+			return limit.max
+		}
+	}
+	return 0
+}
+
+// Validate checks the sequences against the constraints of model: no empty or
+// duplicate sequences, and no more than the model family accepts
+func (s StopSequences) Validate(model string) error {
+	seen := make(map[string]bool, len(s))
+	for i, seq := range s {
+		if seq == "" {
+			return fmt.Errorf("stop sequence %d is empty", i)
+		}
+		if seen[seq] {
+			return fmt.Errorf("stop sequence %q is repeated", seq)
+		}
+		seen[seq] = true
+	}
+
+	if max := MaxStopSequences(model); max > 0 && len(s) > max {
+		return fmt.Errorf("model %s accepts at most %d stop sequences, got %d", model, max, len(s))
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStopSequencesJSON(t *testing.T) {
+	tests := []struct {
+		stop StopSequences
+		want string
+	}{
+		{StopSequences{"END"}, `{"stop":"END"}`},
+		{StopSequences{"END", "\n\n"}, `{"stop":["END","\n\n"]}`},
+		{nil, `{}`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(ChatCompletionRequest{Stop: tt.stop})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("Marshal(%q) = %s, want %s", tt.stop, data, tt.want)
+		}
+
+		var req ChatCompletionRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			t.Fatal(err)
+		}
+		if len(req.Stop) != len(tt.stop) {
+			t.Errorf("round trip of %q gave %q", tt.stop, req.Stop)
+		}
+	}
+}
+
+func TestStopSequencesValidate(t *testing.T) {
+	five := StopSequences{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		model   string
+		stop    StopSequences
+		wantErr bool
+	}{
+		{"openai/gpt-4o", StopSequences{"a", "b", "c", "d"}, false},
+		{"openai/gpt-4o", five, true},
+		{"x-ai/grok-2", five, true},
+		{"google/gemini-pro-1.5", five, false},
+		{"google/gemini-pro-1.5", append(five, "f"), true},
+		{"deepseek/deepseek-chat", append(five, "f"), false},
+		{"anthropic/claude-3.5-sonnet", append(five, "f", "g", "h"), false},
+		{"meta-llama/llama-3-70b-instruct", append(five, "f"), false},
+		{"anthropic/claude-3.5-sonnet", StopSequences{"a", ""}, true},
+		{"anthropic/claude-3.5-sonnet", StopSequences{"a", "a"}, true},
+	}
+	for _, tt := range tests {
+		err := tt.stop.Validate(tt.model)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%s, %d sequences) error = %v, wantErr %v", tt.model, len(tt.stop), err, tt.wantErr)
+		}
+	}
+}
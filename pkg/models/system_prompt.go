@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// SystemPromptMode is how system messages are sent to a model
+type SystemPromptMode string
+
+const (
+	// SystemPromptNative sends system messages unchanged
+	SystemPromptNative SystemPromptMode = "native"
+
+	// SystemPromptMerge prepends the system prompt to the first user message, for
+	// models whose prompt format has no system turn
+	SystemPromptMerge SystemPromptMode = "merge"
+
+	// SystemPromptAsUser sends system messages with the user role
+	SystemPromptAsUser SystemPromptMode = "user"
+)
+
+// RewriteSystemMessages returns the messages rewritten for mode. The input slice is not
+// modified. With SystemPromptMerge, the text of all system messages is joined and
+// prepended to the first user message; if there is none, it becomes a user message.
+func RewriteSystemMessages(messages []Message, mode SystemPromptMode) ([]Message, error) {
+	switch mode {
+	case SystemPromptAsUser:
+		rewritten := append([]Message(nil), messages...)
+		for i := range rewritten {
+			if rewritten[i].Role == RoleSystem {
+				rewritten[i].Role = RoleUser
+			}
+		}
+		return rewritten, nil
+	case SystemPromptMerge:
+		return mergeSystemMessages(messages)
+	default:
+		return messages, nil
+	}
+}
+
+func mergeSystemMessages(messages []Message) ([]Message, error) {
+	var prompts []string
+	rewritten := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role != RoleSystem {
+			rewritten = append(rewritten, msg)
+			continue
+		}
+		parts, err := msg.GetMultiContent()
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range parts {
+			if text, ok := part.(TextContent); ok && text.Text != "" {
+				prompts = append(prompts, text.Text)
+			}
+		}
+	}
+	if len(prompts) == 0 {
+		return rewritten, nil
+	}
+	prompt := strings.Join(prompts, "\n\n")
+
+	for i, msg := range rewritten {
+		if msg.Role != RoleUser {
+			continue
+		}
+		merged, err := prependText(msg, prompt)
+		if err != nil {
+			return nil, err
+		}
+		rewritten[i] = merged
+		return rewritten, nil
+	}
+
+	return append([]Message{NewTextMessage(RoleUser, prompt)}, rewritten...), nil
+}
+
+// prependText adds text before the content of msg, keeping plain string content a string
+func prependText(msg Message, text string) (Message, error) {
+	var existing string
+	if err := json.Unmarshal(msg.Content, &existing); err == nil {
+		content, err := json.Marshal(text + "\n\n" + existing)
+		if err != nil {
+			return Message{}, err
+		}
+		msg.Content = content
+		return msg, nil
+	}
+
+	parts, err := msg.GetMultiContent()
+	if err != nil {
+		return Message{}, err
+	}
+	merged, err := NewMultiContentMessage(msg.Role, append([]Content{Text(text)}, parts...)...)
+	if err != nil {
+		return Message{}, err
+	}
+	msg.Content = merged.Content
+	return msg, nil
+}
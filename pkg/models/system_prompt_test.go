@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestRewriteSystemMessages(t *testing.T) {
+	image, err := NewMultiContentMessage(RoleUser, Text("What is this?"), Image("https://example.com/cat.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := []Message{
+		NewTextMessage(RoleSystem, "Be brief."),
+		NewTextMessage(RoleSystem, "Answer in French."),
+		NewTextMessage(RoleUser, "Hi"),
+	}
+
+	merged, err := RewriteSystemMessages(messages, SystemPromptMerge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 1 || merged[0].Role != RoleUser {
+		t.Fatalf("merged = %+v, want a single user message", merged)
+	}
+	if text, _ := merged[0].GetTextContent(); text != "Be brief.\n\nAnswer in French.\n\nHi" {
+		t.Errorf("merged text = %q", text)
+	}
+	if messages[0].Role != RoleSystem {
+		t.Error("input messages were modified")
+	}
+
+	// Multipart user content gets the prompt as a leading text part
+	merged, err = RewriteSystemMessages([]Message{messages[0], image}, SystemPromptMerge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts, err := merged[0].GetMultiContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 3 || parts[0].(TextContent).Text != "Be brief." {
+		t.Errorf("merged parts = %+v", parts)
+	}
+
+	asUser, err := RewriteSystemMessages(messages, SystemPromptAsUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(asUser) != 3 || asUser[0].Role != RoleUser || messages[0].Role != RoleSystem {
+		t.Errorf("asUser = %+v", asUser)
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// UnixTime is a timestamp the API encodes as Unix seconds, such as Model.CreatedAt. It
+// embeds time.Time, so it compares and formats like one, and Unix returns the seconds the
+// API sent. The zero UnixTime encodes as 0, and 0 decodes as the zero UnixTime.
+type UnixTime struct {
+	time.Time
+}
+
+// UnixSeconds returns the UnixTime sec seconds after the Unix epoch, in UTC. Zero gives
+// the zero UnixTime.
+func UnixSeconds(sec int64) UnixTime {
+	if sec == 0 {
+		return UnixTime{}
+	}
+	return UnixTime{time.Unix(sec, 0).UTC()}
+}
+
+// MarshalJSON encodes the time as Unix seconds
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("0"), nil
+	}
+	return strconv.AppendInt(nil, t.Unix(), 10), nil
+}
+
+// UnmarshalJSON decodes Unix seconds, possibly fractional or quoted, or an RFC 3339
+// string. null leaves the time unchanged.
+func (t *UnixTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if parsed, err := time.Parse(time.RFC3339, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+		data = []byte(s)
+	}
+
+	sec, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %s", data)
+	}
+	if sec == 0 {
+		t.Time = time.Time{}
+		return nil
+	}
+	whole, frac := math.Modf(sec)
+	t.Time = time.Unix(int64(whole), int64(frac*1e9)).UTC()
+	return nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestUnixTimeJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{`1700000000`, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{`1700000000.5`, time.Date(2023, 11, 14, 22, 13, 20, 5e8, time.UTC)},
+		{`"1700000000"`, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{`"2023-11-14T22:13:20Z"`, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{`0`, time.Time{}},
+		{`null`, time.Time{}},
+	}
+	for _, tt := range tests {
+		var model Model
+		if err := json.Unmarshal([]byte(`{"id":"m","created":`+tt.in+`}`), &model); err != nil {
+			t.Fatalf("Unmarshal(%s) = %v", tt.in, err)
+		}
+		if !model.CreatedAt.Equal(tt.want) {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.in, model.CreatedAt, tt.want)
+		}
+	}
+
+	var model Model
+	if err := json.Unmarshal([]byte(`{"created":"yesterday"}`), &model); err == nil {
+		t.Error("Unmarshal of an invalid timestamp succeeded")
+	}
+}
+
+func TestUnixTimeRoundTrip(t *testing.T) {
+	gen := Generation{ID: "gen-1", Created: UnixSeconds(1700000000)}
+	if gen.Created.Unix() != 1700000000 {
+		t.Errorf("Unix() = %d", gen.Created.Unix())
+	}
+
+	data, err := json.Marshal(gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Generation
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Created != gen.Created {
+		t.Errorf("round trip gave %v, want %v", decoded.Created, gen.Created)
+	}
+
+	data, err = json.Marshal(Model{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	if fields["created"] != 0.0 {
+		t.Errorf("zero time encoded as %v, want 0", fields["created"])
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // Tool represents a tool that can be called by the model
 type Tool struct {
@@ -24,8 +27,14 @@ type ToolChoice interface {
 type StringToolChoice string
 
 const (
+	// ToolChoiceNone stops the model from calling tools
 	ToolChoiceNone StringToolChoice = "none"
+
+	// ToolChoiceAuto lets the model decide whether to call tools
 	ToolChoiceAuto StringToolChoice = "auto"
+
+	// ToolChoiceRequired makes the model call at least one tool
+	ToolChoiceRequired StringToolChoice = "required"
 )
 
 func (StringToolChoice) toolChoice() {}
@@ -42,6 +51,9 @@ func (FunctionToolChoice) toolChoice() {}
 
 // ToolCall represents a tool call made by the model
 type ToolCall struct {
+	// Index identifies the tool call a streamed delta belongs to
+	Index *int `json:"index,omitempty"`
+
 	ID       string       `json:"id"`
 	Type     string       `json:"type"`
 	Function FunctionCall `json:"function"`
@@ -81,3 +93,52 @@ func NewFunctionToolChoice(functionName string) ToolChoice {
 		},
 	}
 }
+
+// unmarshalToolChoice decodes a tool choice given as a string or a function object
+func unmarshalToolChoice(data json.RawMessage) (ToolChoice, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return StringToolChoice(s), nil
+	}
+
+	var choice FunctionToolChoice
+	if err := json.Unmarshal(data, &choice); err != nil {
+		return nil, fmt.Errorf("invalid tool_choice: %w", err)
+	}
+	return choice, nil
+}
+
+// ToolCalls returns the tool calls of the first choice, or nil if the model called no tools
+func (r *ChatCompletionResponse) ToolCalls() []ToolCall {
+	if len(r.Choices) == 0 || r.Choices[0].Message == nil {
+		return nil
+	}
+	return r.Choices[0].Message.ToolCalls
+}
+
+// PairToolResults builds the tool messages answering calls, in the order of the calls,
+// from results keyed by tool call ID. Each message carries the ID and function name of
+// its call. It fails if a call has no result or a result matches no call.
+func PairToolResults(calls []ToolCall, results map[string]string) ([]Message, error) {
+	messages := make([]Message, 0, len(calls))
+	seen := make(map[string]bool, len(calls))
+	for _, call := range calls {
+		result, ok := results[call.ID]
+		if !ok {
+			return nil, fmt.Errorf("no result for tool call %s (%s)", call.ID, call.Function.Name)
+		}
+		seen[call.ID] = true
+		messages = append(messages, NewToolMessage(call.ID, call.Function.Name, result))
+	}
+
+	for id := range results {
+		if !seen[id] {
+			return nil, fmt.Errorf("result for unknown tool call %s", id)
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestPairToolResults(t *testing.T) {
+	msg := Message{Role: RoleAssistant, ToolCalls: []ToolCall{
+		{ID: "call_b", Type: "function", Function: FunctionCall{Name: "weather"}},
+		{ID: "call_a", Type: "function", Function: FunctionCall{Name: "time"}},
+	}}
+	resp := &ChatCompletionResponse{Choices: []Choice{{Message: &msg}}}
+
+	calls := resp.ToolCalls()
+	if len(calls) != 2 {
+		t.Fatalf("ToolCalls() = %+v", calls)
+	}
+
+	messages, err := PairToolResults(calls, map[string]string{"call_a": "12:00", "call_b": "sunny"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []struct{ id, name, content string }{
+		{"call_b", "weather", "sunny"},
+		{"call_a", "time", "12:00"},
+	} {
+		got := messages[i]
+		content, _ := got.GetTextContent()
+		if got.Role != RoleTool || got.ToolCallID != want.id || got.Name != want.name || content != want.content {
+			t.Errorf("message %d = %+v, want %+v", i, got, want)
+		}
+	}
+	if err := ValidateConversation(append([]Message{NewTextMessage(RoleUser, "hi"), msg}, messages...)); err != nil {
+		t.Errorf("paired conversation is invalid: %v", err)
+	}
+
+	if _, err := PairToolResults(calls, map[string]string{"call_a": "12:00"}); err == nil {
+		t.Error("expected an error for a missing result")
+	}
+	if _, err := PairToolResults(calls, map[string]string{"call_a": "12:00", "call_b": "sunny", "call_c": "?"}); err == nil {
+		t.Error("expected an error for an unknown result")
+	}
+	if (&ChatCompletionResponse{}).ToolCalls() != nil {
+		t.Error("ToolCalls() of an empty response should be nil")
+	}
+}
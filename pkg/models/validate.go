@@ -0,0 +1,272 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ValidationError lists every problem found in a request
+type ValidationError struct {
+	Problems []string
+}
+
+// Error returns all problems in one message
+func (e *ValidationError) Error() string {
+	return "invalid request: " + strings.Join(e.Problems, "; ")
+}
+
+// toolNamePattern is the tool name format providers accept
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// toolsWithJSONModeUnsupported lists model families, by model ID prefix, that reject
+// tools combined with response_format json_object
+var toolsWithJSONModeUnsupported = []string{
+	"anthropic/",
+	"google/",
+}
+
+// validator collects problems found while checking a request
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) float(name string, value *float64, min, max float64) {
+	if value != nil && (*value < min || *value > max) {
+		v.addf("%s must be between %g and %g, got %g", name, min, max, *value)
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+// Validate checks the request for mistakes the API would reject: missing messages,
+// parameters out of range, conflicting fields, malformed tools, and tool messages that
+// don't answer the preceding assistant tool calls. Model-specific limits are checked
+// against Model. It returns a *ValidationError listing every problem found, or nil.
+func (r *ChatCompletionRequest) Validate() error {
+	v := &validator{}
+
+	switch {
+	case len(r.Messages) == 0 && r.Prompt == "":
+		v.addf("messages or prompt is required")
+	case len(r.Messages) > 0 && r.Prompt != "":
+		v.addf("messages and prompt are mutually exclusive")
+	}
+
+	v.float("temperature", r.Temperature, 0, 2)
+	v.float("top_p", r.TopP, 0, 1)
+	v.float("frequency_penalty", r.FrequencyPenalty, -2, 2)
+	v.float("presence_penalty", r.PresencePenalty, -2, 2)
+	v.float("repetition_penalty", r.RepetitionPenalty, 0, 2)
+	v.float("min_p", r.MinP, 0, 1)
+	v.float("top_a", r.TopA, 0, 1)
+	if r.MaxTokens != nil && *r.MaxTokens < 1 {
+		v.addf("max_tokens must be at least 1, got %d", *r.MaxTokens)
+	}
+	if r.TopK != nil && *r.TopK < 0 {
+		v.addf("top_k must not be negative, got %d", *r.TopK)
+	}
+	if r.TopLogprobs != nil && (*r.TopLogprobs < 0 || *r.TopLogprobs > 20) {
+		v.addf("top_logprobs must be between 0 and 20, got %d", *r.TopLogprobs)
+	}
+	for token, bias := range r.LogitBias {
+		if bias < -100 || bias > 100 {
+			v.addf("logit_bias for token %s must be between -100 and 100, got %g", token, bias)
+		}
+	}
+	if err := r.Stop.Validate(r.Model); err != nil {
+		v.addf("%v", err)
+	}
+
+	for _, key := range r.extraCollisions() {
+		v.addf("extra parameter %s collides with the request field; set the field instead", key)
+	}
+
+	r.validateRoute(v)
+	r.validateProvider(v)
+	r.validateResponseFormat(v)
+	r.validateTools(v)
+	for i, msg := range r.Messages {
+		validateMessage(v, i, msg)
+	}
+	validateToolSequence(v, r.Messages)
+
+	return v.err()
+}
+
+func (r *ChatCompletionRequest) validateRoute(v *validator) {
+	switch r.Route {
+	case "":
+	case RouteFallback:
+		if len(r.Models) == 0 {
+			v.addf("route fallback requires models")
+		}
+	default:
+		v.addf("unknown route %q", r.Route)
+	}
+
+	for i, model := range r.Models {
+		switch {
+		case model == "":
+			v.addf("models[%d] is empty", i)
+		case i > 0 && model == r.Model:
+			v.addf("primary model %s is also listed as fallback models[%d]", model, i)
+		}
+	}
+}
+
+func (r *ChatCompletionRequest) validateProvider(v *validator) {
+	if r.Provider == nil {
+		return
+	}
+	for _, level := range r.Provider.Quantizations {
+		if !level.IsKnown() {
+			v.addf("unknown provider quantization %q", level)
+		}
+	}
+	if r.Provider.Sort != "" && !r.Provider.Sort.IsKnown() {
+		v.addf("unknown provider sort %q", r.Provider.Sort)
+	}
+	switch r.Provider.DataCollection {
+	case "", DataCollectionAllow, DataCollectionDeny:
+	default:
+		v.addf("unknown provider data_collection %q", r.Provider.DataCollection)
+	}
+}
+
+func (r *ChatCompletionRequest) validateResponseFormat(v *validator) {
+	if r.ResponseFormat == nil {
+		return
+	}
+
+	switch r.ResponseFormat.Type {
+	case "text", "json_object":
+	case "json_schema":
+		if r.ResponseFormat.JSONSchema == nil {
+			v.addf("response_format json_schema requires a schema")
+		}
+	case "grammar":
+		if r.ResponseFormat.Grammar == "" {
+			v.addf("response_format grammar requires a grammar")
+		}
+	case "regex":
+		if r.ResponseFormat.Pattern == "" {
+			v.addf("response_format regex requires a pattern")
+		}
+	default:
+		v.addf("unknown response_format type %q", r.ResponseFormat.Type)
+	}
+
+	if r.ResponseFormat.Type == "json_object" && len(r.Tools) > 0 {
+		model := strings.ToLower(r.Model)
+		for _, prefix := range toolsWithJSONModeUnsupported {
+			if strings.HasPrefix(model, prefix) {
+				v.addf("model %s doesn't support tools with response_format json_object", r.Model)
+				break
+			}
+		}
+	}
+}
+
+func (r *ChatCompletionRequest) validateTools(v *validator) {
+	names := make(map[string]bool, len(r.Tools))
+	for i, tool := range r.Tools {
+		name := tool.Function.Name
+		switch {
+		case !toolNamePattern.MatchString(name):
+			v.addf("tool %d: name %q must be 1-64 letters, digits, underscores or dashes", i, name)
+		case names[name]:
+			v.addf("tool %d: name %q is repeated", i, name)
+		}
+		names[name] = true
+	}
+
+	switch choice := r.ToolChoice.(type) {
+	case nil:
+	case StringToolChoice:
+		switch choice {
+		case ToolChoiceNone, ToolChoiceAuto, ToolChoiceRequired:
+		default:
+			v.addf("unknown tool_choice %q", choice)
+		}
+		if choice != ToolChoiceNone && len(r.Tools) == 0 {
+			v.addf("tool_choice %q requires tools", choice)
+		}
+	case FunctionToolChoice:
+		if !names[choice.Function.Name] {
+			v.addf("tool_choice names function %q, which is not in tools", choice.Function.Name)
+		}
+	}
+
+	if r.ParallelToolCalls != nil && len(r.Tools) == 0 {
+		v.addf("parallel_tool_calls requires tools")
+	}
+}
+
+// ValidateForModel checks that model supports the parameters the request sets, using the
+// supported parameters listed in the model catalog. Models that list none are assumed to
+// support everything. It returns a *ValidationError, or nil.
+func (r *ChatCompletionRequest) ValidateForModel(model Model) error {
+	if len(model.SupportedParams) == 0 {
+		return nil
+	}
+
+	used := map[string]bool{
+		"tools":               len(r.Tools) > 0,
+		"tool_choice":         r.ToolChoice != nil,
+		"parallel_tool_calls": r.ParallelToolCalls != nil,
+		"response_format":     r.ResponseFormat != nil,
+		"structured_outputs":  r.ResponseFormat != nil && r.ResponseFormat.Type == "json_schema",
+		"reasoning":           r.Reasoning != nil,
+		"stop":                len(r.Stop) > 0,
+		"seed":                r.Seed != nil,
+		"logit_bias":          len(r.LogitBias) > 0,
+		"top_logprobs":        r.TopLogprobs != nil,
+	}
+
+	v := &validator{}
+	for _, name := range sortedKeys(used) {
+		if used[name] && !model.SupportsParameter(name) {
+			v.addf("model %s doesn't support %s", model.ID, name)
+		}
+	}
+	return v.err()
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validateMessage(v *validator, i int, msg Message) {
+	switch msg.Role {
+	case RoleSystem, RoleUser:
+	case RoleAssistant:
+		for _, call := range msg.ToolCalls {
+			if call.ID == "" || call.Function.Name == "" {
+				v.addf("message %d: assistant tool calls need an id and a function name", i)
+				break
+			}
+		}
+	case RoleTool:
+		if msg.ToolCallID == "" {
+			v.addf("message %d: tool message is missing tool_call_id", i)
+		}
+	default:
+		v.addf("message %d: unknown role %q", i, msg.Role)
+	}
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tool, err := NewTool("get_weather", "Get the weather", map[string]interface{}{"type": "object"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		req  ChatCompletionRequest
+		want []string
+	}{
+		{
+			name: "valid",
+			req:  NewChatRequest("openai/gpt-4o", WithUserMessage("hi"), WithTemperature(0.7)),
+		},
+		{
+			name: "no messages",
+			req:  NewChatRequest("openai/gpt-4o"),
+			want: []string{"messages or prompt is required"},
+		},
+		{
+			name: "parameters out of range",
+			req:  NewChatRequest("openai/gpt-4o", WithUserMessage("hi"), WithTemperature(3), WithMaxTokens(0)),
+			want: []string{"temperature must be between 0 and 2", "max_tokens must be at least 1"},
+		},
+		{
+			name: "tools with json mode on unsupported model",
+			req: NewChatRequest("anthropic/claude-3.5-sonnet", WithUserMessage("hi"), func(r *ChatCompletionRequest) {
+				r.Tools = []Tool{*tool}
+				r.ResponseFormat = &ResponseFormat{Type: "json_object"}
+			}),
+			want: []string{"doesn't support tools with response_format json_object"},
+		},
+		{
+			name: "regex without pattern",
+			req:  NewChatRequest("openai/gpt-4o", WithUserMessage("hi"), WithRegex("")),
+			want: []string{"response_format regex requires a pattern"},
+		},
+		{
+			name: "fallback route without models",
+			req: NewChatRequest("openai/gpt-4o", WithUserMessage("hi"), func(r *ChatCompletionRequest) {
+				r.Route = RouteFallback
+			}),
+			want: []string{"route fallback requires models"},
+		},
+		{
+			name: "primary model listed as later fallback",
+			req: NewChatRequest("openai/gpt-4o", WithUserMessage("hi"), func(r *ChatCompletionRequest) {
+				r.Models = []string{"anthropic/claude-3.5-sonnet", "openai/gpt-4o"}
+			}),
+			want: []string{"primary model openai/gpt-4o is also listed as fallback models[1]"},
+		},
+		{
+			name: "tool choice without matching tool",
+			req: NewChatRequest("openai/gpt-4o", WithUserMessage("hi"), func(r *ChatCompletionRequest) {
+				r.Tools = []Tool{*tool}
+				r.ToolChoice = NewFunctionToolChoice("get_time")
+			}),
+			want: []string{`function "get_time", which is not in tools`},
+		},
+		{
+			name: "tool message without tool_call_id",
+			req: NewChatRequest("openai/gpt-4o", WithUserMessage("hi"),
+				WithMessages(NewTextMessage(RoleTool, "sunny"))),
+			want: []string{"message 1: tool message is missing tool_call_id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() = %v, want a *ValidationError", err)
+			}
+			if len(validationErr.Problems) != len(tt.want) {
+				t.Errorf("Problems = %q, want %d", validationErr.Problems, len(tt.want))
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %v, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateForModel(t *testing.T) {
+	tool, err := NewTool("get_weather", "Get the weather", map[string]interface{}{"type": "object"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := NewChatRequest("acme/model", WithUserMessage("hi"), WithTools(*tool),
+		WithToolChoice(ToolChoiceRequired), WithParallelToolCalls(false))
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	full := Model{ID: "acme/model", SupportedParams: []string{"tools", "tool_choice", "parallel_tool_calls"}}
+	if err := req.ValidateForModel(full); err != nil {
+		t.Errorf("ValidateForModel() = %v, want nil", err)
+	}
+
+	toolsOnly := Model{ID: "acme/model", SupportedParams: []string{"tools"}}
+	err = req.ValidateForModel(toolsOnly)
+	want := "invalid request: model acme/model doesn't support parallel_tool_calls; model acme/model doesn't support tool_choice"
+	if err == nil || err.Error() != want {
+		t.Errorf("ValidateForModel() = %v, want %q", err, want)
+	}
+
+	if err := req.ValidateForModel(Model{ID: "acme/model"}); err != nil {
+		t.Errorf("ValidateForModel() without catalog data = %v, want nil", err)
+	}
+}
@@ -0,0 +1,138 @@
+package pkg_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestModerate(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(
+		openroutertest.TextReply(`{"flagged":true,"categories":["harassment"],"severity":"MEDIUM","rationale":"Insults the reader."}`),
+		openroutertest.TextReply(`{"flagged":false,"categories":[],"severity":"none","rationale":"Fine."}`),
+	)
+	moderator := pkg.NewModerationHelper(srv.Client())
+
+	verdict, err := moderator.Moderate(context.Background(), "You are an idiot.", nil)
+	require.NoError(t, err)
+	assert.True(t, verdict.Flagged)
+	assert.Equal(t, []string{"harassment"}, verdict.Categories)
+	assert.Equal(t, pkg.SeverityMedium, verdict.Severity, "severities are normalized to lower case")
+	assert.Equal(t, "Insults the reader.", verdict.Rationale)
+	require.NotNil(t, verdict.Response)
+
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, pkg.DefaultModerationModel, sent.Model)
+	assert.Equal(t, 0.0, *sent.Temperature)
+	assert.Equal(t, "moderation_verdict", sent.ResponseFormat.JSONSchema.Name)
+	system, _ := sent.Messages[0].GetTextContent()
+	assert.Contains(t, system, "Categories: "+strings.Join(pkg.DefaultModerationCategories, ", "))
+	assert.NotContains(t, system, "Additional rules")
+	content, _ := sent.Messages[1].GetTextContent()
+	assert.Equal(t, "You are an idiot.", content)
+
+	// A policy sets the judge, its categories and extra rules
+	_, err = moderator.Moderate(context.Background(), "Our price beats Acme's.", &pkg.ModerationPolicy{
+		Model:      "openai/gpt-4o",
+		Categories: []string{"competitors", "pricing"},
+		Rules:      "No competitor pricing.",
+	})
+	require.NoError(t, err)
+	sent, err = srv.Requests()[1].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o", sent.Model)
+	system, _ = sent.Messages[0].GetTextContent()
+	assert.Contains(t, system, "Categories: competitors, pricing")
+	assert.Contains(t, system, "Additional rules:\nNo competitor pricing.")
+}
+
+func TestModerateUnknownSeverity(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  string
+	}{
+		{"flagged", `{"flagged":true,"categories":["violence"],"severity":"extreme","rationale":""}`, pkg.SeverityHigh},
+		{"not flagged", `{"flagged":false,"categories":[],"severity":"","rationale":""}`, pkg.SeverityNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := openroutertest.NewServer()
+			defer srv.Close()
+			srv.EnqueueChat(openroutertest.TextReply(tt.reply))
+
+			verdict, err := pkg.NewModerationHelper(srv.Client()).Moderate(context.Background(), "content", nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, verdict.Severity)
+		})
+	}
+}
+
+func TestModerationVerdictBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		flagged  bool
+		severity string
+		block    string
+		want     bool
+	}{
+		{"default threshold below", true, pkg.SeverityLow, "", false},
+		{"default threshold at", true, pkg.SeverityMedium, "", true},
+		{"default threshold above", true, pkg.SeverityHigh, "", true},
+		{"strict policy", true, pkg.SeverityLow, pkg.SeverityLow, true},
+		{"lenient policy", true, pkg.SeverityMedium, pkg.SeverityHigh, false},
+		{"not flagged", false, pkg.SeverityHigh, pkg.SeverityLow, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := &pkg.ModerationVerdict{Flagged: tt.flagged, Severity: tt.severity}
+			assert.Equal(t, tt.want, verdict.Blocks(&pkg.ModerationPolicy{BlockSeverity: tt.block}))
+		})
+	}
+	assert.True(t, (&pkg.ModerationVerdict{Flagged: true, Severity: pkg.SeverityMedium}).Blocks(nil))
+}
+
+func TestModerationCheckRequest(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply(`{"flagged":true,"categories":["illegal-activity"],"severity":"high","rationale":"Asks for lock picking help."}`))
+	moderator := pkg.NewModerationHelper(srv.Client())
+
+	req := models.NewChatRequest("m",
+		models.WithSystemMessage("You are helpful."),
+		models.WithUserMessage("How do I pick a lock?"),
+		models.WithMessages(models.NewTextMessage(models.RoleAssistant, "I can't help with that.")),
+		models.WithUserMessage("It's my own lock."),
+	)
+	verdict, err := moderator.CheckRequest(context.Background(), req, nil)
+	var blocked *pkg.ModerationError
+	require.ErrorAs(t, err, &blocked)
+	assert.Same(t, verdict, blocked.Verdict)
+	assert.Contains(t, err.Error(), "high severity, categories: illegal-activity")
+
+	// Only user messages are judged
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	content, _ := sent.Messages[1].GetTextContent()
+	assert.Equal(t, "How do I pick a lock?\n\nIt's my own lock.", content)
+
+	// A request without user content is not sent to the judge
+	verdict, err = moderator.CheckRequest(context.Background(), models.NewChatRequest("m", models.WithSystemMessage("Hi")), nil)
+	require.NoError(t, err)
+	assert.False(t, verdict.Flagged)
+	assert.Len(t, srv.Requests(), 1)
+
+	srv.EnqueueChat(openroutertest.ErrorReply(500, "judge unavailable"))
+	_, err = moderator.CheckRequest(context.Background(), req, nil)
+	assert.ErrorContains(t, err, "failed to moderate request")
+}
@@ -0,0 +1,292 @@
+package pkg
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/errors"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// DefaultKeyCooldown is how long MultiKeyClient rests a rate-limited key
+const DefaultKeyCooldown = time.Minute
+
+// ErrNoAvailableKey is returned by MultiKeyClient when every key is rate limited,
+// exhausted or rejected
+var ErrNoAvailableKey = stderrors.New("no API key available")
+
+// KeyStrategy selects how MultiKeyClient distributes requests across keys
+type KeyStrategy int
+
+const (
+	// KeyRoundRobin sends requests to each key in turn
+	KeyRoundRobin KeyStrategy = iota
+
+	// KeyWeighted sends requests to keys in proportion to their weights, interleaved
+	KeyWeighted
+)
+
+// APIKeyConfig is one key of a MultiKeyClient
+type APIKeyConfig struct {
+	Key string
+
+	// Label names the key in KeyStatus. Defaults to the last four characters of Key.
+	Label string
+
+	// Weight is the key's share of requests with KeyWeighted. Defaults to 1.
+	Weight int
+}
+
+// MultiKeyOptions configures a MultiKeyClient
+type MultiKeyOptions struct {
+	Strategy KeyStrategy
+
+	// Cooldown is how long a rate-limited key rests before it is used again. Defaults to
+	// DefaultKeyCooldown.
+	Cooldown time.Duration
+
+	// ClientOptions configure the client of every key
+	ClientOptions []Option
+}
+
+// KeyStatus is the state of one key of a MultiKeyClient
+type KeyStatus struct {
+	Label  string
+	Weight int
+
+	// RateLimitedUntil is when a rate-limited key is used again, zero if it isn't
+	RateLimitedUntil time.Time
+
+	// Exhausted is set when the key ran out of credits, until RefreshStatus finds
+	// credits left
+	Exhausted bool
+
+	// Rejected is set when the API rejected the key as invalid
+	Rejected bool
+
+	// Limit and Usage are the key's credit limit and usage in USD as of the last
+	// RefreshStatus. A zero Limit means no limit.
+	Limit float64
+	Usage float64
+
+	Requests int64
+	Failures int64
+}
+
+// Available reports whether the key can take requests at now
+func (s KeyStatus) Available(now time.Time) bool {
+	return !s.Exhausted && !s.Rejected && !now.Before(s.RateLimitedUntil)
+}
+
+// keyState is a key's client and status
+type keyState struct {
+	client *Client
+	status KeyStatus
+
+	// current is the key's smooth weighted round-robin counter
+	current int
+}
+
+// MultiKeyClient spreads requests across several API keys to scale beyond the rate
+// limits and credit limits of one. A key that is rate limited rests for the cooldown, a
+// key out of credits or rejected is taken out of rotation, and the request fails over to
+// the next key. It is safe for concurrent use.
+type MultiKeyClient struct {
+	keys     []*keyState
+	strategy KeyStrategy
+	cooldown time.Duration
+	clock    Clock
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewMultiKeyClient creates a client for keys
+func NewMultiKeyClient(keys []APIKeyConfig, opts MultiKeyOptions) (*MultiKeyClient, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one API key is required")
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = DefaultKeyCooldown
+	}
+
+	m := &MultiKeyClient{strategy: opts.Strategy, cooldown: opts.Cooldown}
+	for i, key := range keys {
+		if key.Key == "" {
+			return nil, fmt.Errorf("API key %d is empty", i)
+		}
+		label := key.Label
+		if label == "" {
+			label = "..." + key.Key[max(0, len(key.Key)-4):]
+		}
+		weight := key.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		m.keys = append(m.keys, &keyState{
+			client: NewClient(key.Key, opts.ClientOptions...),
+			status: KeyStatus{Label: label, Weight: weight},
+		})
+	}
+	m.clock = m.keys[0].client.clock
+	return m, nil
+}
+
+// Status returns the status of every key, in the order they were given
+func (m *MultiKeyClient) Status() []KeyStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make([]KeyStatus, len(m.keys))
+	for i, key := range m.keys {
+		statuses[i] = key.status
+	}
+	return statuses
+}
+
+// RefreshStatus fetches each key's credit limit and usage, marking keys past their limit
+// as exhausted and returning keys with credits left to rotation
+func (m *MultiKeyClient) RefreshStatus(ctx context.Context) error {
+	var errs []error
+	for _, key := range m.keys {
+		info, err := key.client.GetCurrentAPIKey(ctx)
+		m.mu.Lock()
+		if err != nil {
+			if isKeyRejected(err) {
+				key.status.Rejected = true
+			}
+			errs = append(errs, fmt.Errorf("failed to get status of key %s: %w", key.status.Label, err))
+		} else {
+			key.status.Limit = info.Limit
+			key.status.Usage = info.Usage
+			key.status.Exhausted = info.Limit > 0 && info.Usage >= info.Limit
+			key.status.Rejected = info.Disabled
+		}
+		m.mu.Unlock()
+	}
+	return stderrors.Join(errs...)
+}
+
+// CreateChatCompletion creates a chat completion with the next available key, failing
+// over to the other keys when a key is rate limited, out of credits or rejected
+func (m *MultiKeyClient) CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	var resp *models.ChatCompletionResponse
+	err := m.do(ctx, func(ctx context.Context, client *Client) error {
+		var err error
+		resp, err = client.CreateChatCompletion(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+// CreateChatCompletionStream opens a chat completion stream with the next available key,
+// failing over like CreateChatCompletion. Errors after the stream opens don't fail over.
+func (m *MultiKeyClient) CreateChatCompletionStream(ctx context.Context, req models.ChatCompletionRequest) (*streaming.ChatCompletionStreamReader, error) {
+	var stream *streaming.ChatCompletionStreamReader
+	err := m.do(ctx, func(ctx context.Context, client *Client) error {
+		var err error
+		stream, err = client.CreateChatCompletionStream(ctx, req)
+		return err
+	})
+	return stream, err
+}
+
+// do runs fn with available keys until it succeeds, fails with an error unrelated to the
+// key, or every key has been tried
+func (m *MultiKeyClient) do(ctx context.Context, fn func(ctx context.Context, client *Client) error) error {
+	// Every attempt shares one idempotency key so a failed-over request isn't billed twice
+	ctx = ensureIdempotencyKey(ctx)
+
+	tried := make(map[*keyState]bool, len(m.keys))
+	lastErr := ErrNoAvailableKey
+	for len(tried) < len(m.keys) {
+		key := m.pick(tried)
+		if key == nil {
+			break
+		}
+		tried[key] = true
+
+		err := fn(ctx, key.client)
+		if !m.record(key, err) {
+			return err
+		}
+		lastErr = fmt.Errorf("%w: key %s: %w", ErrNoAvailableKey, key.status.Label, err)
+	}
+	return lastErr
+}
+
+// pick selects the next available key that hasn't been tried, or nil
+func (m *MultiKeyClient) pick(tried map[*keyState]bool) *keyState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+
+	if m.strategy == KeyRoundRobin {
+		for i := 0; i < len(m.keys); i++ {
+			key := m.keys[(m.next+i)%len(m.keys)]
+			if !tried[key] && key.status.Available(now) {
+				m.next = (m.next + i + 1) % len(m.keys)
+				key.status.Requests++
+				return key
+			}
+		}
+		return nil
+	}
+
+	// Smooth weighted round-robin: every candidate gains its weight, the highest is
+	// picked and loses the candidates' total weight
+	var best *keyState
+	total := 0
+	for _, key := range m.keys {
+		if tried[key] || !key.status.Available(now) {
+			continue
+		}
+		key.current += key.status.Weight
+		total += key.status.Weight
+		if best == nil || key.current > best.current {
+			best = key
+		}
+	}
+	if best != nil {
+		best.current -= total
+		best.status.Requests++
+	}
+	return best
+}
+
+// record updates a key's status after a request, reporting whether the error was caused
+// by the key so another key should be tried
+func (m *MultiKeyClient) record(key *keyState, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *errors.APIError
+	if !stderrors.As(err, &apiErr) {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch apiErr.Code {
+	case errors.ErrorCodeRateLimited:
+		key.status.RateLimitedUntil = m.clock.Now().Add(m.cooldown)
+	case errors.ErrorCodeInsufficientCredits:
+		key.status.Exhausted = true
+	case errors.ErrorCodeUnauthorized:
+		key.status.Rejected = true
+	default:
+		return false
+	}
+	key.status.Failures++
+	return true
+}
+
+// isKeyRejected reports whether err means the API doesn't accept the key
+func isKeyRejected(err error) bool {
+	var apiErr *errors.APIError
+	return stderrors.As(err, &apiErr) && apiErr.Code == errors.ErrorCodeUnauthorized
+}
@@ -0,0 +1,104 @@
+package pkg_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// usedKeys returns the API key sent with each recorded chat request
+func usedKeys(srv *openroutertest.Server) []string {
+	var keys []string
+	for _, req := range srv.Requests() {
+		if req.Path == "/chat/completions" {
+			keys = append(keys, req.Header.Get("Authorization")[len("Bearer "):])
+		}
+	}
+	return keys
+}
+
+func TestMultiKeyClientWeighted(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+
+	client, err := pkg.NewMultiKeyClient([]pkg.APIKeyConfig{
+		{Key: "key-a", Weight: 2},
+		{Key: "key-b", Weight: 1},
+	}, pkg.MultiKeyOptions{Strategy: pkg.KeyWeighted, ClientOptions: []pkg.Option{pkg.WithBaseURL(srv.URL)}})
+	require.NoError(t, err)
+
+	req := models.NewChatRequest("m", models.WithUserMessage("hi"))
+	for i := 0; i < 6; i++ {
+		_, err := client.CreateChatCompletion(context.Background(), req)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"key-a", "key-b", "key-a", "key-a", "key-b", "key-a"}, usedKeys(srv))
+}
+
+func TestMultiKeyClientFailsOver(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	clock := openroutertest.NewFakeClock(time.Now())
+
+	client, err := pkg.NewMultiKeyClient([]pkg.APIKeyConfig{
+		{Key: "key-a", Label: "a"},
+		{Key: "key-b", Label: "b"},
+	}, pkg.MultiKeyOptions{
+		Cooldown:      time.Minute,
+		ClientOptions: []pkg.Option{pkg.WithBaseURL(srv.URL), pkg.WithClock(clock)},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	req := models.NewChatRequest("m", models.WithUserMessage("hi"))
+
+	// key-a is rate limited, so the request moves to key-b
+	srv.EnqueueChat(openroutertest.ErrorReply(429, "rate limited"), openroutertest.TextReply("ok"))
+	_, err = client.CreateChatCompletion(ctx, req)
+	require.NoError(t, err)
+	status := client.Status()
+	assert.False(t, status[0].Available(clock.Now()))
+	assert.Equal(t, int64(1), status[0].Failures)
+
+	// key-b runs out of credits; key-a is still cooling down
+	srv.EnqueueChat(openroutertest.ErrorReply(402, "insufficient credits"))
+	_, err = client.CreateChatCompletion(ctx, req)
+	require.True(t, errors.Is(err, pkg.ErrNoAvailableKey))
+	assert.True(t, client.Status()[1].Exhausted)
+
+	// After the cooldown key-a takes requests again
+	clock.Advance(time.Minute)
+	_, err = client.CreateChatCompletion(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key-a", "key-b", "key-b", "key-a"}, usedKeys(srv))
+
+	// Errors unrelated to the key don't fail over
+	srv.EnqueueChat(openroutertest.ErrorReply(400, "bad request"))
+	_, err = client.CreateChatCompletion(ctx, req)
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, pkg.ErrNoAvailableKey))
+	assert.Len(t, usedKeys(srv), 5)
+}
+
+func TestMultiKeyClientRefreshStatus(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetCredits(100, 12.5)
+
+	client, err := pkg.NewMultiKeyClient([]pkg.APIKeyConfig{{Key: "sk-or-1234"}},
+		pkg.MultiKeyOptions{ClientOptions: []pkg.Option{pkg.WithBaseURL(srv.URL)}})
+	require.NoError(t, err)
+
+	require.NoError(t, client.RefreshStatus(context.Background()))
+	status := client.Status()[0]
+	assert.Equal(t, "...1234", status.Label)
+	assert.Equal(t, 12.5, status.Usage)
+	assert.False(t, status.Exhausted)
+}
@@ -4,17 +4,33 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
 )
 
 // Logger interface for custom logging
 type Logger interface {
-	Debug(msg string, fields ...interface{})
-	Info(msg string, fields ...interface{})
-	Warn(msg string, fields ...interface{})
-	Error(msg string, fields ...interface{})
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+}
+
+// Field is a structured logging field
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F creates a logging field
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
 }
 
 // MetricsCollector interface for metrics collection
@@ -25,22 +41,94 @@ type MetricsCollector interface {
 	RecordError(operation string, err error, labels map[string]string)
 }
 
+// ResilienceMetricsCollector is a MetricsCollector that also records the behavior of
+// retries, circuit breakers, and concurrency limits
+type ResilienceMetricsCollector interface {
+	MetricsCollector
+
+	// RecordRetry is called before each retry, with attempt starting at 1
+	RecordRetry(operation string, attempt int, err error, labels map[string]string)
+
+	// RecordRetriesExhausted is called when a request fails after its last retry
+	RecordRetriesExhausted(operation string, err error, labels map[string]string)
+
+	// RecordCircuitStateChange is called when a circuit breaker changes state
+	RecordCircuitStateChange(from, to CircuitState, labels map[string]string)
+
+	// RecordQueueDepth reports the number of requests waiting for a concurrency slot
+	RecordQueueDepth(depth int, labels map[string]string)
+
+	// RecordLimiterWait reports how long a request waited for a concurrency slot
+	RecordLimiterWait(duration time.Duration, labels map[string]string)
+}
+
+// StreamingMetricsCollector is a MetricsCollector that also records streaming performance.
+// Streams created by a client with WithMetrics report to it when they end.
+type StreamingMetricsCollector interface {
+	MetricsCollector
+
+	// RecordTimeToFirstToken reports the time from request to first token
+	RecordTimeToFirstToken(duration time.Duration, labels map[string]string)
+
+	// RecordTokensPerSecond reports decode throughput after the first token
+	RecordTokensPerSecond(tokensPerSecond float64, labels map[string]string)
+}
+
+// recordStreamSummary reports a finished stream's usage to metrics, and its latency and
+// throughput to streaming metrics
+func recordStreamSummary(metrics MetricsCollector, summary streaming.StreamSummary) {
+	labels := map[string]string{"model": summary.Model, "operation": "chat_completion_stream"}
+	if summary.Provider != "" {
+		labels["provider"] = summary.Provider
+	}
+
+	if summary.Usage != nil {
+		metrics.RecordTokens(summary.Usage.PromptTokens, summary.Usage.CompletionTokens, labels)
+		if summary.Usage.Cost > 0 {
+			metrics.RecordCost(summary.Usage.Cost, labels)
+		}
+	}
+
+	streamingMetrics, ok := metrics.(StreamingMetricsCollector)
+	if !ok || summary.TimeToFirstToken == 0 {
+		return
+	}
+	streamingMetrics.RecordTimeToFirstToken(summary.TimeToFirstToken, labels)
+	if summary.TokensPerSecond > 0 {
+		streamingMetrics.RecordTokensPerSecond(summary.TokensPerSecond, labels)
+	}
+}
+
+// resilienceMetrics returns the client's collector if it records resilience metrics
+func (c *Client) resilienceMetrics() ResilienceMetricsCollector {
+	collector, _ := c.metrics.(ResilienceMetricsCollector)
+	return collector
+}
+
 // RequestHook is called before a request is made
 type RequestHook func(ctx context.Context, operation string, request interface{}) context.Context
 
 // ResponseHook is called after a response is received
 type ResponseHook func(ctx context.Context, operation string, request interface{}, response interface{}, err error)
 
-// ObservableClient wraps a client with observability features
+// StreamHook is called with every chunk of a stream as it is read, e.g. to sample
+// streamed content, detect policy violations mid-flight, or measure inter-chunk gaps
+type StreamHook func(ctx context.Context, operation string, chunkIndex int, chunk *models.ChatCompletionResponse)
+
+// ObservableClient wraps a client with observability features. It is safe for concurrent
+// use, including adding hooks while requests are in flight.
 type ObservableClient struct {
 	*Client
 	logger        Logger
 	metrics       MetricsCollector
+	hooksMu       sync.RWMutex
 	requestHooks  []RequestHook
 	responseHooks []ResponseHook
+	streamHooks   []StreamHook
 	logRequests   bool
 	logResponses  bool
 	trackCosts    bool
+	redaction     RedactionOptions
 }
 
 // ObservabilityOptions contains options for observability
@@ -50,6 +138,51 @@ type ObservabilityOptions struct {
 	LogRequests  bool
 	LogResponses bool
 	TrackCosts   bool
+
+	// Redaction controls what message content reaches the logs
+	Redaction RedactionOptions
+}
+
+// DefaultMaxLoggedContentLength is the content length logged when RedactionOptions.MaxContentLength is zero
+const DefaultMaxLoggedContentLength = 200
+
+// RedactionOptions controls how request and response content is logged. Content is not
+// logged unless LogContent is set, and API keys are always masked.
+type RedactionOptions struct {
+	// LogContent logs the last request message and the response content
+	LogContent bool
+
+	// MaxContentLength truncates logged content. Defaults to DefaultMaxLoggedContentLength.
+	MaxContentLength int
+}
+
+// apiKeyPattern matches OpenRouter API keys
+var apiKeyPattern = regexp.MustCompile(`sk-or-[A-Za-z0-9_-]+`)
+
+// MaskAPIKey masks all but the last four characters of an API key
+func MaskAPIKey(key string) string {
+	if len(key) <= 8 {
+		return strings.Repeat("*", len(key))
+	}
+	return key[:6] + "..." + key[len(key)-4:]
+}
+
+// redactSecrets masks API keys found in s
+func redactSecrets(s string) string {
+	return apiKeyPattern.ReplaceAllStringFunc(s, MaskAPIKey)
+}
+
+// content prepares message content for logging
+func (r RedactionOptions) content(text string) string {
+	maxLen := r.MaxContentLength
+	if maxLen <= 0 {
+		maxLen = DefaultMaxLoggedContentLength
+	}
+	text = redactSecrets(text)
+	if runes := []rune(text); len(runes) > maxLen {
+		text = string(runes[:maxLen]) + "..."
+	}
+	return text
 }
 
 // NewObservableClient creates a new observable client
@@ -61,36 +194,134 @@ func NewObservableClient(apiKey string, obsOpts ObservabilityOptions, clientOpts
 		logRequests:  obsOpts.LogRequests,
 		logResponses: obsOpts.LogResponses,
 		trackCosts:   obsOpts.TrackCosts,
+		redaction:    obsOpts.Redaction,
 	}
 }
 
 // AddRequestHook adds a request hook
 func (o *ObservableClient) AddRequestHook(hook RequestHook) {
+	o.hooksMu.Lock()
+	defer o.hooksMu.Unlock()
 	o.requestHooks = append(o.requestHooks, hook)
 }
 
 // AddResponseHook adds a response hook
 func (o *ObservableClient) AddResponseHook(hook ResponseHook) {
+	o.hooksMu.Lock()
+	defer o.hooksMu.Unlock()
 	o.responseHooks = append(o.responseHooks, hook)
 }
 
+// AddStreamHook adds a hook called with every chunk of streams opened after it is added
+func (o *ObservableClient) AddStreamHook(hook StreamHook) {
+	o.hooksMu.Lock()
+	defer o.hooksMu.Unlock()
+	o.streamHooks = append(o.streamHooks, hook)
+}
+
+// hooks returns the current hooks
+func (o *ObservableClient) hooks() ([]RequestHook, []ResponseHook) {
+	o.hooksMu.RLock()
+	defer o.hooksMu.RUnlock()
+	return o.requestHooks, o.responseHooks
+}
+
+// CreateChatCompletionStream creates a streaming chat completion with observability.
+// Stream hooks run on every chunk as it is read; response hooks run once the stream ends
+// or is closed, with the stream's streaming.StreamSummary as the response, or when it
+// fails to open.
+func (o *ObservableClient) CreateChatCompletionStream(ctx context.Context, req models.ChatCompletionRequest) (*streaming.ChatCompletionStreamReader, error) {
+	operation := "chat_completion_stream"
+	requestHooks, responseHooks := o.hooks()
+	o.hooksMu.RLock()
+	streamHooks := o.streamHooks
+	o.hooksMu.RUnlock()
+
+	for _, hook := range requestHooks {
+		ctx = hook(ctx, operation, req)
+	}
+	if o.logRequests && o.logger != nil {
+		o.logger.Info("Creating chat completion stream",
+			F("model", req.Model),
+			F("messages", len(req.Messages)),
+		)
+	}
+
+	stream, err := o.Client.CreateChatCompletionStream(ctx, req)
+	labels := map[string]string{
+		"model":     req.Model,
+		"operation": operation,
+		"status":    "success",
+	}
+	if err != nil {
+		labels["status"] = "error"
+		if o.metrics != nil {
+			o.metrics.RecordError(operation, err, labels)
+		}
+		if o.logger != nil {
+			o.logger.Error("Chat completion stream failed",
+				F("error", redactSecrets(err.Error())),
+				F("model", req.Model),
+			)
+		}
+		for _, hook := range responseHooks {
+			hook(ctx, operation, req, nil, err)
+		}
+		return nil, err
+	}
+
+	for _, hook := range streamHooks {
+		hook := hook
+		stream.OnChunk(func(index int, chunk *models.ChatCompletionResponse) {
+			hook(ctx, operation, index, chunk)
+		})
+	}
+	stream.OnComplete(func(summary streaming.StreamSummary) {
+		if summary.Provider != "" {
+			labels["provider"] = summary.Provider
+		}
+		if o.metrics != nil {
+			o.metrics.RecordLatency(operation, summary.Duration, labels)
+			recordStreamSummary(o.metrics, summary)
+		}
+		if o.logResponses && o.logger != nil {
+			o.logger.Info("Chat completion stream finished",
+				F("model", summary.Model),
+				F("provider", summary.Provider),
+				F("duration", summary.Duration),
+				F("chunks", summary.Chunks),
+			)
+		}
+		for _, hook := range responseHooks {
+			hook(ctx, operation, req, summary, nil)
+		}
+	})
+	return stream, nil
+}
+
 // CreateChatCompletion creates a chat completion with observability
 func (o *ObservableClient) CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
 	start := time.Now()
 	operation := "chat_completion"
+	requestHooks, responseHooks := o.hooks()
 
 	// Run request hooks
-	for _, hook := range o.requestHooks {
+	for _, hook := range requestHooks {
 		ctx = hook(ctx, operation, req)
 	}
 
 	// Log request if enabled
 	if o.logRequests && o.logger != nil {
-		o.logger.Info("Creating chat completion",
-			"model", req.Model,
-			"messages", len(req.Messages),
-			"stream", req.Stream,
-		)
+		fields := []Field{
+			F("model", req.Model),
+			F("messages", len(req.Messages)),
+			F("stream", req.Stream),
+		}
+		if o.redaction.LogContent && len(req.Messages) > 0 {
+			text, _ := req.Messages[len(req.Messages)-1].GetTextContent()
+			fields = append(fields, F("content", o.redaction.content(text)))
+		}
+		o.logger.Info("Creating chat completion", fields...)
 	}
 
 	// Make request
@@ -111,19 +342,30 @@ func (o *ObservableClient) CreateChatCompletion(ctx context.Context, req models.
 		}
 		if o.logger != nil {
 			o.logger.Error("Chat completion failed",
-				"error", err,
-				"model", req.Model,
-				"duration", duration,
+				F("error", redactSecrets(err.Error())),
+				F("model", req.Model),
+				F("duration", duration),
 			)
 		}
 	} else {
 		// Log response if enabled
 		if o.logResponses && o.logger != nil {
-			o.logger.Info("Chat completion succeeded",
-				"model", resp.Model,
-				"duration", duration,
-				"choices", len(resp.Choices),
-			)
+			fields := []Field{
+				F("model", resp.Model),
+				F("provider", resp.Provider),
+				F("duration", duration),
+				F("choices", len(resp.Choices)),
+			}
+			if o.redaction.LogContent {
+				if text, err := responseText(resp); err == nil {
+					fields = append(fields, F("content", o.redaction.content(text)))
+				}
+			}
+			o.logger.Info("Chat completion succeeded", fields...)
+		}
+
+		if resp.Provider != "" {
+			labels["provider"] = resp.Provider
 		}
 
 		// Record metrics
@@ -146,7 +388,7 @@ func (o *ObservableClient) CreateChatCompletion(ctx context.Context, req models.
 	}
 
 	// Run response hooks
-	for _, hook := range o.responseHooks {
+	for _, hook := range responseHooks {
 		hook(ctx, operation, req, resp, err)
 	}
 
@@ -156,28 +398,24 @@ func (o *ObservableClient) CreateChatCompletion(ctx context.Context, req models.
 // trackGenerationCost tracks the cost of a generation
 func (o *ObservableClient) trackGenerationCost(ctx context.Context, generationID string, labels map[string]string) {
 	// Wait a bit for generation to be processed
-	time.Sleep(2 * time.Second)
+	select {
+	case <-o.clock.After(2 * time.Second):
+	case <-ctx.Done():
+		return
+	}
 
 	genResp, err := o.Client.GetGeneration(ctx, generationID)
 	if err != nil {
 		if o.logger != nil {
 			o.logger.Warn("Failed to get generation cost",
-				"generation_id", generationID,
-				"error", err,
+				F("generation_id", generationID),
+				F("error", redactSecrets(err.Error())),
 			)
 		}
 		return
 	}
 
-	// Handle usage field which may be interface{} due to API inconsistencies
-	var totalCost float64
-	if usage, ok := genResp.Data.Usage.(map[string]interface{}); ok {
-		if cost, exists := usage["total_cost"]; exists {
-			if costFloat, ok := cost.(float64); ok {
-				totalCost = costFloat
-			}
-		}
-	}
+	totalCost := genResp.Data.Cost()
 
 	if o.metrics != nil && totalCost > 0 {
 		o.metrics.RecordCost(totalCost, labels)
@@ -185,10 +423,11 @@ func (o *ObservableClient) trackGenerationCost(ctx context.Context, generationID
 
 	if o.logger != nil {
 		o.logger.Debug("Generation cost tracked",
-			"generation_id", generationID,
-			"cost", totalCost,
-			"prompt_tokens", genResp.Data.NativeTokenCounts.PromptTokens,
-			"completion_tokens", genResp.Data.NativeTokenCounts.CompletionTokens,
+			F("generation_id", generationID),
+			F("cost", totalCost),
+			F("prompt_tokens", genResp.Data.NativeTokenCounts.PromptTokens),
+			F("completion_tokens", genResp.Data.NativeTokenCounts.CompletionTokens),
+			F("provider_latency", genResp.Data.ProviderLatency()),
 		)
 	}
 }
@@ -213,75 +452,218 @@ func NewSimpleLogger(level LogLevel) *SimpleLogger {
 	return &SimpleLogger{level: level}
 }
 
-func (l *SimpleLogger) Debug(msg string, fields ...interface{}) {
+func (l *SimpleLogger) Debug(msg string, fields ...Field) {
 	if l.level <= LogLevelDebug {
-		log.Printf("[DEBUG] %s %v", msg, fields)
+		log.Printf("[DEBUG] %s%s", msg, formatFields(fields))
 	}
 }
 
-func (l *SimpleLogger) Info(msg string, fields ...interface{}) {
+func (l *SimpleLogger) Info(msg string, fields ...Field) {
 	if l.level <= LogLevelInfo {
-		log.Printf("[INFO] %s %v", msg, fields)
+		log.Printf("[INFO] %s%s", msg, formatFields(fields))
 	}
 }
 
-func (l *SimpleLogger) Warn(msg string, fields ...interface{}) {
+func (l *SimpleLogger) Warn(msg string, fields ...Field) {
 	if l.level <= LogLevelWarn {
-		log.Printf("[WARN] %s %v", msg, fields)
+		log.Printf("[WARN] %s%s", msg, formatFields(fields))
 	}
 }
 
-func (l *SimpleLogger) Error(msg string, fields ...interface{}) {
+func (l *SimpleLogger) Error(msg string, fields ...Field) {
 	if l.level <= LogLevelError {
-		log.Printf("[ERROR] %s %v", msg, fields)
+		log.Printf("[ERROR] %s%s", msg, formatFields(fields))
+	}
+}
+
+// formatFields formats fields as key=value pairs
+func formatFields(fields []Field) string {
+	var b strings.Builder
+	for _, field := range fields {
+		fmt.Fprintf(&b, " %s=%v", field.Key, field.Value)
+	}
+	return b.String()
+}
+
+// SlogLogger adapts a *slog.Logger to the Logger interface
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a Logger that writes to logger, or slog.Default() if nil
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogLogger{logger: logger}
+}
+
+func (l *SlogLogger) Debug(msg string, fields ...Field) {
+	l.logger.Debug(msg, slogAttrs(fields)...)
+}
+
+func (l *SlogLogger) Info(msg string, fields ...Field) {
+	l.logger.Info(msg, slogAttrs(fields)...)
+}
+
+func (l *SlogLogger) Warn(msg string, fields ...Field) {
+	l.logger.Warn(msg, slogAttrs(fields)...)
+}
+
+func (l *SlogLogger) Error(msg string, fields ...Field) {
+	l.logger.Error(msg, slogAttrs(fields)...)
+}
+
+// slogAttrs converts fields to slog attributes
+func slogAttrs(fields []Field) []interface{} {
+	attrs := make([]interface{}, len(fields))
+	for i, field := range fields {
+		attrs[i] = slog.Any(field.Key, field.Value)
 	}
+	return attrs
 }
 
-// SimpleMetricsCollector implements MetricsCollector with in-memory storage
+// SimpleMetricsCollector implements ResilienceMetricsCollector, StreamingMetricsCollector
+// and DriftMetricsCollector with in-memory storage. It is safe for concurrent use.
 type SimpleMetricsCollector struct {
+	mu sync.Mutex
+
 	latencies map[string][]time.Duration
 	tokens    map[string]int
 	costs     float64
 	errors    map[string]int
+
+	retries          map[string]int
+	retriesExhausted map[string]int
+	circuitTrips     int
+	circuitState     CircuitState
+	queueDepth       int
+	limiterWaits     int
+	limiterWaitTotal time.Duration
+
+	timeToFirstToken map[string][]time.Duration
+	tokensPerSecond  map[string][]float64
+
+	driftScores map[string][]float64
 }
 
 // NewSimpleMetricsCollector creates a new simple metrics collector
 func NewSimpleMetricsCollector() *SimpleMetricsCollector {
 	return &SimpleMetricsCollector{
-		latencies: make(map[string][]time.Duration),
-		tokens:    make(map[string]int),
-		errors:    make(map[string]int),
+		latencies:        make(map[string][]time.Duration),
+		tokens:           make(map[string]int),
+		errors:           make(map[string]int),
+		retries:          make(map[string]int),
+		retriesExhausted: make(map[string]int),
+		timeToFirstToken: make(map[string][]time.Duration),
+		tokensPerSecond:  make(map[string][]float64),
+		driftScores:      make(map[string][]float64),
 	}
 }
 
 func (m *SimpleMetricsCollector) RecordLatency(operation string, duration time.Duration, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	key := fmt.Sprintf("%s_%s", operation, labels["model"])
 	m.latencies[key] = append(m.latencies[key], duration)
 }
 
 func (m *SimpleMetricsCollector) RecordTokens(promptTokens, completionTokens int, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.tokens["prompt"] += promptTokens
 	m.tokens["completion"] += completionTokens
 	m.tokens["total"] += promptTokens + completionTokens
 }
 
 func (m *SimpleMetricsCollector) RecordCost(cost float64, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.costs += cost
 }
 
 func (m *SimpleMetricsCollector) RecordError(operation string, err error, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	key := fmt.Sprintf("%s_%s", operation, labels["model"])
 	m.errors[key]++
 }
 
+func (m *SimpleMetricsCollector) RecordRetry(operation string, attempt int, err error, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retries[operation]++
+}
+
+func (m *SimpleMetricsCollector) RecordRetriesExhausted(operation string, err error, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retriesExhausted[operation]++
+}
+
+func (m *SimpleMetricsCollector) RecordCircuitStateChange(from, to CircuitState, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.circuitState = to
+	if to == CircuitOpen {
+		m.circuitTrips++
+	}
+}
+
+func (m *SimpleMetricsCollector) RecordQueueDepth(depth int, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queueDepth = depth
+}
+
+func (m *SimpleMetricsCollector) RecordLimiterWait(duration time.Duration, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.limiterWaits++
+	m.limiterWaitTotal += duration
+}
+
+func (m *SimpleMetricsCollector) RecordTimeToFirstToken(duration time.Duration, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.timeToFirstToken[labels["model"]] = append(m.timeToFirstToken[labels["model"]], duration)
+}
+
+func (m *SimpleMetricsCollector) RecordTokensPerSecond(tokensPerSecond float64, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tokensPerSecond[labels["model"]] = append(m.tokensPerSecond[labels["model"]], tokensPerSecond)
+}
+
+func (m *SimpleMetricsCollector) RecordDriftScore(score float64, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.driftScores[labels["model"]] = append(m.driftScores[labels["model"]], score)
+}
+
 // GetSummary returns a summary of collected metrics
 func (m *SimpleMetricsCollector) GetSummary() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	summary := map[string]interface{}{
 		"total_cost":        m.costs,
 		"total_tokens":      m.tokens["total"],
 		"prompt_tokens":     m.tokens["prompt"],
 		"completion_tokens": m.tokens["completion"],
-		"errors":            m.errors,
+		"errors":            copyCounts(m.errors),
 	}
 
 	// Calculate average latencies
@@ -297,5 +679,53 @@ func (m *SimpleMetricsCollector) GetSummary() map[string]interface{} {
 	}
 	summary["avg_latency_ms"] = avgLatencies
 
+	avgTTFT := make(map[string]float64)
+	for model, durations := range m.timeToFirstToken {
+		var total time.Duration
+		for _, d := range durations {
+			total += d
+		}
+		avgTTFT[model] = float64(total) / float64(len(durations)) / float64(time.Millisecond)
+	}
+	summary["avg_ttft_ms"] = avgTTFT
+
+	avgTPS := make(map[string]float64)
+	for model, rates := range m.tokensPerSecond {
+		var total float64
+		for _, rate := range rates {
+			total += rate
+		}
+		avgTPS[model] = total / float64(len(rates))
+	}
+	summary["avg_tokens_per_second"] = avgTPS
+
+	avgDrift := make(map[string]float64)
+	for model, scores := range m.driftScores {
+		var total float64
+		for _, score := range scores {
+			total += score
+		}
+		avgDrift[model] = total / float64(len(scores))
+	}
+	summary["avg_drift_score"] = avgDrift
+
+	summary["retries"] = copyCounts(m.retries)
+	summary["retries_exhausted"] = copyCounts(m.retriesExhausted)
+	summary["circuit_trips"] = m.circuitTrips
+	summary["circuit_state"] = m.circuitState.String()
+	summary["queue_depth"] = m.queueDepth
+	if m.limiterWaits > 0 {
+		summary["avg_limiter_wait_ms"] = float64(m.limiterWaitTotal) / float64(m.limiterWaits) / float64(time.Millisecond)
+	}
+
 	return summary
 }
+
+// copyCounts copies a counter map so summaries don't share state with the collector
+func copyCounts(counts map[string]int) map[string]int {
+	copied := make(map[string]int, len(counts))
+	for key, count := range counts {
+		copied[key] = count
+	}
+	return copied
+}
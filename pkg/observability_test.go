@@ -0,0 +1,189 @@
+package pkg_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+func TestObservableClientStreamHooks(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("one two three"), openroutertest.ErrorReply(503, "down"))
+
+	observable := pkg.NewObservableClient("sk-or-test", pkg.ObservabilityOptions{}, pkg.WithBaseURL(srv.URL))
+	var indexes []int
+	var text string
+	observable.AddStreamHook(func(ctx context.Context, operation string, chunkIndex int, chunk *models.ChatCompletionResponse) {
+		assert.Equal(t, "chat_completion_stream", operation)
+		indexes = append(indexes, chunkIndex)
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta != nil {
+			content, _ := chunk.Choices[0].Delta.GetTextContent()
+			text += content
+		}
+	})
+	var responses []interface{}
+	var errs []error
+	observable.AddResponseHook(func(ctx context.Context, operation string, request, response interface{}, err error) {
+		responses = append(responses, response)
+		errs = append(errs, err)
+	})
+
+	stream, err := observable.CreateChatCompletionStream(context.Background(), models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	chunks := 0
+	for {
+		_, err := stream.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		chunks++
+	}
+	stream.Close()
+
+	require.Len(t, indexes, chunks)
+	for i, index := range indexes {
+		assert.Equal(t, i, index)
+	}
+	assert.Equal(t, "one two three", text)
+	require.Len(t, responses, 1)
+	summary, ok := responses[0].(streaming.StreamSummary)
+	require.True(t, ok)
+	assert.Equal(t, chunks, summary.Chunks)
+	assert.NoError(t, errs[0])
+
+	_, err = observable.CreateChatCompletionStream(context.Background(), models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.Error(t, err)
+	require.Len(t, errs, 2)
+	assert.Error(t, errs[1])
+	assert.Nil(t, responses[1])
+}
+
+func TestObservableClientStreamMetrics(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("one two three"))
+
+	metrics := pkg.NewSimpleMetricsCollector()
+	observable := pkg.NewObservableClient("sk-or-test", pkg.ObservabilityOptions{Metrics: metrics}, pkg.WithBaseURL(srv.URL))
+
+	stream, err := observable.CreateChatCompletionStream(context.Background(), models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	for {
+		_, err := stream.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	stream.Close()
+
+	summary := metrics.GetSummary()
+	assert.Contains(t, summary["avg_ttft_ms"], openroutertest.DefaultModel)
+	assert.Contains(t, summary["avg_tokens_per_second"], openroutertest.DefaultModel)
+	assert.Positive(t, summary["total_tokens"])
+}
+
+// slogRecords decodes the JSON lines written by a slog.JSONHandler
+func slogRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var record map[string]interface{}
+		require.NoError(t, dec.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestObservableClientRedaction(t *testing.T) {
+	const key = "sk-or-v1-0123456789abcdef"
+	req := models.NewChatRequest("m", models.WithUserMessage("My key is "+key+", héllo wörld"))
+
+	tests := []struct {
+		name      string
+		redaction pkg.RedactionOptions
+		request   interface{}
+		response  interface{}
+	}{
+		{"content off by default", pkg.RedactionOptions{}, nil, nil},
+		{"default length", pkg.RedactionOptions{LogContent: true}, "My key is sk-or-...cdef, héllo wörld", "Réponse à sk-or-...cdef"},
+		{"truncated by rune", pkg.RedactionOptions{LogContent: true, MaxContentLength: 28}, "My key is sk-or-...cdef, hél...", "Réponse à sk-or-...cdef"},
+		{"truncated response", pkg.RedactionOptions{LogContent: true, MaxContentLength: 9}, "My key is...", "Réponse à..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := openroutertest.NewServer()
+			defer srv.Close()
+			srv.EnqueueChat(openroutertest.TextReply("Réponse à " + key))
+
+			var buf bytes.Buffer
+			observable := pkg.NewObservableClient("sk-or-test", pkg.ObservabilityOptions{
+				Logger:       pkg.NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+				LogRequests:  true,
+				LogResponses: true,
+				Redaction:    tt.redaction,
+			}, pkg.WithBaseURL(srv.URL))
+			_, err := observable.CreateChatCompletion(context.Background(), req)
+			require.NoError(t, err)
+
+			records := slogRecords(t, &buf)
+			require.Len(t, records, 2)
+			assert.Equal(t, "Creating chat completion", records[0]["msg"])
+			assert.Equal(t, "m", records[0]["model"])
+			assert.Equal(t, float64(1), records[0]["messages"])
+			assert.Equal(t, tt.request, records[0]["content"])
+			assert.Equal(t, "Chat completion succeeded", records[1]["msg"])
+			assert.Equal(t, float64(1), records[1]["choices"])
+			assert.Equal(t, tt.response, records[1]["content"])
+			assert.NotContains(t, buf.String(), key)
+		})
+	}
+}
+
+func TestObservableClientRedactsErrors(t *testing.T) {
+	const key = "sk-or-v1-0123456789abcdef"
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.ErrorReply(401, "invalid key "+key))
+
+	var buf bytes.Buffer
+	observable := pkg.NewObservableClient("sk-or-test", pkg.ObservabilityOptions{
+		Logger: pkg.NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+	}, pkg.WithBaseURL(srv.URL))
+	_, err := observable.CreateChatCompletion(context.Background(), hiRequest)
+	require.ErrorContains(t, err, key, "only the logs are redacted")
+
+	records := slogRecords(t, &buf)
+	require.Len(t, records, 1)
+	assert.Equal(t, "ERROR", records[0]["level"])
+	assert.Contains(t, records[0]["error"], "invalid key sk-or-...cdef")
+	assert.NotContains(t, buf.String(), key)
+}
+
+func TestMaskAPIKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"sk-or-v1-0123456789abcdef", "sk-or-...cdef"},
+		{"sk-or-v1", "********"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, pkg.MaskAPIKey(tt.key), tt.key)
+	}
+}
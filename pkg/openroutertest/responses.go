@@ -0,0 +1,107 @@
+package openroutertest
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// DefaultModel is the model reported by responses built in this package
+const DefaultModel = "openai/gpt-4o-mini"
+
+// DefaultProvider is the provider reported by responses built in this package
+const DefaultProvider = "OpenRouterTest"
+
+// NewTextResponse builds a non-streaming response with a single assistant message
+func NewTextResponse(text string) *models.ChatCompletionResponse {
+	message := models.NewTextMessage(models.RoleAssistant, text)
+	completionTokens := len(strings.Fields(text))
+
+	return &models.ChatCompletionResponse{
+		Object:   "chat.completion",
+		Created:  time.Now().Unix(),
+		Model:    DefaultModel,
+		Provider: DefaultProvider,
+		Choices: []models.Choice{{
+			Index:              0,
+			Message:            &message,
+			FinishReason:       "stop",
+			NativeFinishReason: "stop",
+		}},
+		Usage: &models.Usage{
+			PromptTokens:     10,
+			CompletionTokens: completionTokens,
+			TotalTokens:      10 + completionTokens,
+		},
+	}
+}
+
+// ResponseToChunks splits a response into the chunks a provider would stream:
+// content deltas word by word, tool calls, then a final chunk with the finish reason and usage
+func ResponseToChunks(resp *models.ChatCompletionResponse) []*models.ChatCompletionResponse {
+	newChunk := func(delta models.Message) *models.ChatCompletionResponse {
+		return &models.ChatCompletionResponse{
+			ID:       resp.ID,
+			Object:   "chat.completion.chunk",
+			Created:  resp.Created,
+			Model:    resp.Model,
+			Provider: resp.Provider,
+			Choices:  []models.Choice{{Index: 0, Delta: &delta}},
+		}
+	}
+
+	var chunks []*models.ChatCompletionResponse
+	finishReason := "stop"
+	nativeReason := ""
+
+	if len(resp.Choices) > 0 {
+		choice := resp.Choices[0]
+		if choice.FinishReason != "" {
+			finishReason = choice.FinishReason
+		}
+		nativeReason = choice.NativeFinishReason
+
+		if msg := choice.Message; msg != nil {
+			text, _ := msg.GetTextContent()
+			for _, piece := range splitWords(text) {
+				delta := models.NewTextMessage(models.RoleAssistant, piece)
+				chunks = append(chunks, newChunk(delta))
+			}
+
+			for i, call := range msg.ToolCalls {
+				index := i
+				call.Index = &index
+				chunks = append(chunks, newChunk(models.Message{
+					Role:      models.RoleAssistant,
+					Content:   json.RawMessage(`""`),
+					ToolCalls: []models.ToolCall{call},
+				}))
+			}
+		}
+	}
+
+	final := newChunk(models.Message{Role: models.RoleAssistant, Content: json.RawMessage(`""`)})
+	final.Choices[0].FinishReason = finishReason
+	final.Choices[0].NativeFinishReason = nativeReason
+	final.Usage = resp.Usage
+	return append(chunks, final)
+}
+
+// splitWords splits text into words that keep their trailing whitespace,
+// so concatenating the pieces reproduces the original text
+func splitWords(text string) []string {
+	var pieces []string
+	start := 0
+	for i := 1; i < len(text); i++ {
+		if text[i-1] == ' ' && text[i] != ' ' {
+			pieces = append(pieces, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		pieces = append(pieces, text[start:])
+	}
+	return pieces
+}
@@ -0,0 +1,692 @@
+// Package openroutertest provides an in-process OpenRouter API emulator for tests.
+//
+// The server implements /chat/completions (JSON and SSE), /completions, /models,
+// /generation, OAuth code exchange, and the key, credits, activity, provider, and
+// endpoint management routes with scriptable replies, latency injection, and error
+// scenarios:
+//
+//	server := openroutertest.NewServer()
+//	defer server.Close()
+//
+//	server.EnqueueChat(openroutertest.TextReply("Hello!"))
+//	client := server.Client()
+//	resp, err := client.CreateChatCompletion(ctx, req)
+package openroutertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// Reply scripts the server's answer to a single completion request
+type Reply struct {
+	// Response is returned for non-streaming requests. For streaming requests
+	// without Chunks, it is split into content chunks automatically.
+	Response *models.ChatCompletionResponse
+
+	// Chunks are sent as SSE events for streaming requests
+	Chunks []*models.ChatCompletionResponse
+
+	// Events, when set, are sent instead of Chunks, for streams with comments, raw data
+	// or error events
+	Events []StreamEvent
+
+	// Body, when set, is sent verbatim instead of Response for non-streaming requests,
+	// e.g. to return fields the SDK doesn't model yet
+	Body string
+
+	// Error makes the server reply with an API error instead of a response
+	Error *Error
+
+	// Latency delays the reply (or the first chunk of a stream)
+	Latency time.Duration
+
+	// ChunkDelay is the pause between streamed chunks
+	ChunkDelay time.Duration
+
+	// Header is added to the response headers, e.g. rate-limit headers
+	Header http.Header
+}
+
+// Error is an API error returned by the server
+type Error struct {
+	// Status is the HTTP status code. Defaults to Code.
+	Status   int
+	Code     int
+	Message  string
+	Metadata map[string]interface{}
+
+	// MidStream sends the error as an SSE event after the scripted chunks
+	// instead of failing the request up front
+	MidStream bool
+}
+
+// TextReply returns a reply with a single assistant message
+func TextReply(text string) Reply {
+	return Reply{Response: NewTextResponse(text)}
+}
+
+// ErrorReply returns a reply that fails with the given status code and message
+func ErrorReply(code int, message string) Reply {
+	return Reply{Error: &Error{Code: code, Message: message}}
+}
+
+// RecordedRequest is a request received by the server
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// ChatRequest decodes the body as a chat completion request
+func (r RecordedRequest) ChatRequest() (models.ChatCompletionRequest, error) {
+	var req models.ChatCompletionRequest
+	err := json.Unmarshal(r.Body, &req)
+	return req, err
+}
+
+// ChatHandler computes a reply for requests that have no enqueued reply
+type ChatHandler func(req models.ChatCompletionRequest) Reply
+
+// Server emulates the OpenRouter API
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	chatReplies []Reply
+	chatHandler ChatHandler
+	errors      map[string][]Error
+	outage      *Error
+	latency     time.Duration
+	requests    []RecordedRequest
+
+	models      []models.Model
+	endpoints   map[string][]models.ModelEndpoint
+	providers   []models.Provider
+	generations map[string]models.Generation
+	keys        map[string]models.APIKey
+	keyOrder    []string
+	authCodes   map[string]string
+	credits     models.CreditsResponse
+	activity    []models.ActivityItem
+	pageSize    int
+	nextID      int
+}
+
+// NewServer starts a new emulated OpenRouter API server
+func NewServer() *Server {
+	s := &Server{
+		errors:      make(map[string][]Error),
+		endpoints:   make(map[string][]models.ModelEndpoint),
+		generations: make(map[string]models.Generation),
+		keys:        make(map[string]models.APIKey),
+		authCodes:   make(map[string]string),
+		chatHandler: EchoHandler,
+		models: []models.Model{
+			{
+				ID:            "openai/gpt-4o-mini",
+				Name:          "OpenAI: GPT-4o-mini",
+				ContextLength: 128000,
+				Pricing:       models.Pricing{Prompt: "0.00000015", Completion: "0.0000006"},
+			},
+		},
+	}
+	s.credits.Data.TotalCredits = 100
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chat/completions", s.handleCompletion)
+	mux.HandleFunc("/completions", s.handleCompletion)
+	mux.HandleFunc("/models", s.handleModels)
+	mux.HandleFunc("/generation", s.handleGeneration)
+	mux.HandleFunc("/api/v1/auth/keys", s.handleAuthKeys)
+	mux.HandleFunc("/api/v1/keys", s.handleKeys)
+	mux.HandleFunc("/api/v1/keys/", s.handleKey)
+	mux.HandleFunc("/api/v1/me/keys", s.handleCurrentKey)
+	mux.HandleFunc("/api/v1/me/credits", s.handleCredits)
+	mux.HandleFunc("/api/v1/activity", s.handleActivity)
+	mux.HandleFunc("/api/v1/providers", s.handleProviders)
+	mux.HandleFunc("/api/v1/endpoints/", s.handleEndpoints)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// Client returns a client configured to talk to the server
+func (s *Server) Client(opts ...pkg.Option) *pkg.Client {
+	return pkg.NewClient("sk-or-test", append([]pkg.Option{pkg.WithBaseURL(s.URL)}, opts...)...)
+}
+
+// EnqueueChat queues replies for upcoming completion requests, in order
+func (s *Server) EnqueueChat(replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatReplies = append(s.chatReplies, replies...)
+}
+
+// SetChatHandler sets the handler used when no reply is enqueued. Defaults to EchoHandler.
+func (s *Server) SetChatHandler(handler ChatHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatHandler = handler
+}
+
+// InjectError makes the next request to path fail with err. Multiple errors are consumed in order.
+func (s *Server) InjectError(path string, err Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[path] = append(s.errors[path], err)
+}
+
+// SetOutage makes every request fail with err until SetOutage(nil) is called, e.g. to
+// simulate a gateway that is down
+func (s *Server) SetOutage(err *Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outage = err
+}
+
+// SetLatency delays every response by d
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// SetModels replaces the models returned by /models
+func (s *Server) SetModels(list ...models.Model) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models = list
+}
+
+// SetProviders replaces the providers returned by /api/v1/providers
+func (s *Server) SetProviders(list ...models.Provider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.providers = list
+}
+
+// SetEndpoints sets the endpoints returned for a model ("author/slug")
+func (s *Server) SetEndpoints(model string, list ...models.ModelEndpoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints[model] = list
+}
+
+// SetGeneration stores generation metadata served by /generation
+func (s *Server) SetGeneration(gen models.Generation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generations[gen.ID] = gen
+}
+
+// AddAuthCode registers an authorization code that /api/v1/auth/keys exchanges for key
+func (s *Server) AddAuthCode(code, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authCodes[code] = key
+}
+
+// SetCredits sets the values returned by /api/v1/me/credits
+func (s *Server) SetCredits(total, usage float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.credits.Data.TotalCredits = total
+	s.credits.Data.TotalUsage = usage
+}
+
+// SetActivity sets the items returned by /api/v1/activity
+func (s *Server) SetActivity(items ...models.ActivityItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activity = items
+}
+
+// SetPageSize limits paginated lists, such as /api/v1/keys, to n items per page. Zero,
+// the default, returns every item from the requested offset on.
+func (s *Server) SetPageSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = n
+}
+
+// Requests returns all requests received so far
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := make([]RecordedRequest, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
+
+// Reset clears recorded requests, queued replies, injected errors, and any outage
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.chatReplies = nil
+	s.errors = make(map[string][]Error)
+	s.outage = nil
+}
+
+// EchoHandler replies with the text of the last message in the request
+func EchoHandler(req models.ChatCompletionRequest) Reply {
+	text := req.Prompt
+	if len(req.Messages) > 0 {
+		text, _ = req.Messages[len(req.Messages)-1].GetTextContent()
+	}
+	resp := NewTextResponse(text)
+	if req.Model != "" {
+		resp.Model = req.Model
+	}
+	return Reply{Response: resp}
+}
+
+// middleware records requests and applies latency and injected errors
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, RecordedRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Header: r.Header.Clone(),
+			Body:   body,
+		})
+		latency := s.latency
+		injected := s.outage
+		if queue := s.errors[r.URL.Path]; injected == nil && len(queue) > 0 {
+			injected = &queue[0]
+			s.errors[r.URL.Path] = queue[1:]
+		}
+		s.mu.Unlock()
+
+		if !sleep(r, latency) {
+			return
+		}
+		if injected != nil {
+			writeError(w, *injected)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleCompletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, Error{Code: http.StatusMethodNotAllowed, Message: "method not allowed"})
+		return
+	}
+
+	var req models.ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, Error{Code: http.StatusBadRequest, Message: "invalid request body: " + err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	var reply Reply
+	if len(s.chatReplies) > 0 {
+		reply = s.chatReplies[0]
+		s.chatReplies = s.chatReplies[1:]
+	} else {
+		reply = s.chatHandler(req)
+	}
+	s.mu.Unlock()
+
+	if !sleep(r, reply.Latency) {
+		return
+	}
+	for name, values := range reply.Header {
+		w.Header()[name] = values
+	}
+	if reply.Error != nil && !reply.Error.MidStream {
+		writeError(w, *reply.Error)
+		return
+	}
+
+	resp := reply.Response
+	if resp == nil {
+		resp = NewTextResponse("")
+	}
+	if resp.ID == "" {
+		resp.ID = s.newID("gen")
+	}
+	s.recordGeneration(req, resp)
+
+	if req.Stream {
+		events := reply.Events
+		if len(events) == 0 {
+			chunks := reply.Chunks
+			if len(chunks) == 0 {
+				chunks = ResponseToChunks(resp)
+			}
+			events = make([]StreamEvent, 0, len(chunks)+1)
+			for _, chunk := range chunks {
+				events = append(events, StreamEvent{Chunk: chunk})
+			}
+		}
+		if reply.Error != nil {
+			events = append(events, StreamEvent{Error: &models.ChoiceError{
+				Code:     reply.Error.Code,
+				Message:  reply.Error.Message,
+				Metadata: reply.Error.Metadata,
+			}})
+		}
+		writeSSE(w, r, events, reply.ChunkDelay)
+		return
+	}
+
+	if reply.Body != "" {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, reply.Body)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// recordGeneration stores generation metadata so GetGeneration works for served completions
+func (s *Server) recordGeneration(req models.ChatCompletionRequest, resp *models.ChatCompletionResponse) {
+	gen := models.Generation{
+		ID:       resp.ID,
+		Model:    resp.Model,
+		Object:   "generation",
+		Created:  models.UnixSeconds(resp.Created),
+		Provider: resp.Provider,
+	}
+	if gen.Provider == "" {
+		gen.Provider = DefaultProvider
+	}
+	if resp.Usage != nil {
+		gen.NativeTokenCounts = models.NativeTokenCounts{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		}
+		gen.Usage = &models.GenerationUsage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+			TotalCost:        resp.Usage.Cost,
+		}
+		gen.TotalCost = resp.Usage.Cost
+		gen.TokensPrompt = resp.Usage.PromptTokens
+		gen.TokensCompletion = resp.Usage.CompletionTokens
+	}
+	if len(resp.Choices) > 0 {
+		gen.FinishReason = resp.Choices[0].FinishReason
+		gen.NativeFinishReason = resp.Choices[0].NativeFinishReason
+	}
+	gen.Streamed = req.Stream
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.generations[gen.ID]; !exists {
+		s.generations[gen.ID] = gen
+	}
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	resp := models.ModelsResponse{Data: append([]models.Model(nil), s.models...)}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleGeneration(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+
+	s.mu.Lock()
+	gen, ok := s.generations[id]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, Error{Code: http.StatusNotFound, Message: fmt.Sprintf("generation %s not found", id)})
+		return
+	}
+	writeJSON(w, http.StatusOK, models.GenerationResponse{Data: gen})
+}
+
+func (s *Server) handleAuthKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, Error{Code: http.StatusMethodNotAllowed, Message: "method not allowed"})
+		return
+	}
+	var req models.ExchangeAuthCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		writeError(w, Error{Code: http.StatusBadRequest, Message: "code is required"})
+		return
+	}
+
+	s.mu.Lock()
+	key, ok := s.authCodes[req.Code]
+	delete(s.authCodes, req.Code)
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, Error{Code: http.StatusForbidden, Message: "invalid authorization code"})
+		return
+	}
+	writeJSON(w, http.StatusOK, models.ExchangeAuthCodeResponse{Key: key, UserID: "user-test"})
+}
+
+func (s *Server) handleKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		includeDisabled := r.URL.Query().Get("include_disabled") == "true"
+		s.mu.Lock()
+		resp := models.APIKeysResponse{Data: []models.APIKey{}}
+		for _, hash := range s.keyOrder {
+			key := s.keys[hash]
+			if key.Disabled && !includeDisabled {
+				continue
+			}
+			key.Key = ""
+			resp.Data = append(resp.Data, key)
+		}
+		pageSize := s.pageSize
+		s.mu.Unlock()
+
+		if offset := r.URL.Query().Get("offset"); offset != "" {
+			var n int
+			fmt.Sscanf(offset, "%d", &n)
+			if n > len(resp.Data) {
+				n = len(resp.Data)
+			}
+			resp.Data = resp.Data[n:]
+		}
+		if pageSize > 0 && len(resp.Data) > pageSize {
+			resp.Data = resp.Data[:pageSize]
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodPost:
+		var req models.CreateAPIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+			writeError(w, Error{Code: http.StatusBadRequest, Message: "name is required"})
+			return
+		}
+
+		s.mu.Lock()
+		hash := s.newIDLocked("key")
+		now := time.Now().UTC()
+		key := models.APIKey{
+			CreatedAt: now,
+			UpdatedAt: now,
+			Hash:      hash,
+			Name:      req.Name,
+			Label:     req.Label,
+			Limit:     req.Limit,
+			Key:       "sk-or-v1-" + hash,
+		}
+		s.keys[hash] = key
+		s.keyOrder = append(s.keyOrder, hash)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, key)
+
+	default:
+		writeError(w, Error{Code: http.StatusMethodNotAllowed, Message: "method not allowed"})
+	}
+}
+
+func (s *Server) handleKey(w http.ResponseWriter, r *http.Request) {
+	hash := strings.TrimPrefix(r.URL.Path, "/api/v1/keys/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[hash]
+	if !ok {
+		writeError(w, Error{Code: http.StatusNotFound, Message: "key not found"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		key.Key = ""
+		writeJSON(w, http.StatusOK, key)
+
+	case http.MethodPatch:
+		var req models.UpdateAPIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, Error{Code: http.StatusBadRequest, Message: "invalid request body"})
+			return
+		}
+		if req.Name != nil {
+			key.Name = *req.Name
+		}
+		if req.Disabled != nil {
+			key.Disabled = *req.Disabled
+		}
+		if req.Limit != nil {
+			key.Limit = *req.Limit
+		}
+		key.UpdatedAt = time.Now().UTC()
+		s.keys[hash] = key
+		key.Key = ""
+		writeJSON(w, http.StatusOK, key)
+
+	case http.MethodDelete:
+		delete(s.keys, hash)
+		for i, h := range s.keyOrder {
+			if h == hash {
+				s.keyOrder = append(s.keyOrder[:i], s.keyOrder[i+1:]...)
+				break
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]bool{"deleted": true})
+
+	default:
+		writeError(w, Error{Code: http.StatusMethodNotAllowed, Message: "method not allowed"})
+	}
+}
+
+func (s *Server) handleCurrentKey(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	usage := s.credits.Data.TotalUsage
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, models.APIKey{
+		Hash:  "current",
+		Name:  "openroutertest",
+		Usage: usage,
+	})
+}
+
+func (s *Server) handleCredits(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	resp := s.credits
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleActivity(w http.ResponseWriter, r *http.Request) {
+	date := r.URL.Query().Get("date")
+
+	s.mu.Lock()
+	resp := models.ActivityResponse{Data: []models.ActivityItem{}}
+	for _, item := range s.activity {
+		if date == "" || item.Date == date {
+			resp.Data = append(resp.Data, item)
+		}
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleProviders(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	resp := models.ProvidersResponse{Data: append([]models.Provider{}, s.providers...)}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleEndpoints(w http.ResponseWriter, r *http.Request) {
+	model := strings.TrimPrefix(r.URL.Path, "/api/v1/endpoints/")
+
+	s.mu.Lock()
+	resp := models.ModelEndpointsResponse{Data: append([]models.ModelEndpoint{}, s.endpoints[model]...)}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) newID(prefix string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newIDLocked(prefix)
+}
+
+func (s *Server) newIDLocked(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-test-%d", prefix, s.nextID)
+}
+
+// sleep waits for d or until the request is cancelled, reporting whether to continue
+func sleep(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	select {
+	case <-time.After(d):
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err Error) {
+	status := err.Status
+	if status == 0 {
+		status = err.Code
+	}
+	if status < 400 || status > 599 {
+		status = http.StatusInternalServerError
+	}
+
+	var body struct {
+		Error models.ChoiceError `json:"error"`
+	}
+	body.Error = models.ChoiceError{Code: err.Code, Message: err.Message, Metadata: err.Metadata}
+	writeJSON(w, status, body)
+}
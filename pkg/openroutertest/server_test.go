@@ -0,0 +1,230 @@
+package openroutertest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/errors"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+var hiRequest = models.NewChatRequest("m", models.WithUserMessage("hi"))
+
+// apiErrorCode returns the code of the API error wrapped by err
+func apiErrorCode(t *testing.T, err error) errors.ErrorCode {
+	t.Helper()
+	require.Error(t, err)
+	var apiErr *errors.APIError
+	require.ErrorAs(t, err, &apiErr)
+	return apiErr.Code
+}
+
+func TestServerRoutes(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	list, err := client.ListModels(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, openroutertest.DefaultModel, list.Data[0].ID)
+
+	srv.SetProviders(models.Provider{ID: "openai", Name: "OpenAI"})
+	providers, err := client.ListProviders(ctx)
+	require.NoError(t, err)
+	require.Len(t, providers.Data, 1)
+	assert.Equal(t, "openai", providers.Data[0].ID)
+
+	srv.SetEndpoints("openai/gpt-4o-mini", models.ModelEndpoint{Provider: "OpenAI", Model: "openai/gpt-4o-mini"})
+	endpoints, err := client.ListModelEndpoints(ctx, "openai/gpt-4o-mini")
+	require.NoError(t, err)
+	assert.Len(t, endpoints.Data, 1)
+	endpoints, err = client.ListModelEndpoints(ctx, "unknown/model")
+	require.NoError(t, err)
+	assert.Empty(t, endpoints.Data)
+
+	srv.SetCredits(50, 12.5)
+	credits, err := client.GetCredits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, credits.Data.TotalCredits)
+	assert.Equal(t, 12.5, credits.Data.TotalUsage)
+
+	// Served completions can be looked up by generation ID
+	resp, err := client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+	gen, err := client.GetGeneration(ctx, resp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, resp.ID, gen.Data.ID)
+	assert.Equal(t, openroutertest.DefaultProvider, gen.Data.Provider)
+	_, err = client.GetGeneration(ctx, "gen-missing")
+	assert.Equal(t, errors.ErrorCode(http.StatusNotFound), apiErrorCode(t, err))
+
+	// Unknown routes and methods fail like the real API
+	httpResp, err := http.Get(srv.URL + "/chat/completions")
+	require.NoError(t, err)
+	httpResp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, httpResp.StatusCode)
+	httpResp, err = http.Get(srv.URL + "/no/such/route")
+	require.NoError(t, err)
+	httpResp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, httpResp.StatusCode)
+}
+
+func TestServerKeyRoutes(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	created, err := client.CreateAPIKey(ctx, models.CreateAPIKeyRequest{Name: "ci"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, "sk-or-v1-"), "only creation returns the key")
+
+	got, err := client.GetAPIKey(ctx, created.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", got.Name)
+	assert.Empty(t, got.Key)
+
+	disabled := true
+	_, err = client.UpdateAPIKey(ctx, created.Hash, models.UpdateAPIKeyRequest{Disabled: &disabled})
+	require.NoError(t, err)
+	keys, err := client.ListAPIKeys(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, keys.Data, "disabled keys are hidden by default")
+
+	require.NoError(t, client.DeleteAPIKey(ctx, created.Hash))
+	_, err = client.GetAPIKey(ctx, created.Hash)
+	assert.Equal(t, errors.ErrorCode(http.StatusNotFound), apiErrorCode(t, err))
+
+	// Authorization codes are exchanged once
+	srv.AddAuthCode("code-1", "sk-or-v1-user")
+	exchanged, err := client.ExchangeAuthCodeForAPIKey(ctx, models.ExchangeAuthCodeRequest{Code: "code-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "sk-or-v1-user", exchanged.Key)
+	_, err = client.ExchangeAuthCodeForAPIKey(ctx, models.ExchangeAuthCodeRequest{Code: "code-1"})
+	assert.Equal(t, errors.ErrorCode(http.StatusForbidden), apiErrorCode(t, err))
+}
+
+func TestServerStreamScripting(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	// Responses are split into word chunks with usage on the last one
+	srv.EnqueueChat(openroutertest.TextReply("one two three"))
+	stream, err := client.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	resp, err := streaming.CollectStream(stream)
+	require.NoError(t, err)
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "one two three", text)
+	require.NotNil(t, resp.Usage)
+	assert.Equal(t, 3, resp.Usage.CompletionTokens)
+
+	// Scripted events are sent in order, with the delay between them; comments are skipped
+	srv.EnqueueChat(openroutertest.Reply{
+		Events: []openroutertest.StreamEvent{
+			openroutertest.CommentEvent("OPENROUTER PROCESSING"),
+			openroutertest.TextChunk("Hello"),
+			openroutertest.TextChunk(", world"),
+			openroutertest.FinishChunk("stop"),
+		},
+		ChunkDelay: 10 * time.Millisecond,
+	})
+	start := time.Now()
+	stream, err = client.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	resp, err = streaming.CollectStream(stream)
+	require.NoError(t, err)
+	text, _ = resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "Hello, world", text)
+	assert.Equal(t, "stop", resp.Choices[0].FinishReason)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+	// Mid-stream errors arrive after the scripted chunks
+	srv.EnqueueChat(openroutertest.Reply{
+		Response: openroutertest.NewTextResponse("partial"),
+		Error:    &openroutertest.Error{Code: 502, Message: "provider disconnected", MidStream: true},
+	})
+	stream, err = client.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	_, err = streaming.CollectStream(stream)
+	assert.ErrorContains(t, err, "provider disconnected")
+
+	// Raw events reach the client verbatim
+	srv.EnqueueChat(openroutertest.Reply{Events: []openroutertest.StreamEvent{{Raw: "{not json"}}})
+	stream, err = client.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	defer stream.Close()
+	_, err = stream.Read()
+	assert.Error(t, err)
+}
+
+func TestServerRecordsRequests(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	_, err := client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+	_, err = client.GetActivity(ctx, &pkg.ActivityOptions{Date: "2025-01-01"})
+	require.NoError(t, err)
+
+	requests := srv.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, http.MethodPost, requests[0].Method)
+	assert.Equal(t, "/chat/completions", requests[0].Path)
+	assert.Equal(t, "Bearer sk-or-test", requests[0].Header.Get("Authorization"))
+	sent, err := requests[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "m", sent.Model)
+	assert.Equal(t, http.MethodGet, requests[1].Method)
+	assert.Equal(t, "/api/v1/activity", requests[1].Path)
+	assert.Equal(t, "date=2025-01-01", requests[1].Query)
+
+	// Requests that fail are recorded too
+	srv.InjectError("/chat/completions", openroutertest.Error{Code: 429, Message: "slow down"})
+	srv.InjectError("/chat/completions", openroutertest.Error{Code: 503, Message: "unavailable"})
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	assert.Equal(t, errors.ErrorCode(429), apiErrorCode(t, err))
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	assert.Equal(t, errors.ErrorCode(503), apiErrorCode(t, err))
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+	assert.Len(t, srv.Requests(), 5)
+
+	srv.SetOutage(&openroutertest.Error{Code: 502, Message: "down"})
+	_, err = client.ListModels(ctx, nil)
+	assert.Equal(t, errors.ErrorCode(502), apiErrorCode(t, err))
+
+	srv.Reset()
+	assert.Empty(t, srv.Requests())
+	_, err = client.ListModels(ctx, nil)
+	require.NoError(t, err, "Reset ends the outage")
+}
+
+func TestServerRawBody(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.Reply{Body: `{"id":"gen-raw","model":"m","choices":[]}`})
+
+	httpResp, err := http.Post(srv.URL+"/chat/completions", "application/json", strings.NewReader(`{"model":"m"}`))
+	require.NoError(t, err)
+	defer httpResp.Body.Close()
+	body, err := io.ReadAll(httpResp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"gen-raw","model":"m","choices":[]}`, string(body))
+}
@@ -0,0 +1,80 @@
+package openroutertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// StreamEvent is a single server-sent event in a scripted stream
+type StreamEvent struct {
+	// Chunk is sent as a "data:" event
+	Chunk *models.ChatCompletionResponse
+
+	// Error is sent as a top-level error event, as OpenRouter does for mid-stream failures
+	Error *models.ChoiceError
+
+	// Comment is sent as an SSE comment line, e.g. "OPENROUTER PROCESSING"
+	Comment string
+
+	// Raw is written verbatim as the event's data, e.g. to simulate malformed chunks
+	Raw string
+}
+
+// writeEvent encodes a single event
+func writeEvent(buf *bytes.Buffer, event StreamEvent) {
+	switch {
+	case event.Comment != "":
+		for _, line := range strings.Split(event.Comment, "\n") {
+			buf.WriteString(": ")
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	case event.Raw != "":
+		buf.WriteString("data: ")
+		buf.WriteString(event.Raw)
+		buf.WriteString("\n\n")
+	case event.Error != nil:
+		data, _ := json.Marshal(map[string]interface{}{"error": event.Error})
+		buf.WriteString("data: ")
+		buf.Write(data)
+		buf.WriteString("\n\n")
+	case event.Chunk != nil:
+		data, _ := json.Marshal(event.Chunk)
+		buf.WriteString("data: ")
+		buf.Write(data)
+		buf.WriteString("\n\n")
+	}
+}
+
+// writeSSE streams events to the response, flushing after each one
+func writeSSE(w http.ResponseWriter, r *http.Request, events []StreamEvent, delay time.Duration) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	var buf bytes.Buffer
+	for i, event := range events {
+		if i > 0 && !sleep(r, delay) {
+			return
+		}
+		buf.Reset()
+		writeEvent(&buf, event)
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	w.Write([]byte("data: [DONE]\n\n"))
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,167 @@
+package openroutertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+// NewStream returns a stream reader that yields the given events, so code consuming
+// streams can be unit tested without an HTTP server:
+//
+//	stream := openroutertest.NewStream(
+//		openroutertest.TextChunk("Hello"),
+//		openroutertest.TextChunk(", world"),
+//		openroutertest.FinishChunk("stop"),
+//	)
+func NewStream(events ...StreamEvent) *streaming.ChatCompletionStreamReader {
+	return streaming.NewChatCompletionStreamReader(io.NopCloser(bytes.NewReader(encodeSSE(events))))
+}
+
+// StreamFromResponse returns a stream reader that yields resp split into chunks
+func StreamFromResponse(resp *models.ChatCompletionResponse) *streaming.ChatCompletionStreamReader {
+	chunks := ResponseToChunks(resp)
+	events := make([]StreamEvent, len(chunks))
+	for i, chunk := range chunks {
+		events[i] = StreamEvent{Chunk: chunk}
+	}
+	return NewStream(events...)
+}
+
+// encodeSSE encodes events as an SSE payload terminated by [DONE]
+func encodeSSE(events []StreamEvent) []byte {
+	var buf bytes.Buffer
+	for _, event := range events {
+		writeEvent(&buf, event)
+	}
+	buf.WriteString("data: [DONE]\n\n")
+	return buf.Bytes()
+}
+
+// Chunk returns an event with a single-choice chunk carrying delta
+func Chunk(delta models.Message) StreamEvent {
+	if delta.Role == "" {
+		delta.Role = models.RoleAssistant
+	}
+	if delta.Content == nil {
+		delta.Content = json.RawMessage(`""`)
+	}
+	return StreamEvent{Chunk: &models.ChatCompletionResponse{
+		ID:      "gen-test-stream",
+		Object:  "chat.completion.chunk",
+		Model:   DefaultModel,
+		Choices: []models.Choice{{Index: 0, Delta: &delta}},
+	}}
+}
+
+// TextChunk returns an event with a content delta
+func TextChunk(text string) StreamEvent {
+	return Chunk(models.NewTextMessage(models.RoleAssistant, text))
+}
+
+// ReasoningChunk returns an event with a reasoning delta
+func ReasoningChunk(text string) StreamEvent {
+	return Chunk(models.Message{Reasoning: text})
+}
+
+// ToolCallChunk returns an event with a tool call delta. Arguments may be a fragment;
+// deltas with the same index are concatenated by consumers. ID and name are usually
+// only sent with the first fragment.
+func ToolCallChunk(index int, id, name, arguments string) StreamEvent {
+	call := models.ToolCall{
+		Index: &index,
+		ID:    id,
+		Function: models.FunctionCall{
+			Name:      name,
+			Arguments: arguments,
+		},
+	}
+	if id != "" {
+		call.Type = "function"
+	}
+	return Chunk(models.Message{ToolCalls: []models.ToolCall{call}})
+}
+
+// FinishChunk returns an event with an empty delta and the given finish reason
+func FinishChunk(reason string) StreamEvent {
+	event := Chunk(models.Message{})
+	event.Chunk.Choices[0].FinishReason = reason
+	return event
+}
+
+// UsageChunk returns the trailing usage event sent at the end of a stream
+func UsageChunk(promptTokens, completionTokens int) StreamEvent {
+	return StreamEvent{Chunk: &models.ChatCompletionResponse{
+		ID:      "gen-test-stream",
+		Object:  "chat.completion.chunk",
+		Model:   DefaultModel,
+		Choices: []models.Choice{},
+		Usage: &models.Usage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
+		},
+	}}
+}
+
+// ErrorEvent returns a mid-stream error event
+func ErrorEvent(code int, message string) StreamEvent {
+	return StreamEvent{Error: &models.ChoiceError{Code: code, Message: message}}
+}
+
+// CommentEvent returns an SSE comment event, like OpenRouter's keep-alive comments
+func CommentEvent(comment string) StreamEvent {
+	return StreamEvent{Comment: comment}
+}
+
+// NewToolCall builds a tool call with JSON-encoded arguments
+func NewToolCall(id, name string, arguments interface{}) models.ToolCall {
+	var args string
+	switch v := arguments.(type) {
+	case string:
+		args = v
+	default:
+		data, _ := json.Marshal(v)
+		args = string(data)
+	}
+
+	return models.ToolCall{
+		ID:   id,
+		Type: "function",
+		Function: models.FunctionCall{
+			Name:      name,
+			Arguments: args,
+		},
+	}
+}
+
+// NewToolCallResponse builds a response in which the assistant requests the given tool calls
+func NewToolCallResponse(calls ...models.ToolCall) *models.ChatCompletionResponse {
+	resp := NewTextResponse("")
+	resp.Choices[0].Message.ToolCalls = calls
+	resp.Choices[0].FinishReason = "tool_calls"
+	resp.Choices[0].NativeFinishReason = "tool_calls"
+	return resp
+}
+
+// NewMessages builds a conversation from alternating user and assistant texts,
+// starting with the user
+func NewMessages(texts ...string) []models.Message {
+	messages := make([]models.Message, len(texts))
+	for i, text := range texts {
+		role := models.RoleUser
+		if i%2 == 1 {
+			role = models.RoleAssistant
+		}
+		messages[i] = models.NewTextMessage(role, text)
+	}
+	return messages
+}
+
+// ToolCallReply returns a reply in which the assistant requests the given tool calls
+func ToolCallReply(calls ...models.ToolCall) Reply {
+	return Reply{Response: NewToolCallResponse(calls...)}
+}
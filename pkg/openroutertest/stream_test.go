@@ -0,0 +1,102 @@
+package openroutertest_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+func TestStreamBuilderFramesParse(t *testing.T) {
+	stream := openroutertest.NewStream(
+		openroutertest.CommentEvent("OPENROUTER PROCESSING"),
+		openroutertest.ReasoningChunk("Look it up."),
+		openroutertest.TextChunk("Checking"),
+		openroutertest.ToolCallChunk(0, "call_1", "get_weather", `{"city":`),
+		openroutertest.ToolCallChunk(0, "", "", `"Paris"}`),
+		openroutertest.FinishChunk("tool_calls"),
+		openroutertest.UsageChunk(12, 8),
+	)
+	defer stream.Close()
+
+	// Every data frame is a chunk; the comment is not
+	var chunks []*models.ChatCompletionResponse
+	for {
+		chunk, err := stream.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		chunks = append(chunks, chunk)
+	}
+	require.Len(t, chunks, 6)
+	assert.Equal(t, "Look it up.", chunks[0].Choices[0].Delta.Reasoning)
+	text, _ := chunks[1].Choices[0].Delta.GetTextContent()
+	assert.Equal(t, "Checking", text)
+	require.Len(t, chunks[2].Choices[0].Delta.ToolCalls, 1)
+	assert.Equal(t, "get_weather", chunks[2].Choices[0].Delta.ToolCalls[0].Function.Name)
+	assert.Equal(t, "tool_calls", chunks[4].Choices[0].FinishReason)
+	assert.Empty(t, chunks[5].Choices)
+	require.NotNil(t, chunks[5].Usage)
+	assert.Equal(t, 20, chunks[5].Usage.TotalTokens)
+}
+
+func TestStreamBuilderAccumulates(t *testing.T) {
+	resp, err := streaming.CollectStream(openroutertest.NewStream(
+		openroutertest.TextChunk("Hello"),
+		openroutertest.TextChunk(", world"),
+		openroutertest.ToolCallChunk(0, "call_1", "get_weather", `{"city":`),
+		openroutertest.ToolCallChunk(0, "", "", `"Paris"}`),
+		openroutertest.FinishChunk("tool_calls"),
+		openroutertest.UsageChunk(12, 8),
+	))
+	require.NoError(t, err)
+
+	message := resp.Choices[0].Message
+	text, _ := message.GetTextContent()
+	assert.Equal(t, "Hello, world", text)
+	require.Len(t, message.ToolCalls, 1)
+	assert.Equal(t, "call_1", message.ToolCalls[0].ID)
+	assert.Equal(t, `{"city":"Paris"}`, message.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, "tool_calls", resp.Choices[0].FinishReason)
+	assert.Equal(t, 8, resp.Usage.CompletionTokens)
+}
+
+func TestStreamFromResponse(t *testing.T) {
+	original := openroutertest.NewToolCallResponse(openroutertest.NewToolCall("call_1", "search", map[string]string{"q": "go"}))
+	message := models.NewTextMessage(models.RoleAssistant, "Let me search for that.")
+	message.ToolCalls = original.Choices[0].Message.ToolCalls
+	original.Choices[0].Message = &message
+
+	resp, err := streaming.CollectStream(openroutertest.StreamFromResponse(original))
+	require.NoError(t, err)
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "Let me search for that.", text)
+	require.Len(t, resp.Choices[0].Message.ToolCalls, 1)
+	assert.Equal(t, `{"q":"go"}`, resp.Choices[0].Message.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, "tool_calls", resp.Choices[0].FinishReason)
+	assert.Equal(t, original.Usage.TotalTokens, resp.Usage.TotalTokens)
+}
+
+func TestStreamBuilderErrorFrames(t *testing.T) {
+	stream := openroutertest.NewStream(
+		openroutertest.TextChunk("partial"),
+		openroutertest.ErrorEvent(502, "provider disconnected"),
+	)
+	defer stream.Close()
+
+	_, err := stream.Read()
+	require.NoError(t, err)
+	_, err = stream.Read()
+	assert.ErrorContains(t, err, "provider disconnected")
+
+	stream = openroutertest.NewStream(openroutertest.StreamEvent{Raw: "{not json"})
+	defer stream.Close()
+	_, err = stream.Read()
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Operation names derived from API requests, shared by fault injection and audit logging
+const (
+	opChatCompletion       = "chat_completion"
+	opChatCompletionStream = "chat_completion_stream"
+	opCompletion           = "completion"
+	opCompletionStream     = "completion_stream"
+	opModels               = "models"
+	opGeneration           = "generation"
+	opAPI                  = "api"
+)
+
+// requestOperation derives the operation name from the request
+func requestOperation(req *http.Request) string {
+	path := req.URL.Path
+	switch {
+	case strings.HasSuffix(path, "/chat/completions"):
+		if isStreamRequest(req) {
+			return opChatCompletionStream
+		}
+		return opChatCompletion
+	case strings.HasSuffix(path, "/completions"):
+		if isStreamRequest(req) {
+			return opCompletionStream
+		}
+		return opCompletion
+	case strings.HasSuffix(path, "/models"):
+		return opModels
+	case strings.HasSuffix(path, "/generation"):
+		return opGeneration
+	default:
+		return opAPI
+	}
+}
+
+// isStreamRequest reports whether the request body asks for a streaming response
+func isStreamRequest(req *http.Request) bool {
+	data := peekRequestBody(req)
+	if data == nil {
+		return false
+	}
+	var fields struct {
+		Stream bool `json:"stream"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&fields); err != nil {
+		return false
+	}
+	return fields.Stream
+}
+
+// peekRequestBody returns the decompressed request body without consuming it, or nil if
+// it can't be read again
+func peekRequestBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+
+	var reader io.Reader = body
+	if req.Header.Get("Content-Encoding") == CompressionGzip {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,270 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// HistoryPacker shrinks a conversation's history to fit a token budget. System messages
+// are kept, and assistant tool calls are kept or dropped together with their results.
+type HistoryPacker interface {
+	Pack(ctx context.Context, messages []models.Message, budget int) ([]models.Message, error)
+}
+
+// Embedder turns texts into embedding vectors. rag.ClientEmbedder implements it.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// EstimateTokens estimates the prompt tokens of messages at about four characters per
+// token, plus a small overhead per message. Use it for budgets, not billing.
+func EstimateTokens(messages []models.Message) int {
+	tokens := 0
+	for _, msg := range messages {
+		tokens += 4 + (len(messageText(msg))+3)/4
+		for _, call := range msg.ToolCalls {
+			tokens += (len(call.Function.Name) + len(call.Function.Arguments) + 3) / 4
+		}
+	}
+	return tokens
+}
+
+// messageText returns the text parts of a message
+func messageText(msg models.Message) string {
+	if text, err := msg.GetTextContent(); err == nil {
+		return text
+	}
+
+	parts, _ := msg.GetMultiContent()
+	var text strings.Builder
+	for _, part := range parts {
+		if t, ok := part.(models.TextContent); ok {
+			if text.Len() > 0 {
+				text.WriteString("\n")
+			}
+			text.WriteString(t.Text)
+		}
+	}
+	return text.String()
+}
+
+// historyUnits splits messages into system messages and the rest of the history, grouped
+// into units that must be kept or dropped together: single messages, or an assistant
+// message with tool calls followed by its tool results
+func historyUnits(messages []models.Message) (system []models.Message, units [][]models.Message) {
+	for _, msg := range messages {
+		switch {
+		case msg.Role == models.RoleSystem:
+			system = append(system, msg)
+		case msg.Role == models.RoleTool && len(units) > 0:
+			units[len(units)-1] = append(units[len(units)-1], msg)
+		default:
+			units = append(units, []models.Message{msg})
+		}
+	}
+	return system, units
+}
+
+// joinUnits concatenates system messages and units
+func joinUnits(system []models.Message, units [][]models.Message) []models.Message {
+	messages := append([]models.Message(nil), system...)
+	for _, unit := range units {
+		messages = append(messages, unit...)
+	}
+	return messages
+}
+
+// lastUnits returns the longest suffix of at most n units that fits budget with the
+// system messages, keeping at least the last unit
+func lastUnits(system []models.Message, units [][]models.Message, n, budget int) [][]models.Message {
+	if n > 0 && len(units) > n {
+		units = units[len(units)-n:]
+	}
+	used := EstimateTokens(system)
+	start := len(units)
+	for start > 0 {
+		cost := EstimateTokens(units[start-1])
+		if start < len(units) && budget > 0 && used+cost > budget {
+			break
+		}
+		used += cost
+		start--
+	}
+	return units[start:]
+}
+
+// KeepLastPacker keeps the system messages and the last N messages, dropping older ones,
+// and drops more if they don't fit the budget
+type KeepLastPacker struct {
+	// N defaults to 20. A tool call and its results count as one message.
+	N int
+}
+
+// Pack implements HistoryPacker
+func (p KeepLastPacker) Pack(ctx context.Context, messages []models.Message, budget int) ([]models.Message, error) {
+	n := p.N
+	if n <= 0 {
+		n = 20
+	}
+	system, units := historyUnits(messages)
+	return joinUnits(system, lastUnits(system, units, n, budget)), nil
+}
+
+// SummarizePacker replaces older messages with a summary written by a model, keeping the
+// most recent ones verbatim
+type SummarizePacker struct {
+	Client *Client
+
+	// Model defaults to DefaultCompressionModel
+	Model string
+
+	// KeepLast is the number of recent messages kept verbatim, as far as they fit the
+	// budget. Defaults to 6.
+	KeepLast int
+}
+
+// Pack implements HistoryPacker
+func (p SummarizePacker) Pack(ctx context.Context, messages []models.Message, budget int) ([]models.Message, error) {
+	keep := p.KeepLast
+	if keep <= 0 {
+		keep = 6
+	}
+	model := p.Model
+	if model == "" {
+		model = DefaultCompressionModel
+	}
+
+	system, units := historyUnits(messages)
+	recent := lastUnits(system, units, keep, budget*3/4)
+	older := units[:len(units)-len(recent)]
+	if len(older) == 0 {
+		return messages, nil
+	}
+
+	var transcript strings.Builder
+	for _, unit := range older {
+		for _, msg := range unit {
+			fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, messageText(msg))
+			for _, call := range msg.ToolCalls {
+				fmt.Fprintf(&transcript, "%s called %s(%s)\n", msg.Role, call.Function.Name, call.Function.Arguments)
+			}
+		}
+	}
+
+	req := models.NewChatRequest(model,
+		models.WithSystemMessage("Summarize the conversation below for the assistant continuing it. Keep facts, "+
+			"decisions, names, numbers, open questions and the user's preferences; drop small talk. Reply "+
+			"with the summary only."),
+		models.WithUserMessage(transcript.String()),
+		models.WithTemperature(0),
+	)
+	resp, err := p.Client.CreateChatCompletion(withoutDecorators(ctx), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize history: %w", err)
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].Message == nil {
+		return nil, fmt.Errorf("no summary in response")
+	}
+	summary, err := resp.Choices[0].Message.GetTextContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summary: %w", err)
+	}
+
+	summaryMsg := models.NewTextMessage(models.RoleSystem, "Summary of the earlier conversation:\n"+strings.TrimSpace(summary))
+	return joinUnits(append(system, summaryMsg), recent), nil
+}
+
+// ImportancePacker keeps the recent messages, then fills the rest of the budget with the
+// older messages most similar to them by embedding, so earlier context relevant to the
+// current topic survives. Kept messages stay in their original order.
+type ImportancePacker struct {
+	Embedder Embedder
+
+	// KeepLast is the number of recent messages always kept, as far as they fit the
+	// budget, and used as the query for similarity. Defaults to 4.
+	KeepLast int
+}
+
+// Pack implements HistoryPacker
+func (p ImportancePacker) Pack(ctx context.Context, messages []models.Message, budget int) ([]models.Message, error) {
+	keep := p.KeepLast
+	if keep <= 0 {
+		keep = 4
+	}
+
+	system, units := historyUnits(messages)
+	recent := lastUnits(system, units, keep, budget)
+	older := units[:len(units)-len(recent)]
+	if len(older) == 0 {
+		return messages, nil
+	}
+
+	texts := make([]string, 0, len(older)+1)
+	var query strings.Builder
+	for _, unit := range recent {
+		for _, msg := range unit {
+			query.WriteString(messageText(msg))
+			query.WriteString("\n")
+		}
+	}
+	texts = append(texts, query.String())
+	for _, unit := range older {
+		var text strings.Builder
+		for _, msg := range unit {
+			text.WriteString(messageText(msg))
+			text.WriteString("\n")
+		}
+		texts = append(texts, text.String())
+	}
+
+	vectors, err := p.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed history: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(vectors))
+	}
+
+	order := make([]int, len(older))
+	scores := make([]float64, len(older))
+	for i := range older {
+		order[i] = i
+		scores[i] = cosineSimilarity(vectors[0], vectors[i+1])
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	used := EstimateTokens(joinUnits(system, recent))
+	kept := make([]bool, len(older))
+	for _, i := range order {
+		if cost := EstimateTokens(older[i]); budget <= 0 || used+cost <= budget {
+			kept[i] = true
+			used += cost
+		}
+	}
+
+	var packed [][]models.Message
+	for i, unit := range older {
+		if kept[i] {
+			packed = append(packed, unit)
+		}
+	}
+	return joinUnits(system, append(packed, recent...)), nil
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
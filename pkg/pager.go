@@ -0,0 +1,107 @@
+package pkg
+
+import (
+	"context"
+	"io"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// PageFunc fetches the page of a list starting at offset. An empty page ends the list.
+type PageFunc[T any] func(ctx context.Context, offset int) ([]T, error)
+
+// Pager iterates over an offset-paginated list, fetching pages as they are needed:
+//
+//	pager := client.ListAPIKeysPager(nil)
+//	for {
+//		key, err := pager.Next(ctx)
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(key.Name)
+//	}
+//
+// The end of the list is found by fetching an empty page. A Pager is not safe for
+// concurrent use.
+type Pager[T any] struct {
+	fetch  PageFunc[T]
+	offset int
+	page   []T
+	done   bool
+	err    error
+}
+
+// NewPager creates a pager over the list fetch returns pages of, starting at offset
+func NewPager[T any](offset int, fetch PageFunc[T]) *Pager[T] {
+	return &Pager[T]{fetch: fetch, offset: offset}
+}
+
+// Next returns the next item, fetching the next page when the current one is used up.
+// It returns io.EOF after the last item. A failed fetch is returned, and retried by the
+// next call.
+func (p *Pager[T]) Next(ctx context.Context) (T, error) {
+	var zero T
+	for len(p.page) == 0 {
+		if p.done {
+			return zero, io.EOF
+		}
+		page, err := p.fetch(ctx, p.offset)
+		if err != nil {
+			return zero, err
+		}
+		if len(page) == 0 {
+			p.done = true
+			return zero, io.EOF
+		}
+		p.page = page
+		p.offset += len(page)
+	}
+	item := p.page[0]
+	p.page = p.page[1:]
+	return item, nil
+}
+
+// All returns the remaining items, fetching every page
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var items []T
+	for {
+		item, err := p.Next(ctx)
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+}
+
+// ListAPIKeysPager returns a pager over the account's API keys, starting at opts.Offset.
+// Requires a Provisioning API key.
+func (c *Client) ListAPIKeysPager(opts *ListAPIKeysOptions) *Pager[models.APIKey] {
+	return apiKeysPager(opts, c.ListAPIKeys)
+}
+
+// ListAPIKeysPager returns a pager over the account's API keys, retrying each page
+func (r *RetryClient) ListAPIKeysPager(opts *ListAPIKeysOptions) *Pager[models.APIKey] {
+	return apiKeysPager(opts, r.ListAPIKeys)
+}
+
+func apiKeysPager(opts *ListAPIKeysOptions, list func(context.Context, *ListAPIKeysOptions) (*models.APIKeysResponse, error)) *Pager[models.APIKey] {
+	var base ListAPIKeysOptions
+	if opts != nil {
+		base = *opts
+	}
+	return NewPager(base.Offset, func(ctx context.Context, offset int) ([]models.APIKey, error) {
+		pageOpts := base
+		pageOpts.Offset = offset
+		resp, err := list(ctx, &pageOpts)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Data, nil
+	})
+}
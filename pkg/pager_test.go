@@ -0,0 +1,81 @@
+package pkg_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestListAPIKeysPager(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		_, err := client.CreateAPIKey(ctx, models.CreateAPIKeyRequest{Name: fmt.Sprintf("key-%d", i)})
+		require.NoError(t, err)
+	}
+	srv.SetPageSize(2)
+
+	keys, err := client.ListAPIKeysPager(nil).All(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 5)
+	for i, key := range keys {
+		assert.Equal(t, fmt.Sprintf("key-%d", i), key.Name)
+	}
+
+	var queries []string
+	for _, req := range srv.Requests() {
+		if req.Method == http.MethodGet {
+			queries = append(queries, req.Query)
+		}
+	}
+	assert.Equal(t, []string{"", "offset=2", "offset=4", "offset=5"}, queries)
+
+	keys, err = client.ListAPIKeysPager(&pkg.ListAPIKeysOptions{Offset: 3}).All(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
+
+func TestPagerNext(t *testing.T) {
+	pages := [][]int{{1, 2}, {3}, nil}
+	failNext := true
+	pager := pkg.NewPager(0, func(ctx context.Context, offset int) ([]int, error) {
+		if offset == 2 && failNext {
+			failNext = false
+			return nil, fmt.Errorf("temporary failure")
+		}
+		switch offset {
+		case 0:
+			return pages[0], nil
+		case 2:
+			return pages[1], nil
+		}
+		return pages[2], nil
+	})
+	ctx := context.Background()
+
+	for _, want := range []int{1, 2} {
+		got, err := pager.Next(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := pager.Next(ctx)
+	require.Error(t, err, "the failed fetch is returned")
+
+	rest, err := pager.All(ctx)
+	require.NoError(t, err, "and retried")
+	assert.Equal(t, []int{3}, rest)
+
+	_, err = pager.Next(ctx)
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,203 @@
+package pkg
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// RequestBuilder builds the request for one item of a pipeline stage from its input, such
+// as the previous stage's output
+type RequestBuilder func(input string) (models.ChatCompletionRequest, error)
+
+// ReduceBuilder builds the request combining the outputs of a pipeline stage
+type ReduceBuilder func(outputs []string) (models.ChatCompletionRequest, error)
+
+// StageResult is one item flowing through a pipeline
+type StageResult struct {
+	// Index is the item's position in the stage's output
+	Index int
+
+	Input  string
+	Output string
+
+	// Response is the response the output was taken from, nil for pipeline inputs
+	Response *models.ChatCompletionResponse
+
+	// Err is a *StageError if the item failed in this stage or an earlier one. Failed
+	// items are passed through later stages unchanged.
+	Err error
+}
+
+// StageError is the failure of one item in a pipeline stage
+type StageError struct {
+	Stage string
+	Index int
+	Err   error
+}
+
+// Error names the stage and item that failed
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %s, item %d: %v", e.Stage, e.Index, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// Inputs turns texts into the input items of a pipeline
+func Inputs(texts ...string) []StageResult {
+	items := make([]StageResult, len(texts))
+	for i, text := range texts {
+		items[i] = StageResult{Index: i, Input: text, Output: text}
+	}
+	return items
+}
+
+// Map sends one request per item, built from the item's output, running them on client
+// with its concurrency limit. Results keep the order of items; items that already failed
+// are passed through without a request.
+func Map(ctx context.Context, client *ConcurrentClient, stage string, items []StageResult, build RequestBuilder) []StageResult {
+	builds := make([]RequestBuilder, len(items))
+	for i := range builds {
+		builds[i] = build
+	}
+	return runStage(ctx, client, stage, items, builds)
+}
+
+// FanOut sends one request per builder for the same item concurrently, such as several
+// analyses of one document. Results are in the order of builds.
+func FanOut(ctx context.Context, client *ConcurrentClient, stage string, item StageResult, builds ...RequestBuilder) []StageResult {
+	items := make([]StageResult, len(builds))
+	for i := range items {
+		items[i] = item
+	}
+	return runStage(ctx, client, stage, items, builds)
+}
+
+// Reduce combines the outputs of the items that succeeded into one request, such as a
+// summary of summaries. Failed items are left out; if every item failed, the result
+// carries the first item's error.
+func Reduce(ctx context.Context, client *ConcurrentClient, stage string, items []StageResult, build ReduceBuilder) StageResult {
+	var outputs []string
+	for _, item := range items {
+		if item.Err == nil {
+			outputs = append(outputs, item.Output)
+		}
+	}
+	if len(outputs) == 0 && len(items) > 0 {
+		return StageResult{Err: items[0].Err}
+	}
+
+	req, err := build(outputs)
+	if err != nil {
+		return StageResult{Err: &StageError{Stage: stage, Err: err}}
+	}
+	results := runStage(ctx, client, stage, []StageResult{{}}, []RequestBuilder{
+		func(string) (models.ChatCompletionRequest, error) { return req, nil },
+	})
+	return results[0]
+}
+
+// runStage sends the request built by builds[i] for items[i], concurrently
+func runStage(ctx context.Context, client *ConcurrentClient, stage string, items []StageResult, builds []RequestBuilder) []StageResult {
+	results := make([]StageResult, len(items))
+	var requests []models.ChatCompletionRequest
+	var pending []int
+	for i, item := range items {
+		results[i] = StageResult{Index: i, Input: item.Output, Err: item.Err}
+		if item.Err != nil {
+			continue
+		}
+		req, err := builds[i](item.Output)
+		if err != nil {
+			results[i].Err = &StageError{Stage: stage, Index: i, Err: err}
+			continue
+		}
+		requests = append(requests, req)
+		pending = append(pending, i)
+	}
+
+	for _, result := range client.CreateChatCompletionsConcurrent(ctx, requests) {
+		item := &results[pending[result.Index]]
+		item.Response = result.Response
+		err := result.Error
+		if err == nil {
+			item.Output, err = responseText(result.Response)
+		}
+		if err != nil {
+			item.Err = &StageError{Stage: stage, Index: item.Index, Err: err}
+		}
+	}
+	return results
+}
+
+// Pipeline chains Map, FanOut and Reduce stages, e.g. extract → enrich → summarize
+//
+//	results, err := pkg.NewPipeline(client).
+//		Map("extract", extract).
+//		Map("enrich", enrich).
+//		Reduce("summarize", summarize).
+//		Run(ctx, documents...)
+type Pipeline struct {
+	client *ConcurrentClient
+	stages []func(ctx context.Context, items []StageResult) []StageResult
+}
+
+// NewPipeline creates an empty pipeline running its requests on client
+func NewPipeline(client *ConcurrentClient) *Pipeline {
+	return &Pipeline{client: client}
+}
+
+// Map adds a stage sending one request per item
+func (p *Pipeline) Map(stage string, build RequestBuilder) *Pipeline {
+	p.stages = append(p.stages, func(ctx context.Context, items []StageResult) []StageResult {
+		return Map(ctx, p.client, stage, items, build)
+	})
+	return p
+}
+
+// FanOut adds a stage sending one request per builder for every item. The outputs of
+// item i are at indexes i*len(builds) to (i+1)*len(builds)-1.
+func (p *Pipeline) FanOut(stage string, builds ...RequestBuilder) *Pipeline {
+	p.stages = append(p.stages, func(ctx context.Context, items []StageResult) []StageResult {
+		fanned := make([]StageResult, 0, len(items)*len(builds))
+		all := make([]RequestBuilder, 0, len(items)*len(builds))
+		for _, item := range items {
+			for range builds {
+				fanned = append(fanned, item)
+			}
+			all = append(all, builds...)
+		}
+		return runStage(ctx, p.client, stage, fanned, all)
+	})
+	return p
+}
+
+// Reduce adds a stage combining all items into one
+func (p *Pipeline) Reduce(stage string, build ReduceBuilder) *Pipeline {
+	p.stages = append(p.stages, func(ctx context.Context, items []StageResult) []StageResult {
+		return []StageResult{Reduce(ctx, p.client, stage, items, build)}
+	})
+	return p
+}
+
+// Run passes inputs through every stage and returns the last stage's results, in order.
+// The error joins the errors of the items that failed.
+func (p *Pipeline) Run(ctx context.Context, inputs ...string) ([]StageResult, error) {
+	items := Inputs(inputs...)
+	for _, stage := range p.stages {
+		items = stage(ctx, items)
+	}
+
+	var errs []error
+	for _, item := range items {
+		if item.Err != nil {
+			errs = append(errs, item.Err)
+		}
+	}
+	return items, stderrors.Join(errs...)
+}
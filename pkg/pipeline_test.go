@@ -0,0 +1,73 @@
+package pkg_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// prompt builds a request asking for an operation on the input
+func prompt(op string) pkg.RequestBuilder {
+	return func(input string) (models.ChatCompletionRequest, error) {
+		return models.NewChatRequest("m", models.WithUserMessage(op+":"+input)), nil
+	}
+}
+
+// pipelineHandler applies the operation named in the prompt
+func pipelineHandler(req models.ChatCompletionRequest) openroutertest.Reply {
+	text, _ := req.Messages[len(req.Messages)-1].GetTextContent()
+	op, input, _ := strings.Cut(text, ":")
+	switch {
+	case strings.Contains(input, "bad"):
+		return openroutertest.ErrorReply(400, "bad input")
+	case op == "upper":
+		return openroutertest.TextReply(strings.ToUpper(input))
+	case op == "first":
+		return openroutertest.TextReply(input[:1])
+	case op == "join":
+		return openroutertest.TextReply(strings.ReplaceAll(input, "\n", "+"))
+	}
+	return openroutertest.TextReply(input)
+}
+
+func TestPipeline(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetChatHandler(pipelineHandler)
+	client := pkg.NewConcurrentClient("key", 2, pkg.WithBaseURL(srv.URL))
+
+	join := func(outputs []string) (models.ChatCompletionRequest, error) {
+		return models.NewChatRequest("m", models.WithUserMessage("join:"+strings.Join(outputs, "\n"))), nil
+	}
+
+	results, err := pkg.NewPipeline(client).
+		Map("extract", prompt("upper")).
+		FanOut("enrich", prompt("first"), prompt("echo")).
+		Run(context.Background(), "alpha", "bad", "gamma")
+
+	require.Len(t, results, 6)
+	assert.Equal(t, "A", results[0].Output)
+	assert.Equal(t, "ALPHA", results[1].Output)
+	assert.Equal(t, "G", results[4].Output)
+	assert.Equal(t, "GAMMA", results[5].Output)
+
+	// The failed item keeps its error and the stage it failed in
+	var stageErr *pkg.StageError
+	require.True(t, errors.As(results[2].Err, &stageErr))
+	assert.Equal(t, "extract", stageErr.Stage)
+	assert.Equal(t, 1, stageErr.Index)
+	assert.Equal(t, results[2].Err, results[3].Err)
+	require.ErrorContains(t, err, "stage extract, item 1")
+
+	summary := pkg.Reduce(context.Background(), client, "summarize", results, join)
+	require.NoError(t, summary.Err)
+	assert.Equal(t, "A+ALPHA+G+GAMMA", summary.Output)
+}
@@ -0,0 +1,125 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// PostProcessor transforms the text of a completion before it is returned. Any
+// func(string) (string, error) can be used, e.g. to apply norm.NFC from golang.org/x/text.
+type PostProcessor func(text string) (string, error)
+
+// WithPostProcessors sets processors applied, in order, to the text of every choice
+// returned by CreateChatCompletion. Apply them to collected streams with PostProcess.
+func WithPostProcessors(processors ...PostProcessor) Option {
+	return func(c *Client) {
+		c.postProcessors = append(c.postProcessors, processors...)
+	}
+}
+
+// PostProcess applies the client's post-processors to resp, e.g. to a response built
+// with streaming.CollectStream
+func (c *Client) PostProcess(resp *models.ChatCompletionResponse) error {
+	return ApplyPostProcessors(resp, c.postProcessors...)
+}
+
+// ApplyPostProcessors applies processors to the text content of each choice in resp.
+// Messages without text content, such as tool calls or multi-part content, are left
+// unchanged.
+func ApplyPostProcessors(resp *models.ChatCompletionResponse, processors ...PostProcessor) error {
+	if resp == nil || len(processors) == 0 {
+		return nil
+	}
+
+	for i := range resp.Choices {
+		msg := resp.Choices[i].Message
+		if msg == nil || len(msg.Content) == 0 {
+			continue
+		}
+		var text string
+		if err := json.Unmarshal(msg.Content, &text); err != nil {
+			continue
+		}
+
+		for _, process := range processors {
+			var err error
+			if text, err = process(text); err != nil {
+				return fmt.Errorf("failed to post-process choice %d: %w", i, err)
+			}
+		}
+
+		content, err := json.Marshal(text)
+		if err != nil {
+			return fmt.Errorf("failed to encode post-processed choice %d: %w", i, err)
+		}
+		msg.Content = content
+	}
+	return nil
+}
+
+// fencePattern matches text wrapped in a Markdown code fence, with an optional language
+var fencePattern = regexp.MustCompile("(?s)^\\s*```[\\w+-]*[ \\t]*\\r?\\n(.*?)\\r?\\n?```\\s*$")
+
+// StripMarkdownFences removes a Markdown code fence wrapping the whole text, as models
+// often add around JSON or code. Fences inside the text are kept.
+func StripMarkdownFences() PostProcessor {
+	return func(text string) (string, error) {
+		if m := fencePattern.FindStringSubmatch(text); m != nil {
+			return m[1], nil
+		}
+		return text, nil
+	}
+}
+
+// TrimWhitespace removes leading and trailing whitespace
+func TrimWhitespace() PostProcessor {
+	return func(text string) (string, error) {
+		return strings.TrimSpace(text), nil
+	}
+}
+
+// NormalizeUnicode converts CRLF line endings to LF and Unicode spaces such as
+// non-breaking spaces to ASCII spaces, and removes zero-width and other invisible
+// formatting characters. It doesn't apply NFC or NFKC normalization, which needs
+// golang.org/x/text.
+func NormalizeUnicode() PostProcessor {
+	return func(text string) (string, error) {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		return strings.Map(func(r rune) rune {
+			switch {
+			case r == '\n' || r == '\t':
+				return r
+			case unicode.IsSpace(r):
+				return ' '
+			case unicode.Is(unicode.Cf, r) || (unicode.IsControl(r) && r != '\r'):
+				return -1
+			}
+			return r
+		}, text), nil
+	}
+}
+
+// ProfanityFilter masks each listed word, matched as a whole word regardless of case,
+// with asterisks of the same length
+func ProfanityFilter(words ...string) PostProcessor {
+	if len(words) == 0 {
+		return func(text string) (string, error) { return text, nil }
+	}
+
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	pattern := regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+
+	return func(text string) (string, error) {
+		return pattern.ReplaceAllStringFunc(text, func(word string) string {
+			return strings.Repeat("*", len([]rune(word)))
+		}), nil
+	}
+}
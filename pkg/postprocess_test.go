@@ -0,0 +1,61 @@
+package pkg_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+func TestPostProcessors(t *testing.T) {
+	tests := []struct {
+		name      string
+		processor pkg.PostProcessor
+		in, want  string
+	}{
+		{"fence with language", pkg.StripMarkdownFences(), "```json\n{\"a\": 1}\n```\n", `{"a": 1}`},
+		{"fence without language", pkg.StripMarkdownFences(), "```\ncode\n```", "code"},
+		{"inner fences kept", pkg.StripMarkdownFences(), "See:\n```go\nx := 1\n```", "See:\n```go\nx := 1\n```"},
+		{"trim", pkg.TrimWhitespace(), "\n  hi \t", "hi"},
+		{"normalize", pkg.NormalizeUnicode(), "a b​c\r\nd", "a bc\nd"},
+		{"profanity", pkg.ProfanityFilter("darn", "heck"), "Darn it, what the heck; darnation", "**** it, what the ****; darnation"},
+	}
+	for _, tt := range tests {
+		got, err := tt.processor(tt.in)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+}
+
+func TestClientAppliesPostProcessors(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	reply := "```json\n{\"ok\": true}\n```"
+	srv.EnqueueChat(openroutertest.TextReply(reply), openroutertest.TextReply(reply))
+
+	upper := pkg.PostProcessor(func(text string) (string, error) { return strings.ToUpper(text), nil })
+	client := srv.Client(pkg.WithPostProcessors(pkg.StripMarkdownFences(), upper))
+	req := models.NewChatRequest("m", models.WithUserMessage("hi"))
+
+	resp, err := client.CreateChatCompletion(context.Background(), req)
+	require.NoError(t, err)
+	text, err := resp.Choices[0].Message.GetTextContent()
+	require.NoError(t, err)
+	assert.Equal(t, `{"OK": TRUE}`, text)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), req)
+	require.NoError(t, err)
+	collected, err := streaming.CollectStream(stream)
+	require.NoError(t, err)
+	require.NoError(t, client.PostProcess(collected))
+	text, err = collected.Choices[0].Message.GetTextContent()
+	require.NoError(t, err)
+	assert.Equal(t, `{"OK": TRUE}`, text)
+}
@@ -0,0 +1,61 @@
+package pkg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestClientProviderPreferences(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	client := srv.Client(pkg.WithProviderPreferences(models.PreferencesPrivacyStrict()))
+	ctx := context.Background()
+
+	// The preset applies to requests without preferences
+	_, err := client.CreateChatCompletion(ctx, models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	require.NotNil(t, sent.Provider)
+	assert.Equal(t, models.DataCollectionDeny, sent.Provider.DataCollection)
+	assert.True(t, *sent.Provider.ZDR)
+
+	// Fields set on the request override it
+	req := models.NewChatRequest("m", models.WithUserMessage("hi"),
+		models.WithProvider(models.NewProviderPreferences().WithZDR(false).WithSort(models.SortByPrice)))
+	_, err = client.CreateChatCompletion(ctx, req)
+	require.NoError(t, err)
+	sent, err = srv.Requests()[1].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, models.DataCollectionDeny, sent.Provider.DataCollection)
+	assert.False(t, *sent.Provider.ZDR)
+	assert.Equal(t, models.SortByPrice, sent.Provider.Sort)
+	assert.Empty(t, req.Provider.DataCollection, "request preferences were modified")
+}
+
+func TestListModelEndpointsRegistersQuantizations(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetEndpoints("meta-llama/llama-3.1-70b-instruct",
+		models.ModelEndpoint{Provider: "together", Quantization: "fp5"})
+	client := srv.Client()
+	ctx := context.Background()
+
+	req := models.NewChatRequest("m", models.WithUserMessage("hi"),
+		models.WithProvider(models.NewProviderPreferences().WithQuantizations("fp5")))
+	_, err := client.CreateChatCompletion(ctx, req)
+	require.ErrorContains(t, err, `unknown provider quantization "fp5"`)
+
+	_, err = client.ListModelEndpoints(ctx, "meta-llama/llama-3.1-70b-instruct")
+	require.NoError(t, err)
+	assert.Contains(t, models.KnownQuantizations(), models.QuantizationLevel("fp5"))
+	_, err = client.CreateChatCompletion(ctx, req)
+	require.NoError(t, err)
+}
@@ -0,0 +1,328 @@
+// Package queue runs chat completion requests in the background from a durable queue.
+// Requests are enqueued to a Store, processed by a pool of workers sharing a
+// ConcurrentClient's concurrency limit, and their status and results are kept in the
+// store, so a pipeline picks up where it left off after a restart.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/sink"
+)
+
+const (
+	// DefaultPollInterval is how often idle workers check the store for new jobs
+	DefaultPollInterval = time.Second
+
+	// DefaultLease is how long a job may run before it is presumed abandoned and claimed
+	// again
+	DefaultLease = 10 * time.Minute
+
+	// DefaultDeliveryAttempts is how often a record is written to the sink, by default,
+	// before its delivery is put off to the job's next claim
+	DefaultDeliveryAttempts = 3
+
+	// DefaultDeliveryBackoff is the wait before the first rewrite of a record, doubling
+	// for each further one
+	DefaultDeliveryBackoff = time.Second
+)
+
+// ErrNotDone is returned by Result for jobs that haven't finished
+var ErrNotDone = errors.New("job not done")
+
+// Status is the state of a queued job
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// Job is a queued request and its outcome
+type Job struct {
+	ID       string                         `json:"id"`
+	Status   Status                         `json:"status"`
+	Request  models.ChatCompletionRequest   `json:"request"`
+	Response *models.ChatCompletionResponse `json:"response,omitempty"`
+	Error    string                         `json:"error,omitempty"`
+
+	// Attempts counts how often the job was claimed, including claims after a worker died
+	Attempts int `json:"attempts"`
+
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+}
+
+// Done reports whether the job has completed or failed
+func (j *Job) Done() bool {
+	return j.Status == StatusCompleted || j.Status == StatusFailed
+}
+
+// Options configures a Queue
+type Options struct {
+	// Workers is the number of jobs processed at once. Defaults to the client's
+	// MaxConcurrency.
+	Workers int
+
+	// MaxAttempts is how often a job is tried before it is marked failed. Defaults to 1;
+	// wrap the client's requests with RetryClient for backoff within an attempt.
+	MaxAttempts int
+
+	// PollInterval defaults to DefaultPollInterval
+	PollInterval time.Duration
+
+	// Lease defaults to DefaultLease. It must exceed the longest expected request.
+	Lease time.Duration
+
+	// Sink receives every finished job, at least once: a job is only marked finished
+	// after its record is written. Failed writes are retried DeliveryAttempts times;
+	// if they all fail, a job with a response keeps it and stays pending, to be
+	// delivered again on its next claim without repeating the request or using up
+	// MaxAttempts. Records have the job ID and source "queue".
+	Sink sink.Sink
+
+	// DeliveryAttempts and DeliveryBackoff configure the retries of sink writes, with
+	// sink.WithRetry. They default to DefaultDeliveryAttempts and DefaultDeliveryBackoff.
+	DeliveryAttempts int
+	DeliveryBackoff  time.Duration
+}
+
+// Queue processes chat completion requests from a Store
+type Queue struct {
+	client *pkg.ConcurrentClient
+	store  Store
+	opts   Options
+
+	// wake lets Enqueue start an idle worker without waiting for the poll interval
+	wake chan struct{}
+}
+
+// New creates a queue processing jobs from store with client
+func New(client *pkg.ConcurrentClient, store Store, opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = client.MaxConcurrency()
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = DefaultLease
+	}
+	if opts.DeliveryAttempts <= 0 {
+		opts.DeliveryAttempts = DefaultDeliveryAttempts
+	}
+	if opts.DeliveryBackoff <= 0 {
+		opts.DeliveryBackoff = DefaultDeliveryBackoff
+	}
+	if opts.Sink != nil {
+		opts.Sink = sink.WithRetry(opts.Sink, opts.DeliveryAttempts, opts.DeliveryBackoff)
+	}
+
+	return &Queue{
+		client: client,
+		store:  store,
+		opts:   opts,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Enqueue adds a request to the queue and returns its job
+func (q *Queue) Enqueue(ctx context.Context, req models.ChatCompletionRequest) (*Job, error) {
+	job := &Job{
+		ID:         "qjob-" + pkg.NewIdempotencyKey(),
+		Status:     StatusPending,
+		Request:    req,
+		EnqueuedAt: time.Now(),
+	}
+	if err := q.store.Put(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Job returns the current state of a job
+func (q *Queue) Job(ctx context.Context, id string) (*Job, error) {
+	return q.store.Get(ctx, id)
+}
+
+// Result returns a completed job's response. It returns ErrNotDone for unfinished jobs,
+// and the job's error for failed ones.
+func (q *Queue) Result(ctx context.Context, id string) (*models.ChatCompletionResponse, error) {
+	job, err := q.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch job.Status {
+	case StatusCompleted:
+		return job.Response, nil
+	case StatusFailed:
+		return nil, fmt.Errorf("job %s failed: %s", id, job.Error)
+	default:
+		return nil, ErrNotDone
+	}
+}
+
+// Run processes jobs until ctx is canceled. Jobs interrupted by the cancellation are
+// returned to the queue. Run returns nil after a cancellation, or the first store error.
+func (q *Queue) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, q.opts.Workers)
+	for i := 0; i < q.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := q.work(ctx); err != nil {
+				errs <- err
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// work claims and processes jobs until ctx is canceled
+func (q *Queue) work(ctx context.Context) error {
+	for {
+		job, err := q.store.Claim(ctx, time.Now(), q.opts.Lease)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to claim job: %w", err)
+		}
+
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-q.wake:
+			case <-time.After(q.opts.PollInterval):
+			}
+			continue
+		}
+
+		if err := q.process(ctx, job); err != nil {
+			return err
+		}
+	}
+}
+
+// process runs a claimed job and records its outcome
+func (q *Queue) process(ctx context.Context, job *Job) error {
+	// The outcome must be saved even though ctx is canceled
+	saveCtx := context.WithoutCancel(ctx)
+
+	// A response kept from an earlier attempt only needs delivering
+	if job.Response == nil {
+		// The job ID doubles as the idempotency key, so reruns after a crash aren't billed twice
+		results := q.client.CreateChatCompletionsConcurrent(pkg.WithIdempotencyKey(ctx, job.ID), []models.ChatCompletionRequest{job.Request})
+		resp, runErr := results[0].Response, results[0].Error
+
+		switch {
+		case runErr == nil:
+			job.Response = resp
+		case ctx.Err() != nil:
+			// Interrupted by shutdown rather than failed; the next run picks it up again
+			job.Status = StatusPending
+			job.Attempts--
+			return q.save(saveCtx, job)
+		case job.Attempts < q.opts.MaxAttempts:
+			job.Status = StatusPending
+			job.Error = runErr.Error()
+			return q.save(saveCtx, job)
+		default:
+			job.Status = StatusFailed
+			job.Error = runErr.Error()
+		}
+	}
+
+	if job.Response != nil {
+		job.Status = StatusCompleted
+		job.Error = ""
+	}
+	job.FinishedAt = time.Now()
+
+	if q.opts.Sink != nil {
+		return q.deliver(ctx, job)
+	}
+	return q.save(saveCtx, job)
+}
+
+// deliver writes a finished job to the sink, then saves it as finished. The response is
+// saved first, so a job whose delivery fails is redelivered without repeating the request.
+func (q *Queue) deliver(ctx context.Context, job *Job) error {
+	saveCtx := context.WithoutCancel(ctx)
+	if job.Response != nil {
+		status := job.Status
+		job.Status = StatusRunning
+		if err := q.save(saveCtx, job); err != nil {
+			return err
+		}
+		job.Status = status
+	}
+
+	if err := q.opts.Sink.Write(ctx, job.record()); err != nil {
+		job.Error = fmt.Sprintf("failed to deliver result: %v", err)
+		switch {
+		case ctx.Err() != nil, job.Response != nil:
+			// Not a failed attempt: the response is kept and delivered on the next claim
+			job.Status = StatusPending
+			job.Attempts--
+		case job.Attempts < q.opts.MaxAttempts:
+			job.Status = StatusPending
+		default:
+			job.Status = StatusFailed
+		}
+		if job.Status == StatusPending {
+			job.FinishedAt = time.Time{}
+		}
+	}
+	return q.save(saveCtx, job)
+}
+
+func (q *Queue) save(ctx context.Context, job *Job) error {
+	if err := q.store.Put(ctx, job); err != nil {
+		return fmt.Errorf("failed to save job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// record converts a finished job to a sink record
+func (j *Job) record() sink.Record {
+	return sink.Record{
+		ID:       j.ID,
+		Source:   "queue",
+		Request:  &j.Request,
+		Response: j.Response,
+		Error:    j.Error,
+		Time:     j.FinishedAt,
+	}
+}
@@ -0,0 +1,164 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/queue"
+	"github.com/rizome-dev/go-openrouter/pkg/sink"
+)
+
+func waitDone(t *testing.T, q *queue.Queue, id string) *queue.Job {
+	t.Helper()
+	var job *queue.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = q.Job(context.Background(), id)
+		return err == nil && job.Done()
+	}, 5*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestQueueProcessesAndRetriesJobs(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(
+		openroutertest.TextReply("first"),
+		openroutertest.ErrorReply(500, "upstream failed"),
+		openroutertest.TextReply("second"),
+	)
+
+	store, err := queue.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	client := pkg.NewConcurrentClient("sk-or-test", 1, pkg.WithBaseURL(srv.URL))
+	q := queue.New(client, store, queue.Options{MaxAttempts: 2, PollInterval: 10 * time.Millisecond})
+
+	ctx := context.Background()
+	first, err := q.Enqueue(ctx, models.NewChatRequest("m", models.WithUserMessage("one")))
+	require.NoError(t, err)
+	second, err := q.Enqueue(ctx, models.NewChatRequest("m", models.WithUserMessage("two")))
+	require.NoError(t, err)
+
+	_, err = q.Result(ctx, first.ID)
+	assert.ErrorIs(t, err, queue.ErrNotDone)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { done <- q.Run(runCtx) }()
+
+	waitDone(t, q, first.ID)
+	job := waitDone(t, q, second.ID)
+	assert.Equal(t, queue.StatusCompleted, job.Status)
+	assert.Equal(t, 2, job.Attempts, "the failed attempt is retried")
+
+	resp, err := q.Result(ctx, first.ID)
+	require.NoError(t, err)
+	text, err := resp.Choices[0].Message.GetTextContent()
+	require.NoError(t, err)
+	assert.Equal(t, "first", text)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestQueueReclaimsAbandonedJobs(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.ErrorReply(400, "bad request"))
+
+	// A job left running by a worker that died before a restart
+	store := queue.NewMemoryStore()
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, &queue.Job{
+		ID:        "abandoned",
+		Status:    queue.StatusRunning,
+		Request:   models.NewChatRequest("m", models.WithUserMessage("hi")),
+		Attempts:  1,
+		StartedAt: time.Now().Add(-time.Hour),
+	}))
+
+	client := pkg.NewConcurrentClient("sk-or-test", 2, pkg.WithBaseURL(srv.URL))
+	q := queue.New(client, store, queue.Options{MaxAttempts: 2, Lease: time.Minute})
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go q.Run(runCtx) //nolint:errcheck
+
+	job := waitDone(t, q, "abandoned")
+	assert.Equal(t, queue.StatusFailed, job.Status)
+	assert.Equal(t, 2, job.Attempts)
+	_, err := q.Result(ctx, "abandoned")
+	assert.ErrorContains(t, err, "bad request")
+}
+
+func TestQueueRedeliversToSinkWithoutRerunning(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("only once"))
+
+	var delivered []sink.Record
+	writes := 0
+	flaky := sink.Func(func(ctx context.Context, record sink.Record) error {
+		writes++
+		if writes == 1 {
+			return errors.New("sink unavailable")
+		}
+		delivered = append(delivered, record)
+		return nil
+	})
+
+	client := pkg.NewConcurrentClient("sk-or-test", 1, pkg.WithBaseURL(srv.URL))
+	// With the default MaxAttempts of 1, a failed delivery doesn't fail the job
+	q := queue.New(client, queue.NewMemoryStore(), queue.Options{DeliveryAttempts: 1, PollInterval: 10 * time.Millisecond, Sink: flaky})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	job, err := q.Enqueue(ctx, models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	go q.Run(ctx) //nolint:errcheck
+
+	done := waitDone(t, q, job.ID)
+	assert.Equal(t, queue.StatusCompleted, done.Status)
+	assert.Empty(t, done.Error)
+	assert.Len(t, srv.Requests(), 1, "the request isn't repeated to redeliver its result")
+	require.Len(t, delivered, 1)
+	assert.Equal(t, job.ID, delivered[0].ID)
+	assert.Equal(t, "queue", delivered[0].Source)
+}
+
+func TestQueueRetriesSinkWrites(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+
+	writes := 0
+	flaky := sink.Func(func(ctx context.Context, record sink.Record) error {
+		writes++
+		if writes < 3 {
+			return errors.New("sink unavailable")
+		}
+		return nil
+	})
+
+	client := pkg.NewConcurrentClient("sk-or-test", 1, pkg.WithBaseURL(srv.URL))
+	store := queue.NewMemoryStore()
+	q := queue.New(client, store, queue.Options{DeliveryBackoff: time.Millisecond, PollInterval: 10 * time.Millisecond, Sink: flaky})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	job, err := q.Enqueue(ctx, models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	go q.Run(ctx) //nolint:errcheck
+
+	done := waitDone(t, q, job.ID)
+	assert.Equal(t, queue.StatusCompleted, done.Status)
+	assert.Equal(t, 1, done.Attempts, "the writes are retried within the attempt")
+	assert.Equal(t, 3, writes)
+}
@@ -0,0 +1,310 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/sink"
+)
+
+// ResultFunc receives the finished jobs of a scheduled job, completed or failed
+type ResultFunc func(ctx context.Context, name string, job *Job) error
+
+// ScheduledJob is a prompt enqueued on a recurring schedule
+type ScheduledJob struct {
+	// Name identifies the job in logs, metrics and results
+	Name string
+
+	// Schedule is a cron expression accepted by ParseSchedule
+	Schedule string
+
+	// Request is the base request, e.g. the model, system message and parameters
+	Request models.ChatCompletionRequest
+
+	// Prompt is a text/template rendered with the value returned by Data and appended
+	// to Request as a user message. Leave it empty to send Request as it is.
+	Prompt string
+
+	// Data returns the template data for a run scheduled at the given time, e.g. the
+	// feed items to summarize. It may be nil.
+	Data func(ctx context.Context, at time.Time) (interface{}, error)
+
+	// Results receive each finished job
+	Results []ResultFunc
+}
+
+// SchedulerOptions configures a Scheduler
+type SchedulerOptions struct {
+	// Location in which schedules are evaluated. Defaults to time.Local.
+	Location *time.Location
+
+	// Clock defaults to pkg.SystemClock; tests can use openroutertest.FakeClock
+	Clock pkg.Clock
+
+	// Logger and Metrics receive failure alerts: runs that can't be enqueued, failed
+	// jobs, and results that can't be delivered. Both are optional.
+	Logger  pkg.Logger
+	Metrics pkg.MetricsCollector
+}
+
+// Scheduler enqueues prompts on cron schedules and delivers their results. Jobs are
+// persisted by the queue; results of jobs still running when the scheduler stops are not
+// delivered after a restart, but remain available from the queue.
+type Scheduler struct {
+	queue *Queue
+	opts  SchedulerOptions
+
+	mu   sync.Mutex
+	jobs []*scheduled
+}
+
+type scheduled struct {
+	ScheduledJob
+	schedule *Schedule
+	prompt   *template.Template
+	next     time.Time
+}
+
+// NewScheduler creates a scheduler that enqueues to q. Run q as well, in this process or
+// another sharing its store.
+func NewScheduler(q *Queue, opts SchedulerOptions) *Scheduler {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	if opts.Clock == nil {
+		opts.Clock = pkg.SystemClock
+	}
+	return &Scheduler{queue: q, opts: opts}
+}
+
+// Add registers a scheduled job
+func (s *Scheduler) Add(job ScheduledJob) error {
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return err
+	}
+
+	entry := &scheduled{ScheduledJob: job, schedule: schedule}
+	if job.Prompt != "" {
+		if entry.prompt, err = template.New(job.Name).Parse(job.Prompt); err != nil {
+			return fmt.Errorf("invalid prompt template for %s: %w", job.Name, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, entry)
+	return nil
+}
+
+// Run enqueues scheduled jobs as they come due and delivers their results, until ctx is
+// canceled. Runs missed while the scheduler wasn't running are skipped.
+func (s *Scheduler) Run(ctx context.Context) error {
+	now := s.opts.Clock.Now().In(s.opts.Location)
+	s.mu.Lock()
+	for _, job := range s.jobs {
+		job.next = job.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	// Enqueued job IDs by scheduled job, waiting for results
+	pending := make(map[string]*scheduled)
+	for {
+		now = s.opts.Clock.Now().In(s.opts.Location)
+		s.mu.Lock()
+		for _, job := range s.jobs {
+			if job.next.IsZero() {
+				// Added after Run started
+				job.next = job.schedule.Next(now)
+			}
+			if job.next.After(now) {
+				continue
+			}
+
+			if id, err := s.enqueue(ctx, job, job.next); err != nil {
+				s.alert(job.Name, "failed to enqueue scheduled job", err)
+			} else {
+				pending[id] = job
+			}
+			job.next = job.schedule.Next(now)
+		}
+		s.mu.Unlock()
+
+		for id, job := range pending {
+			if s.deliver(ctx, job, id) {
+				delete(pending, id)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.opts.Clock.After(s.wait(now)):
+		}
+	}
+}
+
+// wait returns how long to sleep before the next due job or results check
+func (s *Scheduler) wait(now time.Time) time.Duration {
+	wait := s.queue.opts.PollInterval
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if d := job.next.Sub(now); !job.next.IsZero() && d < wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// enqueue renders a scheduled job's prompt and enqueues it
+func (s *Scheduler) enqueue(ctx context.Context, job *scheduled, at time.Time) (string, error) {
+	req := job.Request
+	if job.prompt != nil {
+		var data interface{}
+		if job.Data != nil {
+			var err error
+			if data, err = job.Data(ctx, at); err != nil {
+				return "", fmt.Errorf("failed to load prompt data: %w", err)
+			}
+		}
+
+		var prompt strings.Builder
+		if err := job.prompt.Execute(&prompt, data); err != nil {
+			return "", fmt.Errorf("failed to render prompt: %w", err)
+		}
+		req.Messages = append(append([]models.Message(nil), req.Messages...),
+			models.NewTextMessage(models.RoleUser, prompt.String()))
+	}
+
+	queued, err := s.queue.Enqueue(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if s.opts.Logger != nil {
+		s.opts.Logger.Info("Scheduled job enqueued", pkg.F("job", job.Name), pkg.F("id", queued.ID))
+	}
+	return queued.ID, nil
+}
+
+// deliver passes a finished job to the scheduled job's results, reporting whether it is
+// finished
+func (s *Scheduler) deliver(ctx context.Context, job *scheduled, id string) bool {
+	queued, err := s.queue.Job(ctx, id)
+	if err != nil {
+		s.alert(job.Name, "failed to load scheduled job", err)
+		return errors.Is(err, ErrNotFound)
+	}
+	if !queued.Done() {
+		return false
+	}
+
+	if queued.Status == StatusFailed {
+		s.alert(job.Name, "scheduled job failed", errors.New(queued.Error))
+	}
+	for _, result := range job.Results {
+		if err := result(ctx, job.Name, queued); err != nil {
+			s.alert(job.Name, "failed to deliver scheduled job result", err)
+		}
+	}
+	return true
+}
+
+// alert reports a scheduled job failure to the logger and metrics
+func (s *Scheduler) alert(name, msg string, err error) {
+	if s.opts.Logger != nil {
+		s.opts.Logger.Error(msg, pkg.F("job", name), pkg.F("error", err.Error()))
+	}
+	if s.opts.Metrics != nil {
+		s.opts.Metrics.RecordError("scheduled_job", err, map[string]string{"job": name})
+	}
+}
+
+// ResultCallback adapts a function receiving completed responses to a ResultFunc.
+// Failed jobs are skipped; the scheduler reports them already.
+func ResultCallback(fn func(ctx context.Context, name string, resp *models.ChatCompletionResponse) error) ResultFunc {
+	return func(ctx context.Context, name string, job *Job) error {
+		if job.Status != StatusCompleted {
+			return nil
+		}
+		return fn(ctx, name, job.Response)
+	}
+}
+
+// ResultSink writes each finished job to s, with the scheduled job's name as the source
+func ResultSink(s sink.Sink) ResultFunc {
+	return func(ctx context.Context, name string, job *Job) error {
+		record := job.record()
+		record.Source = name
+		return s.Write(ctx, record)
+	}
+}
+
+// scheduledResult is the record written by ResultFile and ResultWebhook
+type scheduledResult struct {
+	Name string `json:"name"`
+	*Job
+}
+
+// ResultFile appends each finished job as a JSON line to the file at path
+func ResultFile(path string) ResultFunc {
+	var mu sync.Mutex
+	return func(ctx context.Context, name string, job *Job) error {
+		line, err := json.Marshal(scheduledResult{Name: name, Job: job})
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+}
+
+// ResultWebhook posts each finished job as JSON to url
+func ResultWebhook(url string, client *http.Client) ResultFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context, name string, job *Job) error {
+		body, err := json.Marshal(scheduledResult{Name: name, Job: job})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
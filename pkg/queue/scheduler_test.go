@@ -0,0 +1,103 @@
+package queue_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/queue"
+)
+
+func TestScheduleNext(t *testing.T) {
+	from := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC) // a Saturday
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", time.Date(2026, 3, 16, 2, 30, 0, 0, time.UTC)},
+		{"0 9 1 * 7", time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 1,7 *", time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		schedule, err := queue.ParseSchedule(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.want, schedule.Next(from), tt.spec)
+	}
+
+	for _, spec := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *"} {
+		_, err := queue.ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestSchedulerEnqueuesAndDeliversResults(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("summary"))
+
+	client := pkg.NewConcurrentClient("sk-or-test", 1, pkg.WithBaseURL(srv.URL))
+	q := queue.New(client, queue.NewMemoryStore(), queue.Options{PollInterval: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx) //nolint:errcheck
+
+	clock := openroutertest.NewFakeClock(time.Date(2026, 3, 14, 23, 59, 0, 0, time.UTC))
+	scheduler := queue.NewScheduler(q, queue.SchedulerOptions{Location: time.UTC, Clock: clock})
+
+	resultFile := filepath.Join(t.TempDir(), "results.jsonl")
+	results := make(chan string, 1)
+	require.NoError(t, scheduler.Add(queue.ScheduledJob{
+		Name:     "nightly",
+		Schedule: "@daily",
+		Request:  models.NewChatRequest("m", models.WithSystemMessage("Be brief.")),
+		Prompt:   "Summarize {{.}}",
+		Data: func(ctx context.Context, at time.Time) (interface{}, error) {
+			return "the feed for " + at.Format("2006-01-02"), nil
+		},
+		Results: []queue.ResultFunc{
+			queue.ResultFile(resultFile),
+			queue.ResultCallback(func(ctx context.Context, name string, resp *models.ChatCompletionResponse) error {
+				text, err := resp.Choices[0].Message.GetTextContent()
+				results <- name + ": " + text
+				return err
+			}),
+		},
+	}))
+	go scheduler.Run(ctx) //nolint:errcheck
+
+	// Move past midnight, then keep advancing until the result is delivered
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	var got string
+	require.Eventually(t, func() bool {
+		select {
+		case got = <-results:
+			return true
+		default:
+			clock.Advance(10 * time.Millisecond)
+			return false
+		}
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, "nightly: summary", got)
+
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	require.Len(t, sent.Messages, 2)
+	prompt, err := sent.Messages[1].GetTextContent()
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize the feed for 2026-03-15", prompt)
+
+	data, err := os.ReadFile(resultFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"name":"nightly"`)
+}
@@ -0,0 +1,204 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by a Store for unknown job IDs
+var ErrNotFound = errors.New("job not found")
+
+// Store persists queued jobs. MemoryStore and FileStore are provided; implement Store to
+// back the queue with SQLite, Postgres, Redis, or similar. Implementations must be safe
+// for concurrent use, and Claim must never hand the same job to two callers.
+type Store interface {
+	// Put inserts a job or replaces the job with the same ID
+	Put(ctx context.Context, job *Job) error
+
+	// Get returns ErrNotFound for unknown IDs
+	Get(ctx context.Context, id string) (*Job, error)
+
+	// Claim marks the oldest claimable job as running and returns it, or returns nil if
+	// there is none. A job is claimable while pending, or while running with a StartedAt
+	// older than lease, which means the worker running it has died.
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (*Job, error)
+}
+
+// claimable reports whether a job can be claimed at now
+func claimable(job *Job, now time.Time, lease time.Duration) bool {
+	switch job.Status {
+	case StatusPending:
+		return true
+	case StatusRunning:
+		return job.StartedAt.Add(lease).Before(now)
+	}
+	return false
+}
+
+// claim marks a job as running
+func claim(job *Job, now time.Time) {
+	job.Status = StatusRunning
+	job.StartedAt = now
+	job.Attempts++
+}
+
+// MemoryStore keeps jobs in memory. Jobs don't survive a restart; use it for tests and
+// short-lived processes.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]Job)}
+}
+
+// Put implements Store
+func (s *MemoryStore) Put(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &job, nil
+}
+
+// Claim implements Store
+func (s *MemoryStore) Claim(ctx context.Context, now time.Time, lease time.Duration) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next *Job
+	for id := range s.jobs {
+		job := s.jobs[id]
+		if claimable(&job, now, lease) && (next == nil || job.EnqueuedAt.Before(next.EnqueuedAt)) {
+			next = &job
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	claim(next, now)
+	s.jobs[next.ID] = *next
+	return next, nil
+}
+
+// FileStore keeps each job in a JSON file in a directory, so queued jobs survive restarts.
+// Claim scans the directory, which suits queues of up to a few thousand jobs; it is safe
+// for use by one process at a time.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a store in dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Put implements Store
+func (s *FileStore) Put(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(job)
+}
+
+// Get implements Store
+func (s *FileStore) Get(ctx context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read(s.path(id))
+}
+
+// Claim implements Store
+func (s *FileStore) Claim(ctx context.Context, now time.Time, lease time.Duration) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	var next *Job
+	for _, path := range paths {
+		job, err := s.read(path)
+		if err != nil {
+			return nil, err
+		}
+		if claimable(job, now, lease) && (next == nil || job.EnqueuedAt.Before(next.EnqueuedAt)) {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	claim(next, now)
+	if err := s.write(next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
+
+func (s *FileStore) read(path string) (*Job, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job: %w", err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job %s: %w", strings.TrimSuffix(filepath.Base(path), ".json"), err)
+	}
+	return &job, nil
+}
+
+// write replaces a job's file atomically, so a crash never leaves a partial record
+func (s *FileStore) write(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, job.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path(job.ID))
+}
@@ -0,0 +1,160 @@
+package rag
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Default chunking parameters, in characters
+const (
+	DefaultChunkSize    = 1000
+	DefaultChunkOverlap = 200
+)
+
+// Document is a text to be indexed
+type Document struct {
+	ID       string
+	Text     string
+	Metadata map[string]string
+}
+
+// Chunk is a piece of a document that is embedded and retrieved
+type Chunk struct {
+	// ID identifies the chunk as "<document ID>#<index>"
+	ID         string
+	DocumentID string
+	Index      int
+	Text       string
+	Metadata   map[string]string
+}
+
+// ChunkOptions controls how documents are split into chunks
+type ChunkOptions struct {
+	// Size is the maximum chunk length in characters, DefaultChunkSize if zero
+	Size int
+
+	// Overlap is the number of characters repeated from the end of the previous chunk.
+	// Zero uses DefaultChunkOverlap and a negative value disables overlap.
+	Overlap int
+}
+
+// withDefaults fills in unset chunk options
+func (o ChunkOptions) withDefaults() ChunkOptions {
+	if o.Size <= 0 {
+		o.Size = DefaultChunkSize
+	}
+	if o.Overlap < 0 || o.Overlap >= o.Size {
+		o.Overlap = 0
+	} else if o.Overlap == 0 && o.Size > DefaultChunkOverlap {
+		o.Overlap = DefaultChunkOverlap
+	}
+	return o
+}
+
+// ChunkDocument splits a document into chunks, preferring paragraph, then sentence,
+// then word boundaries so chunks read naturally when injected into a prompt
+func ChunkDocument(doc Document, opts ChunkOptions) []Chunk {
+	texts := SplitText(doc.Text, opts)
+	chunks := make([]Chunk, len(texts))
+	for i, text := range texts {
+		chunks[i] = Chunk{
+			ID:         chunkID(doc.ID, i),
+			DocumentID: doc.ID,
+			Index:      i,
+			Text:       text,
+			Metadata:   doc.Metadata,
+		}
+	}
+	return chunks
+}
+
+// SplitText splits text into pieces of at most opts.Size characters
+func SplitText(text string, opts ChunkOptions) []string {
+	opts = opts.withDefaults()
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	// Segments leave room for the overlap carried into the next chunk
+	var chunks []string
+	var current []rune
+	added := 0
+	for _, segment := range segments([]rune(text), opts.Size-opts.Overlap) {
+		if added > 0 && len(current)+len(segment) > opts.Size {
+			chunks = append(chunks, strings.TrimSpace(string(current)))
+			current = overlapTail(current, opts.Overlap)
+			added = 0
+		}
+		current = append(current, segment...)
+		added += len(segment)
+	}
+	if added > 0 {
+		chunks = append(chunks, strings.TrimSpace(string(current)))
+	}
+	return chunks
+}
+
+// segments splits text after paragraph and sentence breaks, then splits segments that
+// are still longer than size at word boundaries
+func segments(text []rune, size int) [][]rune {
+	var result [][]rune
+	start := 0
+	for i := 0; i < len(text); i++ {
+		end := -1
+		switch {
+		case text[i] == '\n' && i+1 < len(text) && text[i+1] == '\n':
+			end = i + 2
+		case (text[i] == '.' || text[i] == '!' || text[i] == '?') && i+1 < len(text) && unicode.IsSpace(text[i+1]):
+			end = i + 2
+		}
+		if end > 0 {
+			result = append(result, splitWords(text[start:end], size)...)
+			start = end
+			i = end - 1
+		}
+	}
+	if start < len(text) {
+		result = append(result, splitWords(text[start:], size)...)
+	}
+	return result
+}
+
+// splitWords splits a segment longer than size at the last space before the limit
+func splitWords(segment []rune, size int) [][]rune {
+	var result [][]rune
+	for len(segment) > size {
+		cut := size
+		for i := size; i > size/2; i-- {
+			if unicode.IsSpace(segment[i]) {
+				cut = i + 1
+				break
+			}
+		}
+		result = append(result, segment[:cut])
+		segment = segment[cut:]
+	}
+	if len(segment) > 0 {
+		result = append(result, segment)
+	}
+	return result
+}
+
+// overlapTail returns the last n characters of chunk, starting at a word boundary
+func overlapTail(chunk []rune, n int) []rune {
+	if n <= 0 {
+		return nil
+	}
+	if n >= len(chunk) {
+		return append([]rune(nil), chunk...)
+	}
+	start := len(chunk) - n
+	for i := start; i < len(chunk); i++ {
+		if unicode.IsSpace(chunk[i]) {
+			start = i + 1
+			break
+		}
+	}
+	return append([]rune(nil), chunk[start:]...)
+}
@@ -0,0 +1,49 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// Embedder turns texts into embedding vectors
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// ClientEmbedder embeds texts with the OpenRouter embeddings endpoint
+type ClientEmbedder struct {
+	client *pkg.Client
+	model  string
+}
+
+// NewEmbedder creates an embedder that uses model through client
+func NewEmbedder(client *pkg.Client, model string) *ClientEmbedder {
+	return &ClientEmbedder{client: client, model: model}
+}
+
+// Embed implements Embedder
+func (e *ClientEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	resp, err := e.client.CreateEmbeddings(ctx, models.EmbeddingRequest{
+		Model: e.model,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Data))
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, embedding := range resp.Data {
+		if embedding.Index < 0 || embedding.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", embedding.Index)
+		}
+		vectors[embedding.Index] = embedding.Embedding
+	}
+	return vectors, nil
+}
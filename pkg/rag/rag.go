@@ -0,0 +1,176 @@
+// Package rag implements retrieval-augmented generation on top of the OpenRouter
+// client: documents are split into chunks, embedded through the embeddings endpoint,
+// stored in a vector store, and retrieved to ground chat completion requests.
+//
+//	pipeline := rag.New(rag.NewEmbedder(client, "openai/text-embedding-3-small"), rag.NewMemoryStore(), rag.Options{})
+//	err := pipeline.AddDocuments(ctx, rag.Document{ID: "handbook", Text: handbook})
+//
+//	req, sources, err := pipeline.WithRAGContext(ctx, req, 4)
+//	resp, err := client.CreateChatCompletion(ctx, req)
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// DefaultBatchSize is the number of chunks embedded per request
+const DefaultBatchSize = 64
+
+// DefaultContextPrompt introduces the retrieved sources in the injected system message
+const DefaultContextPrompt = "Answer using the sources below. Cite the sources you use by their number, like [1]. " +
+	"If the sources do not contain the answer, say so."
+
+// Options configures a Pipeline
+type Options struct {
+	Chunking ChunkOptions
+
+	// BatchSize is the number of chunks embedded per request
+	BatchSize int
+
+	// ContextPrompt replaces DefaultContextPrompt
+	ContextPrompt string
+}
+
+// Pipeline indexes documents and retrieves the chunks relevant to a query
+type Pipeline struct {
+	embedder Embedder
+	store    Store
+	opts     Options
+}
+
+// New creates a pipeline that embeds with embedder and stores vectors in store
+func New(embedder Embedder, store Store, opts Options) *Pipeline {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.ContextPrompt == "" {
+		opts.ContextPrompt = DefaultContextPrompt
+	}
+	return &Pipeline{embedder: embedder, store: store, opts: opts}
+}
+
+// AddDocuments chunks, embeds, and stores documents. Documents are replaced if
+// they were added before.
+func (p *Pipeline) AddDocuments(ctx context.Context, docs ...Document) error {
+	var chunks []Chunk
+	for _, doc := range docs {
+		if doc.ID == "" {
+			return fmt.Errorf("document ID is required")
+		}
+		if err := p.store.Delete(ctx, doc.ID); err != nil {
+			return fmt.Errorf("failed to delete document %s: %w", doc.ID, err)
+		}
+		chunks = append(chunks, ChunkDocument(doc, p.opts.Chunking)...)
+	}
+
+	for start := 0; start < len(chunks); start += p.opts.BatchSize {
+		end := start + p.opts.BatchSize
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+		batch := chunks[start:end]
+
+		texts := make([]string, len(batch))
+		for i, chunk := range batch {
+			texts[i] = chunk.Text
+		}
+		vectors, err := p.embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to embed chunks: %w", err)
+		}
+		if err := p.store.Add(ctx, batch, vectors); err != nil {
+			return fmt.Errorf("failed to store chunks: %w", err)
+		}
+	}
+	return nil
+}
+
+// Retrieve returns the k chunks most relevant to query, best first
+func (p *Pipeline) Retrieve(ctx context.Context, query string, k int) ([]Result, error) {
+	vectors, err := p.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("expected 1 query embedding, got %d", len(vectors))
+	}
+
+	results, err := p.store.Search(ctx, vectors[0], k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search store: %w", err)
+	}
+	return results, nil
+}
+
+// WithRAGContext retrieves the k chunks most relevant to the last user message and
+// injects them into req as a system message with numbered sources, after any existing
+// system messages. The returned results are in source number order, so [1] is results[0].
+// The request is returned unchanged if it has no user message or nothing is retrieved.
+func (p *Pipeline) WithRAGContext(ctx context.Context, req models.ChatCompletionRequest, k int) (models.ChatCompletionRequest, []Result, error) {
+	query := lastUserText(req.Messages)
+	if query == "" {
+		return req, nil, nil
+	}
+
+	results, err := p.Retrieve(ctx, query, k)
+	if err != nil {
+		return req, nil, err
+	}
+	if len(results) == 0 {
+		return req, nil, nil
+	}
+
+	insert := 0
+	for insert < len(req.Messages) && req.Messages[insert].Role == models.RoleSystem {
+		insert++
+	}
+
+	messages := make([]models.Message, 0, len(req.Messages)+1)
+	messages = append(messages, req.Messages[:insert]...)
+	messages = append(messages, models.NewTextMessage(models.RoleSystem, p.contextMessage(results)))
+	messages = append(messages, req.Messages[insert:]...)
+	req.Messages = messages
+
+	return req, results, nil
+}
+
+// contextMessage formats results as numbered sources
+func (p *Pipeline) contextMessage(results []Result) string {
+	var b strings.Builder
+	b.WriteString(p.opts.ContextPrompt)
+	b.WriteString("\n\nSources:")
+	for i, result := range results {
+		fmt.Fprintf(&b, "\n\n[%d] %s\n%s", i+1, result.Chunk.DocumentID, result.Chunk.Text)
+	}
+	return b.String()
+}
+
+// lastUserText returns the text of the last user message
+func lastUserText(messages []models.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != models.RoleUser {
+			continue
+		}
+		contents, err := messages[i].GetMultiContent()
+		if err != nil {
+			return ""
+		}
+		var parts []string
+		for _, part := range contents {
+			if text, ok := part.(models.TextContent); ok {
+				parts = append(parts, text.Text)
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
+
+// chunkID identifies chunk index of a document
+func chunkID(documentID string, index int) string {
+	return fmt.Sprintf("%s#%d", documentID, index)
+}
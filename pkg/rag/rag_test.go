@@ -0,0 +1,92 @@
+package rag
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// keywordEmbedder embeds texts as keyword counts so similarity is predictable
+type keywordEmbedder struct {
+	keywords []string
+}
+
+func (e keywordEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float64, len(e.keywords))
+		for j, keyword := range e.keywords {
+			vectors[i][j] = float64(strings.Count(strings.ToLower(text), keyword))
+		}
+	}
+	return vectors, nil
+}
+
+func TestSplitText(t *testing.T) {
+	text := "First sentence here. Second sentence here.\n\nA new paragraph starts. It ends."
+
+	chunks := SplitText(text, ChunkOptions{Size: 45, Overlap: -1})
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "First sentence here. Second sentence here.", chunks[0])
+	assert.Equal(t, "A new paragraph starts. It ends.", chunks[1])
+
+	for _, chunk := range SplitText(strings.Repeat("word ", 100), ChunkOptions{Size: 50, Overlap: 10}) {
+		assert.LessOrEqual(t, len(chunk), 50)
+		assert.False(t, strings.HasPrefix(chunk, "ord"), "chunks should start at word boundaries")
+	}
+
+	assert.Empty(t, SplitText("   ", ChunkOptions{}))
+}
+
+func TestPipelineRetrieve(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	p := New(keywordEmbedder{keywords: []string{"cat", "dog", "bird"}}, store, Options{BatchSize: 1})
+
+	require.NoError(t, p.AddDocuments(ctx,
+		Document{ID: "cats", Text: "A cat purrs. Every cat sleeps."},
+		Document{ID: "dogs", Text: "A dog barks."},
+	))
+	assert.Equal(t, 2, store.Len())
+
+	results, err := p.Retrieve(ctx, "tell me about the dog", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "dogs", results[0].Chunk.DocumentID)
+	assert.Equal(t, "dogs#0", results[0].Chunk.ID)
+
+	// Re-adding a document replaces its chunks
+	require.NoError(t, p.AddDocuments(ctx, Document{ID: "dogs", Text: "A bird sings."}))
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestWithRAGContext(t *testing.T) {
+	ctx := context.Background()
+	p := New(keywordEmbedder{keywords: []string{"cat", "dog"}}, NewMemoryStore(), Options{})
+	require.NoError(t, p.AddDocuments(ctx,
+		Document{ID: "cats", Text: "Cats sleep sixteen hours a day."},
+		Document{ID: "dogs", Text: "Dogs need daily walks."},
+	))
+
+	req := models.NewChatRequest("openai/gpt-4o",
+		models.WithSystemMessage("You are helpful."),
+		models.WithUserMessage("How long does a cat sleep?"),
+	)
+
+	augmented, results, err := p.WithRAGContext(ctx, req, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, augmented.Messages, 3)
+	assert.Len(t, req.Messages, 2, "the original request is not modified")
+
+	assert.Equal(t, models.RoleSystem, augmented.Messages[1].Role)
+	context, err := augmented.Messages[1].GetTextContent()
+	require.NoError(t, err)
+	assert.Contains(t, context, "[1] cats\nCats sleep sixteen hours a day.")
+	assert.Equal(t, models.RoleUser, augmented.Messages[2].Role)
+}
@@ -0,0 +1,134 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Result is a chunk returned by a similarity search
+type Result struct {
+	Chunk Chunk
+
+	// Score is the cosine similarity between the query and the chunk
+	Score float64
+}
+
+// Store stores chunk embeddings and searches them by similarity. MemoryStore is
+// provided; implement Store to back the pipeline with pgvector, Qdrant, or similar.
+type Store interface {
+	// Add stores chunks with their embeddings, replacing chunks with the same ID
+	Add(ctx context.Context, chunks []Chunk, vectors [][]float64) error
+
+	// Search returns the k chunks most similar to vector, best first
+	Search(ctx context.Context, vector []float64, k int) ([]Result, error)
+
+	// Delete removes all chunks of a document
+	Delete(ctx context.Context, documentID string) error
+}
+
+// MemoryStore is an in-memory Store using brute-force cosine similarity.
+// It is safe for concurrent use.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	chunk  Chunk
+	vector []float64
+	norm   float64
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+// Add implements Store
+func (s *MemoryStore) Add(ctx context.Context, chunks []Chunk, vectors [][]float64) error {
+	if len(chunks) != len(vectors) {
+		return fmt.Errorf("got %d chunks but %d vectors", len(chunks), len(vectors))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, chunk := range chunks {
+		s.entries[chunk.ID] = memoryEntry{
+			chunk:  chunk,
+			vector: vectors[i],
+			norm:   norm(vectors[i]),
+		}
+	}
+	return nil
+}
+
+// Search implements Store
+func (s *MemoryStore) Search(ctx context.Context, vector []float64, k int) ([]Result, error) {
+	queryNorm := norm(vector)
+
+	s.mu.RLock()
+	results := make([]Result, 0, len(s.entries))
+	for _, entry := range s.entries {
+		if len(entry.vector) != len(vector) {
+			s.mu.RUnlock()
+			return nil, fmt.Errorf("vector has %d dimensions, store has %d", len(vector), len(entry.vector))
+		}
+		results = append(results, Result{
+			Chunk: entry.chunk,
+			Score: cosine(vector, entry.vector, queryNorm, entry.norm),
+		})
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Chunk.ID < results[j].Chunk.ID
+	})
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(ctx context.Context, documentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, entry := range s.entries {
+		if entry.chunk.DocumentID == documentID {
+			delete(s.entries, id)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of stored chunks
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}
+
+func norm(v []float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+func cosine(a, b []float64, normA, normB float64) float64 {
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return dot / (normA * normB)
+}
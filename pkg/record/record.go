@@ -0,0 +1,362 @@
+// Package record provides an http.RoundTripper that records OpenRouter API
+// interactions to fixture files and replays them, so integration tests can run
+// deterministically without an API key.
+//
+// Record once against the real API:
+//
+//	rec, _ := record.New("testdata/chat.json", record.ModeRecord)
+//	client := pkg.NewClient(apiKey, pkg.WithHTTPClient(rec.HTTPClient()))
+//	// ... run requests ...
+//	rec.Save()
+//
+// Then replay in CI:
+//
+//	rec, _ := record.New("testdata/chat.json", record.ModeReplay)
+//	client := pkg.NewClient("unused", pkg.WithHTTPClient(rec.HTTPClient()))
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CassetteVersion is the current fixture file format version
+const CassetteVersion = 1
+
+// Mode controls whether the recorder talks to the network
+type Mode int
+
+const (
+	// ModeReplay serves responses from the fixture file and fails on unmatched requests
+	ModeReplay Mode = iota
+
+	// ModeRecord performs real requests and records them
+	ModeRecord
+
+	// ModeAuto replays when the fixture file exists and records otherwise
+	ModeAuto
+)
+
+var (
+	// ErrNoInteraction is returned in replay mode when no recorded interaction matches a request
+	ErrNoInteraction = errors.New("record: no recorded interaction matches request")
+
+	// sensitiveHeaders are removed from recorded requests and responses
+	sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+)
+
+// Cassette is the on-disk fixture format
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request/response pair
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response. Streaming (SSE) bodies are stored verbatim.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Sanitizer modifies an interaction before it is saved, e.g. to scrub personal data
+type Sanitizer func(*Interaction)
+
+// Matcher reports whether a live request matches a recorded one
+type Matcher func(r *http.Request, body []byte, recorded Request) bool
+
+// Option configures a Recorder
+type Option func(*Recorder)
+
+// WithTransport sets the transport used to perform real requests in record mode
+func WithTransport(transport http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithSanitizer adds a sanitizer applied to every recorded interaction
+func WithSanitizer(sanitizer Sanitizer) Option {
+	return func(r *Recorder) {
+		r.sanitizers = append(r.sanitizers, sanitizer)
+	}
+}
+
+// WithMatcher replaces the default matcher (method, URL, and JSON-equivalent body)
+func WithMatcher(matcher Matcher) Option {
+	return func(r *Recorder) {
+		r.matcher = matcher
+	}
+}
+
+// Recorder records and replays HTTP interactions
+type Recorder struct {
+	path       string
+	mode       Mode
+	transport  http.RoundTripper
+	sanitizers []Sanitizer
+	matcher    Matcher
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New creates a recorder backed by the fixture file at path
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		matcher:   DefaultMatcher,
+		cassette:  Cassette{Version: CassetteVersion},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.mode == ModeAuto {
+		if _, err := os.Stat(path); err == nil {
+			r.mode = ModeReplay
+		} else {
+			r.mode = ModeRecord
+		}
+	}
+
+	if r.mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture: %w", err)
+		}
+		if r.cassette.Version > CassetteVersion {
+			return nil, fmt.Errorf("unsupported fixture version %d", r.cassette.Version)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Mode returns the effective mode of the recorder
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// HTTPClient returns an HTTP client that uses the recorder as its transport
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("record: failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+// replay serves the first unused interaction matching the request
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !r.matcher(req, body, interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+
+		header := interaction.Response.Headers.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+}
+
+// record performs the request and captures the response as it is read
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: req.Header.Clone(),
+			Body:    string(body),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    resp.Header.Clone(),
+		},
+	}
+
+	// The body is captured while the caller reads it so streams still arrive incrementally
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		done: func(data []byte) {
+			interaction.Response.Body = string(data)
+			r.add(interaction)
+		},
+	}
+	return resp, nil
+}
+
+// add sanitizes and stores a completed interaction
+func (r *Recorder) add(interaction Interaction) {
+	for _, header := range sensitiveHeaders {
+		interaction.Request.Headers.Del(header)
+		interaction.Response.Headers.Del(header)
+	}
+	for _, sanitize := range r.sanitizers {
+		sanitize(&interaction)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+}
+
+// Interactions returns a copy of the recorded or loaded interactions
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	interactions := make([]Interaction, len(r.cassette.Interactions))
+	copy(interactions, r.cassette.Interactions)
+	return interactions
+}
+
+// Save writes recorded interactions to the fixture file. It is a no-op in replay mode.
+// Response bodies must be fully read or closed before saving.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	return os.WriteFile(r.path, data, 0o644)
+}
+
+// DefaultMatcher matches on method, URL, and body. JSON bodies are compared
+// after compaction so formatting differences don't matter.
+func DefaultMatcher(r *http.Request, body []byte, recorded Request) bool {
+	if r.Method != recorded.Method || r.URL.String() != recorded.URL {
+		return false
+	}
+	return compactJSON(body) == compactJSON([]byte(recorded.Body))
+}
+
+func compactJSON(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+// recordingBody captures everything read from the response body
+type recordingBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+	done func([]byte)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *recordingBody) finish() {
+	b.once.Do(func() {
+		b.done(b.buf.Bytes())
+	})
+}
+
+// RedactBodyField returns a sanitizer that replaces the value of a top-level JSON
+// field in recorded request bodies, e.g. RedactBodyField("user", "REDACTED")
+func RedactBodyField(field string, replacement interface{}) Sanitizer {
+	return func(interaction *Interaction) {
+		var body map[string]interface{}
+		if err := json.Unmarshal([]byte(interaction.Request.Body), &body); err != nil {
+			return
+		}
+		if _, ok := body[field]; !ok {
+			return
+		}
+		body[field] = replacement
+		if data, err := json.Marshal(body); err == nil {
+			interaction.Request.Body = string(data)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package record_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/record"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+var (
+	hiRequest  = models.NewChatRequest("m", models.WithUserMessage("hi"))
+	byeRequest = models.NewChatRequest("m", models.WithUserMessage("bye"))
+)
+
+// newClient returns a client that sends requests to srv through rec
+func newClient(srv *openroutertest.Server, rec *record.Recorder) *pkg.Client {
+	return pkg.NewClient("sk-or-v1-secret", pkg.WithBaseURL(srv.URL), pkg.WithHTTPClient(rec.HTTPClient()))
+}
+
+// streamText reads a stream to the end and returns its text
+func streamText(t *testing.T, stream *streaming.ChatCompletionStreamReader) string {
+	t.Helper()
+	resp, err := streaming.CollectStream(stream)
+	require.NoError(t, err)
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	return text
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "chat.json")
+	ctx := context.Background()
+
+	srv := openroutertest.NewServer()
+	srv.EnqueueChat(openroutertest.TextReply("recorded reply"), openroutertest.TextReply("recorded stream"))
+	rec, err := record.New(path, record.ModeAuto)
+	require.NoError(t, err)
+	require.Equal(t, record.ModeRecord, rec.Mode(), "auto mode records without a fixture")
+	client := newClient(srv, rec)
+
+	resp, err := client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+	stream, err := client.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	assert.Equal(t, "recorded stream", streamText(t, stream))
+	require.NoError(t, rec.Save())
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "sk-or-v1-secret", "credentials are stripped")
+	interactions := rec.Interactions()
+	require.Len(t, interactions, 2)
+	assert.Contains(t, interactions[1].Response.Body, "data: [DONE]", "streams are stored verbatim")
+
+	// The server is gone; replays come from the fixture
+	rec, err = record.New(path, record.ModeAuto)
+	require.NoError(t, err)
+	require.Equal(t, record.ModeReplay, rec.Mode(), "auto mode replays an existing fixture")
+	client = newClient(srv, rec)
+
+	replayed, err := client.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+	assert.Equal(t, resp.ID, replayed.ID)
+	text, _ := replayed.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "recorded reply", text)
+
+	stream, err = client.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	assert.Equal(t, "recorded stream", streamText(t, stream))
+
+	// Each interaction is served once, and unrecorded requests fail
+	_, err = client.CreateChatCompletion(ctx, hiRequest)
+	assert.ErrorIs(t, err, record.ErrNoInteraction)
+	_, err = client.CreateChatCompletion(ctx, byeRequest)
+	assert.ErrorIs(t, err, record.ErrNoInteraction)
+}
+
+func TestRecordSanitizer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chat.json")
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+
+	rec, err := record.New(path, record.ModeRecord, record.WithSanitizer(record.RedactBodyField("user", "REDACTED")))
+	require.NoError(t, err)
+	req := hiRequest
+	req.User = "alice@example.com"
+	_, err = newClient(srv, rec).CreateChatCompletion(context.Background(), req)
+	require.NoError(t, err)
+	require.NoError(t, rec.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "alice@example.com")
+	assert.Contains(t, string(data), "REDACTED")
+}
+
+func TestReplayRequiresFixture(t *testing.T) {
+	_, err := record.New(filepath.Join(t.TempDir(), "missing.json"), record.ModeReplay)
+	assert.ErrorContains(t, err, "failed to read fixture")
+
+	path := filepath.Join(t.TempDir(), "future.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":99,"interactions":[]}`), 0o644))
+	_, err = record.New(path, record.ModeReplay)
+	assert.ErrorContains(t, err, "unsupported fixture version")
+}
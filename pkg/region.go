@@ -0,0 +1,184 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// DefaultRegionTTL is how long a RegionRouter keeps a model's endpoints before refetching them
+const DefaultRegionTTL = 10 * time.Minute
+
+// NoCompliantEndpointError is returned when none of a request's models has an endpoint
+// in the allowed regions
+type NoCompliantEndpointError struct {
+	Models  []string
+	Regions []string
+
+	// Available lists the regions the models' endpoints are in
+	Available []string
+}
+
+// Error names the models and the regions they are available in
+func (e *NoCompliantEndpointError) Error() string {
+	available := "none"
+	if len(e.Available) > 0 {
+		available = strings.Join(e.Available, ", ")
+	}
+	return fmt.Sprintf("no endpoint for %s in region %s (available regions: %s)",
+		strings.Join(e.Models, ", "), strings.Join(e.Regions, " or "), available)
+}
+
+// RegionRouter restricts requests to providers whose endpoints are hosted in given
+// regions, for data residency. It looks up each model's endpoints with ListModelEndpoints
+// and caches them. It is safe for concurrent use.
+type RegionRouter struct {
+	client  *Client
+	regions []string
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	endpoints map[string][]models.ModelEndpoint
+	fetched   map[string]time.Time
+}
+
+// NewRegionRouter creates a router allowing endpoints in any of regions, such as
+// models.RegionEU
+func NewRegionRouter(client *Client, regions ...string) *RegionRouter {
+	return &RegionRouter{
+		client:    client,
+		regions:   regions,
+		ttl:       DefaultRegionTTL,
+		endpoints: make(map[string][]models.ModelEndpoint),
+		fetched:   make(map[string]time.Time),
+	}
+}
+
+// SetTTL sets how long endpoints are cached
+func (r *RegionRouter) SetTTL(ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ttl = ttl
+}
+
+// Restrict limits req to providers with an endpoint in the allowed regions. Fallback
+// models without one are dropped, and Provider.Only is narrowed to the compliant providers.
+// It returns a *NoCompliantEndpointError if no model has a compliant endpoint.
+func (r *RegionRouter) Restrict(ctx context.Context, req *models.ChatCompletionRequest) error {
+	chain := req.ModelChain()
+	if len(chain) == 0 {
+		return fmt.Errorf("a model is required to restrict regions")
+	}
+
+	var kept []string
+	providers := make(map[string]bool)
+	available := make(map[string]bool)
+	for _, model := range chain {
+		endpoints, err := r.Endpoints(ctx, model)
+		if err != nil {
+			return err
+		}
+		compliant := false
+		for _, endpoint := range endpoints {
+			if endpoint.Region != "" {
+				available[endpoint.Region] = true
+			}
+			if r.allows(endpoint) {
+				providers[endpoint.Provider] = true
+				compliant = true
+			}
+		}
+		if compliant {
+			kept = append(kept, model)
+		}
+	}
+
+	if len(kept) == 0 {
+		err := &NoCompliantEndpointError{Models: chain, Regions: r.regions}
+		for region := range available {
+			err.Available = append(err.Available, region)
+		}
+		sort.Strings(err.Available)
+		return err
+	}
+
+	only := make([]string, 0, len(providers))
+	for provider := range providers {
+		only = append(only, provider)
+	}
+	sort.Strings(only)
+	if req.Provider != nil && req.Provider.Only != nil {
+		only = intersect(only, req.Provider.Only)
+		if len(only) == 0 {
+			return &NoCompliantEndpointError{Models: chain, Regions: r.regions}
+		}
+	}
+
+	req.Model = kept[0]
+	req.Models = nil
+	if len(kept) > 1 {
+		req.WithFallbackModels(kept...)
+	} else {
+		req.Route = ""
+	}
+	req.Provider = req.Provider.Merge(&models.ProviderPreferences{Only: only})
+	return nil
+}
+
+// Decorator returns a RequestDecorator restricting every request, for WithRequestDecorators
+func (r *RegionRouter) Decorator() RequestDecorator {
+	return r.Restrict
+}
+
+// Endpoints returns a model's endpoints, cached for the router's TTL
+func (r *RegionRouter) Endpoints(ctx context.Context, model string) ([]models.ModelEndpoint, error) {
+	base, _, _ := strings.Cut(model, ":")
+
+	r.mu.Lock()
+	endpoints, ok := r.endpoints[base]
+	fresh := ok && r.client.clock.Now().Sub(r.fetched[base]) <= r.ttl
+	r.mu.Unlock()
+	if fresh {
+		return endpoints, nil
+	}
+
+	resp, err := r.client.ListModelEndpoints(ctx, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoints for %s: %w", base, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints[base] = resp.Data
+	r.fetched[base] = r.client.clock.Now()
+	return resp.Data, nil
+}
+
+func (r *RegionRouter) allows(endpoint models.ModelEndpoint) bool {
+	for _, region := range r.regions {
+		if endpoint.InRegion(region) {
+			return true
+		}
+	}
+	return false
+}
+
+// intersect returns the elements of a that are also in b, in a's order
+func intersect(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, s := range b {
+		in[s] = true
+	}
+	var out []string
+	for _, s := range a {
+		if in[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
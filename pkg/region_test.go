@@ -0,0 +1,66 @@
+package pkg_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestRegionRouterRestrictsProviders(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetEndpoints("openai/gpt-4o",
+		models.ModelEndpoint{Provider: "openai", Region: "us-east-1"},
+		models.ModelEndpoint{Provider: "azure", Region: "eu-west-1"},
+		models.ModelEndpoint{Provider: "nebius", Region: "europe-north1"},
+	)
+	srv.SetEndpoints("anthropic/claude-3.5-sonnet",
+		models.ModelEndpoint{Provider: "anthropic", Region: "us"},
+	)
+
+	router := pkg.NewRegionRouter(srv.Client(), models.RegionEU)
+	client := srv.Client(pkg.WithRequestDecorators(router.Decorator()))
+
+	req := models.NewChatRequest("anthropic/claude-3.5-sonnet", models.WithUserMessage("hi"),
+		models.WithFallbackModels("openai/gpt-4o"))
+	_, err := client.CreateChatCompletion(context.Background(), req)
+	require.NoError(t, err)
+
+	sent, err := srv.Requests()[len(srv.Requests())-1].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o", sent.Model)
+	assert.Empty(t, sent.Models)
+	assert.Empty(t, sent.Route)
+	require.NotNil(t, sent.Provider)
+	assert.Equal(t, []string{"azure", "nebius"}, sent.Provider.Only)
+}
+
+func TestRegionRouterNoCompliantEndpoint(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetEndpoints("anthropic/claude-3.5-sonnet",
+		models.ModelEndpoint{Provider: "anthropic", Region: "us-east-1"},
+		models.ModelEndpoint{Provider: "bedrock", Region: "us-west-2"},
+	)
+
+	router := pkg.NewRegionRouter(srv.Client(), models.RegionEU)
+	req := models.NewChatRequest("anthropic/claude-3.5-sonnet", models.WithUserMessage("hi"))
+	err := router.Restrict(context.Background(), &req)
+
+	var regionErr *pkg.NoCompliantEndpointError
+	require.True(t, errors.As(err, &regionErr))
+	assert.Equal(t, []string{"us-east-1", "us-west-2"}, regionErr.Available)
+	assert.EqualError(t, err, "no endpoint for anthropic/claude-3.5-sonnet in region eu (available regions: us-east-1, us-west-2)")
+
+	// Endpoints are cached
+	requests := len(srv.Requests())
+	_ = router.Restrict(context.Background(), &req)
+	assert.Len(t, srv.Requests(), requests)
+}
@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// CreateReproducibleCompletion creates a chat completion and captures a bundle that can be
+// saved and replayed later to detect drift. Combine it with models.WithReproducibility
+// to make the replay as likely to match as providers allow.
+func (c *Client) CreateReproducibleCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, *models.ReproBundle, error) {
+	resp, err := c.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	bundle, err := models.NewReproBundle(req, resp)
+	if err != nil {
+		return resp, nil, fmt.Errorf("failed to capture reproducibility bundle: %w", err)
+	}
+	bundle.CapturedAt = c.clock.Now().UTC()
+	return resp, bundle, nil
+}
+
+// Replay sends a bundled request again, pinned to the model version and provider that
+// originally served it, and compares the response with the bundled one
+func (c *Client) Replay(ctx context.Context, bundle *models.ReproBundle) (*models.ChatCompletionResponse, models.ReproDiff, error) {
+	resp, err := c.CreateChatCompletion(ctx, bundle.ReplayRequest())
+	if err != nil {
+		return nil, models.ReproDiff{}, err
+	}
+	return resp, bundle.Compare(resp), nil
+}
+
+// SaveReproBundle writes a bundle to a JSON file
+func SaveReproBundle(path string, bundle *models.ReproBundle) error {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal reproducibility bundle: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write reproducibility bundle: %w", err)
+	}
+	return nil
+}
+
+// LoadReproBundle reads a bundle written by SaveReproBundle
+func LoadReproBundle(path string) (*models.ReproBundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reproducibility bundle: %w", err)
+	}
+	var bundle models.ReproBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse reproducibility bundle: %w", err)
+	}
+	return &bundle, nil
+}
@@ -0,0 +1,45 @@
+package pkg_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestReproBundleRoundTrip(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("Hello"), openroutertest.TextReply("Hello"), openroutertest.TextReply("Goodbye"))
+	client := srv.Client()
+	ctx := context.Background()
+
+	req := models.NewChatRequest("m", models.WithUserMessage("Hi"), models.WithReproducibility(7))
+	_, bundle, err := client.CreateReproducibleCompletion(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 7, *bundle.Request.Seed)
+	assert.Equal(t, openroutertest.DefaultProvider, bundle.Provider)
+
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	require.NoError(t, pkg.SaveReproBundle(path, bundle))
+	loaded, err := pkg.LoadReproBundle(path)
+	require.NoError(t, err)
+	assert.Equal(t, bundle.RequestHash, loaded.RequestHash)
+
+	_, diff, err := client.Replay(ctx, loaded)
+	require.NoError(t, err)
+	assert.False(t, diff.Drifted())
+	sent, err := srv.Requests()[1].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, []string{openroutertest.DefaultProvider}, sent.Provider.Order, "replays are pinned to the provider")
+
+	_, diff, err = client.Replay(ctx, loaded)
+	require.NoError(t, err)
+	assert.True(t, diff.ContentChanged)
+}
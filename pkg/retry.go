@@ -2,9 +2,15 @@ package pkg
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/rizome-dev/go-openrouter/pkg/errors"
@@ -20,16 +26,54 @@ type RetryConfig struct {
 	BackoffFactor   float64
 	JitterFactor    float64
 	RetryableErrors map[errors.ErrorCode]bool
+
+	// JitterSource returns random numbers in [0, 1) for jitter. Defaults to math/rand;
+	// set it to a fixed function for deterministic backoff in tests.
+	JitterSource func() float64
+
+	// TotalTimeout bounds all attempts and backoff delays together. The caller's context
+	// deadline still applies if it is earlier. Zero means no limit.
+	TotalTimeout time.Duration
+
+	// PerAttemptTimeout bounds each attempt. An attempt that hits it is retried like a
+	// timeout error. Zero means no limit.
+	PerAttemptTimeout time.Duration
+
+	// RetryTransportErrors retries network errors that are safe to repeat, such as a reset
+	// connection or a temporary DNS failure. See IsTransientNetworkError.
+	RetryTransportErrors bool
+
+	// ShouldRetry, if set, classifies errors instead of RetryableErrors and
+	// RetryTransportErrors, e.g. to retry a custom transport's errors
+	ShouldRetry func(err error) bool
+
+	// SplitDeadline divides the time remaining until the deadline evenly across the
+	// remaining attempts, so a hanging first attempt can't use up the whole budget.
+	SplitDeadline bool
+
+	// ModelOverrides replace this policy for matching models, e.g. longer 429 backoff
+	// for "*:free" models. The first matching pattern wins.
+	ModelOverrides []ModelRetryOverride
+
+	// OperationOverrides replace this policy per operation (RetryOperationChat,
+	// RetryOperationModels or RetryOperationKeys), e.g. to fail fast on key management.
+	// Model overrides take precedence.
+	OperationOverrides map[string]*RetryConfig
+
+	// Logger, when set, warns about each retry. Overrides without a Logger use the
+	// RetryClient's.
+	Logger Logger
 }
 
 // DefaultRetryConfig returns default retry configuration
 func DefaultRetryConfig() *RetryConfig {
 	return &RetryConfig{
-		MaxRetries:    3,
-		InitialDelay:  1 * time.Second,
-		MaxDelay:      30 * time.Second,
-		BackoffFactor: 2.0,
-		JitterFactor:  0.1,
+		MaxRetries:           3,
+		InitialDelay:         1 * time.Second,
+		MaxDelay:             30 * time.Second,
+		BackoffFactor:        2.0,
+		JitterFactor:         0.1,
+		RetryTransportErrors: true,
 		RetryableErrors: map[errors.ErrorCode]bool{
 			errors.ErrorCodeTimeout:          true,
 			errors.ErrorCodeRateLimited:      true,
@@ -39,7 +83,23 @@ func DefaultRetryConfig() *RetryConfig {
 	}
 }
 
-// RetryClient wraps a client with retry logic
+// Retry operations select per-operation policies in RetryConfig.OperationOverrides
+const (
+	RetryOperationChat   = "chat_completion"
+	RetryOperationModels = "models"
+	RetryOperationKeys   = "keys"
+)
+
+// ModelRetryOverride applies a retry policy to models matching a pattern
+type ModelRetryOverride struct {
+	// Pattern is matched against the model ID, where * matches any characters,
+	// e.g. "*:free" or "openai/*"
+	Pattern string
+	Config  *RetryConfig
+}
+
+// RetryClient wraps a client with retry logic. It is safe for concurrent use as long as
+// the RetryConfig, including JitterSource, is not modified after creation.
 type RetryClient struct {
 	*Client
 	config *RetryConfig
@@ -59,21 +119,133 @@ func NewRetryClient(apiKey string, retryConfig *RetryConfig, opts ...Option) *Re
 
 // CreateChatCompletion creates a chat completion with retry logic
 func (r *RetryClient) CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	// Every attempt shares one idempotency key so a retried request isn't billed twice
+	ctx = ensureIdempotencyKey(ctx)
+
+	return withRetry(ctx, r, RetryOperationChat, req.Model, func(ctx context.Context) (*models.ChatCompletionResponse, error) {
+		return r.Client.CreateChatCompletion(ctx, req)
+	})
+}
+
+// ListModels lists available models with retry logic
+func (r *RetryClient) ListModels(ctx context.Context, opts *ListModelsOptions) (*models.ModelsResponse, error) {
+	return withRetry(ctx, r, RetryOperationModels, "", func(ctx context.Context) (*models.ModelsResponse, error) {
+		return r.Client.ListModels(ctx, opts)
+	})
+}
+
+// ListModelEndpoints lists the endpoints of a model with retry logic
+func (r *RetryClient) ListModelEndpoints(ctx context.Context, model string) (*models.ModelEndpointsResponse, error) {
+	return withRetry(ctx, r, RetryOperationModels, model, func(ctx context.Context) (*models.ModelEndpointsResponse, error) {
+		return r.Client.ListModelEndpoints(ctx, model)
+	})
+}
+
+// ListAPIKeys lists API keys with retry logic
+func (r *RetryClient) ListAPIKeys(ctx context.Context, opts *ListAPIKeysOptions) (*models.APIKeysResponse, error) {
+	return withRetry(ctx, r, RetryOperationKeys, "", func(ctx context.Context) (*models.APIKeysResponse, error) {
+		return r.Client.ListAPIKeys(ctx, opts)
+	})
+}
+
+// GetAPIKey gets an API key with retry logic
+func (r *RetryClient) GetAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	return withRetry(ctx, r, RetryOperationKeys, "", func(ctx context.Context) (*models.APIKey, error) {
+		return r.Client.GetAPIKey(ctx, keyHash)
+	})
+}
+
+// GetCurrentAPIKey gets the current API key with retry logic
+func (r *RetryClient) GetCurrentAPIKey(ctx context.Context) (*models.APIKey, error) {
+	return withRetry(ctx, r, RetryOperationKeys, "", func(ctx context.Context) (*models.APIKey, error) {
+		return r.Client.GetCurrentAPIKey(ctx)
+	})
+}
+
+// policy returns the retry config for an operation and model. Model overrides take
+// precedence over operation overrides; the first matching model pattern wins.
+func (r *RetryClient) policy(operation, model string) *RetryConfig {
+	if model != "" {
+		for _, override := range r.config.ModelOverrides {
+			if matchModelPattern(override.Pattern, model) && override.Config != nil {
+				return override.Config
+			}
+		}
+	}
+	if config := r.config.OperationOverrides[operation]; config != nil {
+		return config
+	}
+	return r.config
+}
+
+// logger returns the policy's logger, falling back to the client's
+func (r *RetryClient) logger(config *RetryConfig) Logger {
+	if config.Logger != nil {
+		return config.Logger
+	}
+	return r.config.Logger
+}
+
+// matchModelPattern reports whether model matches a pattern in which * matches any characters
+func matchModelPattern(pattern, model string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == model
+	}
+	if !strings.HasPrefix(model, parts[0]) {
+		return false
+	}
+	model = model[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(model, part)
+		if i < 0 {
+			return false
+		}
+		model = model[i+len(part):]
+	}
+	return strings.HasSuffix(model, parts[len(parts)-1])
+}
+
+// withRetry calls fn until it succeeds, fails with an error that isn't retryable, or the
+// operation's retry policy is exhausted
+func withRetry[T any](ctx context.Context, r *RetryClient, operation, model string, fn func(context.Context) (T, error)) (T, error) {
+	var zero T
 	var lastErr error
 
-	for attempt := 0; attempt <= r.config.MaxRetries; attempt++ {
+	config := r.policy(operation, model)
+	labels := map[string]string{"model": model}
+
+	if config.TotalTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.TotalTimeout)
+		defer cancel()
+	}
+
+	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
 		// Calculate delay for this attempt
 		if attempt > 0 {
-			delay := r.calculateDelay(attempt)
+			delay := config.delay(attempt)
+
+			// Don't wait for a retry that can't start before the deadline. Context deadlines
+			// are in real time, so they are measured with time.Until rather than r.clock.
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+				if metrics := r.resilienceMetrics(); metrics != nil {
+					metrics.RecordRetriesExhausted(operation, lastErr, labels)
+				}
+				return zero, fmt.Errorf("retry deadline exceeded: %w", lastErr)
+			}
+
 			select {
 			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(delay):
+				return zero, ctx.Err()
+			case <-r.clock.After(delay):
 			}
 		}
 
 		// Make request
-		resp, err := r.Client.CreateChatCompletion(ctx, req)
+		attemptCtx, cancel := config.attemptContext(ctx, config.MaxRetries-attempt+1)
+		resp, err := fn(attemptCtx)
+		cancel()
 		if err == nil {
 			return resp, nil
 		}
@@ -81,17 +253,53 @@ func (r *RetryClient) CreateChatCompletion(ctx context.Context, req models.ChatC
 		lastErr = err
 
 		// Check if error is retryable
-		if !r.isRetryable(err) {
-			return nil, err
+		if !config.retryable(err) && !isAttemptTimeout(ctx, err) {
+			return zero, err
+		}
+
+		if attempt < config.MaxRetries {
+			if logger := r.logger(config); logger != nil {
+				logger.Warn("Retrying request",
+					F("operation", operation),
+					F("model", model),
+					F("attempt", attempt+1),
+					F("max_retries", config.MaxRetries),
+					F("error", err),
+				)
+			}
+			if metrics := r.resilienceMetrics(); metrics != nil {
+				metrics.RecordRetry(operation, attempt+1, err, labels)
+			}
 		}
+	}
+
+	if metrics := r.resilienceMetrics(); metrics != nil {
+		metrics.RecordRetriesExhausted(operation, lastErr, labels)
+	}
+	return zero, fmt.Errorf("max retries exceeded: %w", lastErr)
+}
 
-		// Log retry attempt
-		if attempt < r.config.MaxRetries {
-			fmt.Printf("Retry attempt %d/%d after error: %v\n", attempt+1, r.config.MaxRetries, err)
+// attemptContext bounds a single attempt by the per-attempt timeout and, with SplitDeadline,
+// by an even share of the time left before the deadline
+func (c *RetryConfig) attemptContext(ctx context.Context, attemptsLeft int) (context.Context, context.CancelFunc) {
+	timeout := c.PerAttemptTimeout
+	if deadline, ok := ctx.Deadline(); ok && c.SplitDeadline {
+		share := time.Until(deadline) / time.Duration(attemptsLeft)
+		if timeout <= 0 || share < timeout {
+			timeout = share
 		}
 	}
 
-	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// isAttemptTimeout reports whether err came from an attempt's own timeout rather than
+// the caller's context
+func isAttemptTimeout(ctx context.Context, err error) bool {
+	return stderrors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil
 }
 
 // CreateChatCompletionStream creates a streaming chat completion with retry logic
@@ -100,39 +308,77 @@ func (r *RetryClient) CreateChatCompletionStream(ctx context.Context, req models
 	return nil, fmt.Errorf("retry not supported for streaming")
 }
 
-// calculateDelay calculates the delay for a given attempt
-func (r *RetryClient) calculateDelay(attempt int) time.Duration {
+// delay calculates the backoff delay before a given attempt
+func (c *RetryConfig) delay(attempt int) time.Duration {
 	// Exponential backoff
-	delay := float64(r.config.InitialDelay) * math.Pow(r.config.BackoffFactor, float64(attempt-1))
+	delay := float64(c.InitialDelay) * math.Pow(c.BackoffFactor, float64(attempt-1))
 
 	// Apply max delay
-	if delay > float64(r.config.MaxDelay) {
-		delay = float64(r.config.MaxDelay)
+	if delay > float64(c.MaxDelay) {
+		delay = float64(c.MaxDelay)
 	}
 
 	// Apply jitter
-	jitter := delay * r.config.JitterFactor * (2*rand.Float64() - 1)
+	random := rand.Float64
+	if c.JitterSource != nil {
+		random = c.JitterSource
+	}
+	jitter := delay * c.JitterFactor * (2*random() - 1)
 	delay += jitter
 
 	return time.Duration(delay)
 }
 
-// isRetryable checks if an error is retryable
-func (r *RetryClient) isRetryable(err error) bool {
+// retryable checks if an error is retryable
+func (c *RetryConfig) retryable(err error) bool {
+	if c.ShouldRetry != nil {
+		return c.ShouldRetry(err)
+	}
+
 	apiErr, ok := err.(*errors.APIError)
 	if !ok {
+		return c.RetryTransportErrors && IsTransientNetworkError(err)
+	}
+
+	return c.RetryableErrors[apiErr.Code]
+}
+
+// IsTransientNetworkError reports whether err is a network failure that is safe to retry:
+// a refused or reset connection, a connection closed before the response headers arrived,
+// or a temporary DNS failure. Context cancellation and deadlines are never transient.
+//
+// Chat completion requests carry an idempotency key, so repeating one whose connection
+// dropped after it was sent won't be billed twice.
+func IsTransientNetworkError(err error) bool {
+	if err == nil || stderrors.Is(err, context.Canceled) || stderrors.Is(err, context.DeadlineExceeded) {
 		return false
 	}
 
-	return r.config.RetryableErrors[apiErr.Code]
+	var dnsErr *net.DNSError
+	if stderrors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+
+	if stderrors.Is(err, io.EOF) || stderrors.Is(err, io.ErrUnexpectedEOF) ||
+		stderrors.Is(err, syscall.ECONNRESET) || stderrors.Is(err, syscall.ECONNREFUSED) ||
+		stderrors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if stderrors.As(err, &opErr) {
+		return opErr.Op == "dial"
+	}
+	return false
 }
 
-// CircuitBreaker implements circuit breaker pattern
+// CircuitBreaker implements circuit breaker pattern. It is safe for concurrent use.
 type CircuitBreaker struct {
 	client           *Client
 	failureThreshold int
 	resetTimeout     time.Duration
 
+	mu          sync.Mutex
 	failures    int
 	lastFailure time.Time
 	state       CircuitState
@@ -147,6 +393,20 @@ const (
 	CircuitHalfOpen
 )
 
+// String returns the state name
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
 // NewCircuitBreaker creates a new circuit breaker
 func NewCircuitBreaker(client *Client, failureThreshold int, resetTimeout time.Duration) *CircuitBreaker {
 	return &CircuitBreaker{
@@ -175,11 +435,14 @@ func (cb *CircuitBreaker) CreateChatCompletion(ctx context.Context, req models.C
 
 // checkState checks if the circuit allows requests
 func (cb *CircuitBreaker) checkState() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
 	switch cb.state {
 	case CircuitOpen:
 		// Check if we should transition to half-open
-		if time.Since(cb.lastFailure) > cb.resetTimeout {
-			cb.state = CircuitHalfOpen
+		if cb.client.clock.Now().Sub(cb.lastFailure) > cb.resetTimeout {
+			cb.setState(CircuitHalfOpen)
 			cb.failures = 0
 		} else {
 			return fmt.Errorf("circuit breaker is open")
@@ -190,19 +453,33 @@ func (cb *CircuitBreaker) checkState() error {
 
 // recordResult records the result of a request
 func (cb *CircuitBreaker) recordResult(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
 	if err == nil {
 		// Success
 		if cb.state == CircuitHalfOpen {
-			cb.state = CircuitClosed
+			cb.setState(CircuitClosed)
 		}
 		cb.failures = 0
 	} else {
 		// Failure
 		cb.failures++
-		cb.lastFailure = time.Now()
+		cb.lastFailure = cb.client.clock.Now()
 
 		if cb.failures >= cb.failureThreshold {
-			cb.state = CircuitOpen
+			cb.setState(CircuitOpen)
 		}
 	}
 }
+
+// setState changes the circuit state, recording the transition
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	if cb.state == state {
+		return
+	}
+	if metrics := cb.client.resilienceMetrics(); metrics != nil {
+		metrics.RecordCircuitStateChange(cb.state, state, nil)
+	}
+	cb.state = state
+}
@@ -0,0 +1,249 @@
+package pkg_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestRetryClientBackoffWithFakeClock(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(
+		openroutertest.ErrorReply(429, "rate limited"),
+		openroutertest.ErrorReply(429, "rate limited"),
+		openroutertest.Reply{Response: openroutertest.NewTextResponse("ok")},
+	)
+
+	clock := openroutertest.NewFakeClock(time.Unix(0, 0))
+	config := pkg.DefaultRetryConfig()
+	config.JitterSource = func() float64 { return 0.5 } // no jitter
+	client := pkg.NewRetryClient("sk-or-test", config, pkg.WithBaseURL(srv.URL), pkg.WithClock(clock))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.CreateChatCompletion(context.Background(), models.NewChatRequest("m", models.WithUserMessage("hi")))
+		done <- err
+	}()
+
+	// First retry waits InitialDelay, the second twice that
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second} {
+		clock.BlockUntil(1)
+		clock.Advance(delay - time.Millisecond)
+		assert.Equal(t, 1, clock.Waiters(), "retry fired before its delay")
+		clock.Advance(time.Millisecond)
+	}
+
+	require.NoError(t, <-done)
+	assert.Len(t, srv.Requests(), 3)
+}
+
+func TestCircuitBreakerResetWithFakeClock(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(
+		openroutertest.ErrorReply(502, "bad gateway"),
+		openroutertest.ErrorReply(502, "bad gateway"),
+		openroutertest.Reply{Response: openroutertest.NewTextResponse("ok")},
+	)
+
+	clock := openroutertest.NewFakeClock(time.Unix(0, 0))
+	breaker := pkg.NewCircuitBreaker(srv.Client(pkg.WithClock(clock)), 2, 30*time.Second)
+	ctx := context.Background()
+	req := models.NewChatRequest("m", models.WithUserMessage("hi"))
+
+	for i := 0; i < 2; i++ {
+		_, err := breaker.CreateChatCompletion(ctx, req)
+		require.Error(t, err)
+	}
+
+	_, err := breaker.CreateChatCompletion(ctx, req)
+	require.EqualError(t, err, "circuit breaker is open")
+
+	clock.Advance(31 * time.Second)
+	_, err = breaker.CreateChatCompletion(ctx, req)
+	require.NoError(t, err)
+	assert.Len(t, srv.Requests(), 3)
+}
+
+func TestRetryClientPerAttemptTimeout(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(
+		openroutertest.Reply{Response: openroutertest.NewTextResponse("slow"), Latency: time.Second},
+		openroutertest.Reply{Response: openroutertest.NewTextResponse("ok")},
+	)
+
+	config := pkg.DefaultRetryConfig()
+	config.InitialDelay = time.Millisecond
+	config.PerAttemptTimeout = 50 * time.Millisecond
+	client := pkg.NewRetryClient("sk-or-test", config, pkg.WithBaseURL(srv.URL))
+
+	resp, err := client.CreateChatCompletion(context.Background(), models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	content, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "ok", content)
+	assert.Len(t, srv.Requests(), 2)
+}
+
+func TestRetryClientSkipsRetryPastDeadline(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.ErrorReply(429, "rate limited"))
+
+	config := pkg.DefaultRetryConfig()
+	config.TotalTimeout = 100 * time.Millisecond // shorter than the 1s backoff
+	client := pkg.NewRetryClient("sk-or-test", config, pkg.WithBaseURL(srv.URL))
+
+	start := time.Now()
+	_, err := client.CreateChatCompletion(context.Background(), models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.ErrorContains(t, err, "retry deadline exceeded")
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.Len(t, srv.Requests(), 1)
+}
+
+func TestRetryClientDeadlinesWithFakeClock(t *testing.T) {
+	// Deadlines are real time while backoff runs on the client's clock, wherever it is set
+	for _, start := range []time.Time{time.Unix(0, 0), time.Now().Add(24 * time.Hour)} {
+		t.Run(start.Format(time.DateOnly), func(t *testing.T) {
+			srv := openroutertest.NewServer()
+			defer srv.Close()
+			clock := openroutertest.NewFakeClock(start)
+			config := pkg.DefaultRetryConfig()
+			config.JitterSource = func() float64 { return 0.5 } // no jitter
+			config.SplitDeadline = true
+			client := pkg.NewRetryClient("sk-or-test", config, pkg.WithBaseURL(srv.URL), pkg.WithClock(clock))
+			req := models.NewChatRequest("m", models.WithUserMessage("hi"))
+
+			// A retry that fits in the deadline waits for the clock
+			config.TotalTimeout = 10 * time.Second
+			srv.EnqueueChat(openroutertest.ErrorReply(429, "rate limited"), openroutertest.TextReply("ok"))
+			done := make(chan error, 1)
+			go func() {
+				_, err := client.CreateChatCompletion(context.Background(), req)
+				done <- err
+			}()
+			clock.BlockUntil(1)
+			clock.Advance(time.Second)
+			require.NoError(t, <-done)
+			assert.Len(t, srv.Requests(), 2)
+
+			// A retry that can't start before the deadline is skipped without waiting
+			srv.Reset()
+			config.TotalTimeout = 100 * time.Millisecond
+			srv.EnqueueChat(openroutertest.ErrorReply(429, "rate limited"))
+			_, err := client.CreateChatCompletion(context.Background(), req)
+			require.ErrorContains(t, err, "retry deadline exceeded")
+			assert.Len(t, srv.Requests(), 1)
+		})
+	}
+}
+
+func TestRetryClientLogsRetries(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(
+		openroutertest.ErrorReply(429, "rate limited"),
+		openroutertest.ErrorReply(429, "rate limited"),
+		openroutertest.TextReply("ok"),
+	)
+
+	logger := &recordingLogger{}
+	config := pkg.DefaultRetryConfig()
+	config.InitialDelay = time.Millisecond
+	config.Logger = logger
+	client := pkg.NewRetryClient("sk-or-test", config, pkg.WithBaseURL(srv.URL))
+
+	_, err := client.CreateChatCompletion(context.Background(), models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Retrying request", "Retrying request"}, logger.warnings())
+}
+
+// resetOnceTransport fails the first request with a reset connection
+type resetOnceTransport struct {
+	reset bool
+}
+
+func (t *resetOnceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.reset {
+		t.reset = true
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRetryClientRetriesTransportErrors(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.Reply{Response: openroutertest.NewTextResponse("ok")})
+
+	config := pkg.DefaultRetryConfig()
+	config.InitialDelay = time.Millisecond
+	client := pkg.NewRetryClient("sk-or-test", config,
+		pkg.WithHTTPClient(&http.Client{Transport: &resetOnceTransport{}}), pkg.WithBaseURL(srv.URL))
+
+	_, err := client.CreateChatCompletion(context.Background(), models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	assert.Len(t, srv.Requests(), 1)
+
+	// A custom predicate replaces the default classification
+	config.ShouldRetry = func(error) bool { return false }
+	client = pkg.NewRetryClient("sk-or-test", config,
+		pkg.WithHTTPClient(&http.Client{Transport: &resetOnceTransport{}}), pkg.WithBaseURL(srv.URL))
+	_, err = client.CreateChatCompletion(context.Background(), models.NewChatRequest("m", models.WithUserMessage("hi")))
+	require.ErrorIs(t, err, syscall.ECONNRESET)
+}
+
+func TestIsTransientNetworkError(t *testing.T) {
+	assert.True(t, pkg.IsTransientNetworkError(fmt.Errorf("failed to perform request: %w", io.ErrUnexpectedEOF)))
+	assert.True(t, pkg.IsTransientNetworkError(&net.DNSError{Err: "server misbehaving", IsTemporary: true}))
+	assert.True(t, pkg.IsTransientNetworkError(&net.OpError{Op: "dial", Err: errors.New("no route to host")}))
+	assert.False(t, pkg.IsTransientNetworkError(&net.DNSError{Err: "no such host", IsNotFound: true}))
+	assert.False(t, pkg.IsTransientNetworkError(context.DeadlineExceeded))
+	assert.False(t, pkg.IsTransientNetworkError(errors.New("boom")))
+}
+
+func TestRetryClientModelOverrides(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(
+		openroutertest.ErrorReply(429, "rate limited"),
+		openroutertest.ErrorReply(429, "rate limited"),
+		openroutertest.Reply{Response: openroutertest.NewTextResponse("ok")},
+	)
+
+	failFast := pkg.DefaultRetryConfig()
+	failFast.MaxRetries = 0
+	free := pkg.DefaultRetryConfig()
+	free.InitialDelay = time.Millisecond
+
+	config := pkg.DefaultRetryConfig()
+	config.OperationOverrides = map[string]*pkg.RetryConfig{pkg.RetryOperationChat: failFast}
+	config.ModelOverrides = []pkg.ModelRetryOverride{{Pattern: "*:free", Config: free}}
+	client := pkg.NewRetryClient("sk-or-test", config, pkg.WithBaseURL(srv.URL))
+	ctx := context.Background()
+
+	// The chat override fails fast
+	_, err := client.CreateChatCompletion(ctx, models.NewChatRequest("openai/gpt-4o", models.WithUserMessage("hi")))
+	require.Error(t, err)
+	assert.Len(t, srv.Requests(), 1)
+
+	// Free models match the model override, which takes precedence
+	_, err = client.CreateChatCompletion(ctx, models.NewChatRequest("meta-llama/llama-3-8b:free", models.WithUserMessage("hi")))
+	require.NoError(t, err)
+	assert.Len(t, srv.Requests(), 3)
+}
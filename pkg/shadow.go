@@ -0,0 +1,272 @@
+package pkg
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/sink"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+const (
+	// DefaultShadowMaxInFlight is how many shadow requests run at once by default
+	DefaultShadowMaxInFlight = 10
+
+	// DefaultShadowTimeout bounds each shadow request by default
+	DefaultShadowTimeout = 2 * time.Minute
+)
+
+// ShadowOptions configures a ShadowClient
+type ShadowOptions struct {
+	// Model is the candidate model requests are mirrored to. Required.
+	Model string
+
+	// SampleRate is the fraction of successful requests mirrored, from 0 to 1
+	SampleRate float64
+
+	// Client sends the shadow requests. Defaults to the primary client.
+	Client *Client
+
+	// Sink receives two records per mirrored request, with IDs "<primary response
+	// ID>:primary" and ":candidate" and sources "shadow:primary" and "shadow:candidate",
+	// for offline evaluation of the candidate against production
+	Sink sink.Sink
+
+	// OnResult, when set, is called with each comparison from the shadow goroutine
+	OnResult func(ShadowResult)
+
+	// MaxInFlight bounds concurrent shadow requests; requests sampled while it is
+	// reached are dropped rather than queued. Defaults to DefaultShadowMaxInFlight.
+	MaxInFlight int
+
+	// Timeout bounds each shadow request. Defaults to DefaultShadowTimeout.
+	Timeout time.Duration
+}
+
+// ShadowResult compares a production response with the candidate's response to the
+// same request
+type ShadowResult struct {
+	// Request is the primary request; the candidate's only differs by model
+	Request models.ChatCompletionRequest
+
+	Primary        *models.ChatCompletionResponse
+	PrimaryLatency time.Duration
+
+	// Shadow is nil when the candidate failed with ShadowErr
+	Shadow        *models.ChatCompletionResponse
+	ShadowLatency time.Duration
+	ShadowErr     error
+}
+
+// ShadowStats summarizes the requests a ShadowClient has mirrored
+type ShadowStats struct {
+	// Mirrored counts completed shadow requests, Failed those of them that failed, and
+	// Dropped sampled requests skipped because MaxInFlight was reached
+	Mirrored int
+	Failed   int
+	Dropped  int
+
+	// Mean latencies of the mirrored requests, and of the successful shadow requests
+	PrimaryLatency time.Duration
+	ShadowLatency  time.Duration
+
+	// Total tokens and cost of the mirrored requests, primary and successful shadow
+	PrimaryTokens int
+	ShadowTokens  int
+	PrimaryCost   float64
+	ShadowCost    float64
+}
+
+// ShadowClient serves requests from the primary model and mirrors a sample of them to a
+// candidate model in the background, so the candidate can be evaluated against
+// production traffic before an upgrade. Shadow requests never delay or change the
+// primary response, and their failures are only recorded. It is safe for concurrent use.
+type ShadowClient struct {
+	client *Client
+	opts   ShadowOptions
+	slots  chan struct{}
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	rand  *rand.Rand
+	stats shadowTotals
+}
+
+// shadowTotals accumulates ShadowStats
+type shadowTotals struct {
+	ShadowStats
+	primaryLatency time.Duration
+	shadowLatency  time.Duration
+}
+
+// NewShadowClient creates a shadow client sending production requests with client
+func NewShadowClient(client *Client, opts ShadowOptions) *ShadowClient {
+	if opts.Client == nil {
+		opts.Client = client
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = DefaultShadowMaxInFlight
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultShadowTimeout
+	}
+	return &ShadowClient{
+		client: client,
+		opts:   opts,
+		slots:  make(chan struct{}, opts.MaxInFlight),
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// CreateChatCompletion creates a chat completion with the primary model, mirroring a
+// sample of successful requests to the candidate
+func (s *ShadowClient) CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	start := s.client.clock.Now()
+	resp, err := s.client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if s.sampled() {
+		s.mirror(ctx, req, resp, s.client.clock.Now().Sub(start))
+	}
+	return resp, nil
+}
+
+// CreateChatCompletionStream streams a chat completion from the primary model. A sample
+// of streams that are read to the end are mirrored to the candidate once they finish.
+func (s *ShadowClient) CreateChatCompletionStream(ctx context.Context, req models.ChatCompletionRequest) (*streaming.ChatCompletionStreamReader, error) {
+	stream, err := s.client.CreateChatCompletionStream(ctx, req)
+	if err != nil || !s.sampled() {
+		return stream, err
+	}
+
+	acc := streaming.NewAccumulator()
+	stream.OnChunk(func(index int, chunk *models.ChatCompletionResponse) {
+		acc.Add(chunk)
+	})
+	stream.OnComplete(func(summary streaming.StreamSummary) {
+		// Streams closed early have no finish reason, and nothing to compare
+		if summary.FinishReason != "" {
+			s.mirror(ctx, req, acc.Response(), summary.Duration)
+		}
+	})
+	return stream, nil
+}
+
+// Stats returns a summary of the mirrored requests so far
+func (s *ShadowClient) Stats() ShadowStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats.ShadowStats
+	if stats.Mirrored > 0 {
+		stats.PrimaryLatency = s.stats.primaryLatency / time.Duration(stats.Mirrored)
+	}
+	if succeeded := stats.Mirrored - stats.Failed; succeeded > 0 {
+		stats.ShadowLatency = s.stats.shadowLatency / time.Duration(succeeded)
+	}
+	return stats
+}
+
+// Wait waits for the shadow requests in flight, e.g. before shutting down
+func (s *ShadowClient) Wait() {
+	s.wg.Wait()
+}
+
+// sampled reports whether to mirror the next request
+func (s *ShadowClient) sampled() bool {
+	if s.opts.SampleRate <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Float64() < s.opts.SampleRate
+}
+
+// mirror sends req to the candidate in the background, unless MaxInFlight is reached
+func (s *ShadowClient) mirror(ctx context.Context, req models.ChatCompletionRequest, primary *models.ChatCompletionResponse, latency time.Duration) {
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		s.mu.Lock()
+		s.stats.Dropped++
+		s.mu.Unlock()
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.slots }()
+
+		// The shadow request outlives the caller's request, but not its values
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.opts.Timeout)
+		defer cancel()
+		result := ShadowResult{Request: req, Primary: primary, PrimaryLatency: latency}
+		shadowReq := req
+		shadowReq.Model, shadowReq.Models, shadowReq.Route = s.opts.Model, nil, ""
+
+		start := s.opts.Client.clock.Now()
+		result.Shadow, result.ShadowErr = s.opts.Client.CreateChatCompletion(ctx, shadowReq)
+		result.ShadowLatency = s.opts.Client.clock.Now().Sub(start)
+
+		s.record(result)
+		if s.opts.Sink != nil {
+			s.write(ctx, shadowReq, result)
+		}
+		if s.opts.OnResult != nil {
+			s.opts.OnResult(result)
+		}
+	}()
+}
+
+// record adds a result to the stats and the client's metrics
+func (s *ShadowClient) record(result ShadowResult) {
+	labels := map[string]string{"model": s.opts.Model}
+	s.mu.Lock()
+	s.stats.Mirrored++
+	s.stats.primaryLatency += result.PrimaryLatency
+	if usage := result.Primary.Usage; usage != nil {
+		s.stats.PrimaryTokens += usage.TotalTokens
+		s.stats.PrimaryCost += usage.Cost
+	}
+	if result.ShadowErr != nil {
+		s.stats.Failed++
+	} else {
+		s.stats.shadowLatency += result.ShadowLatency
+		if usage := result.Shadow.Usage; usage != nil {
+			s.stats.ShadowTokens += usage.TotalTokens
+			s.stats.ShadowCost += usage.Cost
+		}
+	}
+	s.mu.Unlock()
+
+	if metrics := s.client.metrics; metrics != nil {
+		if result.ShadowErr != nil {
+			metrics.RecordError("shadow", result.ShadowErr, labels)
+		} else {
+			metrics.RecordLatency("shadow", result.ShadowLatency, labels)
+		}
+	}
+}
+
+// write sends the primary and candidate records of a result to the sink
+func (s *ShadowClient) write(ctx context.Context, shadowReq models.ChatCompletionRequest, result ShadowResult) {
+	now := s.client.clock.Now()
+	id := result.Primary.ID
+	candidate := sink.Record{ID: id + ":candidate", Source: "shadow:candidate", Request: &shadowReq, Response: result.Shadow, Time: now}
+	if result.ShadowErr != nil {
+		candidate.Error = result.ShadowErr.Error()
+	}
+	records := []sink.Record{
+		{ID: id + ":primary", Source: "shadow:primary", Request: &result.Request, Response: result.Primary, Time: now},
+		candidate,
+	}
+	for _, record := range records {
+		if err := s.opts.Sink.Write(ctx, record); err != nil && s.client.metrics != nil {
+			s.client.metrics.RecordError("shadow_sink", err, map[string]string{"model": s.opts.Model})
+		}
+	}
+}
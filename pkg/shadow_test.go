@@ -0,0 +1,111 @@
+package pkg_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/sink"
+)
+
+// byModel replies with the model's name, so primary and shadow replies differ
+func byModel(req models.ChatCompletionRequest) openroutertest.Reply {
+	return openroutertest.TextReply("answer from " + req.Model)
+}
+
+func TestShadowClientMirrors(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetChatHandler(byModel)
+
+	records := make(chan sink.Record, 10)
+	var results []pkg.ShadowResult
+	shadow := pkg.NewShadowClient(srv.Client(), pkg.ShadowOptions{
+		Model:      "candidate/model",
+		SampleRate: 1,
+		Sink:       sink.NewChannelSink(records),
+		OnResult:   func(result pkg.ShadowResult) { results = append(results, result) },
+	})
+
+	resp, err := shadow.CreateChatCompletion(context.Background(), models.NewChatRequest("primary/model", models.WithUserMessage("Hi")))
+	require.NoError(t, err)
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "answer from primary/model", text, "the primary response is unchanged")
+	shadow.Wait()
+
+	require.Len(t, results, 1)
+	require.NoError(t, results[0].ShadowErr)
+	text, _ = results[0].Shadow.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "answer from candidate/model", text)
+
+	primary, candidate := <-records, <-records
+	assert.Equal(t, resp.ID+":primary", primary.ID)
+	assert.Equal(t, "shadow:primary", primary.Source)
+	assert.Equal(t, resp.ID+":candidate", candidate.ID)
+	assert.Equal(t, "candidate/model", candidate.Request.Model)
+
+	stats := shadow.Stats()
+	assert.Equal(t, 1, stats.Mirrored)
+	assert.Zero(t, stats.Failed)
+	assert.Greater(t, stats.ShadowTokens, 0)
+}
+
+func TestShadowClientFailuresDontAffectPrimary(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply("primary"), openroutertest.ErrorReply(500, "candidate down"))
+
+	shadow := pkg.NewShadowClient(srv.Client(), pkg.ShadowOptions{Model: "candidate/model", SampleRate: 1})
+	resp, err := shadow.CreateChatCompletion(context.Background(), hiRequest)
+	require.NoError(t, err)
+	text, _ := resp.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "primary", text)
+	shadow.Wait()
+
+	stats := shadow.Stats()
+	assert.Equal(t, 1, stats.Mirrored)
+	assert.Equal(t, 1, stats.Failed)
+}
+
+func TestShadowClientStreamsAndSampling(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetChatHandler(byModel)
+	ctx := context.Background()
+
+	never := pkg.NewShadowClient(srv.Client(), pkg.ShadowOptions{Model: "candidate/model"})
+	_, err := never.CreateChatCompletion(ctx, hiRequest)
+	require.NoError(t, err)
+	never.Wait()
+	assert.Zero(t, never.Stats().Mirrored)
+	assert.Len(t, srv.Requests(), 1)
+
+	var result pkg.ShadowResult
+	shadow := pkg.NewShadowClient(srv.Client(), pkg.ShadowOptions{
+		Model:      "candidate/model",
+		SampleRate: 1,
+		OnResult:   func(r pkg.ShadowResult) { result = r },
+	})
+	stream, err := shadow.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	for {
+		_, err := stream.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	shadow.Wait()
+
+	require.NotNil(t, result.Primary)
+	text, _ := result.Primary.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "answer from m", text)
+	text, _ = result.Shadow.Choices[0].Message.GetTextContent()
+	assert.Equal(t, "answer from candidate/model", text)
+}
@@ -0,0 +1,237 @@
+// Package sink delivers generation results to files, channels, HTTP endpoints and object
+// storage through one interface, used by BatchProcessor and the queue package.
+//
+// Delivery is at least once: a producer only considers a record delivered once Write
+// returns nil, and writes it again after a failure or crash. Receivers should
+// deduplicate records by ID.
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// Record is one delivered result
+type Record struct {
+	// ID identifies the result, and stays the same when it is delivered again
+	ID string `json:"id"`
+
+	// Source names the producer, e.g. "batch" or a scheduled job's name
+	Source string `json:"source,omitempty"`
+
+	Request  *models.ChatCompletionRequest  `json:"request,omitempty"`
+	Response *models.ChatCompletionResponse `json:"response,omitempty"`
+	Error    string                         `json:"error,omitempty"`
+
+	Time time.Time `json:"time"`
+}
+
+// Sink receives records. Write returns nil only once the record is durably delivered.
+// Implementations must be safe for concurrent use.
+type Sink interface {
+	Write(ctx context.Context, record Record) error
+	Close() error
+}
+
+// Func adapts a function to a Sink with a no-op Close
+type Func func(ctx context.Context, record Record) error
+
+// Write calls f
+func (f Func) Write(ctx context.Context, record Record) error {
+	return f(ctx, record)
+}
+
+// Close does nothing
+func (f Func) Close() error {
+	return nil
+}
+
+// FileSink appends records as JSON lines to a file
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens path for appending, creating it if needed
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sink file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Write appends the record and syncs the file
+func (s *FileSink) Write(ctx context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return s.file.Sync()
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// ChannelSink sends records to a channel, e.g. to hand them to another goroutine
+type ChannelSink struct {
+	ch chan<- Record
+}
+
+// NewChannelSink creates a sink sending to ch. Write blocks until the record is received
+// or ctx is done. The channel is not closed by Close.
+func NewChannelSink(ch chan<- Record) *ChannelSink {
+	return &ChannelSink{ch: ch}
+}
+
+// Write sends the record
+func (s *ChannelSink) Write(ctx context.Context, record Record) error {
+	select {
+	case s.ch <- record:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close does nothing
+func (s *ChannelSink) Close() error {
+	return nil
+}
+
+// HTTPSink posts each record as JSON to a URL. The record ID is sent in the
+// Idempotency-Key header so the receiver can discard redeliveries.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink creates a sink posting to url with client, or http.DefaultClient if nil
+func NewHTTPSink(url string, client *http.Client) *HTTPSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPSink{url: url, client: client}
+}
+
+// Write posts the record and succeeds on a 2xx status
+func (s *HTTPSink) Write(ctx context.Context, record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", record.ID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post record: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sink endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close does nothing
+func (s *HTTPSink) Close() error {
+	return nil
+}
+
+// ObjectStore puts objects in S3-compatible storage. Adapt the AWS SDK, MinIO or a
+// similar client to it.
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+// ObjectSink writes each record to an object named "<prefix><id>.json". Redeliveries
+// overwrite the same object, so the bucket holds each result once.
+type ObjectSink struct {
+	store  ObjectStore
+	prefix string
+}
+
+// NewObjectSink creates a sink writing objects under prefix, e.g. "results/2026-10-15/"
+func NewObjectSink(store ObjectStore, prefix string) *ObjectSink {
+	return &ObjectSink{store: store, prefix: prefix}
+}
+
+// Write puts the record
+func (s *ObjectSink) Write(ctx context.Context, record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	// Keep IDs from creating nested keys
+	key := s.prefix + strings.ReplaceAll(record.ID, "/", "_") + ".json"
+	if err := s.store.PutObject(ctx, key, body, "application/json"); err != nil {
+		return fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+	return nil
+}
+
+// Close does nothing
+func (s *ObjectSink) Close() error {
+	return nil
+}
+
+// retrySink retries failed writes
+type retrySink struct {
+	Sink
+	attempts int
+	backoff  time.Duration
+}
+
+// WithRetry wraps s to try each write up to attempts times, doubling the wait between
+// attempts from backoff
+func WithRetry(s Sink, attempts int, backoff time.Duration) Sink {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return &retrySink{Sink: s, attempts: attempts, backoff: backoff}
+}
+
+// Write writes the record, retrying on failure
+func (s *retrySink) Write(ctx context.Context, record Record) error {
+	wait := s.backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = s.Sink.Write(ctx, record); err == nil {
+			return nil
+		}
+		if attempt == s.attempts {
+			return fmt.Errorf("sink write failed after %d attempts: %w", attempt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
@@ -0,0 +1,125 @@
+package sink_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/sink"
+)
+
+type memoryObjects struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *memoryObjects) PutObject(ctx context.Context, key string, body []byte, contentType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = body
+	return nil
+}
+
+func TestSinks(t *testing.T) {
+	ctx := context.Background()
+	record := sink.Record{ID: "job-1", Source: "test", Error: "boom", Time: time.Now()}
+
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	file, err := sink.NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, file.Write(ctx, record))
+	require.NoError(t, file.Write(ctx, record))
+	require.NoError(t, file.Close())
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	lines := 0
+	for scanner := bufio.NewScanner(f); scanner.Scan(); lines++ {
+		var got sink.Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &got))
+		assert.Equal(t, "job-1", got.ID)
+	}
+	assert.Equal(t, 2, lines)
+
+	var key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.Header.Get("Idempotency-Key")
+	}))
+	defer srv.Close()
+	require.NoError(t, sink.NewHTTPSink(srv.URL, nil).Write(ctx, record))
+	assert.Equal(t, "job-1", key)
+
+	objects := &memoryObjects{objects: make(map[string][]byte)}
+	require.NoError(t, sink.NewObjectSink(objects, "results/").Write(ctx, record))
+	assert.Contains(t, objects.objects, "results/job-1.json")
+
+	ch := make(chan sink.Record, 1)
+	require.NoError(t, sink.NewChannelSink(ch).Write(ctx, record))
+	assert.Equal(t, record.ID, (<-ch).ID)
+}
+
+func TestWithRetry(t *testing.T) {
+	calls := 0
+	flaky := sink.Func(func(ctx context.Context, record sink.Record) error {
+		calls++
+		if calls < 3 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+	require.NoError(t, sink.WithRetry(flaky, 3, time.Millisecond).Write(ctx, sink.Record{ID: "a"}))
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err := sink.WithRetry(flaky, 2, time.Millisecond).Write(ctx, sink.Record{ID: "a"})
+	assert.ErrorContains(t, err, "after 2 attempts")
+}
+
+func TestBatchProcessorToSink(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetChatHandler(openroutertest.EchoHandler)
+
+	client := pkg.NewConcurrentClient("sk-or-test", 2, pkg.WithBaseURL(srv.URL))
+	requests := []models.ChatCompletionRequest{
+		models.NewChatRequest("m", models.WithUserMessage("a")),
+		models.NewChatRequest("m", models.WithUserMessage("b")),
+		models.NewChatRequest("m", models.WithUserMessage("c")),
+	}
+
+	ch := make(chan sink.Record, 2*len(requests))
+	processor := pkg.NewBatchProcessor(client, 2)
+	require.NoError(t, processor.ProcessBatchToSink(context.Background(), requests, sink.NewChannelSink(ch)))
+	require.NoError(t, processor.ProcessBatchToSink(context.Background(), requests, sink.NewChannelSink(ch)))
+	close(ch)
+
+	ids := map[string]bool{}
+	runs := map[string]bool{}
+	for record := range ch {
+		ids[record.ID] = true
+		assert.Equal(t, "batch", record.Source)
+		assert.NotNil(t, record.Response)
+		run := strings.TrimPrefix(record.ID[:strings.LastIndex(record.ID, "-")], "batch-")
+		runs[run] = true
+		assert.Contains(t, []string{"batch-" + run + "-0", "batch-" + run + "-1", "batch-" + run + "-2"}, record.ID)
+	}
+	assert.Len(t, ids, 2*len(requests), "runs don't reuse record IDs")
+	assert.Len(t, runs, 2)
+}
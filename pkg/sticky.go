@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// StickySession pins the requests of a conversation to the provider that served its first
+// successful response, keeping latency and prompt caching consistent across the session.
+// If a pinned request fails, the session unpins so the next request is routed freely and
+// pins again to whichever provider serves it. It is safe for concurrent use.
+type StickySession struct {
+	client *Client
+
+	mu       sync.Mutex
+	provider string
+}
+
+// NewStickySession creates a session that pins its requests to one provider
+func NewStickySession(client *Client) *StickySession {
+	return &StickySession{client: client}
+}
+
+// CreateChatCompletion creates a chat completion, routed to the pinned provider if any
+func (s *StickySession) CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	provider := s.Provider()
+	if provider != "" {
+		req.Provider = pinProvider(req.Provider, provider)
+	}
+
+	resp, err := s.client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		if provider != "" {
+			s.unpinFrom(provider)
+		}
+		return nil, err
+	}
+
+	if provider == "" {
+		s.pin(ctx, resp)
+	}
+	return resp, nil
+}
+
+// Provider returns the pinned provider, or "" if the session isn't pinned
+func (s *StickySession) Provider() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.provider
+}
+
+// Pin pins the session to a provider
+func (s *StickySession) Pin(provider string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.provider = provider
+}
+
+// Unpin clears the pinned provider
+func (s *StickySession) Unpin() {
+	s.Pin("")
+}
+
+// pin pins the session to the provider that served resp, looking it up with GetGeneration
+// if the response doesn't name it. The session stays unpinned if the lookup fails.
+func (s *StickySession) pin(ctx context.Context, resp *models.ChatCompletionResponse) {
+	provider := resp.Provider
+	if provider == "" && resp.ID != "" {
+		if gen, err := s.client.GetGeneration(ctx, resp.ID); err == nil {
+			provider = gen.Data.Provider
+		}
+	}
+	if provider == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.provider == "" {
+		s.provider = provider
+	}
+}
+
+// unpinFrom unpins the session unless a concurrent request already moved it elsewhere
+func (s *StickySession) unpinFrom(provider string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.provider == provider {
+		s.provider = ""
+	}
+}
+
+// pinProvider returns a copy of prefs that routes only to provider
+func pinProvider(prefs *models.ProviderPreferences, provider string) *models.ProviderPreferences {
+	pinned := models.ProviderPreferences{}
+	if prefs != nil {
+		pinned = *prefs
+	}
+	allowFallbacks := false
+	pinned.Order = []string{provider}
+	pinned.AllowFallbacks = &allowFallbacks
+	return &pinned
+}
@@ -0,0 +1,43 @@
+package pkg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestStickySessionPinsAndUnpins(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(
+		openroutertest.Reply{Response: openroutertest.NewTextResponse("first")},
+		openroutertest.ErrorReply(502, "provider down"),
+	)
+
+	session := pkg.NewStickySession(srv.Client())
+	ctx := context.Background()
+	req := models.NewChatRequest("m", models.WithUserMessage("hi"))
+
+	_, err := session.CreateChatCompletion(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "OpenRouterTest", session.Provider())
+
+	// The next request is routed to the pinned provider only
+	_, err = session.CreateChatCompletion(ctx, req)
+	require.Error(t, err)
+	sent, err := srv.Requests()[len(srv.Requests())-1].ChatRequest()
+	require.NoError(t, err)
+	require.NotNil(t, sent.Provider)
+	assert.Equal(t, []string{"OpenRouterTest"}, sent.Provider.Order)
+	assert.False(t, *sent.Provider.AllowFallbacks)
+
+	// The failure unpinned the session
+	assert.Empty(t, session.Provider())
+	assert.Nil(t, req.Provider, "the caller's request must not be modified")
+}
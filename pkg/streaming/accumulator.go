@@ -0,0 +1,187 @@
+package streaming
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// Accumulator rebuilds a complete response from the chunks of a stream.
+// Content and reasoning deltas are concatenated, and tool call fragments are
+// merged by index so arguments split across chunks form one call.
+type Accumulator struct {
+	id                string
+	model             string
+	created           int64
+	provider          string
+	systemFingerprint string
+
+	content      strings.Builder
+	reasoning    strings.Builder
+	toolCalls    []models.ToolCall
+	annotations  []models.Annotation
+	finishReason string
+	nativeReason string
+	usage        *models.Usage
+}
+
+// NewAccumulator creates an empty accumulator
+func NewAccumulator() *Accumulator {
+	return &Accumulator{}
+}
+
+// Add adds a chunk to the accumulated response. Only the first choice is accumulated.
+func (a *Accumulator) Add(chunk *models.ChatCompletionResponse) {
+	if chunk == nil {
+		return
+	}
+
+	if a.id == "" {
+		a.id = chunk.ID
+		a.model = chunk.Model
+		a.created = chunk.Created
+	}
+	if chunk.Provider != "" {
+		a.provider = chunk.Provider
+	}
+	if chunk.SystemFingerprint != "" {
+		a.systemFingerprint = chunk.SystemFingerprint
+	}
+	if chunk.Usage != nil {
+		a.usage = chunk.Usage
+	}
+
+	if len(chunk.Choices) == 0 {
+		return
+	}
+	choice := chunk.Choices[0]
+	if choice.FinishReason != "" {
+		a.finishReason = choice.FinishReason
+	}
+	if choice.NativeFinishReason != "" {
+		a.nativeReason = choice.NativeFinishReason
+	}
+
+	delta := choice.Delta
+	if delta == nil {
+		return
+	}
+
+	if content, err := delta.GetTextContent(); err == nil {
+		a.content.WriteString(content)
+	}
+	a.reasoning.WriteString(delta.Reasoning)
+	a.annotations = append(a.annotations, delta.Annotations...)
+
+	for _, call := range delta.ToolCalls {
+		a.addToolCall(call)
+	}
+}
+
+// addToolCall merges a tool call fragment into the accumulated calls
+func (a *Accumulator) addToolCall(fragment models.ToolCall) {
+	index := -1
+	switch {
+	case fragment.Index != nil:
+		for i := range a.toolCalls {
+			if a.toolCalls[i].Index != nil && *a.toolCalls[i].Index == *fragment.Index {
+				index = i
+				break
+			}
+		}
+	case fragment.ID == "" && len(a.toolCalls) > 0:
+		// Providers that omit indexes only send the ID with the first fragment
+		index = len(a.toolCalls) - 1
+	}
+
+	if index == -1 {
+		a.toolCalls = append(a.toolCalls, fragment)
+		return
+	}
+
+	call := &a.toolCalls[index]
+	if call.ID == "" {
+		call.ID = fragment.ID
+	}
+	if call.Type == "" {
+		call.Type = fragment.Type
+	}
+	call.Function.Name += fragment.Function.Name
+	call.Function.Arguments += fragment.Function.Arguments
+}
+
+// Content returns the text accumulated so far
+func (a *Accumulator) Content() string {
+	return a.content.String()
+}
+
+// Reasoning returns the reasoning accumulated so far
+func (a *Accumulator) Reasoning() string {
+	return a.reasoning.String()
+}
+
+// FinishReason returns the finish reason, if one has been received
+func (a *Accumulator) FinishReason() string {
+	return a.finishReason
+}
+
+// NativeFinishReason returns the provider's raw finish reason, if one has been received
+func (a *Accumulator) NativeFinishReason() string {
+	return a.nativeReason
+}
+
+// Provider returns the name of the provider serving the stream
+func (a *Accumulator) Provider() string {
+	return a.provider
+}
+
+// Usage returns the token usage, which providers send in the final chunk
+func (a *Accumulator) Usage() *models.Usage {
+	return a.usage
+}
+
+// Message returns the accumulated assistant message
+func (a *Accumulator) Message() models.Message {
+	content, _ := json.Marshal(a.content.String())
+	msg := models.Message{
+		Role:        models.RoleAssistant,
+		Content:     content,
+		Reasoning:   a.reasoning.String(),
+		Annotations: a.annotations,
+	}
+
+	if len(a.toolCalls) > 0 {
+		msg.ToolCalls = make([]models.ToolCall, len(a.toolCalls))
+		for i, call := range a.toolCalls {
+			// Indexes only identify fragments within a stream
+			call.Index = nil
+			if call.Type == "" {
+				call.Type = "function"
+			}
+			msg.ToolCalls[i] = call
+		}
+	}
+
+	return msg
+}
+
+// Response returns the accumulated response in the shape of a non-streaming response
+func (a *Accumulator) Response() *models.ChatCompletionResponse {
+	msg := a.Message()
+	return &models.ChatCompletionResponse{
+		ID:                a.id,
+		Object:            "chat.completion",
+		Created:           a.created,
+		Model:             a.model,
+		Provider:          a.provider,
+		SystemFingerprint: a.systemFingerprint,
+		Choices: []models.Choice{{
+			Index:              0,
+			Message:            &msg,
+			FinishReason:       a.finishReason,
+			NativeFinishReason: a.nativeReason,
+		}},
+		Usage: a.usage,
+	}
+}
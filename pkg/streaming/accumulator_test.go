@@ -0,0 +1,66 @@
+package streaming
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+func deltaChunk(delta models.Message) *models.ChatCompletionResponse {
+	return &models.ChatCompletionResponse{
+		ID:      "gen-1",
+		Model:   "openai/gpt-4o",
+		Choices: []models.Choice{{Delta: &delta}},
+	}
+}
+
+func TestAccumulator(t *testing.T) {
+	zero, one := 0, 1
+	chunks := []*models.ChatCompletionResponse{
+		deltaChunk(models.Message{Reasoning: "Need the weather. "}),
+		deltaChunk(models.NewTextMessage(models.RoleAssistant, "Checking ")),
+		deltaChunk(models.NewTextMessage(models.RoleAssistant, "now.")),
+		deltaChunk(models.Message{ToolCalls: []models.ToolCall{{Index: &zero, ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "get_weather", Arguments: `{"city":`}}}}),
+		deltaChunk(models.Message{ToolCalls: []models.ToolCall{{Index: &one, ID: "call_2", Function: models.FunctionCall{Name: "get_time", Arguments: `{}`}}}}),
+		deltaChunk(models.Message{ToolCalls: []models.ToolCall{{Index: &zero, Function: models.FunctionCall{Arguments: `"Paris"}`}}}}),
+		{Choices: []models.Choice{{Delta: &models.Message{}, FinishReason: "tool_calls"}}},
+		{Usage: &models.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}},
+	}
+
+	accumulator := NewAccumulator()
+	for _, chunk := range chunks {
+		accumulator.Add(chunk)
+	}
+
+	resp := accumulator.Response()
+	if resp.ID != "gen-1" || resp.Model != "openai/gpt-4o" {
+		t.Errorf("unexpected response metadata: %+v", resp)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 15 {
+		t.Errorf("expected usage from the final chunk, got %+v", resp.Usage)
+	}
+
+	choice := resp.Choices[0]
+	if choice.FinishReason != "tool_calls" {
+		t.Errorf("expected finish reason tool_calls, got %q", choice.FinishReason)
+	}
+
+	msg := choice.Message
+	if text, _ := msg.GetTextContent(); text != "Checking now." {
+		t.Errorf("unexpected content %q", text)
+	}
+	if msg.Reasoning != "Need the weather. " {
+		t.Errorf("unexpected reasoning %q", msg.Reasoning)
+	}
+
+	want := []models.ToolCall{
+		{ID: "call_1", Type: "function", Function: models.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		{ID: "call_2", Type: "function", Function: models.FunctionCall{Name: "get_time", Arguments: `{}`}},
+	}
+	got, _ := json.Marshal(msg.ToolCalls)
+	expected, _ := json.Marshal(want)
+	if string(got) != string(expected) {
+		t.Errorf("unexpected tool calls:\nwant %s\ngot  %s", expected, got)
+	}
+}
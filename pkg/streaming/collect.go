@@ -0,0 +1,27 @@
+package streaming
+
+import (
+	"errors"
+	"io"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// CollectStream reads a stream to the end and returns the complete response, including
+// the usage from the final usage chunk if the provider sent one. The stream is closed.
+// If the stream fails mid-generation, the error is a *StreamInterruptedError carrying
+// the partial response.
+func CollectStream(stream *ChatCompletionStreamReader) (*models.ChatCompletionResponse, error) {
+	defer stream.Close()
+
+	stream.KeepPartial()
+	for {
+		_, err := stream.Read()
+		if errors.Is(err, io.EOF) {
+			return stream.partial.Response(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package streaming
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCollectStream(t *testing.T) {
+	body := strings.Join([]string{
+		`data: {"id":"gen-1","model":"openai/gpt-4o","choices":[{"delta":{"role":"assistant","content":"Hello"}}]}`,
+		`data: {"id":"gen-1","model":"openai/gpt-4o","choices":[{"delta":{"content":" world"},"finish_reason":"stop"}]}`,
+		`data: {"id":"gen-1","model":"openai/gpt-4o","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5,"cost":0.0001}}`,
+		`data: [DONE]`,
+		``,
+	}, "\n\n")
+
+	resp, err := CollectStream(NewChatCompletionStreamReader(io.NopCloser(strings.NewReader(body))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := resp.Choices[0].Message.GetTextContent(); text != "Hello world" {
+		t.Errorf("content = %q", text)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 5 || resp.Usage.Cost != 0.0001 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+}
+
+func TestCollectStreamInterrupted(t *testing.T) {
+	body := strings.Join([]string{
+		`data: {"id":"gen-1","choices":[{"delta":{"role":"assistant","content":"Once upon"}}]}`,
+		`data: {"id":"gen-1","choices":[{"delta":{"content":" a time"}}]}`,
+		`data: {"error":{"code":502,"message":"provider disconnected"}}`,
+		``,
+	}, "\n\n")
+
+	resp, err := CollectStream(NewChatCompletionStreamReader(io.NopCloser(strings.NewReader(body))))
+	if resp != nil {
+		t.Errorf("resp = %+v, want nil", resp)
+	}
+
+	var interrupted *StreamInterruptedError
+	if !errors.As(err, &interrupted) {
+		t.Fatalf("err = %v, want a *StreamInterruptedError", err)
+	}
+	if text, _ := interrupted.Partial.Choices[0].Message.GetTextContent(); text != "Once upon a time" {
+		t.Errorf("partial content = %q", text)
+	}
+	if !strings.Contains(err.Error(), "provider disconnected") {
+		t.Errorf("err = %v, want the underlying error", err)
+	}
+}
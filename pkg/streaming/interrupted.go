@@ -0,0 +1,30 @@
+package streaming
+
+import (
+	"fmt"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// StreamInterruptedError is returned when a stream fails mid-generation, by CollectStream
+// or by Read after KeepPartial. Partial holds the text, tool calls, and usage received
+// before the failure, so the caller can keep the output, retry, or ask the model to
+// continue from it.
+type StreamInterruptedError struct {
+	Err     error
+	Partial *models.ChatCompletionResponse
+}
+
+// Error returns the underlying error with the amount of output received
+func (e *StreamInterruptedError) Error() string {
+	content := ""
+	if len(e.Partial.Choices) > 0 && e.Partial.Choices[0].Message != nil {
+		content, _ = e.Partial.Choices[0].Message.GetTextContent()
+	}
+	return fmt.Sprintf("stream interrupted after %d bytes of content: %v", len(content), e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *StreamInterruptedError) Unwrap() error {
+	return e.Err
+}
@@ -7,8 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"strings"
+	"sync"
+	"time"
 
+	"github.com/rizome-dev/go-openrouter/pkg/codec"
 	"github.com/rizome-dev/go-openrouter/pkg/models"
 )
 
@@ -18,80 +20,182 @@ var (
 
 	// ErrInvalidSSE is returned when SSE data is malformed
 	ErrInvalidSSE = errors.New("invalid SSE format")
+
+	// ErrLineTooLong is returned when an SSE field line exceeds MaxLineSize
+	ErrLineTooLong = errors.New("SSE line too long")
+)
+
+// MaxLineSize is the longest SSE field line the parser accepts. Longer comment
+// lines are skipped without being buffered.
+const MaxLineSize = 16 * 1024 * 1024
+
+// sseReaderSize is the buffer size of pooled stream readers
+const sseReaderSize = 32 * 1024
+
+// readerPool reuses buffered readers across streams to avoid a large allocation per stream
+var readerPool = sync.Pool{
+	New: func() interface{} {
+		return bufio.NewReaderSize(nil, sseReaderSize)
+	},
+}
+
+var (
+	doneMarker    = []byte("[DONE]")
+	commentPrefix = []byte(": ")
 )
 
-// SSEParser parses Server-Sent Events
+// SSEParser parses Server-Sent Events.
+//
+// The parser reuses its line and data buffers between events, so parsing a
+// stream performs no per-event allocations beyond those made by the caller.
 type SSEParser struct {
 	reader *bufio.Reader
 	closed bool
+
+	// eof is set when the stream ended right after the last returned event
+	eof bool
+
+	// line accumulates lines longer than the reader's buffer
+	line []byte
+
+	// Fields of the event currently being parsed
+	data  []byte
+	event string
+	id    string
 }
 
 // NewSSEParser creates a new SSE parser
 func NewSSEParser(reader io.Reader) *SSEParser {
+	br := readerPool.Get().(*bufio.Reader)
+	br.Reset(reader)
 	return &SSEParser{
-		reader: bufio.NewReader(reader),
+		reader: br,
 		closed: false,
 	}
 }
 
 // ParseNext parses the next SSE event
 func (p *SSEParser) ParseNext() (*SSEEvent, error) {
+	data, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSEEvent{
+		Event: p.event,
+		Data:  string(data),
+		ID:    p.id,
+	}, nil
+}
+
+// next parses the next event and returns its data. The returned slice is only
+// valid until the following call to next.
+func (p *SSEParser) next() ([]byte, error) {
+	if p.eof {
+		p.eof = false
+		return nil, io.EOF
+	}
 	if p.closed {
 		return nil, ErrStreamClosed
 	}
 
-	var event SSEEvent
-	var dataBuffer bytes.Buffer
+	p.data = p.data[:0]
+	p.event = ""
+	p.id = ""
 
 	for {
-		line, err := p.reader.ReadString('\n')
+		line, err := p.readLine()
+
+		// A final line without a trailing newline is still processed
+		if len(line) > 0 {
+			line = bytes.TrimSpace(line)
+
+			// Empty line signals end of event
+			if len(line) == 0 && len(p.data) > 0 {
+				return p.data, nil
+			}
+
+			// Skip empty lines and comments
+			if len(line) > 0 && line[0] != ':' {
+				p.parseField(line)
+			}
+		}
+
 		if err != nil {
+			p.close()
 			if err == io.EOF {
-				p.closed = true
-				if dataBuffer.Len() > 0 {
-					event.Data = dataBuffer.String()
-					return &event, nil
+				if len(p.data) > 0 {
+					p.eof = true
+					return p.data, nil
 				}
 				return nil, io.EOF
 			}
 			return nil, fmt.Errorf("error reading stream: %w", err)
 		}
+	}
+}
 
-		line = strings.TrimSpace(line)
+// readLine reads a line without copying it unless it exceeds the reader's buffer
+func (p *SSEParser) readLine() ([]byte, error) {
+	line, err := p.reader.ReadSlice('\n')
+	if err != bufio.ErrBufferFull {
+		return line, err
+	}
 
-		// Empty line signals end of event
-		if line == "" && dataBuffer.Len() > 0 {
-			event.Data = dataBuffer.String()
-			return &event, nil
+	// Oversized comments, such as keep-alive padding, are discarded as they are read
+	if trimmed := bytes.TrimLeft(line, " \t"); len(trimmed) > 0 && trimmed[0] == ':' {
+		for err == bufio.ErrBufferFull {
+			_, err = p.reader.ReadSlice('\n')
 		}
+		return nil, err
+	}
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, ":") {
-			continue
+	p.line = append(p.line[:0], line...)
+	for {
+		line, err = p.reader.ReadSlice('\n')
+		if len(p.line)+len(line) > MaxLineSize {
+			return nil, ErrLineTooLong
 		}
-
-		// Parse field
-		colonIndex := strings.Index(line, ":")
-		if colonIndex == -1 {
-			continue
+		p.line = append(p.line, line...)
+		if err != bufio.ErrBufferFull {
+			return p.line, err
 		}
+	}
+}
 
-		field := line[:colonIndex]
-		value := strings.TrimSpace(line[colonIndex+1:])
+// parseField parses a single "field: value" line into the current event
+func (p *SSEParser) parseField(line []byte) {
+	colonIndex := bytes.IndexByte(line, ':')
+	if colonIndex == -1 {
+		return
+	}
 
-		switch field {
-		case "event":
-			event.Event = value
-		case "data":
-			if dataBuffer.Len() > 0 {
-				dataBuffer.WriteString("\n")
-			}
-			dataBuffer.WriteString(value)
-		case "id":
-			event.ID = value
-		case "retry":
-			// Ignore retry field for now
+	field := line[:colonIndex]
+	value := bytes.TrimSpace(line[colonIndex+1:])
+
+	switch string(field) {
+	case "event":
+		p.event = string(value)
+	case "data":
+		if len(p.data) > 0 {
+			p.data = append(p.data, '\n')
 		}
+		p.data = append(p.data, value...)
+	case "id":
+		p.id = string(value)
+	case "retry":
+		// Ignore retry field for now
+	}
+}
+
+// close marks the parser as closed and returns its reader to the pool.
+// It is only called from the goroutine that is parsing.
+func (p *SSEParser) close() {
+	p.closed = true
+	if p.reader != nil {
+		p.reader.Reset(nil)
+		readerPool.Put(p.reader)
+		p.reader = nil
 	}
 }
 
@@ -102,56 +206,129 @@ type SSEEvent struct {
 	ID    string
 }
 
+// streamChunk decodes a chunk and a possible top-level error in a single pass
+type streamChunk struct {
+	models.ChatCompletionResponse
+	Error *models.ChoiceError `json:"error,omitempty"`
+}
+
 // ChatCompletionStreamReader reads streaming chat completions
 type ChatCompletionStreamReader struct {
 	parser *SSEParser
 	closer io.Closer
+	codec  codec.Codec
+
+	// Timing and token counts for Summary
+	stats streamStats
+
+	// Output so far, returned in a StreamInterruptedError if the stream fails. It is nil
+	// until KeepPartial is called, so plain reads don't pay for accumulating.
+	partial *Accumulator
+
+	// Conditions that end the stream early, and whether one has
+	stopWhen []StopCondition
+	stopped  bool
+
+	// Callbacks run for every chunk read
+	onChunk []func(index int, chunk *models.ChatCompletionResponse)
 }
 
 // NewChatCompletionStreamReader creates a new stream reader
 func NewChatCompletionStreamReader(reader io.ReadCloser) *ChatCompletionStreamReader {
+	return NewChatCompletionStreamReaderWithCodec(reader, codec.Std)
+}
+
+// NewChatCompletionStreamReaderWithCodec creates a new stream reader that decodes chunks with the given codec
+func NewChatCompletionStreamReaderWithCodec(reader io.ReadCloser, jsonCodec codec.Codec) *ChatCompletionStreamReader {
+	if jsonCodec == nil {
+		jsonCodec = codec.Std
+	}
 	return &ChatCompletionStreamReader{
 		parser: NewSSEParser(reader),
 		closer: reader,
+		codec:  jsonCodec,
+		stats:  streamStats{start: time.Now()},
 	}
 }
 
-// Read reads the next chunk from the stream
+// Read reads the next chunk from the stream. It returns io.EOF at the end of the stream.
+// After KeepPartial, if the stream fails after chunks were received, the error is a
+// *StreamInterruptedError carrying the partial response.
 func (r *ChatCompletionStreamReader) Read() (*models.ChatCompletionResponse, error) {
-	event, err := r.parser.ParseNext()
+	if r.stopped {
+		r.stats.finish()
+		return nil, io.EOF
+	}
+
+	chunk, err := r.read()
 	if err != nil {
+		r.stats.finish()
+		if err != io.EOF && r.partial != nil && r.stats.chunks > 0 {
+			err = &StreamInterruptedError{Err: err, Partial: r.partial.Response()}
+		}
 		return nil, err
 	}
-
-	// Skip comments
-	if strings.HasPrefix(event.Data, ": ") {
-		return r.Read() // Recursively read next event
+	r.stats.add(chunk)
+	if r.partial != nil {
+		r.partial.Add(chunk)
 	}
-
-	// Check for end of stream
-	if event.Data == "[DONE]" {
-		return nil, io.EOF
+	for _, fn := range r.onChunk {
+		fn(r.stats.chunks-1, chunk)
 	}
+	r.checkStop()
+	return chunk, nil
+}
 
-	// Check for top-level error first
-	var errorCheck struct {
-		Error *models.ChoiceError `json:"error,omitempty"`
-	}
-	if err := json.Unmarshal([]byte(event.Data), &errorCheck); err == nil && errorCheck.Error != nil {
-		return nil, fmt.Errorf("openrouter error %d: %s", errorCheck.Error.Code, errorCheck.Error.Message)
+// KeepPartial accumulates the output as it is read, so that a stream failing
+// mid-generation returns a *StreamInterruptedError carrying it. CollectStream and StopWhen
+// enable it. Call it before the first Read; chunks read earlier are not included.
+func (r *ChatCompletionStreamReader) KeepPartial() {
+	if r.partial == nil {
+		r.partial = NewAccumulator()
 	}
+}
 
-	// Parse JSON response
-	var response models.ChatCompletionResponse
-	if err := json.Unmarshal([]byte(event.Data), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
+// OnChunk registers fn to be called with every chunk as it is read, before Read returns
+// it, along with the chunk's zero-based index in the stream
+func (r *ChatCompletionStreamReader) OnChunk(fn func(index int, chunk *models.ChatCompletionResponse)) {
+	r.onChunk = append(r.onChunk, fn)
+}
 
-	return &response, nil
+// read reads the next chunk without updating the stream statistics
+func (r *ChatCompletionStreamReader) read() (*models.ChatCompletionResponse, error) {
+	for {
+		data, err := r.parser.next()
+		if err != nil {
+			return nil, err
+		}
+
+		// Skip comments
+		if bytes.HasPrefix(data, commentPrefix) {
+			continue
+		}
+
+		// Check for end of stream
+		if bytes.Equal(data, doneMarker) {
+			return nil, io.EOF
+		}
+
+		var chunk streamChunk
+		if err := r.codec.Unmarshal(data, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		// Check for top-level error
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("openrouter error %d: %s", chunk.Error.Code, chunk.Error.Message)
+		}
+
+		return &chunk.ChatCompletionResponse, nil
+	}
 }
 
 // Close closes the stream
 func (r *ChatCompletionStreamReader) Close() error {
+	r.stats.finish()
 	if r.closer != nil {
 		return r.closer.Close()
 	}
@@ -174,28 +351,30 @@ func NewCompletionStreamReader(reader io.ReadCloser) *CompletionStreamReader {
 
 // Read reads the next chunk from the stream
 func (r *CompletionStreamReader) Read() (*CompletionResponse, error) {
-	event, err := r.parser.ParseNext()
-	if err != nil {
-		return nil, err
-	}
+	for {
+		data, err := r.parser.next()
+		if err != nil {
+			return nil, err
+		}
 
-	// Skip comments
-	if strings.HasPrefix(event.Data, ": ") {
-		return r.Read() // Recursively read next event
-	}
+		// Skip comments
+		if bytes.HasPrefix(data, commentPrefix) {
+			continue
+		}
 
-	// Check for end of stream
-	if event.Data == "[DONE]" {
-		return nil, io.EOF
-	}
+		// Check for end of stream
+		if bytes.Equal(data, doneMarker) {
+			return nil, io.EOF
+		}
 
-	// Parse JSON response
-	var response CompletionResponse
-	if err := json.Unmarshal([]byte(event.Data), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
+		// Parse JSON response
+		var response CompletionResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
 
-	return &response, nil
+		return &response, nil
+	}
 }
 
 // Close closes the stream
@@ -0,0 +1,299 @@
+package streaming
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rizome-dev/go-openrouter/pkg/codec"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// benchmarkCodecs lists the codecs compared by the streaming benchmarks.
+// Add third-party codecs here locally to compare them against encoding/json.
+var benchmarkCodecs = map[string]codec.Codec{
+	"std": codec.Std,
+}
+
+// sseStream builds an SSE payload with n content chunks followed by [DONE]
+func sseStream(n int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		chunk := models.ChatCompletionResponse{
+			ID:      "gen-1234567890",
+			Object:  "chat.completion.chunk",
+			Created: 1700000000,
+			Model:   "openai/gpt-4o",
+			Choices: []models.Choice{{
+				Index: 0,
+				Delta: &models.Message{
+					Role:    models.RoleAssistant,
+					Content: json.RawMessage(fmt.Sprintf("%q", fmt.Sprintf("token %d of the streamed response ", i))),
+				},
+			}},
+		}
+		data, _ := json.Marshal(chunk)
+		buf.WriteString("data: ")
+		buf.Write(data)
+		buf.WriteString("\n\n")
+		if i%50 == 0 {
+			buf.WriteString(": OPENROUTER PROCESSING\n\n")
+		}
+	}
+	buf.WriteString("data: [DONE]\n\n")
+	return buf.Bytes()
+}
+
+func TestSSEParser(t *testing.T) {
+	long := strings.Repeat("x", sseReaderSize*2+10)
+	input := "event: message\nid: 1\ndata: first\ndata: second\n\n" +
+		": keep-alive comment\n\n" +
+		"data: " + long + "\n\n" +
+		"data: trailing"
+
+	parser := NewSSEParser(strings.NewReader(input))
+
+	event, err := parser.ParseNext()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Event != "message" || event.ID != "1" || event.Data != "first\nsecond" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	event, err = parser.ParseNext()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Data != long {
+		t.Errorf("expected long line of %d bytes, got %d", len(long), len(event.Data))
+	}
+
+	event, err = parser.ParseNext()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Data != "trailing" {
+		t.Errorf("expected trailing data without newline, got %q", event.Data)
+	}
+
+	if _, err := parser.ParseNext(); err != io.EOF {
+		t.Errorf("expected EOF after trailing data, got %v", err)
+	}
+	if _, err := parser.ParseNext(); err != ErrStreamClosed {
+		t.Errorf("expected ErrStreamClosed, got %v", err)
+	}
+}
+
+func TestChatCompletionStreamReaderError(t *testing.T) {
+	input := "data: {\"error\":{\"code\":502,\"message\":\"provider down\"}}\n\n"
+	reader := NewChatCompletionStreamReader(io.NopCloser(strings.NewReader(input)))
+
+	_, err := reader.Read()
+	if err == nil || !strings.Contains(err.Error(), "provider down") {
+		t.Errorf("expected provider error, got %v", err)
+	}
+}
+
+func TestChatCompletionStreamReaderAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector drops pooled objects, adding allocations")
+	}
+	payload := sseStream(200)
+
+	// Parsing and decoding every chunk is the floor for reading a stream
+	decodeOnly := testing.AllocsPerRun(10, func() {
+		parser := NewSSEParser(bytes.NewReader(payload))
+		for {
+			data, err := parser.next()
+			if err != nil {
+				return
+			}
+			if !bytes.Equal(data, doneMarker) {
+				var chunk streamChunk
+				codec.Std.Unmarshal(data, &chunk)
+			}
+		}
+	})
+	read := testing.AllocsPerRun(10, func() {
+		reader := NewChatCompletionStreamReader(io.NopCloser(bytes.NewReader(payload)))
+		for {
+			if _, err := reader.Read(); err != nil {
+				return
+			}
+		}
+	})
+
+	// Statistics and a few per-stream allocations are all Read may add
+	if read > decodeOnly+5 {
+		t.Errorf("Read made %.0f allocations for 200 chunks, decoding alone %.0f", read, decodeOnly)
+	}
+}
+
+func BenchmarkChatCompletionStreamReader(b *testing.B) {
+	const chunks = 1000
+	payload := sseStream(chunks)
+
+	for name, c := range benchmarkCodecs {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(payload)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				reader := NewChatCompletionStreamReaderWithCodec(io.NopCloser(bytes.NewReader(payload)), c)
+				count := 0
+				for {
+					_, err := reader.Read()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						b.Fatal(err)
+					}
+					count++
+				}
+				if count != chunks {
+					b.Fatalf("expected %d chunks, got %d", chunks, count)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*chunks), "ns/chunk")
+		})
+	}
+}
+
+func BenchmarkSSEParser(b *testing.B) {
+	const chunks = 1000
+	payload := sseStream(chunks)
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parser := NewSSEParser(bytes.NewReader(payload))
+		for {
+			_, err := parser.ParseNext()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkStreamMemoryPerMB reports the bytes allocated to read one MB of stream,
+// including accumulating the content as CollectStream does
+func BenchmarkStreamMemoryPerMB(b *testing.B) {
+	payload := sseStream(1000)
+	var stats runtime.MemStats
+
+	b.SetBytes(int64(len(payload)))
+	runtime.ReadMemStats(&stats)
+	before := stats.TotalAlloc
+	for i := 0; i < b.N; i++ {
+		reader := NewChatCompletionStreamReader(io.NopCloser(bytes.NewReader(payload)))
+		if _, err := CollectStream(reader); err != nil {
+			b.Fatal(err)
+		}
+	}
+	runtime.ReadMemStats(&stats)
+
+	mb := float64(len(payload)) * float64(b.N) / (1 << 20)
+	b.ReportMetric(float64(stats.TotalAlloc-before)/mb, "alloc-B/MB")
+}
+
+func TestSSEParserLongComment(t *testing.T) {
+	comment := ": " + strings.Repeat("p", MaxLineSize+sseReaderSize) + "\n\n"
+	parser := NewSSEParser(strings.NewReader(comment + "data: after\n\n"))
+
+	event, err := parser.ParseNext()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Data != "after" {
+		t.Errorf("expected event after comment, got %q", event.Data)
+	}
+	if cap(parser.line) > 0 {
+		t.Errorf("expected comment not to be buffered, line buffer has capacity %d", cap(parser.line))
+	}
+}
+
+func TestSSEParserLineTooLong(t *testing.T) {
+	input := "data: " + strings.Repeat("x", MaxLineSize) + "\n\n"
+	parser := NewSSEParser(strings.NewReader(input))
+
+	if _, err := parser.ParseNext(); !errors.Is(err, ErrLineTooLong) {
+		t.Fatalf("expected ErrLineTooLong, got %v", err)
+	}
+	if _, err := parser.ParseNext(); err != ErrStreamClosed {
+		t.Errorf("expected closed parser after error, got %v", err)
+	}
+}
+
+func FuzzSSEParser(f *testing.F) {
+	f.Add([]byte("event: message\nid: 1\ndata: first\ndata: second\n\n"))
+	f.Add([]byte(": OPENROUTER PROCESSING\n\ndata: {\"id\":\"x\"}\n\ndata: [DONE]\n\n"))
+	f.Add([]byte("data:no-space\r\n\r\ndata: trailing"))
+	f.Add([]byte("data: \xff\xfe\n\n:\n\n\n\n"))
+	f.Add(sseStream(3))
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		parser := NewSSEParser(bytes.NewReader(input))
+
+		// Every event consumes at least one line, so parsing must end within that many events
+		limit := bytes.Count(input, []byte("\n")) + 2
+		for i := 0; ; i++ {
+			if i > limit {
+				t.Fatalf("parser produced more than %d events", limit)
+			}
+			event, err := parser.ParseNext()
+			if err != nil {
+				if err != io.EOF {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				break
+			}
+			if event.Data == "" {
+				t.Fatalf("parser returned an event without data")
+			}
+		}
+
+		if _, err := parser.ParseNext(); err != ErrStreamClosed {
+			t.Fatalf("expected ErrStreamClosed after EOF, got %v", err)
+		}
+	})
+}
+
+func FuzzChatCompletionStreamReader(f *testing.F) {
+	f.Add([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\ndata: [DONE]\n\n"))
+	f.Add([]byte("data: {\"error\":{\"code\":502,\"message\":\"down\"}}\n\n"))
+	f.Add([]byte("data: {\"choices\":[{\"delta\":{\"content\":[[[\"nested\"]]]}}]}\n\n"))
+	f.Add([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"\xff\"}}]}\n\n"))
+	f.Add([]byte("data: {\"choices\":null,\"usage\":{}}\n\n"))
+	f.Add(sseStream(3))
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		reader := NewChatCompletionStreamReader(io.NopCloser(bytes.NewReader(input)))
+		defer reader.Close()
+
+		for {
+			chunk, err := reader.Read()
+			if err != nil {
+				return
+			}
+			if chunk == nil {
+				t.Fatal("Read returned neither a chunk nor an error")
+			}
+			for _, choice := range chunk.Choices {
+				if choice.Delta != nil {
+					choice.Delta.GetTextContent()
+					choice.Delta.GetMultiContent()
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package streaming
+
+// raceEnabled is set when the race detector is on, which makes allocation counts unreliable
+const raceEnabled = true
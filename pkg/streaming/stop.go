@@ -0,0 +1,101 @@
+package streaming
+
+import (
+	"regexp"
+	"strings"
+)
+
+// StopCondition reports whether a stream should stop, given the text streamed so far.
+// It is called after every chunk with the growing text, so it may keep state to avoid
+// rescanning text it has already seen.
+type StopCondition func(text string) bool
+
+// StopWhen ends the stream as soon as any condition holds: the chunk that satisfied it is
+// returned, the connection is closed to cancel the generation, and the next Read returns
+// io.EOF. Use it to save tokens when the output is known to be complete, e.g. once a JSON
+// value has closed. Call it before the first Read.
+func (r *ChatCompletionStreamReader) StopWhen(conditions ...StopCondition) {
+	r.KeepPartial()
+	r.stopWhen = append(r.stopWhen, conditions...)
+}
+
+// Stopped reports whether a StopWhen condition ended the stream
+func (r *ChatCompletionStreamReader) Stopped() bool {
+	return r.stopped
+}
+
+// checkStop evaluates the stop conditions after a chunk, closing the stream if one holds
+func (r *ChatCompletionStreamReader) checkStop() {
+	if len(r.stopWhen) == 0 {
+		return
+	}
+
+	text := r.partial.Content()
+	for _, condition := range r.stopWhen {
+		if condition(text) {
+			r.stopped = true
+			if r.closer != nil {
+				r.closer.Close()
+			}
+			return
+		}
+	}
+}
+
+// StopOnSubstring stops once the text contains s
+func StopOnSubstring(s string) StopCondition {
+	return func(text string) bool {
+		return strings.Contains(text, s)
+	}
+}
+
+// StopOnRegexp stops once re matches the text
+func StopOnRegexp(re *regexp.Regexp) StopCondition {
+	return func(text string) bool {
+		return re.MatchString(text)
+	}
+}
+
+// StopOnBalancedJSON stops once the first JSON object or array in the text is closed,
+// ignoring brackets inside strings. Text before the opening bracket, such as a Markdown
+// code fence, is skipped.
+func StopOnBalancedJSON() StopCondition {
+	var (
+		pos      int
+		depth    int
+		started  bool
+		inString bool
+		escaped  bool
+	)
+	return func(text string) bool {
+		for ; pos < len(text); pos++ {
+			c := text[pos]
+			switch {
+			case !started:
+				if c == '{' || c == '[' {
+					started = true
+					depth = 1
+				}
+			case inString:
+				switch {
+				case escaped:
+					escaped = false
+				case c == '\\':
+					escaped = true
+				case c == '"':
+					inString = false
+				}
+			case c == '"':
+				inString = true
+			case c == '{' || c == '[':
+				depth++
+			case c == '}' || c == ']':
+				depth--
+				if depth == 0 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
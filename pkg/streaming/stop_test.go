@@ -0,0 +1,59 @@
+package streaming
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestStopWhenBalancedJSON(t *testing.T) {
+	var events []string
+	for _, delta := range []string{"```json\\n{\\\"a\\\": ", "\\\"}{\\\", \\\"b\\\": [1, ", "2]}", "\\n```", " and more"} {
+		events = append(events, `data: {"choices":[{"delta":{"content":"`+delta+`"}}]}`)
+	}
+	body := &closeRecorder{Reader: strings.NewReader(strings.Join(events, "\n\n") + "\n\n")}
+
+	stream := NewChatCompletionStreamReader(body)
+	stream.StopWhen(StopOnBalancedJSON())
+	chunks := 0
+	for {
+		_, err := stream.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks++
+	}
+
+	if chunks != 3 {
+		t.Errorf("read %d chunks, want 3", chunks)
+	}
+	if !stream.Stopped() || !body.closed {
+		t.Errorf("stopped = %v, closed = %v, want both", stream.Stopped(), body.closed)
+	}
+	if text := stream.partial.Content(); !strings.HasSuffix(text, `"b": [1, 2]}`) {
+		t.Errorf("content = %q", text)
+	}
+}
+
+func TestStopConditions(t *testing.T) {
+	if !StopOnSubstring("END")("text END") || StopOnSubstring("END")("text") {
+		t.Error("StopOnSubstring")
+	}
+	if !StopOnRegexp(regexp.MustCompile(`Answer: \d+\n`))("Answer: 42\n") {
+		t.Error("StopOnRegexp")
+	}
+
+	balanced := StopOnBalancedJSON()
+	for _, text := range []string{`[`, `[{"x": "]"}`, `[{"x": "]"}, "\"]"`} {
+		if balanced(text) {
+			t.Errorf("StopOnBalancedJSON stopped early at %q", text)
+		}
+	}
+	if !balanced(`[{"x": "]"}, "\"]"]`) {
+		t.Error("StopOnBalancedJSON didn't stop at the closing bracket")
+	}
+}
@@ -0,0 +1,161 @@
+package streaming
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// StreamSummary describes the performance of a completed stream
+type StreamSummary struct {
+	Model    string
+	Provider string
+
+	// FinishReason is the normalized finish reason of the first choice, and
+	// NativeFinishReason the provider's raw reason
+	FinishReason       string
+	NativeFinishReason string
+
+	// TimeToFirstToken is the time from the request to the first content, reasoning,
+	// or tool call delta. It is zero if no token was received.
+	TimeToFirstToken time.Duration
+
+	// Duration is the time from the request to the end of the stream
+	Duration time.Duration
+
+	// Chunks is the number of chunks received
+	Chunks int
+
+	// CompletionTokens is taken from the final usage chunk, or estimated as the number
+	// of chunks carrying tokens when the provider sends no usage
+	CompletionTokens int
+
+	// TokensPerSecond is the decode throughput after the first token
+	TokensPerSecond float64
+
+	// Usage is the token usage from the final usage chunk, or nil if the provider sent
+	// none. Request it with stream_options include_usage, e.g. models.WithStreamUsage().
+	Usage *models.Usage
+}
+
+// streamStats tracks timing and token counts as chunks are read
+type streamStats struct {
+	start      time.Time
+	firstToken time.Time
+	end        time.Time
+
+	model        string
+	provider     string
+	finishReason string
+	nativeReason string
+	chunks       int
+	tokenChunks  int
+	usage        *models.Usage
+
+	done       bool
+	onComplete []func(StreamSummary)
+}
+
+// add records a chunk
+func (s *streamStats) add(chunk *models.ChatCompletionResponse) {
+	s.chunks++
+	if s.model == "" {
+		s.model = chunk.Model
+	}
+	if chunk.Provider != "" {
+		s.provider = chunk.Provider
+	}
+	if chunk.Usage != nil {
+		s.usage = chunk.Usage
+	}
+	if len(chunk.Choices) == 0 {
+		return
+	}
+	if reason := chunk.Choices[0].FinishReason; reason != "" {
+		s.finishReason = reason
+	}
+	if reason := chunk.Choices[0].NativeFinishReason; reason != "" {
+		s.nativeReason = reason
+	}
+	if chunk.Choices[0].Delta == nil {
+		return
+	}
+
+	delta := chunk.Choices[0].Delta
+	if !hasContent(delta.Content) && delta.Reasoning == "" && len(delta.ToolCalls) == 0 {
+		return
+	}
+	s.tokenChunks++
+	if s.firstToken.IsZero() {
+		s.firstToken = time.Now()
+	}
+}
+
+// hasContent reports whether raw message content is non-empty without decoding it
+func hasContent(raw []byte) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && string(raw) != `""` && string(raw) != "null" && string(raw) != "[]"
+}
+
+// finish marks the stream complete and runs the completion callbacks once
+func (s *streamStats) finish() {
+	if s.done {
+		return
+	}
+	s.done = true
+	s.end = time.Now()
+
+	summary := s.summary()
+	for _, fn := range s.onComplete {
+		fn(summary)
+	}
+}
+
+// summary builds a summary from the statistics so far
+func (s *streamStats) summary() StreamSummary {
+	end := s.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	summary := StreamSummary{
+		Model:              s.model,
+		Provider:           s.provider,
+		FinishReason:       s.finishReason,
+		NativeFinishReason: s.nativeReason,
+		Duration:           end.Sub(s.start),
+		Chunks:             s.chunks,
+		CompletionTokens:   s.tokenChunks,
+		Usage:              s.usage,
+	}
+	if s.usage != nil && s.usage.CompletionTokens > 0 {
+		summary.CompletionTokens = s.usage.CompletionTokens
+	}
+	if !s.firstToken.IsZero() {
+		summary.TimeToFirstToken = s.firstToken.Sub(s.start)
+		if decode := end.Sub(s.firstToken); decode > 0 {
+			summary.TokensPerSecond = float64(summary.CompletionTokens) / decode.Seconds()
+		}
+	}
+	return summary
+}
+
+// Summary returns the time to first token, throughput, and token counts of the stream.
+// The values are final once Read has returned an error, such as io.EOF, or the stream
+// has been closed.
+func (r *ChatCompletionStreamReader) Summary() StreamSummary {
+	return r.stats.summary()
+}
+
+// SetStartTime sets the time the request was sent, which time to first token is measured
+// from. It defaults to when the reader was created.
+func (r *ChatCompletionStreamReader) SetStartTime(start time.Time) {
+	r.stats.start = start
+}
+
+// OnComplete registers fn to be called with the stream summary when the stream ends
+// or is closed
+func (r *ChatCompletionStreamReader) OnComplete(fn func(StreamSummary)) {
+	r.stats.onComplete = append(r.stats.onComplete, fn)
+}
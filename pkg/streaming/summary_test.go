@@ -0,0 +1,57 @@
+package streaming
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamSummary(t *testing.T) {
+	body := strings.Join([]string{
+		`data: {"model":"openai/gpt-4o","choices":[{"delta":{"role":"assistant","content":""}}]}`,
+		`data: {"model":"openai/gpt-4o","choices":[{"delta":{"content":"Hello"}}]}`,
+		`data: {"model":"openai/gpt-4o","choices":[{"delta":{"content":" world"}}]}`,
+		`data: {"model":"openai/gpt-4o","provider":"Azure","choices":[{"delta":{},"finish_reason":"stop","native_finish_reason":"end_turn"}],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`,
+		`data: [DONE]`,
+		``,
+	}, "\n\n")
+
+	reader := NewChatCompletionStreamReader(io.NopCloser(strings.NewReader(body)))
+	start := time.Now().Add(-50 * time.Millisecond)
+	reader.SetStartTime(start)
+
+	var completed []StreamSummary
+	reader.OnComplete(func(summary StreamSummary) {
+		completed = append(completed, summary)
+	})
+
+	for {
+		if _, err := reader.Read(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	reader.Close()
+
+	if len(completed) != 1 {
+		t.Fatalf("OnComplete called %d times, want 1", len(completed))
+	}
+	summary := completed[0]
+	if summary.Model != "openai/gpt-4o" || summary.Chunks != 4 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if summary.Provider != "Azure" || summary.FinishReason != "stop" || summary.NativeFinishReason != "end_turn" {
+		t.Errorf("unexpected response metadata: %+v", summary)
+	}
+	if summary.CompletionTokens != 4 {
+		t.Errorf("CompletionTokens = %d, want usage value 4", summary.CompletionTokens)
+	}
+	if summary.TimeToFirstToken < 50*time.Millisecond || summary.TimeToFirstToken > summary.Duration {
+		t.Errorf("TimeToFirstToken = %v, Duration = %v", summary.TimeToFirstToken, summary.Duration)
+	}
+	if reader.Summary() != summary {
+		t.Errorf("Summary() changed after the stream ended")
+	}
+}
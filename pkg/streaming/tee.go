@@ -0,0 +1,125 @@
+package streaming
+
+import (
+	"io"
+	"sync"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// TeeReader is one of several independent readers over a shared stream, created by Tee
+type TeeReader struct {
+	tee *tee
+	pos int
+}
+
+// tee buffers chunks read from the source until every reader has consumed them
+type tee struct {
+	src *ChatCompletionStreamReader
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	buf     []*models.ChatCompletionResponse
+	base    int // stream position of buf[0]
+	err     error
+	reading bool
+	readers map[*TeeReader]bool
+}
+
+// Tee splits the stream into n independent readers, so one live stream can, for example,
+// be rendered to a user, persisted, and checked by a moderator at the same time. Each
+// reader sees every chunk in order, at its own pace; chunks are buffered until the
+// slowest reader has read them. The source stream is closed once every reader is closed.
+//
+// Chunks are shared between readers and must not be modified. Don't read from the
+// original stream after calling Tee.
+func (r *ChatCompletionStreamReader) Tee(n int) []*TeeReader {
+	t := &tee{src: r, readers: make(map[*TeeReader]bool, n)}
+	t.cond = sync.NewCond(&t.mu)
+
+	readers := make([]*TeeReader, n)
+	for i := range readers {
+		readers[i] = &TeeReader{tee: t}
+		t.readers[readers[i]] = true
+	}
+	return readers
+}
+
+// Read reads the next chunk, blocking until another reader or this one has read it
+// from the source
+func (r *TeeReader) Read() (*models.ChatCompletionResponse, error) {
+	t := r.tee
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for {
+		if !t.readers[r] {
+			return nil, io.ErrClosedPipe
+		}
+		if i := r.pos - t.base; i < len(t.buf) {
+			chunk := t.buf[i]
+			r.pos++
+			t.trim()
+			return chunk, nil
+		}
+		if t.err != nil {
+			return nil, t.err
+		}
+		if t.reading {
+			t.cond.Wait()
+			continue
+		}
+
+		// Read the next chunk from the source without holding the lock, so readers
+		// with buffered chunks aren't blocked
+		t.reading = true
+		t.mu.Unlock()
+		chunk, err := t.src.Read()
+		t.mu.Lock()
+		t.reading = false
+		if err != nil {
+			t.err = err
+		} else {
+			t.buf = append(t.buf, chunk)
+		}
+		t.cond.Broadcast()
+	}
+}
+
+// Close detaches the reader. Closing the last reader closes the source stream. Don't call
+// it while a Read on the same reader is in progress.
+func (r *TeeReader) Close() error {
+	t := r.tee
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.readers[r] {
+		return nil
+	}
+	delete(t.readers, r)
+	t.cond.Broadcast()
+	if len(t.readers) == 0 {
+		t.buf = nil
+		return t.src.Close()
+	}
+	t.trim()
+	return nil
+}
+
+// trim drops buffered chunks every open reader has read
+func (t *tee) trim() {
+	min := -1
+	for reader := range t.readers {
+		if min < 0 || reader.pos < min {
+			min = reader.pos
+		}
+	}
+	if drop := min - t.base; drop > 0 {
+		// Clear dropped entries so their chunks can be collected
+		for i := 0; i < drop; i++ {
+			t.buf[i] = nil
+		}
+		t.buf = t.buf[drop:]
+		t.base = min
+	}
+}
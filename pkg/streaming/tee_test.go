@@ -0,0 +1,65 @@
+package streaming
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestTee(t *testing.T) {
+	var events []string
+	for i := 0; i < 50; i++ {
+		events = append(events, fmt.Sprintf(`data: {"choices":[{"delta":{"content":"%d "}}]}`, i))
+	}
+	events = append(events, "data: [DONE]", "")
+	body := &closeRecorder{Reader: strings.NewReader(strings.Join(events, "\n\n"))}
+
+	readers := NewChatCompletionStreamReader(body).Tee(3)
+
+	var wg sync.WaitGroup
+	texts := make([]string, len(readers))
+	for i, reader := range readers {
+		i, reader := i, reader
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer reader.Close()
+
+			acc := NewAccumulator()
+			for {
+				chunk, err := reader.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				acc.Add(chunk)
+			}
+			texts[i] = acc.Content()
+		}()
+	}
+	wg.Wait()
+
+	for i, text := range texts {
+		if !strings.HasPrefix(text, "0 1 2 ") || !strings.HasSuffix(text, "48 49 ") {
+			t.Errorf("reader %d read %q", i, text)
+		}
+	}
+	if !body.closed {
+		t.Error("source stream was not closed after every reader closed")
+	}
+}
@@ -0,0 +1,178 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// SummaryOptions configures SummarizeConversation
+type SummaryOptions struct {
+	// Client writes the summary. Required.
+	Client *Client
+
+	// Model defaults to DefaultCompressionModel
+	Model string
+
+	// Focus, when set, tells the summarizer what matters most, e.g. "the customer's
+	// refund request"
+	Focus string
+
+	// MaxToolResultLength truncates tool results, in characters, in the transcript sent
+	// to the model and in the summary's tool calls. Defaults to 500.
+	MaxToolResultLength int
+}
+
+// ConversationSummary is a structured summary of a conversation, for storing with a
+// session as an audit record or handing the conversation over to another agent
+type ConversationSummary struct {
+	Summary       string   `json:"summary"`
+	Topics        []string `json:"topics"`
+	Decisions     []string `json:"decisions"`
+	OpenQuestions []string `json:"open_questions"`
+
+	// ToolCalls are read from the messages rather than written by the model, so they are
+	// complete and exact
+	ToolCalls []ToolCallRecord `json:"tool_calls,omitempty"`
+
+	Messages  int       `json:"messages"`
+	Model     string    `json:"model"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ToolCallRecord is a tool call made in a conversation, with its result when the
+// conversation contains one
+type ToolCallRecord struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result,omitempty"`
+}
+
+// Message returns the summary as a system message that briefs an agent taking over the
+// conversation
+func (s *ConversationSummary) Message() models.Message {
+	var text strings.Builder
+	text.WriteString("Summary of the conversation so far:\n")
+	text.WriteString(s.Summary)
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&text, "\n\n%s:", title)
+		for _, item := range items {
+			fmt.Fprintf(&text, "\n- %s", item)
+		}
+	}
+	writeList("Topics", s.Topics)
+	writeList("Decisions", s.Decisions)
+	writeList("Open questions", s.OpenQuestions)
+	if len(s.ToolCalls) > 0 {
+		calls := make([]string, len(s.ToolCalls))
+		for i, call := range s.ToolCalls {
+			calls[i] = fmt.Sprintf("%s(%s)", call.Name, call.Arguments)
+		}
+		writeList("Tool calls made", calls)
+	}
+	return models.NewTextMessage(models.RoleSystem, text.String())
+}
+
+// SummarizeConversation summarizes messages with a cheap model into topics, decisions,
+// open questions and the tool calls made
+func SummarizeConversation(ctx context.Context, messages []models.Message, opts SummaryOptions) (*ConversationSummary, error) {
+	if opts.Client == nil {
+		return nil, fmt.Errorf("summary options: client is required")
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages to summarize")
+	}
+	if opts.Model == "" {
+		opts.Model = DefaultCompressionModel
+	}
+	if opts.MaxToolResultLength <= 0 {
+		opts.MaxToolResultLength = 500
+	}
+
+	calls := toolCallRecords(messages, opts.MaxToolResultLength)
+	var transcript strings.Builder
+	for _, msg := range messages {
+		switch msg.Role {
+		case models.RoleTool:
+			fmt.Fprintf(&transcript, "tool result: %s\n", truncate(messageText(msg), opts.MaxToolResultLength))
+		case models.RoleSystem:
+			fmt.Fprintf(&transcript, "system instructions: %s\n", messageText(msg))
+		default:
+			if text := messageText(msg); text != "" {
+				fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, text)
+			}
+		}
+		for _, call := range msg.ToolCalls {
+			fmt.Fprintf(&transcript, "%s called %s(%s)\n", msg.Role, call.Function.Name, call.Function.Arguments)
+		}
+	}
+
+	system := "Summarize the conversation below for an audit record or for another agent taking it over. " +
+		"List the topics discussed, the decisions made or agreed, and the questions still open. Keep names, " +
+		"numbers and commitments exact; drop small talk."
+	if opts.Focus != "" {
+		system += " Focus on " + opts.Focus + "."
+	}
+	req := models.NewChatRequest(opts.Model,
+		models.WithSystemMessage(system),
+		models.WithUserMessage(transcript.String()),
+		models.WithTemperature(0),
+	)
+
+	var result struct {
+		Summary       string   `json:"summary" description:"A few sentences on what happened"`
+		Topics        []string `json:"topics" description:"Topics discussed"`
+		Decisions     []string `json:"decisions" description:"Decisions made or agreed"`
+		OpenQuestions []string `json:"open_questions" description:"Questions or tasks still unresolved"`
+	}
+	resp, err := NewStructuredOutput(opts.Client).CreateWithSchema(withoutDecorators(ctx), req, "conversation_summary", result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize conversation: %w", err)
+	}
+	if err := ParseStructuredResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return &ConversationSummary{
+		Summary:       strings.TrimSpace(result.Summary),
+		Topics:        result.Topics,
+		Decisions:     result.Decisions,
+		OpenQuestions: result.OpenQuestions,
+		ToolCalls:     calls,
+		Messages:      len(messages),
+		Model:         resp.Model,
+		CreatedAt:     opts.Client.clock.Now(),
+	}, nil
+}
+
+// toolCallRecords lists the tool calls in messages, matched with their results
+func toolCallRecords(messages []models.Message, maxResultLength int) []ToolCallRecord {
+	var records []ToolCallRecord
+	index := make(map[string]int)
+	for _, msg := range messages {
+		for _, call := range msg.ToolCalls {
+			index[call.ID] = len(records)
+			records = append(records, ToolCallRecord{ID: call.ID, Name: call.Function.Name, Arguments: call.Function.Arguments})
+		}
+		if i, ok := index[msg.ToolCallID]; ok && msg.Role == models.RoleTool {
+			records[i].Result = truncate(messageText(msg), maxResultLength)
+		}
+	}
+	return records
+}
+
+// truncate shortens text to at most n characters, marking the cut
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,50 @@
+package pkg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestSummarizeConversation(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply(`{"summary":"The user asked for a refund of order 42.",` +
+		`"topics":["refund"],"decisions":["Refund approved"],"open_questions":["Ship a replacement?"]}`))
+
+	messages := []models.Message{
+		models.NewTextMessage(models.RoleUser, "Please refund order 42"),
+		{Role: models.RoleAssistant, ToolCalls: []models.ToolCall{{ID: "call_1", Type: "function",
+			Function: models.FunctionCall{Name: "refund", Arguments: `{"order":42}`}}}},
+		{Role: models.RoleTool, ToolCallID: "call_1", Content: []byte(`"refunded"`)},
+		models.NewTextMessage(models.RoleAssistant, "Done, order 42 is refunded."),
+	}
+	summary, err := pkg.SummarizeConversation(context.Background(), messages, pkg.SummaryOptions{Client: srv.Client()})
+	require.NoError(t, err)
+
+	assert.Equal(t, "The user asked for a refund of order 42.", summary.Summary)
+	assert.Equal(t, []string{"Refund approved"}, summary.Decisions)
+	assert.Equal(t, []string{"Ship a replacement?"}, summary.OpenQuestions)
+	assert.Equal(t, []pkg.ToolCallRecord{{ID: "call_1", Name: "refund", Arguments: `{"order":42}`, Result: "refunded"}}, summary.ToolCalls)
+	assert.Equal(t, 4, summary.Messages)
+
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, pkg.DefaultCompressionModel, sent.Model)
+	transcript, _ := sent.Messages[1].GetTextContent()
+	assert.Contains(t, transcript, `assistant called refund({"order":42})`)
+	assert.Contains(t, transcript, "tool result: refunded")
+
+	handoff, _ := summary.Message().GetTextContent()
+	assert.Contains(t, handoff, "Decisions:\n- Refund approved")
+	assert.Contains(t, handoff, `Tool calls made:`+"\n"+`- refund({"order":42})`)
+
+	_, err = pkg.SummarizeConversation(context.Background(), nil, pkg.SummaryOptions{Client: srv.Client()})
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+)
+
+// SystemPromptRule sets the system prompt mode for models matching Pattern, where *
+// matches any characters
+type SystemPromptRule struct {
+	Pattern string
+	Mode    models.SystemPromptMode
+}
+
+// SystemPromptCompat rewrites system messages for models that reject the system role.
+// The mode for a model comes from the first matching rule in Rules, then the built-in
+// rules, then the instruct type in Catalog; models matching none are left unchanged.
+type SystemPromptCompat struct {
+	Rules []SystemPromptRule
+
+	// Catalog, if set, supplies each model's instruct type, so models whose prompt format
+	// has no system turn are detected without a rule
+	Catalog *ModelCatalog
+}
+
+// defaultSystemPromptRules covers models known to reject system messages
+var defaultSystemPromptRules = []SystemPromptRule{
+	{Pattern: "openai/o1-mini*", Mode: models.SystemPromptMerge},
+	{Pattern: "openai/o1-preview*", Mode: models.SystemPromptMerge},
+	{Pattern: "google/gemma-*", Mode: models.SystemPromptMerge},
+}
+
+// instructTypeSystemPromptModes maps catalog instruct types whose prompt format has no
+// system turn to a mode
+var instructTypeSystemPromptModes = map[string]models.SystemPromptMode{
+	"gemma": models.SystemPromptMerge,
+}
+
+// WithSystemPromptCompat rewrites system messages in chat requests for models that reject
+// the system role, e.g. merging them into the first user message
+func WithSystemPromptCompat(compat SystemPromptCompat) Option {
+	return func(c *Client) {
+		c.systemPrompts = &compat
+	}
+}
+
+// mode returns the system prompt mode for a model
+func (s *SystemPromptCompat) mode(ctx context.Context, model string) models.SystemPromptMode {
+	model = strings.ToLower(model)
+	for _, rules := range [][]SystemPromptRule{s.Rules, defaultSystemPromptRules} {
+		for _, rule := range rules {
+			if matchModelPattern(strings.ToLower(rule.Pattern), model) {
+				return rule.Mode
+			}
+		}
+	}
+
+	// The model's entry in the catalog is best effort; without it the request is sent as is
+	if s.Catalog != nil {
+		if entry, err := s.Catalog.Model(ctx, model); err == nil && entry != nil {
+			if mode, ok := instructTypeSystemPromptModes[entry.Architecture.InstructType]; ok {
+				return mode
+			}
+		}
+	}
+	return models.SystemPromptNative
+}
+
+// apply rewrites the system messages of req for its model
+func (s *SystemPromptCompat) apply(ctx context.Context, req *models.ChatCompletionRequest) error {
+	if s == nil || len(req.Messages) == 0 {
+		return nil
+	}
+
+	hasSystem := false
+	for _, msg := range req.Messages {
+		if msg.Role == models.RoleSystem {
+			hasSystem = true
+			break
+		}
+	}
+	if !hasSystem {
+		return nil
+	}
+
+	messages, err := models.RewriteSystemMessages(req.Messages, s.mode(ctx, req.Model))
+	if err != nil {
+		return fmt.Errorf("failed to rewrite system messages: %w", err)
+	}
+	req.Messages = messages
+	return nil
+}
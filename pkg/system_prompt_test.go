@@ -0,0 +1,54 @@
+package pkg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestSystemPromptCompat(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetModels(
+		models.Model{ID: "acme/instruct-7b", Architecture: models.Architecture{InstructType: "gemma"}},
+		models.Model{ID: "openai/gpt-4o", Architecture: models.Architecture{InstructType: "chatml"}},
+	)
+
+	client := srv.Client()
+	client = srv.Client(pkg.WithSystemPromptCompat(pkg.SystemPromptCompat{
+		Rules:   []pkg.SystemPromptRule{{Pattern: "legacy/*", Mode: models.SystemPromptAsUser}},
+		Catalog: pkg.NewModelCatalog(client, 0),
+	}))
+	ctx := context.Background()
+
+	tests := []struct {
+		model string
+		roles []models.Role
+	}{
+		{"acme/instruct-7b:free", []models.Role{models.RoleUser}},        // catalog instruct type
+		{"google/gemma-2-9b-it", []models.Role{models.RoleUser}},         // built-in rule
+		{"legacy/chat", []models.Role{models.RoleUser, models.RoleUser}}, // custom rule
+		{"openai/gpt-4o", []models.Role{models.RoleSystem, models.RoleUser}},
+	}
+	for _, tt := range tests {
+		req := models.NewChatRequest(tt.model, models.WithSystemMessage("Be brief."), models.WithUserMessage("hi"))
+		_, err := client.CreateChatCompletion(ctx, req)
+		require.NoError(t, err)
+
+		requests := srv.Requests()
+		sent, err := requests[len(requests)-1].ChatRequest()
+		require.NoError(t, err)
+
+		var roles []models.Role
+		for _, msg := range sent.Messages {
+			roles = append(roles, msg.Role)
+		}
+		assert.Equal(t, tt.roles, roles, tt.model)
+	}
+}
@@ -0,0 +1,99 @@
+package tenancy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store persists tenants and their usage. MemoryStore is provided; implement Store to
+// share quotas between processes with SQLite, Postgres, Redis, or similar.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Tenant returns ErrUnknownTenant for unknown IDs
+	Tenant(ctx context.Context, id string) (*Tenant, error)
+
+	// PutTenant inserts a tenant or replaces the tenant with the same ID, keeping its usage
+	PutTenant(ctx context.Context, tenant *Tenant) error
+
+	// DeleteTenant removes a tenant and its usage
+	DeleteTenant(ctx context.Context, id string) error
+
+	// Usage returns the tenant's usage in the period starting at period, which is zero
+	// for periods that have recorded none
+	Usage(ctx context.Context, id string, period time.Time) (Usage, error)
+
+	// AddUsage atomically adds delta to the tenant's usage in the period starting at
+	// period and returns the new total. Usage of earlier periods may be discarded.
+	AddUsage(ctx context.Context, id string, period time.Time, delta Usage) (Usage, error)
+}
+
+// MemoryStore keeps tenants and usage in memory. Neither survives a restart, and quotas
+// aren't shared between processes; use it for tests and single-instance services.
+type MemoryStore struct {
+	mu      sync.Mutex
+	tenants map[string]Tenant
+	usage   map[string]Usage
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tenants: make(map[string]Tenant), usage: make(map[string]Usage)}
+}
+
+// Tenant implements Store
+func (s *MemoryStore) Tenant(ctx context.Context, id string) (*Tenant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tenant, ok := s.tenants[id]
+	if !ok {
+		return nil, ErrUnknownTenant
+	}
+	return &tenant, nil
+}
+
+// PutTenant implements Store
+func (s *MemoryStore) PutTenant(ctx context.Context, tenant *Tenant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tenants[tenant.ID] = *tenant
+	return nil
+}
+
+// DeleteTenant implements Store
+func (s *MemoryStore) DeleteTenant(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tenants, id)
+	delete(s.usage, id)
+	return nil
+}
+
+// Usage implements Store
+func (s *MemoryStore) Usage(ctx context.Context, id string, period time.Time) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage, ok := s.usage[id]
+	if !ok || !usage.Period.Equal(period) {
+		return Usage{Period: period}, nil
+	}
+	return usage, nil
+}
+
+// AddUsage implements Store. Usage charged to a period that has already ended is dropped.
+func (s *MemoryStore) AddUsage(ctx context.Context, id string, period time.Time, delta Usage) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage, ok := s.usage[id]
+	if ok && period.Before(usage.Period) {
+		return Usage{Period: period}, nil
+	}
+	if !ok || !usage.Period.Equal(period) {
+		usage = Usage{Period: period}
+	}
+	usage.Requests += delta.Requests
+	usage.Tokens += delta.Tokens
+	usage.Cost += delta.Cost
+	s.usage[id] = usage
+	return usage, nil
+}
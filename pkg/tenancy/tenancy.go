@@ -0,0 +1,392 @@
+// Package tenancy serves the tenants of a multi-tenant application from one OpenRouter
+// account. A tenant either gets a provisioned OpenRouter sub-key, whose credit limit
+// OpenRouter enforces, or shares the account's key under request, token and cost quotas
+// enforced locally and tracked in a Store. Middleware resolves the tenant of each HTTP
+// request into its context, and the Manager sends chat requests made with that context
+// with the tenant's key and charges them to its quota.
+//
+//	manager := tenancy.New(tenancy.Options{Client: client, Keys: provisioningClient})
+//	manager.Register(ctx, "acme", tenancy.Quota{Requests: 1000, Cost: 5, Period: 24 * time.Hour})
+//	manager.Provision(ctx, "globex", 20)
+//	http.Handle("/api/chat", tenancy.Middleware(tenantFromHost, chathttp.ChatHandler(manager, opts)))
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
+)
+
+var (
+	// ErrUnknownTenant is returned for tenants that haven't been registered or provisioned
+	ErrUnknownTenant = errors.New("unknown tenant")
+
+	// ErrNoTenant is returned for requests whose context names no tenant
+	ErrNoTenant = errors.New("no tenant in context")
+
+	// ErrQuotaExceeded rejects a request from a tenant that used up a quota
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+// QuotaError is returned for requests from a tenant that used up one of its quotas
+type QuotaError struct {
+	Tenant string
+
+	// Limit is the quota used up: "requests", "tokens" or "cost"
+	Limit string
+
+	// RetryAfter is when the quota resets, or zero for quotas that never reset
+	RetryAfter time.Duration
+}
+
+// Error describes the quota
+func (e *QuotaError) Error() string {
+	msg := fmt.Sprintf("tenant %q exceeded its %s quota", e.Tenant, e.Limit)
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retry in %s", e.RetryAfter.Round(time.Second))
+	}
+	return msg
+}
+
+// Unwrap returns ErrQuotaExceeded
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// Quota limits what a tenant may use per period. Zero limits are unlimited.
+type Quota struct {
+	Requests int     `json:"requests,omitempty"`
+	Tokens   int     `json:"tokens,omitempty"`
+	Cost     float64 `json:"cost,omitempty"`
+
+	// Period is how often usage resets. Periods are whole multiples of the period since
+	// the zero time, so daily quotas reset at midnight UTC. Zero never resets.
+	Period time.Duration `json:"period,omitempty"`
+}
+
+// Usage is what a tenant used in a period
+type Usage struct {
+	Requests int     `json:"requests"`
+	Tokens   int     `json:"tokens"`
+	Cost     float64 `json:"cost"`
+
+	// Period is when the period started, or the zero time for quotas that never reset
+	Period time.Time `json:"period"`
+}
+
+// Tenant is a tenant's key and quota
+type Tenant struct {
+	ID    string `json:"id"`
+	Quota Quota  `json:"quota"`
+
+	// Key is the tenant's provisioned OpenRouter sub-key, which its requests are sent
+	// with, and KeyHash identifies it to the key management API. Tenants without one
+	// share the Manager's client.
+	Key     string `json:"key,omitempty"`
+	KeyHash string `json:"key_hash,omitempty"`
+}
+
+// Provisioned reports whether the tenant has its own sub-key
+func (t *Tenant) Provisioned() bool {
+	return t.Key != ""
+}
+
+// Client is the part of a go-openrouter client the Manager uses, implemented by
+// *pkg.Client and *pkg.ObservableClient
+type Client interface {
+	CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, req models.ChatCompletionRequest) (*streaming.ChatCompletionStreamReader, error)
+}
+
+// KeyManager provisions sub-keys, implemented by a *pkg.Client created with a
+// provisioning key
+type KeyManager interface {
+	CreateAPIKey(ctx context.Context, req models.CreateAPIKeyRequest) (*models.APIKey, error)
+	UpdateAPIKey(ctx context.Context, keyHash string, req models.UpdateAPIKeyRequest) (*models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, keyHash string) error
+}
+
+// Options configures a Manager
+type Options struct {
+	// Client sends the requests of tenants without a sub-key. Required.
+	Client Client
+
+	// Keys provisions sub-keys. Required for Provision and for revoking provisioned
+	// tenants.
+	Keys KeyManager
+
+	// NewClient creates the client for a tenant's sub-key. Defaults to pkg.NewClient with
+	// no options.
+	NewClient func(key string) Client
+
+	// Store defaults to a new MemoryStore
+	Store Store
+
+	// Logger, when set, logs usage that couldn't be recorded
+	Logger pkg.Logger
+
+	// Clock defaults to pkg.SystemClock
+	Clock pkg.Clock
+}
+
+// Manager sends chat requests on behalf of the tenant in their context. It implements
+// chathttp.Client. It is safe for concurrent use.
+type Manager struct {
+	opts Options
+
+	mu      sync.Mutex
+	clients map[string]Client
+}
+
+// New creates a Manager
+func New(opts Options) *Manager {
+	if opts.NewClient == nil {
+		opts.NewClient = func(key string) Client { return pkg.NewClient(key) }
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemoryStore()
+	}
+	if opts.Clock == nil {
+		opts.Clock = pkg.SystemClock
+	}
+	return &Manager{opts: opts, clients: make(map[string]Client)}
+}
+
+// Register adds a tenant sharing the Manager's client under quota, or replaces the
+// quota of an existing tenant, keeping its key
+func (m *Manager) Register(ctx context.Context, id string, quota Quota) (*Tenant, error) {
+	tenant, err := m.opts.Store.Tenant(ctx, id)
+	if errors.Is(err, ErrUnknownTenant) {
+		tenant, err = &Tenant{ID: id}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	tenant.Quota = quota
+	if err := m.opts.Store.PutTenant(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("failed to store tenant: %w", err)
+	}
+	return tenant, nil
+}
+
+// Provision gives a tenant its own sub-key with a credit limit, registering the tenant
+// if needed. For tenants that already have one, the key's limit is updated.
+func (m *Manager) Provision(ctx context.Context, id string, limit float64) (*Tenant, error) {
+	if m.opts.Keys == nil {
+		return nil, errors.New("tenancy: Options.Keys is required to provision keys")
+	}
+	tenant, err := m.opts.Store.Tenant(ctx, id)
+	if errors.Is(err, ErrUnknownTenant) {
+		tenant, err = &Tenant{ID: id}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	if tenant.Provisioned() {
+		if _, err := m.opts.Keys.UpdateAPIKey(ctx, tenant.KeyHash, models.UpdateAPIKeyRequest{Limit: &limit}); err != nil {
+			return nil, fmt.Errorf("failed to update key: %w", err)
+		}
+		return tenant, nil
+	}
+
+	key, err := m.opts.Keys.CreateAPIKey(ctx, models.CreateAPIKeyRequest{Name: "tenant " + id, Label: id, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key: %w", err)
+	}
+	tenant.Key = key.Key
+	tenant.KeyHash = key.Hash
+	if err := m.opts.Store.PutTenant(ctx, tenant); err != nil {
+		// Don't leave a key behind that no tenant knows about
+		m.opts.Keys.DeleteAPIKey(context.WithoutCancel(ctx), key.Hash)
+		return nil, fmt.Errorf("failed to store tenant: %w", err)
+	}
+	return tenant, nil
+}
+
+// Revoke removes a tenant, deleting its sub-key if it has one
+func (m *Manager) Revoke(ctx context.Context, id string) error {
+	tenant, err := m.opts.Store.Tenant(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant: %w", err)
+	}
+	if tenant.Provisioned() {
+		if m.opts.Keys == nil {
+			return errors.New("tenancy: Options.Keys is required to revoke provisioned tenants")
+		}
+		if err := m.opts.Keys.DeleteAPIKey(ctx, tenant.KeyHash); err != nil {
+			return fmt.Errorf("failed to delete key: %w", err)
+		}
+		m.mu.Lock()
+		delete(m.clients, tenant.Key)
+		m.mu.Unlock()
+	}
+	if err := m.opts.Store.DeleteTenant(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	return nil
+}
+
+// Tenant returns a registered tenant
+func (m *Manager) Tenant(ctx context.Context, id string) (*Tenant, error) {
+	return m.opts.Store.Tenant(ctx, id)
+}
+
+// Usage returns what a tenant has used in its current period
+func (m *Manager) Usage(ctx context.Context, id string) (Usage, error) {
+	tenant, err := m.opts.Store.Tenant(ctx, id)
+	if err != nil {
+		return Usage{}, err
+	}
+	return m.opts.Store.Usage(ctx, id, m.period(tenant))
+}
+
+// CreateChatCompletion sends a request on behalf of the context's tenant and charges its
+// usage to the tenant
+func (m *Manager) CreateChatCompletion(ctx context.Context, req models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	tenant, period, err := m.admit(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.client(tenant).CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	m.charge(ctx, tenant, period, resp.Usage)
+	return resp, nil
+}
+
+// CreateChatCompletionStream streams a request on behalf of the context's tenant and
+// charges its usage to the tenant when the stream reports it, at its end
+func (m *Manager) CreateChatCompletionStream(ctx context.Context, req models.ChatCompletionRequest) (*streaming.ChatCompletionStreamReader, error) {
+	tenant, period, err := m.admit(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := m.client(tenant).CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	stream.OnChunk(func(index int, chunk *models.ChatCompletionResponse) {
+		if chunk.Usage != nil {
+			m.charge(ctx, tenant, period, chunk.Usage)
+		}
+	})
+	return stream, nil
+}
+
+// admit checks the context's tenant against its quota and counts the request. A request
+// is admitted while the tenant is under quota, so concurrent requests may overshoot it.
+func (m *Manager) admit(ctx context.Context, req *models.ChatCompletionRequest) (*Tenant, time.Time, error) {
+	id, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil, time.Time{}, ErrNoTenant
+	}
+	tenant, err := m.opts.Store.Tenant(ctx, id)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	period := m.period(tenant)
+	usage, err := m.opts.Store.Usage(ctx, id, period)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to get usage: %w", err)
+	}
+	quota := tenant.Quota
+	var limit string
+	switch {
+	case quota.Requests > 0 && usage.Requests >= quota.Requests:
+		limit = "requests"
+	case quota.Tokens > 0 && usage.Tokens >= quota.Tokens:
+		limit = "tokens"
+	case quota.Cost > 0 && usage.Cost >= quota.Cost:
+		limit = "cost"
+	}
+	if limit != "" {
+		quotaErr := &QuotaError{Tenant: id, Limit: limit}
+		if quota.Period > 0 {
+			quotaErr.RetryAfter = period.Add(quota.Period).Sub(m.opts.Clock.Now())
+		}
+		return nil, time.Time{}, quotaErr
+	}
+
+	if _, err := m.opts.Store.AddUsage(ctx, id, period, Usage{Requests: 1}); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to record usage: %w", err)
+	}
+	if quota.Cost > 0 {
+		// Usage accounting reports the cost the quota is charged with
+		models.WithUsageAccounting()(req)
+	}
+	return tenant, period, nil
+}
+
+// charge adds a completed request's usage to the tenant's period
+func (m *Manager) charge(ctx context.Context, tenant *Tenant, period time.Time, usage *models.Usage) {
+	if usage == nil {
+		return
+	}
+	delta := Usage{Tokens: usage.TotalTokens, Cost: usage.Cost}
+	if _, err := m.opts.Store.AddUsage(context.WithoutCancel(ctx), tenant.ID, period, delta); err != nil && m.opts.Logger != nil {
+		m.opts.Logger.Warn("Failed to record tenant usage", pkg.F("tenant", tenant.ID), pkg.F("error", err))
+	}
+}
+
+// period returns when the tenant's current quota period started
+func (m *Manager) period(tenant *Tenant) time.Time {
+	if tenant.Quota.Period <= 0 {
+		return time.Time{}
+	}
+	return m.opts.Clock.Now().Truncate(tenant.Quota.Period)
+}
+
+// client returns the client the tenant's requests are sent with
+func (m *Manager) client(tenant *Tenant) Client {
+	if !tenant.Provisioned() {
+		return m.opts.Client
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	client, ok := m.clients[tenant.Key]
+	if !ok {
+		client = m.opts.NewClient(tenant.Key)
+		m.clients[tenant.Key] = client
+	}
+	return client
+}
+
+type tenantContextKey struct{}
+
+// ContextWithTenant returns a context whose requests are made on behalf of the tenant
+func ContextWithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, id)
+}
+
+// TenantFromContext returns the tenant stored in the context, if any
+func TenantFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantContextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Resolver returns the tenant an HTTP request belongs to, e.g. from its host name, a
+// header or the session
+type Resolver func(r *http.Request) (string, error)
+
+// Middleware stores each request's tenant in its context for the Manager. Requests whose
+// tenant can't be resolved are rejected with 401 Unauthorized.
+func Middleware(resolve Resolver, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := resolve(r)
+		if err != nil || id == "" {
+			http.Error(w, "unknown tenant", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithTenant(r.Context(), id)))
+	})
+}
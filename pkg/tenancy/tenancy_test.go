@@ -0,0 +1,168 @@
+package tenancy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+	"github.com/rizome-dev/go-openrouter/pkg/tenancy"
+)
+
+var hiRequest = models.NewChatRequest("m", models.WithUserMessage("Hi"))
+
+func newManager(srv *openroutertest.Server, clock pkg.Clock) *tenancy.Manager {
+	return tenancy.New(tenancy.Options{
+		Client: srv.Client(),
+		Keys:   srv.Client(),
+		NewClient: func(key string) tenancy.Client {
+			return pkg.NewClient(key, pkg.WithBaseURL(srv.URL))
+		},
+		Clock: clock,
+	})
+}
+
+func TestRequestQuota(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	clock := openroutertest.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	manager := newManager(srv, clock)
+
+	_, err := manager.Register(context.Background(), "acme", tenancy.Quota{Requests: 2, Period: 24 * time.Hour})
+	require.NoError(t, err)
+	ctx := tenancy.ContextWithTenant(context.Background(), "acme")
+
+	for i := 0; i < 2; i++ {
+		_, err := manager.CreateChatCompletion(ctx, hiRequest)
+		require.NoError(t, err)
+	}
+	_, err = manager.CreateChatCompletion(ctx, hiRequest)
+	var quotaErr *tenancy.QuotaError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.ErrorIs(t, err, tenancy.ErrQuotaExceeded)
+	assert.Equal(t, "requests", quotaErr.Limit)
+	assert.Equal(t, 12*time.Hour, quotaErr.RetryAfter)
+	assert.Len(t, srv.Requests(), 2)
+
+	usage, err := manager.Usage(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage.Requests)
+	assert.Greater(t, usage.Tokens, 0)
+
+	// The quota resets at midnight
+	clock.Advance(12 * time.Hour)
+	_, err = manager.CreateChatCompletion(ctx, hiRequest)
+	assert.NoError(t, err)
+}
+
+func TestTokenQuotaCountsStreams(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	manager := newManager(srv, nil)
+
+	_, err := manager.Register(context.Background(), "acme", tenancy.Quota{Tokens: 15})
+	require.NoError(t, err)
+	ctx := tenancy.ContextWithTenant(context.Background(), "acme")
+
+	srv.EnqueueChat(openroutertest.TextReply("one two three four five six"))
+	stream, err := manager.CreateChatCompletionStream(ctx, hiRequest)
+	require.NoError(t, err)
+	for {
+		_, err := stream.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	stream.Close()
+
+	usage, err := manager.Usage(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, 16, usage.Tokens)
+
+	_, err = manager.CreateChatCompletion(ctx, hiRequest)
+	var quotaErr *tenancy.QuotaError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, "tokens", quotaErr.Limit)
+	assert.Zero(t, quotaErr.RetryAfter, "quotas without a period never reset")
+}
+
+func TestProvisionedTenant(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	manager := newManager(srv, nil)
+	ctx := context.Background()
+
+	tenant, err := manager.Provision(ctx, "globex", 20)
+	require.NoError(t, err)
+	require.True(t, tenant.Provisioned())
+
+	key, err := srv.Client().GetAPIKey(ctx, tenant.KeyHash)
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, key.Limit)
+	assert.Equal(t, "globex", key.Label)
+
+	// Provisioning again updates the limit
+	again, err := manager.Provision(ctx, "globex", 50)
+	require.NoError(t, err)
+	assert.Equal(t, tenant.Key, again.Key)
+	key, err = srv.Client().GetAPIKey(ctx, tenant.KeyHash)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, key.Limit)
+
+	// Requests are sent with the tenant's key
+	_, err = manager.CreateChatCompletion(tenancy.ContextWithTenant(ctx, "globex"), hiRequest)
+	require.NoError(t, err)
+	requests := srv.Requests()
+	assert.Equal(t, "Bearer "+tenant.Key, requests[len(requests)-1].Header.Get("Authorization"))
+
+	require.NoError(t, manager.Revoke(ctx, "globex"))
+	_, err = srv.Client().GetAPIKey(ctx, tenant.KeyHash)
+	assert.Error(t, err, "the key is deleted")
+	_, err = manager.CreateChatCompletion(tenancy.ContextWithTenant(ctx, "globex"), hiRequest)
+	assert.ErrorIs(t, err, tenancy.ErrUnknownTenant)
+}
+
+func TestMiddleware(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	manager := newManager(srv, nil)
+	_, err := manager.Register(context.Background(), "acme", tenancy.Quota{})
+	require.NoError(t, err)
+
+	handler := tenancy.Middleware(func(r *http.Request) (string, error) {
+		if tenant := r.Header.Get("X-Tenant"); tenant != "" {
+			return tenant, nil
+		}
+		return "", errors.New("no tenant header")
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := manager.CreateChatCompletion(r.Context(), hiRequest); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+		}
+	}))
+
+	serve := func(tenant string) int {
+		req := httptest.NewRequest(http.MethodPost, "/chat", nil)
+		if tenant != "" {
+			req.Header.Set("X-Tenant", tenant)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, serve("acme"))
+	assert.Equal(t, http.StatusForbidden, serve("initech"), "unknown tenants are rejected by the manager")
+	assert.Equal(t, http.StatusUnauthorized, serve(""))
+
+	_, err = manager.CreateChatCompletion(context.Background(), hiRequest)
+	assert.ErrorIs(t, err, tenancy.ErrNoTenant)
+}
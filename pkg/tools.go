@@ -1,11 +1,20 @@
 package pkg
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/streaming"
 )
 
 // ToolExecutor is an interface for executing tool calls
@@ -21,42 +30,211 @@ func (f ToolExecutorFunc) Execute(toolCall models.ToolCall) (string, error) {
 	return f(toolCall)
 }
 
-// ToolRegistry manages tool executors
+// ToolNamespaceSeparator separates a tool's namespace from its name, as in "search.web"
+const ToolNamespaceSeparator = "."
+
+// toolWireSeparator replaces ToolNamespaceSeparator in the names sent to models, since
+// providers only accept letters, digits, underscores and dashes
+const toolWireSeparator = "__"
+
+// ToolWireName returns the name a tool is sent to models as: namespaced names have their
+// separators replaced, e.g. "search.web" becomes "search__web"
+func ToolWireName(name string) string {
+	return strings.ReplaceAll(name, ToolNamespaceSeparator, toolWireSeparator)
+}
+
+// RegisteredTool describes a tool in a ToolRegistry
+type RegisteredTool struct {
+	// Name is the registered name, e.g. "search.web"
+	Name string
+
+	// Tool is the definition sent to models, named with ToolWireName; nil for tools
+	// registered without a schema
+	Tool *models.Tool
+}
+
+// ToolRegistry manages tool executors. Tools may be namespaced with dotted names such as
+// "search.web" and "db.query"; models see them by ToolWireName, and calls by either name
+// are executed. It is safe for concurrent use.
 type ToolRegistry struct {
+	mu        sync.RWMutex
 	executors map[string]ToolExecutor
+	tools     map[string]models.Tool
+
+	// wireNames maps wire names to registered names
+	wireNames map[string]string
 }
 
 // NewToolRegistry creates a new tool registry
 func NewToolRegistry() *ToolRegistry {
 	return &ToolRegistry{
 		executors: make(map[string]ToolExecutor),
+		tools:     make(map[string]models.Tool),
+		wireNames: make(map[string]string),
 	}
 }
 
-// Register registers a tool executor
+// Register registers a tool executor without a schema, so it is executable but not
+// included in Tools
 func (r *ToolRegistry) Register(name string, executor ToolExecutor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.executors[name] = executor
+	delete(r.tools, name)
+	r.wireNames[ToolWireName(name)] = name
 }
 
 // RegisterFunc registers a tool executor function
 func (r *ToolRegistry) RegisterFunc(name string, fn func(models.ToolCall) (string, error)) {
-	r.executors[name] = ToolExecutorFunc(fn)
+	r.Register(name, ToolExecutorFunc(fn))
+}
+
+// RegisterTool registers a tool executor with its definition, under the tool's name
+func (r *ToolRegistry) RegisterTool(tool models.Tool, executor ToolExecutor) {
+	name := tool.Function.Name
+	tool.Function.Name = ToolWireName(name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executors[name] = executor
+	r.tools[name] = tool
+	r.wireNames[tool.Function.Name] = name
 }
 
-// Execute executes a tool call
+// Namespace returns a view of the registry that registers tools under prefix, e.g.
+// Namespace("db").RegisterFunc("query", fn) registers "db.query"
+func (r *ToolRegistry) Namespace(prefix string) *ToolNamespace {
+	return &ToolNamespace{registry: r, prefix: prefix + ToolNamespaceSeparator}
+}
+
+// Unregister removes the tools whose names match pattern, returning how many were
+// removed. Patterns use path.Match syntax, so "search.*" removes every tool in the
+// search namespace and "*" removes all tools.
+func (r *ToolRegistry) Unregister(pattern string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	removed := 0
+	for name := range r.executors {
+		if ok, _ := path.Match(pattern, name); ok || name == pattern {
+			delete(r.executors, name)
+			delete(r.tools, name)
+			delete(r.wireNames, ToolWireName(name))
+			removed++
+		}
+	}
+	return removed
+}
+
+// List returns the registered tools sorted by name
+func (r *ToolRegistry) List() []RegisteredTool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]RegisteredTool, 0, len(r.executors))
+	for name := range r.executors {
+		registered := RegisteredTool{Name: name}
+		if tool, ok := r.tools[name]; ok {
+			registered.Tool = &tool
+		}
+		list = append(list, registered)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Tools returns the definitions of the tools registered with a schema, sorted by name,
+// for a request's tools. With patterns, only tools matching one of them are included.
+func (r *ToolRegistry) Tools(patterns ...string) []models.Tool {
+	var tools []models.Tool
+	for _, registered := range r.List() {
+		if registered.Tool != nil && matchesAny(patterns, registered.Name) {
+			tools = append(tools, *registered.Tool)
+		}
+	}
+	return tools
+}
+
+// Execute executes a tool call, by registered or wire name
 func (r *ToolRegistry) Execute(toolCall models.ToolCall) (string, error) {
-	executor, exists := r.executors[toolCall.Function.Name]
+	r.mu.RLock()
+	executor, exists := r.executors[r.resolveLocked(toolCall.Function.Name)]
+	r.mu.RUnlock()
 	if !exists {
 		return "", fmt.Errorf("tool %s not registered", toolCall.Function.Name)
 	}
 	return executor.Execute(toolCall)
 }
 
-// Agent represents an autonomous agent that can handle tool calls
+// has reports whether a tool is registered, by registered or wire name
+func (r *ToolRegistry) has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.executors[r.resolveLocked(name)]
+	return ok
+}
+
+// resolveLocked returns the registered name of a tool called by name
+func (r *ToolRegistry) resolveLocked(name string) string {
+	if _, ok := r.executors[name]; ok {
+		return name
+	}
+	if registered, ok := r.wireNames[name]; ok {
+		return registered
+	}
+	return name
+}
+
+// matchesAny reports whether name matches one of patterns, or whether there are none
+func matchesAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok || pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ToolNamespace registers tools in a registry under a common prefix
+type ToolNamespace struct {
+	registry *ToolRegistry
+	prefix   string
+}
+
+// Register registers a tool executor without a schema as prefix.name
+func (n *ToolNamespace) Register(name string, executor ToolExecutor) {
+	n.registry.Register(n.prefix+name, executor)
+}
+
+// RegisterFunc registers a tool executor function as prefix.name
+func (n *ToolNamespace) RegisterFunc(name string, fn func(models.ToolCall) (string, error)) {
+	n.registry.RegisterFunc(n.prefix+name, fn)
+}
+
+// RegisterTool registers a tool executor with its definition as prefix.name
+func (n *ToolNamespace) RegisterTool(tool models.Tool, executor ToolExecutor) {
+	tool.Function.Name = n.prefix + tool.Function.Name
+	n.registry.RegisterTool(tool, executor)
+}
+
+// Unregister removes every tool in the namespace
+func (n *ToolNamespace) Unregister() int {
+	return n.registry.Unregister(n.prefix + "*")
+}
+
+// Agent represents an autonomous agent that can handle tool calls. Runs may execute
+// concurrently; configure the agent with setters such as SetToolCache before starting them.
 type Agent struct {
 	client   *Client
 	registry *ToolRegistry
 	model    string
+
+	// Defaults for every run, set by AgentFromConfig
+	profile *agentProfile
+
+	// Tool results shared across runs, nil when disabled
+	toolCache ToolCache
 }
 
 // NewAgent creates a new agent
@@ -70,32 +248,264 @@ func NewAgent(client *Client, model string) *Agent {
 
 // RegisterTool registers a tool with the agent
 func (a *Agent) RegisterTool(tool models.Tool, executor ToolExecutor) {
-	a.registry.Register(tool.Function.Name, executor)
+	a.registry.RegisterTool(tool, executor)
 }
 
 // RegisterToolFunc registers a tool function with the agent
 func (a *Agent) RegisterToolFunc(tool models.Tool, fn func(models.ToolCall) (string, error)) {
-	a.registry.RegisterFunc(tool.Function.Name, fn)
+	a.registry.RegisterTool(tool, ToolExecutorFunc(fn))
+}
+
+// Registry returns the agent's tool registry, e.g. to register namespaced tools or
+// unregister tools between runs
+func (a *Agent) Registry() *ToolRegistry {
+	return a.registry
+}
+
+// SetToolCache sets a cache for tool results shared across runs, so identical tool
+// calls (same name and arguments) are only executed once. Pass nil to disable it. A
+// MemoryToolCache without a Clock expires entries with the client's clock.
+func (a *Agent) SetToolCache(cache ToolCache) {
+	if memory, ok := cache.(*MemoryToolCache); ok && memory.Clock == nil && a.client != nil {
+		memory.Clock = a.client.clock
+	}
+	a.toolCache = cache
+}
+
+// ToolCache stores tool results by a key derived from the tool name and arguments
+type ToolCache interface {
+	Get(key string) (string, bool)
+	Set(key, result string)
+}
+
+// MemoryToolCache is an in-memory ToolCache safe for concurrent use
+type MemoryToolCache struct {
+	// Clock expires entries. Defaults to SystemClock.
+	Clock Clock
+
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]toolCacheEntry
+}
+
+type toolCacheEntry struct {
+	result  string
+	expires time.Time
+}
+
+// NewMemoryToolCache creates an in-memory tool cache. Entries expire after ttl,
+// or never when ttl is zero.
+func NewMemoryToolCache(ttl time.Duration) *MemoryToolCache {
+	return &MemoryToolCache{
+		ttl:     ttl,
+		entries: make(map[string]toolCacheEntry),
+	}
+}
+
+// Get implements ToolCache
+func (c *MemoryToolCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !entry.expires.IsZero() && c.now().After(entry.expires) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.result, true
+}
+
+// Set implements ToolCache
+func (c *MemoryToolCache) Set(key, result string) {
+	entry := toolCacheEntry{result: result}
+	if c.ttl > 0 {
+		entry.expires = c.now().Add(c.ttl)
+	}
+
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+}
+
+func (c *MemoryToolCache) now() time.Time {
+	if c.Clock != nil {
+		return c.Clock.Now()
+	}
+	return SystemClock.Now()
+}
+
+var (
+	// ErrMaxIterations is returned when the model still requests tools after MaxIterations
+	// turns and RunLimits.FailOnMaxIterations is set
+	ErrMaxIterations = stderrors.New("agent reached max iterations")
+
+	// ErrMaxToolCalls is returned when a run exceeds MaxToolCalls
+	ErrMaxToolCalls = stderrors.New("agent exceeded max tool calls")
+
+	// ErrToolLoop is returned when the model repeats an identical tool call more than MaxRepeatedToolCalls times
+	ErrToolLoop = stderrors.New("agent repeated an identical tool call")
+)
+
+// AgentRunError is returned when a run is stopped by one of its limits.
+// It holds the conversation up to that point for inspection.
+type AgentRunError struct {
+	Err        error
+	Iterations int
+	ToolCalls  int
+	Messages   []models.Message
+}
+
+// Error implements the error interface
+func (e *AgentRunError) Error() string {
+	return fmt.Sprintf("agent run stopped after %d iterations and %d tool calls: %v", e.Iterations, e.ToolCalls, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *AgentRunError) Unwrap() error {
+	return e.Err
+}
+
+// RunLimits are safeguards against runaway tool loops. Zero values disable a limit.
+type RunLimits struct {
+	// MaxToolCalls limits the total number of tool calls in a run
+	MaxToolCalls int
+
+	// MaxRepeatedToolCalls limits how often the same tool may be called with identical arguments
+	MaxRepeatedToolCalls int
+
+	// Timeout limits the wall-clock duration of a run
+	Timeout time.Duration
+
+	// FailOnMaxIterations returns ErrMaxIterations when the model still requests tools
+	// after MaxIterations turns. By default the run ends there with the conversation so
+	// far and a nil error.
+	FailOnMaxIterations bool
 }
 
 // RunOptions contains options for running the agent
 type RunOptions struct {
 	MaxIterations int
-	Tools         []models.Tool
-	ToolChoice    models.ToolChoice
+
+	// Tools are the tools offered to the model. Defaults to the agent config's tools, or
+	// else the registry's Tools, so what's offered can't drift from what's executable.
+	Tools      []models.Tool
+	ToolChoice models.ToolChoice
+	RunLimits
+
+	// OnText and OnReasoning receive text and reasoning deltas as they are generated.
+	// Setting either streams every model turn, including those after tool calls.
+	OnText      func(delta string)
+	OnReasoning func(delta string)
+
+	// OnToolCall is called with the result of every executed tool call
+	OnToolCall func(toolCall models.ToolCall, result string) error
+
+	// CacheToolResults reuses the result of identical tool calls (same name and
+	// arguments) within the run instead of executing the tool again
+	CacheToolResults bool
 }
 
 // Run runs the agent with the given messages
 func (a *Agent) Run(ctx context.Context, messages []models.Message, opts RunOptions) ([]models.Message, error) {
+	var callbacks *turnCallbacks
+	if opts.OnText != nil || opts.OnReasoning != nil {
+		callbacks = &turnCallbacks{onText: opts.OnText, onReasoning: opts.OnReasoning}
+	}
+	return a.run(ctx, messages, opts, callbacks)
+}
+
+// StreamOptions contains options for streaming with tool support
+type StreamOptions struct {
+	MaxIterations int
+	Tools         []models.Tool
+	ToolChoice    models.ToolChoice
+	RunLimits
+
+	OnChunk     func(chunk *models.ChatCompletionResponse) error
+	OnText      func(delta string)
+	OnReasoning func(delta string)
+	OnToolCall  func(toolCall models.ToolCall, result string) error
+
+	CacheToolResults bool
+}
+
+// RunStream runs the agent with streaming support. Every model turn is streamed,
+// including the turns that follow tool calls, and the returned messages hold the
+// complete transcript.
+func (a *Agent) RunStream(ctx context.Context, messages []models.Message, opts StreamOptions) ([]models.Message, error) {
+	callbacks := &turnCallbacks{
+		onChunk:     opts.OnChunk,
+		onText:      opts.OnText,
+		onReasoning: opts.OnReasoning,
+	}
+	runOpts := RunOptions{
+		MaxIterations:    opts.MaxIterations,
+		Tools:            opts.Tools,
+		ToolChoice:       opts.ToolChoice,
+		RunLimits:        opts.RunLimits,
+		OnToolCall:       opts.OnToolCall,
+		CacheToolResults: opts.CacheToolResults,
+	}
+	return a.run(ctx, messages, runOpts, callbacks)
+}
+
+// turnCallbacks receive the output of a streamed model turn
+type turnCallbacks struct {
+	onChunk     func(chunk *models.ChatCompletionResponse) error
+	onText      func(delta string)
+	onReasoning func(delta string)
+}
+
+// run is the tool loop shared by Run and RunStream. Turns are streamed when callbacks is not nil.
+func (a *Agent) run(ctx context.Context, messages []models.Message, opts RunOptions, callbacks *turnCallbacks) ([]models.Message, error) {
+	if a.profile != nil {
+		opts = a.profile.applyRunDefaults(opts)
+		messages = a.profile.withSystemPrompt(messages)
+	}
+	if len(opts.Tools) == 0 {
+		opts.Tools = a.registry.Tools()
+	}
 	if opts.MaxIterations <= 0 {
 		opts.MaxIterations = 10
 	}
 
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
 	// Copy messages to avoid modifying the original
 	conversationMessages := make([]models.Message, len(messages))
 	copy(conversationMessages, messages)
 
-	for iteration := 0; iteration < opts.MaxIterations; iteration++ {
+	toolCalls := 0
+	repeated := make(map[string]int)
+
+	var runCache map[string]string
+	if opts.CacheToolResults {
+		runCache = make(map[string]string)
+	}
+	stop := func(iteration int, err error) ([]models.Message, error) {
+		return conversationMessages, &AgentRunError{
+			Err:        err,
+			Iterations: iteration,
+			ToolCalls:  toolCalls,
+			Messages:   conversationMessages,
+		}
+	}
+
+	for iteration := 0; ; iteration++ {
+		if iteration == opts.MaxIterations {
+			if !opts.FailOnMaxIterations {
+				return conversationMessages, nil
+			}
+			return stop(iteration, ErrMaxIterations)
+		}
+
 		// Create request
 		req := models.ChatCompletionRequest{
 			Model:      a.model,
@@ -103,20 +513,38 @@ func (a *Agent) Run(ctx context.Context, messages []models.Message, opts RunOpti
 			Tools:      opts.Tools,
 			ToolChoice: opts.ToolChoice,
 		}
+		if a.profile != nil {
+			a.profile.applyRequestDefaults(&req)
+		}
 
 		// Get response
-		resp, err := a.client.CreateChatCompletion(ctx, req)
-		if err != nil {
-			return conversationMessages, fmt.Errorf("iteration %d: %w", iteration, err)
-		}
+		var assistantMessage *models.Message
+		if callbacks != nil {
+			message, err := a.streamTurn(ctx, req, callbacks)
+			if err != nil {
+				if stderrors.Is(ctx.Err(), context.DeadlineExceeded) && opts.Timeout > 0 {
+					return stop(iteration, fmt.Errorf("iteration %d: %w", iteration, err))
+				}
+				return conversationMessages, fmt.Errorf("iteration %d: %w", iteration, err)
+			}
+			assistantMessage = &message
+		} else {
+			resp, err := a.client.CreateChatCompletion(ctx, req)
+			if err != nil {
+				if stderrors.Is(ctx.Err(), context.DeadlineExceeded) && opts.Timeout > 0 {
+					return stop(iteration, fmt.Errorf("iteration %d: %w", iteration, err))
+				}
+				return conversationMessages, fmt.Errorf("iteration %d: %w", iteration, err)
+			}
 
-		if len(resp.Choices) == 0 {
-			return conversationMessages, fmt.Errorf("no choices in response")
-		}
+			if len(resp.Choices) == 0 {
+				return conversationMessages, fmt.Errorf("no choices in response")
+			}
 
-		assistantMessage := resp.Choices[0].Message
-		if assistantMessage == nil {
-			return conversationMessages, fmt.Errorf("no message in choice")
+			assistantMessage = resp.Choices[0].Message
+			if assistantMessage == nil {
+				return conversationMessages, fmt.Errorf("no message in choice")
+			}
 		}
 
 		// Add assistant message to conversation
@@ -125,138 +553,118 @@ func (a *Agent) Run(ctx context.Context, messages []models.Message, opts RunOpti
 		// Check if there are tool calls
 		if len(assistantMessage.ToolCalls) == 0 {
 			// No tool calls, we're done
-			break
+			return conversationMessages, nil
 		}
 
 		// Execute tool calls
 		for _, toolCall := range assistantMessage.ToolCalls {
-			result, err := a.registry.Execute(toolCall)
+			toolCalls++
+			if opts.MaxToolCalls > 0 && toolCalls > opts.MaxToolCalls {
+				return stop(iteration+1, ErrMaxToolCalls)
+			}
+
+			key := toolCallKey(toolCall)
+			repeated[key]++
+			if opts.MaxRepeatedToolCalls > 0 && repeated[key] > opts.MaxRepeatedToolCalls {
+				return stop(iteration+1, fmt.Errorf("%w: %s(%s)", ErrToolLoop, toolCall.Function.Name, toolCall.Function.Arguments))
+			}
+
+			result, err := a.executeTool(toolCall, key, runCache)
 			if err != nil {
 				result = fmt.Sprintf("Error executing tool: %v", err)
 			}
 
+			// Call tool callback if provided
+			if opts.OnToolCall != nil {
+				if err := opts.OnToolCall(toolCall, result); err != nil {
+					return conversationMessages, err
+				}
+			}
+
 			// Add tool result to conversation
 			toolMessage := models.NewToolMessage(toolCall.ID, toolCall.Function.Name, result)
 			conversationMessages = append(conversationMessages, toolMessage)
 		}
 	}
-
-	return conversationMessages, nil
 }
 
-// StreamOptions contains options for streaming with tool support
-type StreamOptions struct {
-	Tools      []models.Tool
-	ToolChoice models.ToolChoice
-	OnChunk    func(chunk *models.ChatCompletionResponse) error
-	OnToolCall func(toolCall models.ToolCall, result string) error
-}
+// executeTool executes a tool call unless its result is cached. Only successful results are cached.
+func (a *Agent) executeTool(toolCall models.ToolCall, key string, runCache map[string]string) (string, error) {
+	if result, ok := runCache[key]; ok {
+		return result, nil
+	}
+	if a.toolCache != nil {
+		if result, ok := a.toolCache.Get(key); ok {
+			if runCache != nil {
+				runCache[key] = result
+			}
+			return result, nil
+		}
+	}
 
-// RunStream runs the agent with streaming support
-func (a *Agent) RunStream(ctx context.Context, messages []models.Message, opts StreamOptions) ([]models.Message, error) {
-	conversationMessages := make([]models.Message, len(messages))
-	copy(conversationMessages, messages)
+	result, err := a.registry.Execute(toolCall)
+	if err != nil {
+		return "", err
+	}
+
+	if runCache != nil {
+		runCache[key] = result
+	}
+	if a.toolCache != nil {
+		a.toolCache.Set(key, result)
+	}
+	return result, nil
+}
 
-	// Create request
-	req := models.ChatCompletionRequest{
-		Model:      a.model,
-		Messages:   conversationMessages,
-		Tools:      opts.Tools,
-		ToolChoice: opts.ToolChoice,
-		Stream:     true,
+// toolCallKey identifies a tool call by name and arguments, ignoring JSON formatting
+func toolCallKey(toolCall models.ToolCall) string {
+	args := []byte(toolCall.Function.Arguments)
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, args); err == nil {
+		args = compact.Bytes()
 	}
+	return toolCall.Function.Name + "\x00" + string(args)
+}
 
-	// Create stream
+// streamTurn streams a single model turn, passing its output to the callbacks, and
+// returns the complete assistant message
+func (a *Agent) streamTurn(ctx context.Context, req models.ChatCompletionRequest, callbacks *turnCallbacks) (models.Message, error) {
 	stream, err := a.client.CreateChatCompletionStream(ctx, req)
 	if err != nil {
-		return conversationMessages, err
+		return models.Message{}, err
 	}
 	defer stream.Close()
 
-	// Accumulate assistant message
-	var assistantMessage models.Message
-	assistantMessage.Role = models.RoleAssistant
-	var contentBuilder []byte
-	var toolCalls []models.ToolCall
-
-	// Read stream
+	accumulator := streaming.NewAccumulator()
 	for {
 		chunk, err := stream.Read()
-		if err != nil {
-			if err.Error() == "EOF" {
-				break
-			}
-			return conversationMessages, err
-		}
-
-		// Call chunk callback if provided
-		if opts.OnChunk != nil {
-			if err := opts.OnChunk(chunk); err != nil {
-				return conversationMessages, err
-			}
-		}
-
-		// Process chunk
-		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta != nil {
-			delta := chunk.Choices[0].Delta
-
-			// Accumulate content
-			if content, err := delta.GetTextContent(); err == nil && content != "" {
-				contentBuilder = append(contentBuilder, content...)
-			}
-
-			// Accumulate tool calls
-			if len(delta.ToolCalls) > 0 {
-				toolCalls = append(toolCalls, delta.ToolCalls...)
-			}
+		if err == io.EOF {
+			break
 		}
-	}
-
-	// Set final content
-	if len(contentBuilder) > 0 {
-		contentJSON, _ := json.Marshal(string(contentBuilder))
-		assistantMessage.Content = contentJSON
-	}
-	assistantMessage.ToolCalls = toolCalls
-
-	// Add assistant message
-	conversationMessages = append(conversationMessages, assistantMessage)
-
-	// Execute tool calls if any
-	for _, toolCall := range toolCalls {
-		result, err := a.registry.Execute(toolCall)
 		if err != nil {
-			result = fmt.Sprintf("Error executing tool: %v", err)
+			return models.Message{}, err
 		}
 
-		// Call tool callback if provided
-		if opts.OnToolCall != nil {
-			if err := opts.OnToolCall(toolCall, result); err != nil {
-				return conversationMessages, err
+		if callbacks.onChunk != nil {
+			if err := callbacks.onChunk(chunk); err != nil {
+				return models.Message{}, err
 			}
 		}
+		accumulator.Add(chunk)
 
-		// Add tool result
-		toolMessage := models.NewToolMessage(toolCall.ID, toolCall.Function.Name, result)
-		conversationMessages = append(conversationMessages, toolMessage)
-	}
-
-	// If there were tool calls, make another request to get the final response
-	if len(toolCalls) > 0 {
-		finalReq := models.ChatCompletionRequest{
-			Model:    a.model,
-			Messages: conversationMessages,
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta == nil {
+			continue
 		}
-
-		finalResp, err := a.client.CreateChatCompletion(ctx, finalReq)
-		if err != nil {
-			return conversationMessages, err
+		delta := chunk.Choices[0].Delta
+		if callbacks.onReasoning != nil && delta.Reasoning != "" {
+			callbacks.onReasoning(delta.Reasoning)
 		}
-
-		if len(finalResp.Choices) > 0 && finalResp.Choices[0].Message != nil {
-			conversationMessages = append(conversationMessages, *finalResp.Choices[0].Message)
+		if callbacks.onText != nil {
+			if text, err := delta.GetTextContent(); err == nil && text != "" {
+				callbacks.onText(text)
+			}
 		}
 	}
 
-	return conversationMessages, nil
+	return accumulator.Message(), nil
 }
@@ -0,0 +1,309 @@
+package pkg_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+// queryTool returns a tool definition taking a query
+func queryTool(t *testing.T, name string) models.Tool {
+	t.Helper()
+	tool, err := models.NewTool(name, "Looks something up", map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"q": map[string]interface{}{"type": "string"}},
+	})
+	require.NoError(t, err)
+	return *tool
+}
+
+func echoName(call models.ToolCall) (string, error) {
+	return "ran " + call.Function.Name, nil
+}
+
+func TestToolRegistryNamespaces(t *testing.T) {
+	registry := pkg.NewToolRegistry()
+	registry.RegisterTool(queryTool(t, "search.web"), pkg.ToolExecutorFunc(echoName))
+	registry.Namespace("search").RegisterTool(queryTool(t, "news"), pkg.ToolExecutorFunc(echoName))
+	registry.Namespace("db").RegisterFunc("query", echoName)
+
+	list := registry.List()
+	require.Len(t, list, 3)
+	assert.Equal(t, "db.query", list[0].Name)
+	assert.Nil(t, list[0].Tool, "registered without a schema")
+	assert.Equal(t, "search.news", list[1].Name)
+	assert.Equal(t, "search__news", list[1].Tool.Function.Name)
+
+	tools := registry.Tools()
+	require.Len(t, tools, 2)
+	assert.Equal(t, "search__web", tools[1].Function.Name)
+	req := models.NewChatRequest("m", models.WithUserMessage("Hi"), models.WithTools(tools...))
+	assert.NoError(t, req.Validate(), "wire names are valid tool names")
+	assert.Len(t, registry.Tools("db.*"), 0)
+
+	// Calls by wire or registered name both execute
+	for _, name := range []string{"search__web", "search.web"} {
+		result, err := registry.Execute(models.ToolCall{Function: models.FunctionCall{Name: name}})
+		require.NoError(t, err)
+		assert.Equal(t, "ran "+name, result)
+	}
+
+	assert.Equal(t, 2, registry.Unregister("search.*"))
+	_, err := registry.Execute(models.ToolCall{Function: models.FunctionCall{Name: "search__web"}})
+	assert.Error(t, err)
+	assert.Equal(t, 1, registry.Namespace("db").Unregister())
+	assert.Empty(t, registry.List())
+}
+
+func TestAgentOffersRegisteredTools(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	call := openroutertest.NewTextResponse("")
+	call.Choices[0].Message.ToolCalls = []models.ToolCall{{ID: "call_1", Type: "function",
+		Function: models.FunctionCall{Name: "search__web", Arguments: `{"q":"go"}`}}}
+	srv.EnqueueChat(openroutertest.Reply{Response: call}, openroutertest.TextReply("Found it"))
+
+	agent := pkg.NewAgent(srv.Client(), "m")
+	agent.Registry().Namespace("search").RegisterTool(queryTool(t, "web"), pkg.ToolExecutorFunc(echoName))
+	messages, err := agent.Run(context.Background(), []models.Message{models.NewTextMessage(models.RoleUser, "Search go")}, pkg.RunOptions{})
+	require.NoError(t, err)
+
+	result, _ := messages[2].GetTextContent()
+	assert.Equal(t, "ran search__web", result)
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	require.Len(t, sent.Tools, 1)
+	assert.Equal(t, "search__web", sent.Tools[0].Function.Name)
+}
+
+// enqueueWeatherRun scripts a run in which the model looks up one city, then two more
+// in parallel, and then answers
+func enqueueWeatherRun(srv *openroutertest.Server) {
+	srv.EnqueueChat(
+		openroutertest.ToolCallReply(openroutertest.NewToolCall("call_1", "weather", map[string]string{"q": "Paris"})),
+		openroutertest.ToolCallReply(
+			openroutertest.NewToolCall("call_2", "weather", map[string]string{"q": "Rome"}),
+			openroutertest.NewToolCall("call_3", "weather", map[string]string{"q": "Oslo"}),
+		),
+		openroutertest.TextReply("Rome is warmest."),
+	)
+}
+
+// weatherAgent returns an agent with a weather tool answering from a fixed table
+func weatherAgent(t *testing.T, client *pkg.Client) *pkg.Agent {
+	t.Helper()
+	temperatures := map[string]string{`{"q":"Paris"}`: "18C", `{"q":"Rome"}`: "24C", `{"q":"Oslo"}`: "9C"}
+	agent := pkg.NewAgent(client, "m")
+	agent.RegisterToolFunc(queryTool(t, "weather"), func(call models.ToolCall) (string, error) {
+		return temperatures[call.Function.Arguments], nil
+	})
+	return agent
+}
+
+// toolEvent is a tool call reported to OnToolCall
+type toolEvent struct{ id, result string }
+
+// assertWeatherTranscript checks the messages and requests of a weather run
+func assertWeatherTranscript(t *testing.T, srv *openroutertest.Server, messages []models.Message, events []toolEvent) {
+	t.Helper()
+	roles := make([]models.Role, len(messages))
+	for i, msg := range messages {
+		roles[i] = msg.Role
+	}
+	assert.Equal(t, []models.Role{
+		models.RoleUser,
+		models.RoleAssistant, models.RoleTool,
+		models.RoleAssistant, models.RoleTool, models.RoleTool,
+		models.RoleAssistant,
+	}, roles)
+	final, _ := messages[len(messages)-1].GetTextContent()
+	assert.Equal(t, "Rome is warmest.", final)
+	assert.Equal(t, "call_3", messages[5].ToolCallID)
+
+	assert.Equal(t, []toolEvent{{"call_1", "18C"}, {"call_2", "24C"}, {"call_3", "9C"}}, events)
+
+	// Every turn sees the tool results so far
+	requests := srv.Requests()
+	require.Len(t, requests, 3)
+	last, err := requests[2].ChatRequest()
+	require.NoError(t, err)
+	require.Len(t, last.Messages, 6)
+	result, _ := last.Messages[4].GetTextContent()
+	assert.Equal(t, "24C", result)
+}
+
+func TestAgentRunToolLoop(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	enqueueWeatherRun(srv)
+
+	var events []toolEvent
+	messages, err := weatherAgent(t, srv.Client()).Run(context.Background(), openroutertest.NewMessages("Which city is warmest?"), pkg.RunOptions{
+		OnToolCall: func(call models.ToolCall, result string) error {
+			events = append(events, toolEvent{call.ID, result})
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assertWeatherTranscript(t, srv, messages, events)
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.False(t, sent.Stream)
+}
+
+func TestAgentRunStreamToolLoop(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	enqueueWeatherRun(srv)
+
+	var events []toolEvent
+	var text strings.Builder
+	chunks := 0
+	messages, err := weatherAgent(t, srv.Client()).RunStream(context.Background(), openroutertest.NewMessages("Which city is warmest?"), pkg.StreamOptions{
+		OnChunk: func(chunk *models.ChatCompletionResponse) error {
+			chunks++
+			return nil
+		},
+		OnText: func(delta string) { text.WriteString(delta) },
+		OnToolCall: func(call models.ToolCall, result string) error {
+			events = append(events, toolEvent{call.ID, result})
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assertWeatherTranscript(t, srv, messages, events)
+	assert.Equal(t, "Rome is warmest.", text.String(), "only the final turn has text")
+	assert.Greater(t, chunks, 3, "every turn is streamed")
+	for _, request := range srv.Requests() {
+		sent, err := request.ChatRequest()
+		require.NoError(t, err)
+		assert.True(t, sent.Stream)
+	}
+}
+
+func TestAgentRunLimits(t *testing.T) {
+	tests := []struct {
+		name string
+
+		// sameArgs makes the model repeat an identical call instead of varying its arguments
+		sameArgs   bool
+		opts       pkg.RunOptions
+		want       error
+		iterations int
+	}{
+		{name: "max iterations", opts: pkg.RunOptions{MaxIterations: 2, RunLimits: pkg.RunLimits{FailOnMaxIterations: true}}, want: pkg.ErrMaxIterations, iterations: 2},
+		{name: "max tool calls", opts: pkg.RunOptions{RunLimits: pkg.RunLimits{MaxToolCalls: 3}}, want: pkg.ErrMaxToolCalls, iterations: 4},
+		{name: "tool loop", sameArgs: true, opts: pkg.RunOptions{RunLimits: pkg.RunLimits{MaxRepeatedToolCalls: 2}}, want: pkg.ErrToolLoop, iterations: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The model never stops calling tools
+			srv := openroutertest.NewServer()
+			defer srv.Close()
+			calls := 0
+			srv.SetChatHandler(func(req models.ChatCompletionRequest) openroutertest.Reply {
+				calls++
+				query := fmt.Sprintf("page %d", calls)
+				if tt.sameArgs {
+					query = "page 1"
+				}
+				return openroutertest.ToolCallReply(openroutertest.NewToolCall(fmt.Sprintf("call_%d", calls), "search", map[string]string{"q": query}))
+			})
+			agent := pkg.NewAgent(srv.Client(), "m")
+			agent.RegisterToolFunc(queryTool(t, "search"), echoName)
+
+			_, err := agent.Run(context.Background(), openroutertest.NewMessages("Search"), tt.opts)
+			assert.ErrorIs(t, err, tt.want)
+			for _, other := range []error{pkg.ErrMaxIterations, pkg.ErrMaxToolCalls, pkg.ErrToolLoop} {
+				if other != tt.want {
+					assert.NotErrorIs(t, err, other)
+				}
+			}
+			var runErr *pkg.AgentRunError
+			require.ErrorAs(t, err, &runErr)
+			assert.Equal(t, tt.iterations, runErr.Iterations)
+			assert.NotEmpty(t, runErr.Messages, "the conversation so far is kept")
+		})
+	}
+}
+
+func TestAgentRunMaxIterationsWithoutError(t *testing.T) {
+	// The model never stops calling tools
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.SetChatHandler(func(req models.ChatCompletionRequest) openroutertest.Reply {
+		return openroutertest.ToolCallReply(openroutertest.NewToolCall(fmt.Sprintf("call_%d", len(req.Messages)), "search", map[string]string{"q": "more"}))
+	})
+	agent := pkg.NewAgent(srv.Client(), "m")
+	agent.RegisterToolFunc(queryTool(t, "search"), echoName)
+
+	// Unless FailOnMaxIterations is set, the cap ends the run like a final answer would
+	messages, err := agent.Run(context.Background(), openroutertest.NewMessages("Search"), pkg.RunOptions{MaxIterations: 2})
+	require.NoError(t, err)
+	assert.Len(t, srv.Requests(), 2)
+	require.Len(t, messages, 5, "the user message, then a tool call and its result per iteration")
+	assert.Equal(t, models.RoleTool, messages[4].Role)
+}
+
+func TestMemoryToolCache(t *testing.T) {
+	clock := openroutertest.NewFakeClock(time.Now())
+	cache := pkg.NewMemoryToolCache(time.Minute)
+	cache.Clock = clock
+
+	_, ok := cache.Get("weather")
+	assert.False(t, ok, "miss")
+	cache.Set("weather", "18C")
+	result, ok := cache.Get("weather")
+	assert.True(t, ok, "hit")
+	assert.Equal(t, "18C", result)
+
+	clock.Advance(time.Minute + time.Second)
+	_, ok = cache.Get("weather")
+	assert.False(t, ok, "expired")
+
+	forever := pkg.NewMemoryToolCache(0)
+	forever.Clock = clock
+	forever.Set("weather", "18C")
+	clock.Advance(24 * time.Hour)
+	_, ok = forever.Get("weather")
+	assert.True(t, ok, "entries never expire without a ttl")
+}
+
+func TestAgentToolCacheUsesClientClock(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	clock := openroutertest.NewFakeClock(time.Now())
+	executions := 0
+	agent := pkg.NewAgent(srv.Client(pkg.WithClock(clock)), "m")
+	agent.RegisterToolFunc(queryTool(t, "weather"), func(call models.ToolCall) (string, error) {
+		executions++
+		return "18C", nil
+	})
+	agent.SetToolCache(pkg.NewMemoryToolCache(time.Minute))
+
+	run := func() {
+		t.Helper()
+		srv.EnqueueChat(
+			openroutertest.ToolCallReply(openroutertest.NewToolCall("call_1", "weather", map[string]string{"q": "Paris"})),
+			openroutertest.TextReply("18C in Paris."),
+		)
+		_, err := agent.Run(context.Background(), openroutertest.NewMessages("Weather in Paris?"), pkg.RunOptions{})
+		require.NoError(t, err)
+	}
+	run()
+	run()
+	assert.Equal(t, 1, executions, "the second run is served from the cache")
+
+	clock.Advance(2 * time.Minute)
+	run()
+	assert.Equal(t, 2, executions, "entries expire on the client's clock")
+}
@@ -0,0 +1,106 @@
+package pkg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestTranslate(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply(`{"translation":"Bonjour, où est ma commande ?","source_language":"en"}`))
+
+	translator := pkg.NewTranslationHelper(srv.Client())
+	translation, err := translator.Translate(context.Background(), "Hello, where is my order?", "French", &pkg.TranslateOptions{
+		Glossary:     map[string]string{"order": "commande", "Hello": "Bonjour"},
+		Formality:    "formal",
+		Instructions: "Keep punctuation French.",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Bonjour, où est ma commande ?", translation.Text)
+	assert.Equal(t, "en", translation.SourceLanguage)
+	require.NotNil(t, translation.Response)
+
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, pkg.DefaultTranslationModel, sent.Model)
+	assert.Equal(t, 0.0, *sent.Temperature)
+	require.NotNil(t, sent.ResponseFormat)
+	assert.Equal(t, "translation", sent.ResponseFormat.JSONSchema.Name)
+	require.Len(t, sent.Messages, 2)
+	system, _ := sent.Messages[0].GetTextContent()
+	assert.Contains(t, system, "into French")
+	assert.Contains(t, system, "Use a formal register.")
+	assert.Contains(t, system, "\n- Hello: Bonjour\n- order: commande", "glossary terms are sorted")
+	assert.Contains(t, system, "Keep punctuation French.")
+	user, _ := sent.Messages[1].GetTextContent()
+	assert.Equal(t, "Hello, where is my order?", user)
+}
+
+func TestTranslateSourceLanguage(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply(`{"translation":"Hallo"}`))
+
+	translator := pkg.NewTranslationHelper(srv.Client())
+	translator.SetDefaultModel("anthropic/claude-3-haiku")
+	translation, err := translator.Translate(context.Background(), "Hello", "de", &pkg.TranslateOptions{SourceLang: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, "en", translation.SourceLanguage, "the given source language fills in for the model's")
+
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic/claude-3-haiku", sent.Model)
+	system, _ := sent.Messages[0].GetTextContent()
+	assert.Contains(t, system, "The text is in en.")
+}
+
+func TestTranslateFailures(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	translator := pkg.NewTranslationHelper(srv.Client())
+	ctx := context.Background()
+
+	_, err := translator.Translate(ctx, "Hello", "", nil)
+	assert.ErrorContains(t, err, "target language is required")
+	_, err = translator.Translate(ctx, "", "fr", nil)
+	assert.ErrorContains(t, err, "text is required")
+	assert.Empty(t, srv.Requests())
+
+	srv.EnqueueChat(openroutertest.TextReply(`{"translation":"  ","source_language":"en"}`))
+	_, err = translator.Translate(ctx, "Hello", "fr", nil)
+	assert.ErrorContains(t, err, "empty translation")
+
+	refused := openroutertest.NewTextResponse("")
+	refusal := "I can't help with that."
+	refused.Choices[0].Message.Refusal = &refusal
+	srv.EnqueueChat(openroutertest.Reply{Response: refused})
+	_, err = translator.Translate(ctx, "Hello", "fr", nil)
+	assert.ErrorContains(t, err, "model refused: I can't help with that.")
+
+	srv.EnqueueChat(openroutertest.TextReply("Bonjour"))
+	_, err = translator.Translate(ctx, "Hello", "fr", nil)
+	assert.ErrorContains(t, err, "failed to unmarshal response")
+}
+
+func TestDetectLanguage(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+	srv.EnqueueChat(openroutertest.TextReply(`{"language":"PT","name":"Portuguese","confidence":0.97}`))
+
+	detection, err := pkg.NewTranslationHelper(srv.Client()).DetectLanguage(context.Background(), "Onde está o meu pedido?")
+	require.NoError(t, err)
+	assert.Equal(t, "pt", detection.Language, "codes are normalized to lower case")
+	assert.Equal(t, "Portuguese", detection.Name)
+	assert.Equal(t, 0.97, detection.Confidence)
+
+	sent, err := srv.Requests()[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "language_detection", sent.ResponseFormat.JSONSchema.Name)
+}
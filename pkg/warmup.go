@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// WarmupOptions configures Client.WarmupWithOptions
+type WarmupOptions struct {
+	// Connections is the number of connections opened in parallel, for clients that will
+	// send concurrent requests over HTTP/1.1. HTTP/2 shares one connection. Defaults to 1
+	// and is capped by the transport's idle connection limit.
+	Connections int
+
+	// ListModels also calls ListModels, warming the API's side of the path and
+	// checking the API key
+	ListModels bool
+}
+
+// Warmup resolves the API host and opens a connection to it, including the TLS
+// handshake, so the first request doesn't pay for them. Call it during startup, e.g. in
+// a serverless cold start.
+func (c *Client) Warmup(ctx context.Context) error {
+	return c.WarmupWithOptions(ctx, WarmupOptions{})
+}
+
+// WarmupWithOptions is Warmup with several connections or an API call
+func (c *Client) WarmupWithOptions(ctx context.Context, opts WarmupOptions) error {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+	if _, err := net.DefaultResolver.LookupHost(ctx, base.Hostname()); err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", base.Hostname(), err)
+	}
+
+	connections := opts.Connections
+	if connections <= 0 {
+		connections = 1
+	}
+	if transport, ok := c.httpClient.Transport.(*http.Transport); ok && transport.MaxIdleConnsPerHost > 0 && connections > transport.MaxIdleConnsPerHost {
+		connections = transport.MaxIdleConnsPerHost
+	}
+
+	// Each request holds its connection until every request has a response, so they
+	// can't share one; once their bodies are drained, the connections stay in the pool
+	var wg, responded sync.WaitGroup
+	errs := make([]error, connections)
+	responded.Add(connections)
+	for i := 0; i < connections; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.preconnect(ctx, &responded)
+		}(i)
+	}
+	wg.Wait()
+	if err := stderrors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to warm up connections: %w", err)
+	}
+
+	if opts.ListModels {
+		if _, err := c.ListModels(ctx, nil); err != nil {
+			return fmt.Errorf("failed to warm up API: %w", err)
+		}
+	}
+	return nil
+}
+
+// preconnect opens a connection with a GET request to the base URL; any response means
+// the connection is established. It marks responded done once the response arrives and
+// waits for the other requests before releasing the connection.
+func (c *Client) preconnect(ctx context.Context, responded *sync.WaitGroup) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL, nil)
+	if err != nil {
+		responded.Done()
+		return err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	responded.Done()
+	if err != nil {
+		return err
+	}
+	responded.Wait()
+	io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
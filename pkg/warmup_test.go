@@ -0,0 +1,51 @@
+package pkg_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg"
+	"github.com/rizome-dev/go-openrouter/pkg/models"
+	"github.com/rizome-dev/go-openrouter/pkg/openroutertest"
+)
+
+func TestWarmupOpensConnections(t *testing.T) {
+	srv := openroutertest.NewServer()
+	defer srv.Close()
+
+	var dialed atomic.Int64
+	transport := pkg.NewDefaultTransport()
+	dialer := &net.Dialer{}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed.Add(1)
+		return dialer.DialContext(ctx, network, addr)
+	}
+	client := srv.Client(pkg.WithHTTPClient(&http.Client{Transport: transport}))
+	ctx := context.Background()
+
+	require.NoError(t, client.WarmupWithOptions(ctx, pkg.WarmupOptions{Connections: 3, ListModels: true}))
+	assert.Equal(t, int64(3), dialed.Load())
+	assert.Equal(t, "/models", srv.Requests()[len(srv.Requests())-1].Path)
+
+	// Requests reuse the warm connections
+	concurrent := pkg.NewConcurrentClient("key", 3, pkg.WithBaseURL(srv.URL), pkg.WithHTTPClient(&http.Client{Transport: transport}))
+	requests := make([]models.ChatCompletionRequest, 3)
+	for i := range requests {
+		requests[i] = models.NewChatRequest("m", models.WithUserMessage("hi"))
+	}
+	for _, result := range concurrent.CreateChatCompletionsConcurrent(ctx, requests) {
+		require.NoError(t, result.Error)
+	}
+	assert.LessOrEqual(t, dialed.Load(), int64(3))
+}
+
+func TestWarmupFailsForUnreachableHost(t *testing.T) {
+	client := pkg.NewClient("key", pkg.WithBaseURL("http://127.0.0.1:1"))
+	require.Error(t, client.Warmup(context.Background()))
+}
@@ -0,0 +1,275 @@
+// Package webhook signs and verifies webhook deliveries, such as the finished jobs an
+// AsyncClient posts, so integrations don't roll their own crypto. Deliveries follow the
+// Standard Webhooks scheme: an HMAC-SHA256 over the delivery ID, timestamp and body,
+// sent in the webhook-id, webhook-timestamp and webhook-signature headers. Verifiers
+// reject deliveries whose timestamp is outside a tolerance, and copies of deliveries
+// they have already verified, so a captured request can't be replayed.
+//
+//	signer := webhook.NewSigner(secret)
+//	signer.SignRequest(req, body)
+//
+//	verifier := webhook.NewVerifier(secret)
+//	http.Handle("/hooks/jobs", verifier.Middleware(handler))
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// IDHeader carries the delivery's unique ID, which stays the same when it is retried
+	IDHeader = "Webhook-Id"
+
+	// TimestampHeader carries when the delivery was signed, in Unix seconds
+	TimestampHeader = "Webhook-Timestamp"
+
+	// SignatureHeader carries space-separated "v1,<base64>" signatures
+	SignatureHeader = "Webhook-Signature"
+
+	// DefaultTolerance is how far a delivery's timestamp may be from the verifier's clock
+	DefaultTolerance = 5 * time.Minute
+
+	// MaxBodyBytes is the largest body VerifyRequest and Middleware read
+	MaxBodyBytes = 10 << 20
+)
+
+var (
+	// ErrMissingSignature is returned for deliveries without the signature headers
+	ErrMissingSignature = errors.New("webhook: missing signature headers")
+
+	// ErrInvalidSignature is returned for deliveries whose signature doesn't match
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+
+	// ErrTimestampOutOfRange is returned for deliveries signed too long ago, or in the future
+	ErrTimestampOutOfRange = errors.New("webhook: timestamp out of tolerance")
+
+	// ErrReplayed is returned for copies of a delivery that was already verified
+	ErrReplayed = errors.New("webhook: delivery already received")
+)
+
+// Signature returns the "v1,<base64>" signature of a delivery
+func Signature(secret []byte, id string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s.%d.", id, timestamp.Unix())
+	mac.Write(body)
+	return "v1," + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Signer signs outgoing deliveries
+type Signer struct {
+	secret []byte
+
+	// Now defaults to time.Now
+	Now func() time.Time
+}
+
+// NewSigner creates a signer. The secret should be at least 32 random bytes, shared with
+// the receiver.
+func NewSigner(secret []byte) *Signer {
+	return &Signer{secret: secret}
+}
+
+// Headers returns the headers of a new delivery of body, with a random ID
+func (s *Signer) Headers(body []byte) http.Header {
+	return s.HeadersWithID(newID(), body)
+}
+
+// HeadersWithID returns the headers of a delivery of body with the given ID. Reuse the
+// ID when retrying a delivery, so receivers can tell it is the same one.
+func (s *Signer) HeadersWithID(id string, body []byte) http.Header {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	timestamp := now()
+
+	header := make(http.Header)
+	header.Set(IDHeader, id)
+	header.Set(TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	header.Set(SignatureHeader, Signature(s.secret, id, timestamp, body))
+	return header
+}
+
+// SignRequest adds the headers of a new delivery of body to req
+func (s *Signer) SignRequest(req *http.Request, body []byte) {
+	for name, values := range s.Headers(body) {
+		req.Header[name] = values
+	}
+}
+
+// Verifier checks incoming deliveries. It is safe for concurrent use.
+type Verifier struct {
+	secrets [][]byte
+
+	// Tolerance is how far a delivery's timestamp may be from now. Defaults to
+	// DefaultTolerance.
+	Tolerance time.Duration
+
+	// Replays remembers verified deliveries, so each signed request is accepted once.
+	// NewVerifier sets a MemoryReplayCache; share a persistent one between instances
+	// behind a load balancer. Nil disables replay protection.
+	Replays ReplayCache
+
+	// Now defaults to time.Now
+	Now func() time.Time
+}
+
+// NewVerifier creates a verifier accepting deliveries signed with any of secrets, so a
+// secret can be rotated by accepting the old and new one for a while
+func NewVerifier(secrets ...[]byte) *Verifier {
+	return &Verifier{secrets: secrets, Replays: NewMemoryReplayCache()}
+}
+
+// Verify checks a delivery's headers against its body
+func (v *Verifier) Verify(header http.Header, body []byte) error {
+	id := header.Get(IDHeader)
+	timestampHeader := header.Get(TimestampHeader)
+	signatures := header.Get(SignatureHeader)
+	if id == "" || timestampHeader == "" || signatures == "" {
+		return ErrMissingSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	timestamp := time.Unix(seconds, 0)
+	tolerance := v.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	if skew := now().Sub(timestamp); skew > tolerance || skew < -tolerance {
+		return ErrTimestampOutOfRange
+	}
+
+	if !v.matches(id, timestamp, body, signatures) {
+		return ErrInvalidSignature
+	}
+	// Only signed deliveries are remembered, so forged ones can't fill the cache. A retry
+	// is signed afresh with a new timestamp, so only a copy of the same request is
+	// rejected; once the tolerance has passed, the timestamp check rejects it instead.
+	if v.Replays != nil && v.Replays.Seen(id+"."+timestampHeader, timestamp.Add(tolerance)) {
+		return ErrReplayed
+	}
+	return nil
+}
+
+// matches reports whether any of the signatures is valid for any secret
+func (v *Verifier) matches(id string, timestamp time.Time, body []byte, signatures string) bool {
+	for _, secret := range v.secrets {
+		expected := []byte(Signature(secret, id, timestamp, body))
+		for _, signature := range strings.Fields(signatures) {
+			if hmac.Equal([]byte(signature), expected) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VerifyRequest reads and verifies a delivery, returning its body. The request body is
+// replaced, so handlers can read it again.
+func (v *Verifier) VerifyRequest(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook body: %w", err)
+	}
+	if len(body) > MaxBodyBytes {
+		return nil, fmt.Errorf("webhook body exceeds %d bytes", MaxBodyBytes)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err := v.Verify(r.Header, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Middleware passes verified deliveries to next and rejects the rest with 401
+// Unauthorized
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := v.VerifyRequest(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ReplayCache remembers verified deliveries by key. Implementations must be safe for
+// concurrent use.
+type ReplayCache interface {
+	// Seen reports whether key was recorded before, and records it until expires
+	// otherwise, atomically
+	Seen(key string, expires time.Time) bool
+}
+
+// MemoryReplayCache is an in-memory ReplayCache that drops keys once they expire. The
+// zero value is ready to use.
+type MemoryReplayCache struct {
+	// Now defaults to time.Now
+	Now func() time.Time
+
+	mu      sync.Mutex
+	expires map[string]time.Time
+	pruned  time.Time
+}
+
+// NewMemoryReplayCache creates an empty replay cache
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{expires: make(map[string]time.Time)}
+}
+
+// Seen implements ReplayCache
+func (c *MemoryReplayCache) Seen(key string, expires time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Deliveries expire after the tolerance, so pruning once a minute bounds the cache
+	// to the deliveries of the last tolerance plus a minute
+	now := time.Now()
+	if c.Now != nil {
+		now = c.Now()
+	}
+	if now.Sub(c.pruned) > time.Minute {
+		for seen, until := range c.expires {
+			if now.After(until) {
+				delete(c.expires, seen)
+			}
+		}
+		c.pruned = now
+	}
+
+	if _, ok := c.expires[key]; ok {
+		return true
+	}
+	if c.expires == nil {
+		c.expires = make(map[string]time.Time)
+	}
+	c.expires[key] = expires
+	return false
+}
+
+// newID returns a random delivery ID
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "msg_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,102 @@
+package webhook_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rizome-dev/go-openrouter/pkg/webhook"
+)
+
+var (
+	secret = []byte("0123456789abcdef0123456789abcdef")
+	body   = []byte(`{"id":"job-1","status":"completed"}`)
+)
+
+func fixedClock(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	signer := webhook.NewSigner(secret)
+	signer.Now = fixedClock(now)
+	verifier := webhook.NewVerifier(secret)
+	verifier.Now = fixedClock(now.Add(time.Minute))
+
+	header := signer.HeadersWithID("msg_1", body)
+	assert.Equal(t, "msg_1", header.Get(webhook.IDHeader))
+	assert.Equal(t, "1700000000", header.Get(webhook.TimestampHeader))
+	assert.Equal(t, webhook.Signature(secret, "msg_1", now, body), header.Get(webhook.SignatureHeader))
+	require.NoError(t, verifier.Verify(header, body))
+
+	// The same request again is a replay, but a retry is signed afresh
+	assert.ErrorIs(t, verifier.Verify(header, body), webhook.ErrReplayed)
+	signer.Now = fixedClock(now.Add(30 * time.Second))
+	assert.NoError(t, verifier.Verify(signer.HeadersWithID("msg_1", body), body))
+
+	tampered := signer.Headers(body)
+	assert.ErrorIs(t, verifier.Verify(tampered, []byte(`{"id":"job-1","status":"failed"}`)), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, verifier.Verify(http.Header{}, body), webhook.ErrMissingSignature)
+
+	forged := webhook.NewSigner([]byte("another secret"))
+	forged.Now = signer.Now
+	assert.ErrorIs(t, verifier.Verify(forged.Headers(body), body), webhook.ErrInvalidSignature)
+}
+
+func TestVerifyTimestampTolerance(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	signer := webhook.NewSigner(secret)
+	verifier := webhook.NewVerifier(secret)
+	verifier.Now = fixedClock(now)
+
+	for _, skew := range []time.Duration{-6 * time.Minute, 6 * time.Minute} {
+		signer.Now = fixedClock(now.Add(skew))
+		assert.ErrorIs(t, verifier.Verify(signer.Headers(body), body), webhook.ErrTimestampOutOfRange, "skew %s", skew)
+	}
+
+	verifier.Tolerance = 10 * time.Minute
+	assert.NoError(t, verifier.Verify(signer.Headers(body), body))
+}
+
+func TestVerifySecretRotation(t *testing.T) {
+	newSecret := []byte("fedcba9876543210fedcba9876543210")
+	verifier := webhook.NewVerifier(newSecret, secret)
+
+	assert.NoError(t, verifier.Verify(webhook.NewSigner(secret).Headers(body), body))
+	assert.NoError(t, verifier.Verify(webhook.NewSigner(newSecret).Headers(body), body))
+
+	// Senders may send several signatures while rotating
+	header := webhook.NewSigner(newSecret).HeadersWithID("msg_2", body)
+	header.Set(webhook.SignatureHeader, "v1,bogus "+header.Get(webhook.SignatureHeader))
+	assert.NoError(t, webhook.NewVerifier(newSecret).Verify(header, body))
+}
+
+func TestMiddleware(t *testing.T) {
+	var received string
+	handler := webhook.NewVerifier(secret).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		received = string(data)
+	}))
+
+	post := func(sign bool) int {
+		req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(string(body)))
+		if sign {
+			webhook.NewSigner(secret).SignRequest(req, body)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post(false))
+	assert.Empty(t, received)
+	assert.Equal(t, http.StatusOK, post(true))
+	assert.Equal(t, string(body), received, "the handler can read the verified body")
+}